@@ -11,3 +11,8 @@ package api
 
 // @host      master.swat4stats.com
 // @BasePath  /api/
+
+// @securityDefinitions.apikey  AdminToken
+// @in                          header
+// @name                        Authorization
+// @description                 Bearer token required by the admin endpoints
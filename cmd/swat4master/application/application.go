@@ -1,18 +1,30 @@
 package application
 
 import (
+	"context"
+
 	"github.com/jonboulle/clockwork"
 	"go.uber.org/fx"
 
 	"github.com/sergeii/swat4master/cmd/swat4master/components/exporter"
 	"github.com/sergeii/swat4master/cmd/swat4master/container"
 	"github.com/sergeii/swat4master/cmd/swat4master/logging"
+	"github.com/sergeii/swat4master/cmd/swat4master/scheduling"
 	"github.com/sergeii/swat4master/internal/core/repositories"
+	"github.com/sergeii/swat4master/internal/geoip"
 	"github.com/sergeii/swat4master/internal/metrics"
 	"github.com/sergeii/swat4master/internal/persistence/redis/redislock"
+	"github.com/sergeii/swat4master/internal/persistence/redis/repositories/featuredservers"
+	"github.com/sergeii/swat4master/internal/persistence/redis/repositories/histories"
 	"github.com/sergeii/swat4master/internal/persistence/redis/repositories/instances"
+	"github.com/sergeii/swat4master/internal/persistence/redis/repositories/jobruns"
+	"github.com/sergeii/swat4master/internal/persistence/redis/repositories/matches"
+	"github.com/sergeii/swat4master/internal/persistence/redis/repositories/portoffsets"
 	"github.com/sergeii/swat4master/internal/persistence/redis/repositories/probes"
+	"github.com/sergeii/swat4master/internal/persistence/redis/repositories/profiles"
 	"github.com/sergeii/swat4master/internal/persistence/redis/repositories/servers"
+	"github.com/sergeii/swat4master/internal/persistence/redis/repositories/sessions"
+	"github.com/sergeii/swat4master/internal/settings"
 	"github.com/sergeii/swat4master/internal/validation"
 )
 
@@ -22,18 +34,59 @@ type Repositories struct {
 	Servers   repositories.ServerRepository
 	Instances repositories.InstanceRepository
 	Probes    repositories.ProbeRepository
+	Featured  repositories.FeaturedRepository
+	Offsets   repositories.PortOffsetRepository
+	JobRuns   repositories.JobRunRepository
+	Sessions  repositories.SessionRepository
+	Matches   repositories.MatchRepository
+	History   repositories.HistoryRepository
+	Profiles  repositories.ProfileRepository
 }
 
 func provideRepositories(
 	serverRepo *servers.Repository,
 	instanceRepo *instances.Repository,
 	probeRepo *probes.Repository,
+	featuredRepo *featuredservers.Repository,
+	offsetRepo *portoffsets.Repository,
+	jobRunRepo *jobruns.Repository,
+	sessionRepo *sessions.Repository,
+	matchRepo *matches.Repository,
+	historyRepo *histories.Repository,
+	profileRepo *profiles.Repository,
 ) Repositories {
 	return Repositories{
 		Servers:   serverRepo,
 		Instances: instanceRepo,
 		Probes:    probeRepo,
+		Featured:  featuredRepo,
+		Offsets:   offsetRepo,
+		JobRuns:   jobRunRepo,
+		Sessions:  sessionRepo,
+		Matches:   matchRepo,
+		History:   historyRepo,
+		Profiles:  profileRepo,
+	}
+}
+
+// provideLocator opens the GeoIP databases, if any are configured.
+// With no databases, the servers are left with no location.
+func provideLocator(cfg settings.Settings, lc fx.Lifecycle) (geoip.Locator, error) {
+	reader, err := geoip.New(geoip.Config{
+		CountryDB: cfg.GeoIPCountryDB,
+		ASNDB:     cfg.GeoIPASNDB,
+	})
+	if err != nil {
+		return nil, err
 	}
+
+	lc.Append(fx.Hook{
+		OnStop: func(context.Context) error {
+			return reader.Close()
+		},
+	})
+
+	return reader, nil
 }
 
 type Builder struct {
@@ -66,8 +119,13 @@ var Module = fx.Module("application",
 	fx.Provide(clockwork.NewRealClock),
 	fx.Provide(validation.New),
 	fx.Provide(redislock.NewManager),
-	fx.Provide(servers.New, instances.New, probes.New),
+	fx.Provide(
+		servers.New, instances.New, probes.New, featuredservers.New, portoffsets.New, jobruns.New, sessions.New,
+		matches.New, histories.New, profiles.New,
+	),
 	fx.Provide(provideRepositories),
+	fx.Provide(provideLocator),
 	fx.Provide(metrics.New),
+	fx.Provide(scheduling.Provide),
 	container.Module,
 )
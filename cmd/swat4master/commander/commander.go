@@ -22,19 +22,33 @@ type Globals struct {
 	ExporterHTTPShutdownTimeout time.Duration `default:"10s"   help:"The amount of time the server will wait gracefully closing connections before exiting"` //nolint:lll
 
 	BrowsingServerLiveness time.Duration `default:"3m" help:"Determines the maximum time a game server can remain unseen before being considered offline"` //nolint:lll
+	BrowsingHideUnverified bool          `help:"Hides the servers never reached by a probe or reporting info that disagrees with their queries"`           //nolint:lll
+
+	GeoIPCountryDB string `help:"Path to a MaxMind DB file used to resolve the country of game servers (e.g., GeoLite2-Country.mmdb)"`       //nolint:lll
+	GeoIPASNDB     string `help:"Path to a MaxMind DB file used to resolve the autonomous system of game servers (e.g., GeoLite2-ASN.mmdb)"` //nolint:lll
 
 	DiscoveryRefreshInterval time.Duration `default:"5s" help:"Sets how frequently game server details are refreshed"`
 	DiscoveryRefreshRetries  int           `default:"4"  help:"Specifies how many times a failed server details refresh should be retried"` //nolint:lll
 
+	DiscoveryRefreshMinInterval time.Duration `default:"5s" help:"Sets the shortest interval between refreshes of a busy server's details"` //nolint:lll
+	DiscoveryRefreshMaxInterval time.Duration `default:"2m" help:"Sets the longest interval between refreshes of an idle server's details"` //nolint:lll
+
 	DiscoveryRevivalInterval  time.Duration `default:"10m"     help:"Sets how often delisted servers are checked for possible revival"`                            //nolint:lll
 	DiscoveryRevivalScope     time.Duration `default:"1h"      help:"Limits how long a delisted server remains eligible for revival after it was last seen"`       //nolint:lll
 	DiscoveryRevivalCountdown time.Duration `default:"5m"      help:"Sets the maximum random delay to stagger revival probes"`                                     //nolint:lll
 	DiscoveryRevivalPorts     []int         `default:"1,2,3,4" help:"Defines port offsets to probe when searching for a server's query port (e.g., +1, +2, etc.)"` //nolint:lll
 	DiscoveryRevivalRetries   int           `default:"2"       help:"Sets how many times a failed revival probe should be retried"`                                //nolint:lll
 
+	DiscoveryRevivalFallbackPorts      []int `help:"Defines port offsets to probe when none of the revival port offsets has answered (e.g., 5,6,...,20)"` //nolint:lll
+	DiscoveryRevivalFallbackQueryPorts []int `help:"Defines query ports to probe when none of the revival port offsets has answered (e.g., 10481)"`       //nolint:lll
+
 	ProbePollSchedule time.Duration `default:"250ms" help:"Determines how often the system checks for pending discovery probes"`          //nolint:lll
 	ProbeTimeout      time.Duration `default:"1s"    help:"Sets the maximum time to wait for a response from a discovery probe"`          //nolint:lll
 	ProbeConcurrency  int           `default:"25"    help:"Specifies the maximum number of discovery probes that can run simultaneously"` //nolint:lll
+
+	ProbeMinConcurrency int           `default:"5"  help:"Specifies the number of discovery workers kept running when the probe queue is quiet"`   //nolint:lll
+	ProbeScaleInterval  time.Duration `default:"5s" help:"Sets how often the number of discovery workers is adjusted to the probe queue load"`     //nolint:lll
+	ProbeQuerySockets   int           `default:"4"  help:"Sets the number of sockets shared by server queries (0 opens a socket for every query)"` //nolint:lll
 }
 
 type VersionCmd struct{}
@@ -52,6 +66,8 @@ type RunCmd struct {
 
 type CLI struct {
 	Globals
+	// Plugins are the one-shot commands that run outside the application
+	kong.Plugins
 
 	Version VersionCmd `cmd:"" help:"Display the app version and exit"`
 	Run     RunCmd     `cmd:""`
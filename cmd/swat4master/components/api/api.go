@@ -22,6 +22,7 @@ type Config struct {
 	HTTPReadTimeout     time.Duration
 	HTTPWriteTimeout    time.Duration
 	HTTPShutdownTimeout time.Duration
+	HTTPAdminToken      string
 }
 
 type Component struct{}
@@ -82,6 +83,7 @@ type command struct {
 	HTTPReadTimeout     time.Duration `default:"5s"    help:"Sets the maximum duration to write a response before timing out"`                  //nolint:lll
 	HTTPWriteTimeout    time.Duration `default:"5s"    help:"Sets the maximum duration to write a response after reading the request body"`     //nolint:lll
 	HTTPShutdownTimeout time.Duration `default:"10s"   help:"Defines how long the server waits to gracefully close connections before exiting"` //nolint:lll
+	HTTPAdminToken      string        `help:"Sets the bearer token required by the admin endpoints. The endpoints are disabled unless set"`     //nolint:lll
 }
 
 func (c *command) Run(_ *commander.Globals, builder *application.Builder) error {
@@ -93,6 +95,7 @@ func (c *command) Run(_ *commander.Globals, builder *application.Builder) error
 					HTTPReadTimeout:     c.HTTPReadTimeout,
 					HTTPWriteTimeout:    c.HTTPWriteTimeout,
 					HTTPShutdownTimeout: c.HTTPShutdownTimeout,
+					HTTPAdminToken:      c.HTTPAdminToken,
 				},
 			),
 			Module,
@@ -115,7 +118,14 @@ type CLI struct {
 	API command `cmd:"" help:"Start API server"`
 }
 
+func provideAPIOpts(cfg Config) api.Opts {
+	return api.Opts{
+		AdminToken: cfg.HTTPAdminToken,
+	}
+}
+
 var Module = fx.Module("api",
+	fx.Provide(fx.Private, provideAPIOpts),
 	fx.Provide(fx.Private, api.New),
 	fx.Provide(rest.NewRouter),
 	fx.Provide(New),
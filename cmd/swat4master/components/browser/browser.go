@@ -18,6 +18,11 @@ import (
 type Config struct {
 	ListenAddr    string
 	ClientTimeout time.Duration
+	ListingPolicy browser.ListingPolicy
+	ListingOrder  browser.ListingOrder
+	ListingLimit  int
+	HideFederated bool
+	Announcements string
 }
 
 type Component struct{}
@@ -74,7 +79,12 @@ func New(
 
 type command struct {
 	BrowserListenAddr    string        `default:":28910" help:"Sets the listen address for the browser TCP server"`
-	BrowserClientTimeout time.Duration `default:"1s"     help:"Sets the maximum duration before an accepted connection times out"` //nolint:lll
+	BrowserClientTimeout time.Duration `default:"1s"     help:"Sets the maximum duration before an accepted connection times out"`                                             //nolint:lll
+	BrowserListingPolicy string        `default:"reported" enum:"reported,discovered,both" help:"Selects the servers listed in-game: reporting, discovered or both"`           //nolint:lll
+	BrowserListingOrder  string        `default:"none"     enum:"none,nearest"             help:"Orders the servers listed in-game: as stored or nearest to the player first"` //nolint:lll
+	BrowserListingLimit  int           `default:"0"        help:"Caps the number of servers listed in-game, featured servers included (0 means no limit)"`                     //nolint:lll
+	BrowserHideFederated bool          `default:"false"    help:"Leaves the servers imported from upstream master servers out of the in-game server list"`                     //nolint:lll
+	BrowserAnnouncements string        `type:"path"        help:"Path to a JSON file with announcement entries injected into the in-game server list"`                         //nolint:lll
 }
 
 func (c *command) Run(_ *commander.Globals, builder *application.Builder) error {
@@ -83,6 +93,11 @@ func (c *command) Run(_ *commander.Globals, builder *application.Builder) error
 			fx.Supply(Config{
 				ListenAddr:    c.BrowserListenAddr,
 				ClientTimeout: c.BrowserClientTimeout,
+				ListingPolicy: browser.ListingPolicy(c.BrowserListingPolicy),
+				ListingOrder:  browser.ListingOrder(c.BrowserListingOrder),
+				ListingLimit:  c.BrowserListingLimit,
+				HideFederated: c.BrowserHideFederated,
+				Announcements: c.BrowserAnnouncements,
 			}),
 			Module,
 			fx.Invoke(func(_ *Component) {}),
@@ -100,10 +115,20 @@ type CLI struct {
 var Module = fx.Module("browser",
 	fx.Provide(
 		fx.Private,
-		func(settings settings.Settings) browser.HandlerOpts {
-			return browser.HandlerOpts{
-				Liveness: settings.ServerLiveness,
+		func(settings settings.Settings, cfg Config) (browser.HandlerOpts, error) {
+			announcements, err := browser.LoadAnnouncements(cfg.Announcements)
+			if err != nil {
+				return browser.HandlerOpts{}, err
 			}
+			return browser.HandlerOpts{
+				Liveness:       settings.ServerLiveness,
+				Policy:         cfg.ListingPolicy,
+				Order:          cfg.ListingOrder,
+				Limit:          cfg.ListingLimit,
+				HideUnverified: settings.HideUnverified,
+				HideFederated:  cfg.HideFederated,
+				Announcements:  announcements,
+			}, nil
 		},
 	),
 	fx.Provide(fx.Private, browser.NewHandler),
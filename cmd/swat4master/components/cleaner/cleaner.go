@@ -4,7 +4,6 @@ import (
 	"context"
 	"time"
 
-	"github.com/jonboulle/clockwork"
 	"github.com/rs/zerolog"
 	"go.uber.org/fx"
 
@@ -13,69 +12,49 @@ import (
 	"github.com/sergeii/swat4master/internal/cleanup"
 	"github.com/sergeii/swat4master/internal/cleanup/cleaners/instancecleaner"
 	"github.com/sergeii/swat4master/internal/cleanup/cleaners/servercleaner"
+	"github.com/sergeii/swat4master/internal/scheduler"
 )
 
 type Config struct {
 	CleanRetention time.Duration
 	CleanInterval  time.Duration
+	// CleanSchedule is the cron schedule that overrides the interval, if set
+	CleanSchedule string
 }
 
 type Component struct{}
 
-func run(
-	stop chan struct{},
-	stopped chan struct{},
-	clock clockwork.Clock,
-	logger *zerolog.Logger,
-	manager *cleanup.Manager,
-	cfg Config,
-) {
-	ticker := clock.NewTicker(cfg.CleanInterval)
-	tickerCh := ticker.Chan()
-	defer ticker.Stop()
-
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	logger.Info().
-		Dur("interval", cfg.CleanInterval).Dur("retention", cfg.CleanRetention).
-		Msg("Starting cleaner")
-
-	for {
-		select {
-		case <-stop:
-			close(stopped)
-			return
-		case <-tickerCh:
-			manager.Clean(ctx)
-		}
-	}
-}
-
 func New(
-	lc fx.Lifecycle,
 	cfg Config,
-	clock clockwork.Clock,
+	sched *scheduler.Scheduler,
 	manager *cleanup.Manager,
 	logger *zerolog.Logger,
-) *Component {
-	stopped := make(chan struct{})
-	stop := make(chan struct{})
+) (*Component, error) {
+	var schedule scheduler.Schedule = scheduler.Every(cfg.CleanInterval)
+	if cfg.CleanSchedule != "" {
+		var err error
+		if schedule, err = scheduler.Parse(cfg.CleanSchedule); err != nil {
+			return nil, err
+		}
+	}
 
-	lc.Append(fx.Hook{
-		OnStart: func(context.Context) error {
-			go run(stop, stopped, clock, logger, manager, cfg) //nolint: contextcheck
-			return nil
-		},
-		OnStop: func(context.Context) error {
-			close(stop)
-			<-stopped
-			logger.Info().Msg("Cleaner stopped")
+	job := scheduler.Job{
+		Name:     "cleaner",
+		Schedule: schedule,
+		Run: func(ctx context.Context) error {
+			manager.Clean(ctx)
 			return nil
 		},
-	})
+	}
+	if err := sched.Add(job); err != nil {
+		return nil, err
+	}
+
+	logger.Info().
+		Dur("interval", cfg.CleanInterval).Str("schedule", cfg.CleanSchedule).Dur("retention", cfg.CleanRetention).
+		Msg("Starting cleaner")
 
-	return &Component{}
+	return &Component{}, nil
 }
 
 type Opts struct {
@@ -99,6 +78,7 @@ func provideCleanerConfigs(cfg Config) Opts {
 type command struct {
 	CleanRetention time.Duration `default:"1h"  help:"Sets how long a game server is kept after going offline"`
 	CleanInterval  time.Duration `default:"10m" help:"Sets how often offline servers are cleaned up"`
+	CleanSchedule  string        `help:"Sets a cron schedule for offline server cleanup that overrides the interval (e.g., '0 * * * *')"` //nolint:lll
 }
 
 func (c *command) Run(_ *commander.Globals, builder *application.Builder) error {
@@ -107,6 +87,7 @@ func (c *command) Run(_ *commander.Globals, builder *application.Builder) error
 			fx.Supply(Config{
 				CleanRetention: c.CleanRetention,
 				CleanInterval:  c.CleanInterval,
+				CleanSchedule:  c.CleanSchedule,
 			}),
 			Module,
 			fx.Invoke(func(_ *Component) {}),
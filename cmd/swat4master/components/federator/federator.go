@@ -0,0 +1,121 @@
+package federator
+
+import (
+	"context"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/rs/zerolog"
+	"go.uber.org/fx"
+
+	"github.com/sergeii/swat4master/cmd/swat4master/application"
+	"github.com/sergeii/swat4master/cmd/swat4master/commander"
+	"github.com/sergeii/swat4master/internal/federation"
+)
+
+type Config struct {
+	Upstreams []string
+	Interval  time.Duration
+	Timeout   time.Duration
+}
+
+type Component struct{}
+
+func run(
+	stop chan struct{},
+	stopped chan struct{},
+	clock clockwork.Clock,
+	logger *zerolog.Logger,
+	federator *federation.Federator,
+	cfg Config,
+) {
+	ticker := clock.NewTicker(cfg.Interval)
+	tickerCh := ticker.Chan()
+	defer ticker.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger.Info().
+		Strs("upstreams", cfg.Upstreams).Dur("interval", cfg.Interval).Dur("timeout", cfg.Timeout).
+		Msg("Starting federator")
+
+	// do not keep the upstream servers waiting until the first tick
+	federator.Federate(ctx)
+
+	for {
+		select {
+		case <-stop:
+			close(stopped)
+			return
+		case <-tickerCh:
+			federator.Federate(ctx)
+		}
+	}
+}
+
+func New(
+	lc fx.Lifecycle,
+	cfg Config,
+	clock clockwork.Clock,
+	federator *federation.Federator,
+	logger *zerolog.Logger,
+) *Component {
+	stopped := make(chan struct{})
+	stop := make(chan struct{})
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go run(stop, stopped, clock, logger, federator, cfg) //nolint: contextcheck
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			close(stop)
+			<-stopped
+			logger.Info().Msg("Federator stopped")
+			return nil
+		},
+	})
+
+	return &Component{}
+}
+
+func provideFederatorOpts(cfg Config) federation.Opts {
+	return federation.Opts{
+		Upstreams: cfg.Upstreams,
+		Timeout:   cfg.Timeout,
+	}
+}
+
+type command struct {
+	FederationUpstreams []string      `help:"Defines the addresses of upstream master servers to import game servers from (e.g., master.example.com:28910)"` //nolint:lll
+	FederationInterval  time.Duration `default:"5m" help:"Sets how often upstream master servers are queried for their server lists"`                        //nolint:lll
+	FederationTimeout   time.Duration `default:"5s" help:"Sets the maximum time to wait for a server list from an upstream master server"`                   //nolint:lll
+}
+
+func (c *command) Run(_ *commander.Globals, builder *application.Builder) error {
+	app := builder.
+		Add(
+			fx.Supply(Config{
+				Upstreams: c.FederationUpstreams,
+				Interval:  c.FederationInterval,
+				Timeout:   c.FederationTimeout,
+			}),
+			Module,
+			fx.Invoke(func(_ *Component) {}),
+		).
+		WithExporter().
+		Build()
+	app.Run()
+	return nil
+}
+
+type CLI struct {
+	Federator command `cmd:"" help:"Start federator"`
+}
+
+var Module = fx.Module("federator",
+	fx.Provide(fx.Private, provideFederatorOpts),
+	fx.Provide(fx.Private, federation.New),
+	fx.Provide(New),
+)
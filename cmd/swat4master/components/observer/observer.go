@@ -4,7 +4,6 @@ import (
 	"context"
 	"time"
 
-	"github.com/jonboulle/clockwork"
 	"github.com/rs/zerolog"
 	"go.uber.org/fx"
 
@@ -14,6 +13,7 @@ import (
 	"github.com/sergeii/swat4master/internal/metrics/observers/instanceobserver"
 	"github.com/sergeii/swat4master/internal/metrics/observers/probeobserver"
 	"github.com/sergeii/swat4master/internal/metrics/observers/serverobserver"
+	"github.com/sergeii/swat4master/internal/scheduler"
 	"github.com/sergeii/swat4master/internal/settings"
 )
 
@@ -23,58 +23,27 @@ type Config struct {
 
 type Component struct{}
 
-func run(
-	stop chan struct{},
-	stopped chan struct{},
-	clock clockwork.Clock,
-	logger *zerolog.Logger,
-	collector *metrics.Collector,
-	cfg Config,
-) {
-	ticker := clock.NewTicker(cfg.ObserveInterval)
-	tickerCh := ticker.Chan()
-	defer ticker.Stop()
-
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	logger.Info().Dur("interval", cfg.ObserveInterval).Msg("Starting observer")
-
-	for {
-		select {
-		case <-stop:
-			close(stopped)
-			return
-		case <-tickerCh:
-			collector.Observe(ctx)
-		}
-	}
-}
-
 func New(
-	lc fx.Lifecycle,
 	cfg Config,
-	clock clockwork.Clock,
+	sched *scheduler.Scheduler,
 	collector *metrics.Collector,
 	logger *zerolog.Logger,
-) *Component {
-	stopped := make(chan struct{})
-	stop := make(chan struct{})
-
-	lc.Append(fx.Hook{
-		OnStart: func(context.Context) error {
-			go run(stop, stopped, clock, logger, collector, cfg) //nolint: contextcheck
-			return nil
-		},
-		OnStop: func(context.Context) error {
-			close(stop)
-			<-stopped
-			logger.Info().Msg("Observer stopped")
+) (*Component, error) {
+	job := scheduler.Job{
+		Name:     "observer",
+		Schedule: scheduler.Every(cfg.ObserveInterval),
+		Run: func(ctx context.Context) error {
+			collector.Observe(ctx)
 			return nil
 		},
-	})
+	}
+	if err := sched.Add(job); err != nil {
+		return nil, err
+	}
+
+	logger.Info().Dur("interval", cfg.ObserveInterval).Msg("Starting observer")
 
-	return &Component{}
+	return &Component{}, nil
 }
 
 type command struct {
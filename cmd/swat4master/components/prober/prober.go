@@ -10,17 +10,29 @@ import (
 	"github.com/sergeii/swat4master/cmd/swat4master/application"
 	"github.com/sergeii/swat4master/cmd/swat4master/commander"
 	"github.com/sergeii/swat4master/internal/core/entities/probe"
+	"github.com/sergeii/swat4master/internal/core/entities/server"
 	"github.com/sergeii/swat4master/internal/prober/probers"
 	"github.com/sergeii/swat4master/internal/prober/probers/detailsprober"
 	"github.com/sergeii/swat4master/internal/prober/probers/portprober"
 	"github.com/sergeii/swat4master/internal/prober/proberunner"
+	"github.com/sergeii/swat4master/pkg/udp/udpclient"
 )
 
 type Config struct {
-	PollInterval time.Duration
-	Concurrency  int
-	ProbeTimeout time.Duration
-	PortOffsets  []int
+	PollInterval   time.Duration
+	Concurrency    int
+	MinConcurrency int
+	ScaleInterval  time.Duration
+	ProbeTimeout   time.Duration
+	PortOffsets    []int
+	// FallbackOffsets and FallbackPorts are tried when none of the port offsets has answered
+	FallbackOffsets []int
+	FallbackPorts   []int
+	// QuerySockets is the number of sockets shared by the queries, with zero every query dials its own socket
+	QuerySockets int
+
+	RefreshMinInterval time.Duration
+	RefreshMaxInterval time.Duration
 }
 
 type Component struct{}
@@ -38,6 +50,7 @@ func run(
 	logger.Info().
 		Dur("interval", cfg.PollInterval).
 		Int("concurrency", cfg.Concurrency).
+		Int("min_concurrency", cfg.MinConcurrency).
 		Dur("timeout", cfg.ProbeTimeout).
 		Msg("Starting prober")
 
@@ -78,10 +91,18 @@ func (c *command) Run(globals *commander.Globals, builder *application.Builder)
 	app := builder.
 		Add(
 			fx.Supply(Config{
-				PollInterval: globals.ProbePollSchedule,
-				Concurrency:  globals.ProbeConcurrency,
-				ProbeTimeout: globals.ProbeTimeout,
-				PortOffsets:  globals.DiscoveryRevivalPorts,
+				PollInterval:    globals.ProbePollSchedule,
+				Concurrency:     globals.ProbeConcurrency,
+				MinConcurrency:  globals.ProbeMinConcurrency,
+				ScaleInterval:   globals.ProbeScaleInterval,
+				ProbeTimeout:    globals.ProbeTimeout,
+				PortOffsets:     globals.DiscoveryRevivalPorts,
+				FallbackOffsets: globals.DiscoveryRevivalFallbackPorts,
+				FallbackPorts:   globals.DiscoveryRevivalFallbackQueryPorts,
+				QuerySockets:    globals.ProbeQuerySockets,
+
+				RefreshMinInterval: globals.DiscoveryRefreshMinInterval,
+				RefreshMaxInterval: globals.DiscoveryRefreshMaxInterval,
 			}),
 			Module,
 			fx.Invoke(func(_ *Component) {}),
@@ -98,21 +119,53 @@ type CLI struct {
 
 func provideRunnerOpts(cfg Config) proberunner.RunnerOpts {
 	return proberunner.RunnerOpts{
-		PollInterval: cfg.PollInterval,
-		Concurrency:  cfg.Concurrency,
-		ProbeTimeout: cfg.ProbeTimeout,
+		PollInterval:   cfg.PollInterval,
+		Concurrency:    cfg.Concurrency,
+		MinConcurrency: cfg.MinConcurrency,
+		ScaleInterval:  cfg.ScaleInterval,
+		ProbeTimeout:   cfg.ProbeTimeout,
 	}
 }
 
 func providePortProberOpts(cfg Config) portprober.Opts {
 	return portprober.Opts{
-		Offsets: cfg.PortOffsets,
+		Offsets:         cfg.PortOffsets,
+		FallbackOffsets: cfg.FallbackOffsets,
+		FallbackPorts:   cfg.FallbackPorts,
 	}
 }
 
+func provideDetailsProberOpts(cfg Config) detailsprober.Opts {
+	return detailsprober.Opts{
+		RefreshPolicy: server.RefreshPolicy{
+			MinInterval: cfg.RefreshMinInterval,
+			MaxInterval: cfg.RefreshMaxInterval,
+		},
+	}
+}
+
+func provideDialer(lc fx.Lifecycle, cfg Config, logger *zerolog.Logger) (udpclient.Dialer, error) {
+	if cfg.QuerySockets <= 0 {
+		return udpclient.Direct{}, nil
+	}
+	mux, err := udpclient.NewMux(cfg.QuerySockets)
+	if err != nil {
+		return nil, err
+	}
+	lc.Append(fx.StopHook(func() {
+		mux.Close()
+		if readErrors := mux.ReadErrors(); readErrors > 0 {
+			logger.Warn().Uint64("errors", readErrors).Msg("Query sockets failed to read replies")
+		}
+	}))
+	return mux, nil
+}
+
 var Module = fx.Module("prober",
+	fx.Provide(fx.Private, provideDialer),
 	fx.Provide(fx.Private, provideRunnerOpts),
 	fx.Provide(fx.Private, providePortProberOpts),
+	fx.Provide(fx.Private, provideDetailsProberOpts),
 	fx.Provide(
 		portprober.New,
 		detailsprober.New,
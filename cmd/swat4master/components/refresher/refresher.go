@@ -2,6 +2,7 @@ package refresher
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/jonboulle/clockwork"
@@ -11,6 +12,7 @@ import (
 	"github.com/sergeii/swat4master/cmd/swat4master/application"
 	"github.com/sergeii/swat4master/cmd/swat4master/commander"
 	"github.com/sergeii/swat4master/internal/core/usecases/refreshservers"
+	"github.com/sergeii/swat4master/internal/scheduler"
 )
 
 type Config struct {
@@ -19,57 +21,27 @@ type Config struct {
 
 type Component struct{}
 
-func run(
-	stop chan struct{},
-	stopped chan struct{},
-	clock clockwork.Clock,
-	logger *zerolog.Logger,
-	uc refreshservers.UseCase,
-	cfg Config,
-) {
-	refresher := clock.NewTicker(cfg.RefreshInterval)
-	defer refresher.Stop()
-
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	logger.Info().Dur("interval", cfg.RefreshInterval).Msg("Starting refresher")
-
-	for {
-		select {
-		case <-stop:
-			close(stopped)
-			return
-		case <-refresher.Chan():
-			refresh(ctx, clock, logger, uc, cfg)
-		}
-	}
-}
-
 func New(
-	lc fx.Lifecycle,
 	cfg Config,
+	sched *scheduler.Scheduler,
 	clock clockwork.Clock,
 	uc refreshservers.UseCase,
 	logger *zerolog.Logger,
-) *Component {
-	stopped := make(chan struct{})
-	stop := make(chan struct{})
-
-	lc.Append(fx.Hook{
-		OnStart: func(context.Context) error {
-			go run(stop, stopped, clock, logger, uc, cfg) //nolint: contextcheck
-			return nil
-		},
-		OnStop: func(context.Context) error {
-			close(stop)
-			<-stopped
-			logger.Info().Msg("Refresher stopped")
-			return nil
+) (*Component, error) {
+	job := scheduler.Job{
+		Name:     "refresher",
+		Schedule: scheduler.Every(cfg.RefreshInterval),
+		Run: func(ctx context.Context) error {
+			return refresh(ctx, clock, logger, uc, cfg)
 		},
-	})
+	}
+	if err := sched.Add(job); err != nil {
+		return nil, err
+	}
 
-	return &Component{}
+	logger.Info().Dur("interval", cfg.RefreshInterval).Msg("Starting refresher")
+
+	return &Component{}, nil
 }
 
 func refresh(
@@ -78,15 +50,15 @@ func refresh(
 	logger *zerolog.Logger,
 	uc refreshservers.UseCase,
 	cfg Config,
-) {
+) error {
+	now := clock.Now()
 	// make sure the probes don't run beyond the next cycle of discovery
-	deadline := clock.Now().Add(cfg.RefreshInterval)
+	deadline := now.Add(cfg.RefreshInterval)
 
-	ucRequest := refreshservers.NewRequest(deadline)
+	ucRequest := refreshservers.NewRequest(now, deadline)
 	result, err := uc.Execute(ctx, ucRequest)
 	if err != nil {
-		logger.Warn().Err(err).Msg("Unable to refresh details for servers")
-		return
+		return fmt.Errorf("unable to refresh details for servers: %w", err)
 	}
 
 	if result.Count > 0 {
@@ -94,6 +66,8 @@ func refresh(
 	} else {
 		logger.Debug().Msg("Added no servers to refresh queue")
 	}
+
+	return nil
 }
 
 type command struct{}
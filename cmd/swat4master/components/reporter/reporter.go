@@ -2,23 +2,35 @@ package reporter
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"time"
 
+	"github.com/jonboulle/clockwork"
 	"github.com/rs/zerolog"
 	"go.uber.org/fx"
 
 	"github.com/sergeii/swat4master/cmd/swat4master/application"
 	"github.com/sergeii/swat4master/cmd/swat4master/commander"
+	"github.com/sergeii/swat4master/internal/metrics"
 	"github.com/sergeii/swat4master/internal/reporter"
 	"github.com/sergeii/swat4master/internal/reporter/handlers/available"
 	"github.com/sergeii/swat4master/internal/reporter/handlers/challenge"
 	"github.com/sergeii/swat4master/internal/reporter/handlers/heartbeat"
 	"github.com/sergeii/swat4master/internal/reporter/handlers/keepalive"
+	"github.com/sergeii/swat4master/internal/reporter/relay"
 	"github.com/sergeii/swat4master/pkg/udp/udpserver"
 )
 
 type Config struct {
 	ListenAddr string
 	BufferSize int
+
+	RelayPeers      []string
+	RelaySources    []string
+	RelayQueueSize  int
+	RelayMinBackoff time.Duration
+	RelayMaxBackoff time.Duration
 }
 
 type Component struct{}
@@ -72,17 +84,72 @@ func New(
 	return &Component{}, nil
 }
 
+func provideRelay(
+	lc fx.Lifecycle,
+	cfg Config,
+	metrics *metrics.Collector,
+	clock clockwork.Clock,
+	logger *zerolog.Logger,
+) *relay.Relay {
+	rel := relay.New(
+		relay.Opts{
+			Peers:      cfg.RelayPeers,
+			QueueSize:  cfg.RelayQueueSize,
+			MinBackoff: cfg.RelayMinBackoff,
+			MaxBackoff: cfg.RelayMaxBackoff,
+		},
+		metrics,
+		clock,
+		logger,
+	)
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			rel.Start()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			rel.Stop()
+			return nil
+		},
+	})
+
+	return rel
+}
+
+func provideDispatcherOpts(cfg Config) (reporter.DispatcherOpts, error) {
+	sources := make([]net.IP, 0, len(cfg.RelaySources))
+	for _, source := range cfg.RelaySources {
+		ip := net.ParseIP(source)
+		if ip == nil {
+			return reporter.DispatcherOpts{}, fmt.Errorf("invalid relay source address '%s'", source)
+		}
+		sources = append(sources, ip)
+	}
+	return reporter.DispatcherOpts{RelaySources: sources}, nil
+}
+
 type command struct {
-	ReporterListenAddr string `default:":27900" help:"Sets the listen address for the reporter UDP server"`
-	ReporterBufferSize int    `default:"2048"   help:"Sets the UDP buffer size for incoming packets"`
+	ReporterListenAddr      string        `default:":27900" help:"Sets the listen address for the reporter UDP server"`
+	ReporterBufferSize      int           `default:"2048"   help:"Sets the UDP buffer size for incoming packets"`
+	ReporterRelayPeers      []string      `help:"Defines the addresses of peer masters to relay accepted heartbeat and keepalive requests to, along with their source address"` //nolint:lll
+	ReporterRelaySources    []string      `help:"Defines the IP addresses of peer masters allowed to relay requests to this master"`                                            //nolint:lll
+	ReporterRelayQueueSize  int           `default:"1024"   help:"Sets the maximum number of requests waiting to be forwarded to a single peer master"`                         //nolint:lll
+	ReporterRelayMinBackoff time.Duration `default:"1s"     help:"Sets the initial pause in forwarding to a peer master after a failure"`                                       //nolint:lll
+	ReporterRelayMaxBackoff time.Duration `default:"1m"     help:"Sets the maximum pause in forwarding to a peer master after repeated failures"`                               //nolint:lll
 }
 
 func (c *command) Run(_ *commander.Globals, builder *application.Builder) error {
 	app := builder.
 		Add(
 			fx.Supply(Config{
-				ListenAddr: c.ReporterListenAddr,
-				BufferSize: c.ReporterBufferSize,
+				ListenAddr:      c.ReporterListenAddr,
+				BufferSize:      c.ReporterBufferSize,
+				RelayPeers:      c.ReporterRelayPeers,
+				RelaySources:    c.ReporterRelaySources,
+				RelayQueueSize:  c.ReporterRelayQueueSize,
+				RelayMinBackoff: c.ReporterRelayMinBackoff,
+				RelayMaxBackoff: c.ReporterRelayMaxBackoff,
 			}),
 			Module,
 			fx.Invoke(func(_ *Component) {}),
@@ -100,6 +167,8 @@ type CLI struct {
 var Module = fx.Module("reporter",
 	fx.Provide(
 		fx.Private,
+		provideRelay,
+		provideDispatcherOpts,
 		reporter.NewDispatcher,
 	),
 	fx.Invoke(
@@ -2,6 +2,7 @@ package reviver
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/jonboulle/clockwork"
@@ -11,6 +12,7 @@ import (
 	"github.com/sergeii/swat4master/cmd/swat4master/application"
 	"github.com/sergeii/swat4master/cmd/swat4master/commander"
 	"github.com/sergeii/swat4master/internal/core/usecases/reviveservers"
+	"github.com/sergeii/swat4master/internal/scheduler"
 )
 
 type Config struct {
@@ -21,20 +23,23 @@ type Config struct {
 
 type Component struct{}
 
-func run(
-	stop chan struct{},
-	stopped chan struct{},
+func New(
+	cfg Config,
+	sched *scheduler.Scheduler,
 	clock clockwork.Clock,
-	logger *zerolog.Logger,
 	uc reviveservers.UseCase,
-	cfg Config,
-) {
-	reviver := clock.NewTicker(cfg.RevivalInterval)
-	reviverCh := reviver.Chan()
-	defer reviver.Stop()
-
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	logger *zerolog.Logger,
+) (*Component, error) {
+	job := scheduler.Job{
+		Name:     "reviver",
+		Schedule: scheduler.Every(cfg.RevivalInterval),
+		Run: func(ctx context.Context) error {
+			return revive(ctx, clock, logger, uc, cfg)
+		},
+	}
+	if err := sched.Add(job); err != nil {
+		return nil, err
+	}
 
 	logger.Info().
 		Dur("interval", cfg.RevivalInterval).
@@ -42,41 +47,7 @@ func run(
 		Dur("scope", cfg.RevivalScope).
 		Msg("Starting reviver")
 
-	for {
-		select {
-		case <-stop:
-			close(stopped)
-			return
-		case <-reviverCh:
-			revive(ctx, clock, logger, uc, cfg)
-		}
-	}
-}
-
-func New(
-	lc fx.Lifecycle,
-	cfg Config,
-	clock clockwork.Clock,
-	uc reviveservers.UseCase,
-	logger *zerolog.Logger,
-) *Component {
-	stopped := make(chan struct{})
-	stop := make(chan struct{})
-
-	lc.Append(fx.Hook{
-		OnStart: func(context.Context) error {
-			go run(stop, stopped, clock, logger, uc, cfg) //nolint: contextcheck
-			return nil
-		},
-		OnStop: func(context.Context) error {
-			close(stop)
-			<-stopped
-			logger.Info().Msg("Reviver stopped")
-			return nil
-		},
-	})
-
-	return &Component{}
+	return &Component{}, nil
 }
 
 func revive(
@@ -85,7 +56,7 @@ func revive(
 	logger *zerolog.Logger,
 	uc reviveservers.UseCase,
 	cfg Config,
-) {
+) error {
 	now := clock.Now()
 
 	// make sure the probes don't run beyond the next cycle of discovery
@@ -100,8 +71,7 @@ func revive(
 	)
 	result, err := uc.Execute(ctx, ucRequest)
 	if err != nil {
-		logger.Warn().Err(err).Msg("Unable to revive outdated servers")
-		return
+		return fmt.Errorf("unable to revive outdated servers: %w", err)
 	}
 
 	if result.Count > 0 {
@@ -109,6 +79,8 @@ func revive(
 	} else {
 		logger.Debug().Msg("Added no servers to revival queue")
 	}
+
+	return nil
 }
 
 type command struct{}
@@ -0,0 +1,156 @@
+package sampler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/rs/zerolog"
+	"go.uber.org/fx"
+
+	"github.com/sergeii/swat4master/cmd/swat4master/application"
+	"github.com/sergeii/swat4master/cmd/swat4master/commander"
+	"github.com/sergeii/swat4master/internal/core/usecases/linkduplicates"
+	"github.com/sergeii/swat4master/internal/core/usecases/recordhistory"
+	"github.com/sergeii/swat4master/internal/core/usecases/trackavailability"
+	"github.com/sergeii/swat4master/internal/scheduler"
+)
+
+type Config struct {
+	SampleInterval       time.Duration
+	AvailabilityInterval time.Duration
+	DuplicatesInterval   time.Duration
+}
+
+type Component struct{}
+
+func New(
+	cfg Config,
+	sched *scheduler.Scheduler,
+	clock clockwork.Clock,
+	uc recordhistory.UseCase,
+	availabilityUC trackavailability.UseCase,
+	duplicatesUC linkduplicates.UseCase,
+	logger *zerolog.Logger,
+) (*Component, error) {
+	jobs := []scheduler.Job{
+		{
+			Name:     "sampler",
+			Schedule: scheduler.Every(cfg.SampleInterval),
+			Run: func(ctx context.Context) error {
+				return sample(ctx, clock, logger, uc)
+			},
+		},
+		{
+			Name:     "availability",
+			Schedule: scheduler.Every(cfg.AvailabilityInterval),
+			Run: func(ctx context.Context) error {
+				return trackAvailability(ctx, clock, logger, availabilityUC)
+			},
+		},
+		{
+			Name:     "duplicates",
+			Schedule: scheduler.Every(cfg.DuplicatesInterval),
+			Run: func(ctx context.Context) error {
+				return linkDuplicates(ctx, clock, logger, duplicatesUC)
+			},
+		},
+	}
+	for _, job := range jobs {
+		if err := sched.Add(job); err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Info().
+		Dur("interval", cfg.SampleInterval).
+		Dur("availability", cfg.AvailabilityInterval).
+		Dur("duplicates", cfg.DuplicatesInterval).
+		Msg("Starting sampler")
+
+	return &Component{}, nil
+}
+
+func sample(
+	ctx context.Context,
+	clock clockwork.Clock,
+	logger *zerolog.Logger,
+	uc recordhistory.UseCase,
+) error {
+	result, err := uc.Execute(ctx, clock.Now())
+	if err != nil {
+		return fmt.Errorf("unable to record server history: %w", err)
+	}
+
+	logger.Debug().Int("online", result.Online).Int("offline", result.Offline).Msg("Recorded server history")
+
+	return nil
+}
+
+func trackAvailability(
+	ctx context.Context,
+	clock clockwork.Clock,
+	logger *zerolog.Logger,
+	uc trackavailability.UseCase,
+) error {
+	result, err := uc.Execute(ctx, clock.Now())
+	if err != nil {
+		return fmt.Errorf("unable to track server availability: %w", err)
+	}
+
+	logger.Debug().
+		Int("down", result.WentDown).Int("up", result.CameUp).Int("errors", result.Errors).
+		Msg("Tracked server availability")
+
+	return nil
+}
+
+func linkDuplicates(
+	ctx context.Context,
+	clock clockwork.Clock,
+	logger *zerolog.Logger,
+	uc linkduplicates.UseCase,
+) error {
+	result, err := uc.Execute(ctx, clock.Now())
+	if err != nil {
+		return fmt.Errorf("unable to link duplicate servers: %w", err)
+	}
+
+	logger.Debug().
+		Int("duplicates", result.Duplicates).Int("updated", result.Updated).Int("errors", result.Errors).
+		Msg("Linked duplicate servers")
+
+	return nil
+}
+
+type command struct {
+	SampleInterval       time.Duration `default:"1m" help:"Sets how often the history samples of game servers are taken"`
+	AvailabilityInterval time.Duration `default:"1m" help:"Sets how often the availability of game servers is checked"`
+	DuplicatesInterval   time.Duration `default:"1m" help:"Sets how often the game servers are checked for duplicates"`
+}
+
+func (c *command) Run(_ *commander.Globals, builder *application.Builder) error {
+	app := builder.
+		Add(
+			fx.Supply(Config{
+				SampleInterval:       c.SampleInterval,
+				AvailabilityInterval: c.AvailabilityInterval,
+				DuplicatesInterval:   c.DuplicatesInterval,
+			}),
+			Module,
+			fx.Invoke(func(_ *Component) {}),
+		).
+		WithExporter().
+		Build()
+	app.Run()
+	return nil
+}
+
+type CLI struct {
+	Sampler command `cmd:"" help:"Start sampler"`
+}
+
+var Module = fx.Module("sampler",
+	fx.Provide(New),
+)
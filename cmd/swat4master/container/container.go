@@ -4,14 +4,30 @@ import (
 	"go.uber.org/fx"
 
 	"github.com/sergeii/swat4master/internal/core/usecases/addserver"
+	"github.com/sergeii/swat4master/internal/core/usecases/claimserver"
+	"github.com/sergeii/swat4master/internal/core/usecases/featureserver"
+	"github.com/sergeii/swat4master/internal/core/usecases/getmatch"
+	"github.com/sergeii/swat4master/internal/core/usecases/getprofile"
 	"github.com/sergeii/swat4master/internal/core/usecases/getserver"
+	"github.com/sergeii/swat4master/internal/core/usecases/getserverhistory"
+	"github.com/sergeii/swat4master/internal/core/usecases/linkduplicates"
+	"github.com/sergeii/swat4master/internal/core/usecases/listfeatured"
+	"github.com/sergeii/swat4master/internal/core/usecases/listjobruns"
+	"github.com/sergeii/swat4master/internal/core/usecases/listjobs"
+	"github.com/sergeii/swat4master/internal/core/usecases/listmatches"
 	"github.com/sergeii/swat4master/internal/core/usecases/listservers"
+	"github.com/sergeii/swat4master/internal/core/usecases/listsessions"
 	"github.com/sergeii/swat4master/internal/core/usecases/probeserver"
+	"github.com/sergeii/swat4master/internal/core/usecases/recordhistory"
 	"github.com/sergeii/swat4master/internal/core/usecases/refreshservers"
 	"github.com/sergeii/swat4master/internal/core/usecases/removeserver"
 	"github.com/sergeii/swat4master/internal/core/usecases/renewserver"
 	"github.com/sergeii/swat4master/internal/core/usecases/reportserver"
 	"github.com/sergeii/swat4master/internal/core/usecases/reviveservers"
+	"github.com/sergeii/swat4master/internal/core/usecases/searchplayers"
+	"github.com/sergeii/swat4master/internal/core/usecases/trackavailability"
+	"github.com/sergeii/swat4master/internal/core/usecases/unfeatureserver"
+	"github.com/sergeii/swat4master/internal/core/usecases/updateprofile"
 	"github.com/sergeii/swat4master/internal/settings"
 )
 
@@ -22,6 +38,8 @@ type UseCaseConfigs struct {
 	ReportServerOptions   reportserver.UseCaseOptions
 	RefreshServersOptions refreshservers.UseCaseOptions
 	ReviveServersOptions  reviveservers.UseCaseOptions
+	AvailabilityOptions   trackavailability.UseCaseOptions
+	DuplicatesOptions     linkduplicates.UseCaseOptions
 }
 
 func NewUseCaseConfigs(settings settings.Settings) UseCaseConfigs {
@@ -38,42 +56,96 @@ func NewUseCaseConfigs(settings settings.Settings) UseCaseConfigs {
 		ReviveServersOptions: reviveservers.UseCaseOptions{
 			MaxProbeRetries: settings.DiscoveryRevivalRetries,
 		},
+		AvailabilityOptions: trackavailability.UseCaseOptions{
+			ServerLiveness: settings.ServerLiveness,
+		},
+		DuplicatesOptions: linkduplicates.UseCaseOptions{
+			ServerLiveness: settings.ServerLiveness,
+		},
 	}
 }
 
 type Container struct {
-	AddServer      addserver.UseCase
-	GetServer      getserver.UseCase
-	ListServers    listservers.UseCase
-	ProbeServer    probeserver.UseCase
-	RefreshServers refreshservers.UseCase
-	RemoveServer   removeserver.UseCase
-	RenewServer    renewserver.UseCase
-	ReportServer   reportserver.UseCase
-	ReviveServers  reviveservers.UseCase
+	AddServer         addserver.UseCase
+	ClaimServer       claimserver.UseCase
+	FeatureServer     featureserver.UseCase
+	GetMatch          getmatch.UseCase
+	GetProfile        getprofile.UseCase
+	GetServer         getserver.UseCase
+	GetHistory        getserverhistory.UseCase
+	LinkDuplicates    linkduplicates.UseCase
+	ListFeatured      listfeatured.UseCase
+	ListJobs          listjobs.UseCase
+	ListJobRuns       listjobruns.UseCase
+	ListMatches       listmatches.UseCase
+	ListServers       listservers.UseCase
+	ListSessions      listsessions.UseCase
+	ProbeServer       probeserver.UseCase
+	RecordHistory     recordhistory.UseCase
+	RefreshServers    refreshservers.UseCase
+	RemoveServer      removeserver.UseCase
+	RenewServer       renewserver.UseCase
+	ReportServer      reportserver.UseCase
+	ReviveServers     reviveservers.UseCase
+	SearchPlayers     searchplayers.UseCase
+	TrackAvailability trackavailability.UseCase
+	UnfeatureServer   unfeatureserver.UseCase
+	UpdateProfile     updateprofile.UseCase
 }
 
 func NewContainer(
 	addServerUseCase addserver.UseCase,
+	claimServerUseCase claimserver.UseCase,
+	featureServerUseCase featureserver.UseCase,
+	getMatchUseCase getmatch.UseCase,
+	getProfileUseCase getprofile.UseCase,
 	getServerUseCase getserver.UseCase,
+	getHistoryUseCase getserverhistory.UseCase,
+	linkDuplicatesUseCase linkduplicates.UseCase,
+	listFeaturedUseCase listfeatured.UseCase,
+	listJobsUseCase listjobs.UseCase,
+	listJobRunsUseCase listjobruns.UseCase,
+	listMatchesUseCase listmatches.UseCase,
 	listServersUseCase listservers.UseCase,
+	listSessionsUseCase listsessions.UseCase,
 	probeServerUseCase probeserver.UseCase,
+	recordHistoryUseCase recordhistory.UseCase,
 	refreshServersUseCase refreshservers.UseCase,
 	removeServerUseCase removeserver.UseCase,
 	renewServerUseCase renewserver.UseCase,
 	reportServerUseCase reportserver.UseCase,
 	reviveServersUseCase reviveservers.UseCase,
+	searchPlayersUseCase searchplayers.UseCase,
+	trackAvailabilityUseCase trackavailability.UseCase,
+	unfeatureServerUseCase unfeatureserver.UseCase,
+	updateProfileUseCase updateprofile.UseCase,
 ) Container {
 	return Container{
-		AddServer:      addServerUseCase,
-		GetServer:      getServerUseCase,
-		ListServers:    listServersUseCase,
-		ProbeServer:    probeServerUseCase,
-		RefreshServers: refreshServersUseCase,
-		RemoveServer:   removeServerUseCase,
-		RenewServer:    renewServerUseCase,
-		ReportServer:   reportServerUseCase,
-		ReviveServers:  reviveServersUseCase,
+		AddServer:         addServerUseCase,
+		ClaimServer:       claimServerUseCase,
+		FeatureServer:     featureServerUseCase,
+		GetMatch:          getMatchUseCase,
+		GetProfile:        getProfileUseCase,
+		GetServer:         getServerUseCase,
+		GetHistory:        getHistoryUseCase,
+		LinkDuplicates:    linkDuplicatesUseCase,
+		ListFeatured:      listFeaturedUseCase,
+		ListJobs:          listJobsUseCase,
+		ListJobRuns:       listJobRunsUseCase,
+		ListMatches:       listMatchesUseCase,
+		ListServers:       listServersUseCase,
+		ListSessions:      listSessionsUseCase,
+		ProbeServer:       probeServerUseCase,
+		RecordHistory:     recordHistoryUseCase,
+		RefreshServers:    refreshServersUseCase,
+		RemoveServer:      removeServerUseCase,
+		RenewServer:       renewServerUseCase,
+		ReportServer:      reportServerUseCase,
+		ReviveServers:     reviveServersUseCase,
+		SearchPlayers:     searchPlayersUseCase,
+		TrackAvailability: trackAvailabilityUseCase,
+		UnfeatureServer:   unfeatureServerUseCase,
+		UpdateProfile:     updateProfileUseCase,
 	}
 }
 
@@ -87,6 +159,22 @@ var Module = fx.Module("container",
 	fx.Provide(refreshservers.New),
 	fx.Provide(reviveservers.New),
 	fx.Provide(probeserver.New),
+	fx.Provide(featureserver.New),
+	fx.Provide(unfeatureserver.New),
+	fx.Provide(listfeatured.New),
+	fx.Provide(listjobs.New),
+	fx.Provide(listjobruns.New),
+	fx.Provide(listsessions.New),
+	fx.Provide(searchplayers.New),
+	fx.Provide(getmatch.New),
+	fx.Provide(listmatches.New),
+	fx.Provide(recordhistory.New),
+	fx.Provide(getserverhistory.New),
+	fx.Provide(trackavailability.New),
+	fx.Provide(linkduplicates.New),
+	fx.Provide(claimserver.New),
+	fx.Provide(getprofile.New),
+	fx.Provide(updateprofile.New),
 	fx.Provide(NewUseCaseConfigs),
 	fx.Provide(NewContainer),
 )
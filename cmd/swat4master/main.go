@@ -10,13 +10,16 @@ import (
 	"github.com/sergeii/swat4master/cmd/swat4master/components/browser"
 	"github.com/sergeii/swat4master/cmd/swat4master/components/cleaner"
 	"github.com/sergeii/swat4master/cmd/swat4master/components/exporter"
+	"github.com/sergeii/swat4master/cmd/swat4master/components/federator"
 	"github.com/sergeii/swat4master/cmd/swat4master/components/observer"
 	"github.com/sergeii/swat4master/cmd/swat4master/components/prober"
 	"github.com/sergeii/swat4master/cmd/swat4master/components/refresher"
 	"github.com/sergeii/swat4master/cmd/swat4master/components/reporter"
 	"github.com/sergeii/swat4master/cmd/swat4master/components/reviver"
+	"github.com/sergeii/swat4master/cmd/swat4master/components/sampler"
 	"github.com/sergeii/swat4master/cmd/swat4master/logging"
 	"github.com/sergeii/swat4master/cmd/swat4master/persistence"
+	"github.com/sergeii/swat4master/cmd/swat4master/tools"
 	"github.com/sergeii/swat4master/internal/settings"
 )
 
@@ -26,11 +29,16 @@ func main() {
 		&api.CLI{},
 		&browser.CLI{},
 		&cleaner.CLI{},
+		&federator.CLI{},
 		&observer.CLI{},
 		&prober.CLI{},
 		&refresher.CLI{},
 		&reporter.CLI{},
 		&reviver.CLI{},
+		&sampler.CLI{},
+	}
+	cli.Plugins = kong.Plugins{
+		&tools.CLI{},
 	}
 	ctx := kong.Parse(
 		&cli,
@@ -57,8 +65,11 @@ func main() {
 		}),
 		fx.Supply(settings.Settings{
 			ServerLiveness:          cli.Globals.BrowsingServerLiveness,
+			HideUnverified:          cli.Globals.BrowsingHideUnverified,
 			DiscoveryRevivalRetries: cli.Globals.DiscoveryRevivalRetries,
 			DiscoveryRefreshRetries: cli.Globals.DiscoveryRefreshRetries,
+			GeoIPCountryDB:          cli.Globals.GeoIPCountryDB,
+			GeoIPASNDB:              cli.Globals.GeoIPASNDB,
 		}),
 		fx.Provide(logging.Provide),
 		fx.WithLogger(logging.FxLogger),
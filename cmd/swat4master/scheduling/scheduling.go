@@ -0,0 +1,41 @@
+package scheduling
+
+import (
+	"context"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/rs/zerolog"
+	"go.uber.org/fx"
+
+	"github.com/sergeii/swat4master/internal/core/repositories"
+	"github.com/sergeii/swat4master/internal/metrics"
+	"github.com/sergeii/swat4master/internal/persistence/redis/redislock"
+	"github.com/sergeii/swat4master/internal/scheduler"
+)
+
+// Provide provides the scheduler shared by the periodic components.
+// The scheduler is started along with the application and runs the jobs the components have added.
+func Provide(
+	lc fx.Lifecycle,
+	runRepo repositories.JobRunRepository,
+	locker *redislock.Manager,
+	collector *metrics.Collector,
+	clock clockwork.Clock,
+	logger *zerolog.Logger,
+) *scheduler.Scheduler {
+	sched := scheduler.New(runRepo, locker, collector, clock, logger)
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			sched.Start()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			sched.Stop()
+			logger.Info().Msg("Scheduler stopped")
+			return nil
+		},
+	})
+
+	return sched
+}
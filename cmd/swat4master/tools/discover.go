@@ -0,0 +1,122 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/rs/zerolog"
+
+	"github.com/sergeii/swat4master/cmd/swat4master/commander"
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/details"
+	"github.com/sergeii/swat4master/internal/core/entities/portoffset"
+	"github.com/sergeii/swat4master/internal/metrics"
+	"github.com/sergeii/swat4master/internal/prober/probers/portprober"
+	"github.com/sergeii/swat4master/internal/validation"
+	"github.com/sergeii/swat4master/pkg/udp/udpclient"
+)
+
+var ErrNoQueryPort = errors.New("no query port has answered")
+
+type DiscoverCmd struct {
+	Addr string `arg:"" help:"Game address of the server (e.g., 1.2.3.4:10480)"`
+}
+
+func (c *DiscoverCmd) Run(globals *commander.Globals) error {
+	opts := portprober.Opts{
+		Offsets:         globals.DiscoveryRevivalPorts,
+		FallbackOffsets: globals.DiscoveryRevivalFallbackPorts,
+		FallbackPorts:   globals.DiscoveryRevivalFallbackQueryPorts,
+	}
+	return Discover(context.Background(), os.Stdout, c.Addr, opts, globals.ProbeTimeout)
+}
+
+// Discover probes every candidate query port of the server the same way the port prober does,
+// and writes the outcome of each port to w, followed by the details obtained from the preferred port.
+func Discover(
+	ctx context.Context,
+	w io.Writer,
+	address string,
+	opts portprober.Opts,
+	timeout time.Duration,
+) error {
+	addrPort, err := parseAddr(address)
+	if err != nil {
+		return err
+	}
+	svrAddr := addr.Addr{IP: addrPort.Addr().As4(), Port: int(addrPort.Port())}
+
+	logger := zerolog.Nop()
+	validate, err := validation.New()
+	if err != nil {
+		return err
+	}
+	prober := portprober.New(
+		opts,
+		udpclient.Direct{},
+		noOffsets{},
+		validate,
+		clockwork.NewRealClock(),
+		metrics.New(),
+		&logger,
+	)
+
+	outcomes := prober.Survey(ctx, svrAddr, timeout)
+
+	var best portprober.Outcome
+	found := false
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "OFFSET\tPORT\tOUTCOME")
+	for _, outcome := range outcomes {
+		result := ""
+		if outcome.Answered() {
+			result = fmt.Sprintf("%s in %s", outcome.Response.Variant(), outcome.Response.RTT.Round(time.Millisecond))
+			if !found || outcome.Response.Rank() > best.Response.Rank() {
+				best, found = outcome, true
+			}
+		} else {
+			// the errors of every protocol are joined with newlines
+			result = "failed: " + strings.ReplaceAll(outcome.Err.Error(), "\n", "; ")
+		}
+		offset := fmt.Sprintf("%+d", outcome.Offset)
+		if outcome.Fallback {
+			offset = "fallback"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", offset, outcome.Port, result)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if !found {
+		return fmt.Errorf("%w: %s", ErrNoQueryPort, svrAddr)
+	}
+
+	det, err := details.NewDetailsFromParams(best.Response.Fields, best.Response.Players, best.Response.Objectives)
+	if err != nil {
+		return fmt.Errorf("unable to parse response from port %d: %w", best.Port, err)
+	}
+
+	fmt.Fprintf(w, "\nPreferred port %d (%s)\n\n", best.Port, best.Response.Variant())
+
+	return printDetails(w, det)
+}
+
+// noOffsets is the port offset repository that has learned nothing,
+// so that the port prober runs without Redis
+type noOffsets struct{}
+
+func (noOffsets) Record(context.Context, [4]byte, int) error {
+	return nil
+}
+
+func (noOffsets) Get(context.Context, [4]byte) (portoffset.Learned, error) {
+	return portoffset.Learned{}, nil
+}
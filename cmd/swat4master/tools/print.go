@@ -0,0 +1,90 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/sergeii/swat4master/internal/core/entities/details"
+	"github.com/sergeii/swat4master/internal/validation"
+)
+
+// printDetails writes the server details to w, followed by the validation errors, if any.
+// ErrInvalidDetails is returned in case the details have failed validation.
+func printDetails(w io.Writer, det details.Details) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	info := det.Info
+	fmt.Fprintf(tw, "Hostname\t%s\n", info.Hostname)
+	fmt.Fprintf(tw, "Host port\t%d\n", info.HostPort)
+	fmt.Fprintf(tw, "Version\t%s %s\n", info.GameVariant, info.GameVersion)
+	fmt.Fprintf(tw, "Game type\t%s\n", info.GameType)
+	fmt.Fprintf(tw, "Map\t%s\n", info.MapName)
+	fmt.Fprintf(tw, "Players\t%d/%d\n", info.NumPlayers, info.MaxPlayers)
+	fmt.Fprintf(tw, "Round\t%d/%d\n", info.Round, info.NumRounds)
+	fmt.Fprintf(tw, "Time left\t%d\n", info.TimeLeft)
+	fmt.Fprintf(tw, "Score\t%d:%d\n", info.SwatScore, info.SuspectsScore)
+	fmt.Fprintf(tw, "Password\t%t\n", info.Password)
+	fmt.Fprintf(tw, "Stats\t%t\n", info.StatsEnabled)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(det.Players) > 0 {
+		fmt.Fprintf(w, "\nPlayers (%d):\n", len(det.Players))
+		fmt.Fprintln(tw, "  NAME\tTEAM\tSCORE\tPING\tKILLS\tDEATHS\tARRESTS")
+		for _, player := range det.Players {
+			fmt.Fprintf(
+				tw, "  %s\t%s\t%d\t%d\t%d\t%d\t%d\n",
+				player.Name, player.Team, player.Score, player.Ping, player.Kills, player.Deaths, player.Arrests,
+			)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(det.Objectives) > 0 {
+		fmt.Fprintf(w, "\nObjectives (%d):\n", len(det.Objectives))
+		for _, obj := range det.Objectives {
+			fmt.Fprintf(tw, "  %s\t%s\n", obj.Name, obj.Status)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	validate, err := validation.New()
+	if err != nil {
+		return err
+	}
+	validateErr := det.Validate(validate)
+	if validateErr == nil {
+		return nil
+	}
+
+	fmt.Fprintln(w, "\nValidation errors:")
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(validateErr, &fieldErrs) {
+		fmt.Fprintf(w, "  %s\n", validateErr)
+		return fmt.Errorf("%w: %w", ErrInvalidDetails, validateErr)
+	}
+	for _, fieldErr := range fieldErrs {
+		fmt.Fprintf(
+			w, "  %s: failed %s check (value %q)\n",
+			fieldErr.Namespace(), constraint(fieldErr), fmt.Sprint(fieldErr.Value()),
+		)
+	}
+
+	return fmt.Errorf("%w: %w", ErrInvalidDetails, validateErr)
+}
+
+func constraint(fieldErr validator.FieldError) string {
+	if fieldErr.Param() == "" {
+		return fieldErr.Tag()
+	}
+	return fieldErr.Tag() + "=" + fieldErr.Param()
+}
@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/sergeii/swat4master/cmd/swat4master/commander"
+	"github.com/sergeii/swat4master/internal/core/entities/details"
+	"github.com/sergeii/swat4master/pkg/gamespy/serverquery/gs1"
+)
+
+type QueryCmd struct {
+	Addr string `arg:"" help:"Query address of the server (e.g., 1.2.3.4:10481)"`
+}
+
+func (c *QueryCmd) Run(globals *commander.Globals) error {
+	return Query(context.Background(), os.Stdout, c.Addr, globals.ProbeTimeout)
+}
+
+// Query queries the server with the gs1 protocol and writes the parsed details to w.
+// The details that fail validation are written along with the validation errors.
+func Query(ctx context.Context, w io.Writer, address string, timeout time.Duration) error {
+	addrPort, err := parseAddr(address)
+	if err != nil {
+		return err
+	}
+
+	started := time.Now()
+	resp, err := gs1.Query(ctx, addrPort, timeout)
+	if err != nil {
+		return fmt.Errorf("unable to query %s: %w", addrPort, err)
+	}
+	rtt := time.Since(started)
+
+	det, err := details.NewDetailsFromParams(resp.Fields, resp.Players, resp.Objectives)
+	if err != nil {
+		return fmt.Errorf("unable to parse response from %s: %w", addrPort, err)
+	}
+
+	fmt.Fprintf(w, "Queried %s (gs1/%s) in %s\n\n", addrPort, resp.Version, rtt.Round(time.Millisecond))
+
+	return printDetails(w, det)
+}
@@ -0,0 +1,30 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+)
+
+var (
+	ErrInvalidAddress = errors.New("invalid server address")
+	ErrInvalidDetails = errors.New("server details failed validation")
+)
+
+// CLI contains the one-shot commands that troubleshoot a single server.
+// Unlike the components, they run neither the application nor require Redis.
+type CLI struct {
+	Query    QueryCmd    `cmd:"" help:"Query a server with the gs1 protocol and print its details"`
+	Discover DiscoverCmd `cmd:"" help:"Probe the candidate query ports of a server and print the outcome of each port"`
+}
+
+func parseAddr(address string) (netip.AddrPort, error) {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return netip.AddrPort{}, fmt.Errorf("%w: %w", ErrInvalidAddress, err)
+	}
+	if !addrPort.Addr().Is4() {
+		return netip.AddrPort{}, fmt.Errorf("%w: %s is not an IPv4 address", ErrInvalidAddress, addrPort.Addr())
+	}
+	return addrPort, nil
+}
@@ -0,0 +1,141 @@
+package tools_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/cmd/swat4master/tools"
+	"github.com/sergeii/swat4master/internal/prober/probers/portprober"
+	"github.com/sergeii/swat4master/pkg/gamespy/serverquery/gs1"
+)
+
+func statusResponse(hostname string, hostPort int) []byte {
+	return []byte(fmt.Sprintf(
+		"\\hostname\\%s\\numplayers\\1\\maxplayers\\16\\gametype\\VIP Escort"+
+			"\\gamevariant\\SWAT 4\\mapname\\Fairfax Residence\\hostport\\%d\\password\\false"+ //nolint:misspell
+			"\\gamever\\1.1\\round\\1\\numrounds\\5\\timeleft\\100\\player_0\\Serge\\score_0\\10"+
+			"\\ping_0\\42\\team_0\\0\\kills_0\\2\\queryid\\1\\final\\",
+		hostname, hostPort,
+	))
+}
+
+func TestQuery_OK(t *testing.T) {
+	responses := make(chan []byte, 1)
+	server, cancel := gs1.PrepareGS1Server(responses)
+	defer cancel()
+	responses <- statusResponse("Swat4 Server", 10480)
+
+	out := &bytes.Buffer{}
+	err := tools.Query(context.TODO(), out, server.LocalAddr().String(), time.Millisecond*100)
+	require.NoError(t, err)
+
+	assert.Contains(t, out.String(), "(gs1/gs1)")
+	assert.Regexp(t, `Hostname\s+Swat4 Server`, out.String())
+	assert.Regexp(t, `Host port\s+10480`, out.String())
+	assert.Regexp(t, `Map\s+Fairfax Residence`, out.String())
+	assert.Contains(t, out.String(), "Players (1):")
+	assert.Regexp(t, `Serge\s+swat\s+10\s+42\s+2`, out.String())
+	assert.NotContains(t, out.String(), "Validation errors")
+}
+
+func TestQuery_ValidationErrors(t *testing.T) {
+	responses := make(chan []byte, 1)
+	server, cancel := gs1.PrepareGS1Server(responses)
+	defer cancel()
+	responses <- statusResponse("", 10480)
+
+	out := &bytes.Buffer{}
+	err := tools.Query(context.TODO(), out, server.LocalAddr().String(), time.Millisecond*100)
+	assert.ErrorIs(t, err, tools.ErrInvalidDetails)
+
+	// the details are printed regardless
+	assert.Regexp(t, `Map\s+Fairfax Residence`, out.String())
+	assert.Contains(t, out.String(), "Validation errors:")
+	assert.Contains(t, out.String(), "Details.Info.Hostname: failed required check")
+}
+
+func TestQuery_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		wantErr error
+	}{
+		{
+			"no port",
+			"1.1.1.1",
+			tools.ErrInvalidAddress,
+		},
+		{
+			"ipv6 address",
+			"[::1]:10481",
+			tools.ErrInvalidAddress,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			err := tools.Query(context.TODO(), out, tt.address, time.Millisecond*100)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Empty(t, out.String())
+		})
+	}
+}
+
+func TestQuery_NoResponse(t *testing.T) {
+	server, cancel := gs1.PrepareGS1Server(make(chan []byte))
+	defer cancel()
+
+	out := &bytes.Buffer{}
+	err := tools.Query(context.TODO(), out, server.LocalAddr().String(), time.Millisecond*50)
+	assert.ErrorContains(t, err, "unable to query")
+	assert.Empty(t, out.String())
+}
+
+func TestDiscover_OK(t *testing.T) {
+	responses := make(chan []byte, 1)
+	server, cancel := gs1.PrepareGS1Server(responses)
+	defer cancel()
+
+	queryAddr := server.LocalAddr()
+	gamePort := queryAddr.Port - 2
+	responses <- statusResponse("Swat4 Server", gamePort)
+
+	out := &bytes.Buffer{}
+	gameAddr := queryAddr.IP.String() + ":" + strconv.Itoa(gamePort)
+	opts := portprober.Opts{Offsets: []int{1, 2}}
+	err := tools.Discover(context.TODO(), out, gameAddr, opts, time.Millisecond*100)
+	require.NoError(t, err)
+
+	assert.Regexp(t, fmt.Sprintf(`\+1\s+%d\s+failed`, gamePort+1), out.String())
+	assert.Regexp(t, fmt.Sprintf(`\+2\s+%d\s+gs1/gs1 in`, gamePort+2), out.String())
+	assert.Contains(t, out.String(), fmt.Sprintf("Preferred port %d (gs1/gs1)", queryAddr.Port))
+	assert.Regexp(t, `Hostname\s+Swat4 Server`, out.String())
+}
+
+func TestDiscover_NoQueryPort(t *testing.T) {
+	responses := make(chan []byte, 1)
+	server, cancel := gs1.PrepareGS1Server(responses)
+	defer cancel()
+
+	// the server on the port is another one
+	queryAddr := server.LocalAddr()
+	responses <- statusResponse("Swat4 Server", 10480)
+
+	out := &bytes.Buffer{}
+	gameAddr := queryAddr.IP.String() + ":" + strconv.Itoa(queryAddr.Port-1)
+	opts := portprober.Opts{Offsets: []int{1}}
+	err := tools.Discover(context.TODO(), out, gameAddr, opts, time.Millisecond*100)
+	assert.ErrorIs(t, err, tools.ErrNoQueryPort)
+
+	wantOutcome := fmt.Sprintf(`\+1\s+%d\s+failed: server reports another game port: 10480`, queryAddr.Port)
+	assert.Regexp(t, wantOutcome, out.String())
+	assert.NotContains(t, out.String(), "Preferred port")
+}
@@ -0,0 +1,114 @@
+package browser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/netip"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/sergeii/swat4master/internal/core/entities/details"
+	"github.com/sergeii/swat4master/pkg/gamespy/browsing"
+	"github.com/sergeii/swat4master/pkg/gamespy/browsing/query"
+)
+
+var (
+	ErrAnnouncementHostnameRequired = errors.New("announcement hostname is required")
+	ErrAnnouncementInvalidAddress   = errors.New("announcement address must be a valid ipv4 address with port")
+	ErrAnnouncementInvalidWindow    = errors.New("announcement must end after it starts")
+)
+
+// Announcement is a pseudo-entry injected into the in-game server list,
+// such as a news line or a patch notice.
+// The hostname may be styled with the game's markup, e.g. [c=FF0000]Patch 1.2 is out[\c].
+// The address is only displayed in the list and does not have to point to a live server.
+type Announcement struct {
+	Hostname string            `json:"hostname"`
+	Address  string            `json:"address"`
+	Params   map[string]string `json:"params"`
+	Since    *time.Time        `json:"since"`
+	Until    *time.Time        `json:"until"`
+	// Games limits the announcement to the clients requesting these games, e.g. swat4 or swat4xp1
+	Games []string `json:"games"`
+	// ExcludeVersions turns the announcement off for the clients reporting these game versions
+	ExcludeVersions []uint32 `json:"exclude_versions"`
+
+	addr   netip.AddrPort
+	fields map[string]string
+	info   details.Info
+}
+
+// LoadAnnouncements reads the announcements from a JSON file containing an array of announcements.
+// An empty path means no announcements.
+func LoadAnnouncements(path string) ([]Announcement, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var items []Announcement
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("unable to parse announcements: %w", err)
+	}
+	for i := range items {
+		if err := items[i].prepare(); err != nil {
+			return nil, fmt.Errorf("invalid announcement #%d: %w", i+1, err)
+		}
+	}
+	return items, nil
+}
+
+func (a *Announcement) prepare() error {
+	if a.Hostname == "" {
+		return ErrAnnouncementHostnameRequired
+	}
+	addr, err := netip.ParseAddrPort(a.Address)
+	if err != nil || !addr.Addr().Is4() {
+		return ErrAnnouncementInvalidAddress
+	}
+	if a.Since != nil && a.Until != nil && !a.Until.After(*a.Since) {
+		return ErrAnnouncementInvalidWindow
+	}
+
+	fields := make(map[string]string, len(a.Params)+2)
+	maps.Copy(fields, a.Params)
+	fields["hostname"] = a.Hostname
+	if _, ok := fields["hostport"]; !ok {
+		fields["hostport"] = strconv.Itoa(int(addr.Port()))
+	}
+	info, err := details.NewInfoFromParams(fields)
+	if err != nil {
+		return fmt.Errorf("invalid params: %w", err)
+	}
+
+	a.addr = addr
+	a.fields = fields
+	a.info = info
+	return nil
+}
+
+// IsTargeted tells whether the announcement should be shown in response to the browser request.
+// Besides the time window, the game and the client version, the announcement is subject
+// to the client's filters, as if it were a regular server with the announcement params.
+func (a Announcement) IsTargeted(now time.Time, req browsing.Request, q query.Query) bool {
+	if a.Since != nil && now.Before(*a.Since) {
+		return false
+	}
+	if a.Until != nil && !now.Before(*a.Until) {
+		return false
+	}
+	if len(a.Games) > 0 && !slices.Contains(a.Games, req.GameName) {
+		return false
+	}
+	if slices.Contains(a.ExcludeVersions, req.GameVersion) {
+		return false
+	}
+	info := a.info
+	return q.Match(&info)
+}
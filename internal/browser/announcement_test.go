@@ -0,0 +1,159 @@
+package browser_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/browser"
+	"github.com/sergeii/swat4master/pkg/gamespy/browsing"
+	"github.com/sergeii/swat4master/pkg/gamespy/browsing/query"
+)
+
+func writeAnnouncements(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "announcements.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadAnnouncements_OK(t *testing.T) {
+	path := writeAnnouncements(t, `[
+		{
+			"hostname": "[c=FF0000]Patch 1.2 is out[\\c]",
+			"address": "127.0.0.1:1",
+			"params": {"gametype": "CO-OP"},
+			"since": "2025-01-01T00:00:00Z",
+			"until": "2025-02-01T00:00:00Z",
+			"games": ["swat4"],
+			"exclude_versions": [1]
+		},
+		{"hostname": "News", "address": "127.0.0.2:2"}
+	]`)
+
+	items, err := browser.LoadAnnouncements(path)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, "[c=FF0000]Patch 1.2 is out[\\c]", items[0].Hostname)
+	assert.Equal(t, "127.0.0.1:1", items[0].Address)
+	assert.Equal(t, map[string]string{"gametype": "CO-OP"}, items[0].Params)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), *items[0].Since)
+	assert.Equal(t, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), *items[0].Until)
+	assert.Equal(t, []string{"swat4"}, items[0].Games)
+	assert.Equal(t, []uint32{1}, items[0].ExcludeVersions)
+	assert.Equal(t, "News", items[1].Hostname)
+	assert.Nil(t, items[1].Since)
+	assert.Nil(t, items[1].Until)
+}
+
+func TestLoadAnnouncements_NoPath(t *testing.T) {
+	items, err := browser.LoadAnnouncements("")
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func TestLoadAnnouncements_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr error
+	}{
+		{
+			name:    "no hostname",
+			content: `[{"address": "127.0.0.1:1"}]`,
+			wantErr: browser.ErrAnnouncementHostnameRequired,
+		},
+		{
+			name:    "no address",
+			content: `[{"hostname": "News"}]`,
+			wantErr: browser.ErrAnnouncementInvalidAddress,
+		},
+		{
+			name:    "no port",
+			content: `[{"hostname": "News", "address": "127.0.0.1"}]`,
+			wantErr: browser.ErrAnnouncementInvalidAddress,
+		},
+		{
+			name:    "ipv6 address",
+			content: `[{"hostname": "News", "address": "[::1]:1"}]`,
+			wantErr: browser.ErrAnnouncementInvalidAddress,
+		},
+		{
+			name: "window ends before it starts",
+			content: `[{"hostname": "News", "address": "127.0.0.1:1",
+				"since": "2025-02-01T00:00:00Z", "until": "2025-01-01T00:00:00Z"}]`,
+			wantErr: browser.ErrAnnouncementInvalidWindow,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := browser.LoadAnnouncements(writeAnnouncements(t, tt.content))
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestLoadAnnouncements_InvalidFile(t *testing.T) {
+	_, err := browser.LoadAnnouncements(filepath.Join(t.TempDir(), "missing.json"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = browser.LoadAnnouncements(writeAnnouncements(t, `{"hostname": "News"}`))
+	assert.ErrorContains(t, err, "unable to parse announcements")
+
+	_, err = browser.LoadAnnouncements(writeAnnouncements(t,
+		`[{"hostname": "News", "address": "127.0.0.1:1", "params": {"numplayers": "foo"}}]`,
+	))
+	assert.ErrorContains(t, err, "invalid params")
+}
+
+func TestAnnouncement_IsTargeted(t *testing.T) {
+	now := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	items, err := browser.LoadAnnouncements(writeAnnouncements(t, `[
+		{
+			"hostname": "Patch notice",
+			"address": "127.0.0.1:1",
+			"params": {"gametype": "CO-OP", "gamever": "1.1"},
+			"since": "2025-01-01T00:00:00Z",
+			"until": "2025-02-01T00:00:00Z",
+			"games": ["swat4xp1"],
+			"exclude_versions": [11]
+		}
+	]`))
+	require.NoError(t, err)
+	a := items[0]
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		game    string
+		version uint32
+		filters string
+		want    bool
+	}{
+		{"targeted", now, "swat4xp1", 0, "", true},
+		{"matching filters", now, "swat4xp1", 0, "gametype='CO-OP' and gamever='1.1'", true},
+		{"window starts", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), "swat4xp1", 0, "", true},
+		{"window has not started", time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), "swat4xp1", 0, "", false},
+		{"window ends", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), "swat4xp1", 0, "", false},
+		{"other game", now, "swat4", 0, "", false},
+		{"excluded version", now, "swat4xp1", 11, "", false},
+		{"other version", now, "swat4xp1", 10, "", true},
+		{"filters not matching", now, "swat4xp1", 0, "gametype='VIP Escort'", false},
+		{"filters partially matching", now, "swat4xp1", 0, "gametype='CO-OP' and gamever='1.0'", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var q query.Query
+			if tt.filters != "" {
+				q = query.MustNewFromString(tt.filters)
+			}
+			req := browsing.Request{GameName: tt.game, GameVersion: tt.version}
+			assert.Equal(t, tt.want, a.IsTargeted(tt.now, req, q))
+		})
+	}
+}
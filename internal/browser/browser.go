@@ -1,18 +1,24 @@
 package browser
 
 import (
+	"cmp"
 	"context"
 	"encoding/binary"
 	"fmt"
 	"net"
+	"slices"
 	"time"
 
 	"github.com/jonboulle/clockwork"
 	"github.com/rs/zerolog"
 
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
 	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/internal/core/entities/featured"
 	"github.com/sergeii/swat4master/internal/core/entities/server"
+	"github.com/sergeii/swat4master/internal/core/usecases/listfeatured"
 	"github.com/sergeii/swat4master/internal/core/usecases/listservers"
+	"github.com/sergeii/swat4master/internal/geoip"
 	"github.com/sergeii/swat4master/internal/metrics"
 	"github.com/sergeii/swat4master/pkg/gamespy/browsing"
 	"github.com/sergeii/swat4master/pkg/gamespy/browsing/query"
@@ -22,17 +28,61 @@ import (
 
 const GameEncKey = "tG3j8c"
 
+// ListingPolicy defines which servers are admitted to the in-game server list
+type ListingPolicy string
+
+const (
+	// ListReported admits the servers reporting to this master
+	ListReported ListingPolicy = "reported"
+	// ListDiscovered admits the servers whose details are obtained by discovery
+	ListDiscovered ListingPolicy = "discovered"
+	// ListBoth admits both the reported and the discovered servers
+	ListBoth ListingPolicy = "both"
+)
+
+// ListingOrder defines how the servers are ordered in the in-game server list
+type ListingOrder string
+
+const (
+	// OrderNone keeps the servers in the order they are obtained from the repository
+	OrderNone ListingOrder = "none"
+	// OrderNearest lists the servers closest to the client first, the busier servers going first
+	// among the equally close ones
+	OrderNearest ListingOrder = "nearest"
+)
+
+// The paths an entry is admitted to the list by, as counted by the listed entries metric
+const (
+	admittedReported   = "reported"
+	admittedDiscovered = "discovered"
+	// an announcement is not a server, but a pseudo-entry
+	admittedAnnouncement = "announcement"
+)
+
 type HandlerOpts struct {
-	Liveness time.Duration
+	Liveness       time.Duration
+	Policy         ListingPolicy
+	Order          ListingOrder
+	Limit          int  // caps the number of servers in the list, announcements excluded; zero means no limit
+	HideUnverified bool // leaves out the servers never reached by a probe or caught reporting false info
+	HideFederated  bool // leaves out the servers imported from upstream masters
+	Announcements  []Announcement
+}
+
+type entry struct {
+	svr       server.Server
+	admission string
 }
 
 type Handler struct {
-	metrics *metrics.Collector
-	logger  *zerolog.Logger
-	clock   clockwork.Clock
-	uc      listservers.UseCase
-	opts    HandlerOpts
-	gameKey [6]byte
+	metrics    *metrics.Collector
+	logger     *zerolog.Logger
+	clock      clockwork.Clock
+	uc         listservers.UseCase
+	featuredUC listfeatured.UseCase
+	locator    geoip.Locator
+	opts       HandlerOpts
+	gameKey    [6]byte
 }
 
 func NewHandler(
@@ -40,14 +90,18 @@ func NewHandler(
 	logger *zerolog.Logger,
 	clock clockwork.Clock,
 	uc listservers.UseCase,
+	featuredUC listfeatured.UseCase,
+	locator geoip.Locator,
 	opts HandlerOpts,
 ) Handler {
 	handler := Handler{
-		metrics: metrics,
-		logger:  logger,
-		clock:   clock,
-		uc:      uc,
-		opts:    opts,
+		metrics:    metrics,
+		logger:     logger,
+		clock:      clock,
+		uc:         uc,
+		featuredUC: featuredUC,
+		locator:    locator,
+		opts:       opts,
 	}
 	copy(handler.gameKey[:], GameEncKey)
 	return handler
@@ -127,22 +181,132 @@ func (h Handler) process(
 		}
 	}
 
-	ucRequest := listservers.NewRequest(q, h.opts.Liveness, ds.Master)
-
-	servers, err := h.uc.Execute(ctx, ucRequest)
+	entries, err := h.listServers(ctx, q)
 	if err != nil {
 		return nil, err
 	}
+	if h.opts.Order == OrderNearest {
+		h.sortByProximity(entries, remoteAddr)
+	}
+	entries = h.promoteFeatured(ctx, entries)
+	if h.opts.Limit > 0 && len(entries) > h.opts.Limit {
+		entries = entries[:h.opts.Limit]
+	}
+	announcements := h.selectAnnouncements(req, q)
 
-	resp := h.packServers(servers, remoteAddr, req.Fields)
+	resp := h.packServers(announcements, entries, remoteAddr, req.Fields)
 	h.logger.Debug().
-		Int("count", len(servers)).Stringer("src", remoteAddr).Str("filters", req.Filters).
+		Int("count", len(entries)).Int("announcements", len(announcements)).
+		Stringer("src", remoteAddr).Str("filters", req.Filters).
+		Str("game", req.GameName).Uint32("version", req.GameVersion).
 		Msg("Packed available")
 
 	return crypt.Encrypt(h.gameKey, req.Challenge, resp), nil
 }
 
-func (h Handler) packServers(servers []server.Server, addr *net.TCPAddr, fields []string) []byte {
+// listServers obtains the servers admitted to the list according to the listing policy.
+// A server that is both reported and discovered is admitted as reported.
+func (h Handler) listServers(ctx context.Context, q query.Query) ([]entry, error) {
+	entries := make([]entry, 0)
+	seen := make(map[addr.Addr]struct{})
+
+	if h.opts.Policy != ListDiscovered {
+		reported, err := h.uc.Execute(ctx, h.prepareRequest(listservers.NewRequest(q, h.opts.Liveness, ds.Master)))
+		if err != nil {
+			return nil, err
+		}
+		for _, svr := range reported {
+			seen[svr.Addr] = struct{}{}
+			entries = append(entries, entry{svr: svr, admission: admittedReported})
+		}
+	}
+
+	if h.opts.Policy == ListDiscovered || h.opts.Policy == ListBoth {
+		// discovered servers are only listed for as long as their details keep being refreshed,
+		// so the servers with failing details probes are left out
+		ucRequest := listservers.NewRequest(q, h.opts.Liveness, ds.Details).WithoutStatus(ds.DetailsRetry)
+		discovered, err := h.uc.Execute(ctx, h.prepareRequest(ucRequest))
+		if err != nil {
+			return nil, err
+		}
+		for _, svr := range discovered {
+			if _, ok := seen[svr.Addr]; ok {
+				continue
+			}
+			entries = append(entries, entry{svr: svr, admission: admittedDiscovered})
+		}
+	}
+
+	return entries, nil
+}
+
+// prepareRequest applies the listing options shared by the requests for the reported and the discovered servers
+func (h Handler) prepareRequest(req listservers.Request) listservers.Request {
+	if h.opts.HideUnverified {
+		req = req.OnlyTrusted()
+	}
+	if h.opts.HideFederated {
+		req = req.WithoutStatus(ds.Federated)
+	}
+	return req
+}
+
+// sortByProximity orders the servers by their estimated distance to the client
+// and then by the number of players, so that the nearest busy servers are listed first.
+// Should the client's location be unknown, the servers are only ordered by the number of players.
+func (h Handler) sortByProximity(entries []entry, remoteAddr *net.TCPAddr) {
+	clientLoc, err := h.locator.Locate(remoteAddr.IP)
+	if err != nil {
+		h.logger.Warn().Err(err).Stringer("src", remoteAddr).Msg("Unable to resolve client location")
+	}
+	slices.SortStableFunc(entries, func(a, b entry) int {
+		return cmp.Or(
+			cmp.Compare(clientLoc.ProximityTo(a.svr.Location), clientLoc.ProximityTo(b.svr.Location)),
+			cmp.Compare(b.svr.Info.NumPlayers, a.svr.Info.NumPlayers),
+		)
+	})
+}
+
+// promoteFeatured moves the featured servers to the top of the list.
+// Failing to obtain the featured servers is not fatal, the list is then left as is.
+func (h Handler) promoteFeatured(ctx context.Context, entries []entry) []entry {
+	items, err := h.featuredUC.Execute(ctx, listfeatured.NewRequest(true))
+	if err != nil {
+		h.logger.Warn().Err(err).Msg("Unable to obtain featured servers")
+		return entries
+	}
+	if len(items) == 0 {
+		return entries
+	}
+	ranking := featured.NewRanking(items)
+	slices.SortStableFunc(entries, func(a, b entry) int {
+		return ranking.Compare(a.svr.Addr, b.svr.Addr)
+	})
+	return entries
+}
+
+// selectAnnouncements picks the announcements targeted at the client
+func (h Handler) selectAnnouncements(req browsing.Request, q query.Query) []Announcement {
+	if len(h.opts.Announcements) == 0 {
+		return nil
+	}
+	now := h.clock.Now()
+	selected := make([]Announcement, 0, len(h.opts.Announcements))
+	for _, a := range h.opts.Announcements {
+		if a.IsTargeted(now, req, q) {
+			selected = append(selected, a)
+		}
+	}
+	return selected
+}
+
+// packServers packs the announcements and the servers, in this order, into the server list response
+func (h Handler) packServers(
+	announcements []Announcement,
+	entries []entry,
+	addr *net.TCPAddr,
+	fields []string,
+) []byte {
 	payload := make([]byte, 6, 26)
 	// the first 6 bytes are the client's IP and port
 	copy(payload[:4], addr.IP.To4())
@@ -158,7 +322,18 @@ func (h Handler) packServers(servers []server.Server, addr *net.TCPAddr, fields
 		payload = append(payload, []byte(field)...)
 		payload = append(payload, 0x00, 0x00)
 	}
-	for _, svr := range servers {
+	for _, a := range announcements {
+		payload = appendEntryAddr(payload, a.addr.Addr().AsSlice(), a.addr.Port())
+		h.metrics.BrowserListed.WithLabelValues(admittedAnnouncement).Inc()
+		// the fields missing in the announcement are left blank
+		for _, field := range fields {
+			payload = append(payload, 0xff)
+			payload = append(payload, []byte(a.fields[field])...)
+			payload = append(payload, 0x00)
+		}
+	}
+	for _, e := range entries {
+		svr := e.svr
 		svrInfo := svr.Info
 		svrParams, err := params.Marshal(&svrInfo)
 		if err != nil {
@@ -167,12 +342,8 @@ func (h Handler) packServers(servers []server.Server, addr *net.TCPAddr, fields
 				Msg("Unable to obtain params for server")
 			continue
 		}
-		// first 7 bytes is the server address
-		serverAddr := make([]byte, 7)
-		serverAddr[0] = 0x51
-		copy(serverAddr[1:5], svr.Addr.GetIP())
-		binary.BigEndian.PutUint16(serverAddr[5:7], uint16(svr.QueryPort)) //nolint:gosec
-		payload = append(payload, serverAddr...)
+		payload = appendEntryAddr(payload, svr.Addr.GetIP(), uint16(svr.QueryPort)) //nolint:gosec
+		h.metrics.BrowserListed.WithLabelValues(e.admission).Inc()
 		// insert field values' in the same order as in the field declaration
 		for _, field := range fields {
 			payload = append(payload, 0xff)
@@ -189,3 +360,12 @@ func (h Handler) packServers(servers []server.Server, addr *net.TCPAddr, fields
 	}
 	return append(payload, 0x00, 0xff, 0xff, 0xff, 0xff)
 }
+
+// appendEntryAddr appends the 7 bytes that open a list entry with its address
+func appendEntryAddr(payload []byte, ip net.IP, port uint16) []byte {
+	entryAddr := make([]byte, 7)
+	entryAddr[0] = 0x51
+	copy(entryAddr[1:5], ip.To4())
+	binary.BigEndian.PutUint16(entryAddr[5:7], port)
+	return append(payload, entryAddr...)
+}
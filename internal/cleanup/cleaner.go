@@ -24,8 +24,13 @@ func (m *Manager) AddCleaner(c Cleaner) {
 	m.cleaners = append(m.cleaners, c)
 }
 
+// Clean runs the cleaners concurrently and waits for all of them to complete
 func (m *Manager) Clean(ctx context.Context) {
+	wg := &sync.WaitGroup{}
 	for _, c := range m.cleaners {
-		go c.Clean(ctx)
+		wg.Go(func() {
+			c.Clean(ctx)
+		})
 	}
+	wg.Wait()
 }
@@ -19,27 +19,33 @@ type Opts struct {
 }
 
 type ServerCleaner struct {
-	opts       Opts
-	serverRepo repositories.ServerRepository
-	clock      clockwork.Clock
-	metrics    *metrics.Collector
-	logger     *zerolog.Logger
+	opts        Opts
+	serverRepo  repositories.ServerRepository
+	profileRepo repositories.ProfileRepository
+	sessionRepo repositories.SessionRepository
+	clock       clockwork.Clock
+	metrics     *metrics.Collector
+	logger      *zerolog.Logger
 }
 
 func New(
 	manager *cleanup.Manager,
 	opts Opts,
 	serverRepo repositories.ServerRepository,
+	profileRepo repositories.ProfileRepository,
+	sessionRepo repositories.SessionRepository,
 	clock clockwork.Clock,
 	metrics *metrics.Collector,
 	logger *zerolog.Logger,
 ) ServerCleaner {
 	cleaner := ServerCleaner{
-		opts:       opts,
-		serverRepo: serverRepo,
-		clock:      clock,
-		metrics:    metrics,
-		logger:     logger,
+		opts:        opts,
+		serverRepo:  serverRepo,
+		profileRepo: profileRepo,
+		sessionRepo: sessionRepo,
+		clock:       clock,
+		metrics:     metrics,
+		logger:      logger,
 	}
 	manager.AddCleaner(&cleaner)
 	return cleaner
@@ -74,11 +80,13 @@ func (c ServerCleaner) cleanServers(
 ) (int, int) {
 	removed, errors := 0, 0
 	for _, svr := range servers {
+		kept := false
 		err := c.serverRepo.Remove(ctx, svr, func(conflict *server.Server) bool {
 			if conflict.RefreshedAt.After(cleanUntil) {
 				c.logger.Info().
 					Stringer("server", conflict).Stringer("refreshed", conflict.RefreshedAt).
 					Msg("Removed server is more recent")
+				kept = true
 				return false
 			}
 			return true
@@ -91,6 +99,24 @@ func (c ServerCleaner) cleanServers(
 			errors++
 			continue
 		}
+		// the profile is only editable by the owner of the server, who is gone along with the server
+		if !kept {
+			if err = c.profileRepo.Remove(ctx, svr.Addr); err != nil {
+				c.logger.Error().
+					Err(err).Stringer("addr", svr.Addr).
+					Msg("Failed to remove profile of outdated server")
+				errors++
+				continue
+			}
+			// the players are gone along with the server
+			if _, err = c.sessionRepo.EndActive(ctx, svr.Addr, c.clock.Now()); err != nil {
+				c.logger.Error().
+					Err(err).Stringer("addr", svr.Addr).
+					Msg("Failed to end player sessions of outdated server")
+				errors++
+				continue
+			}
+		}
 		removed++
 	}
 	return removed, errors
@@ -14,6 +14,7 @@ import (
 
 	"github.com/sergeii/swat4master/internal/cleanup"
 	"github.com/sergeii/swat4master/internal/cleanup/cleaners/servercleaner"
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
 	"github.com/sergeii/swat4master/internal/core/entities/filterset"
 	"github.com/sergeii/swat4master/internal/core/entities/server"
 	"github.com/sergeii/swat4master/internal/core/repositories"
@@ -40,6 +41,26 @@ func (m *MockServerRepository) Remove(
 	return args.Error(0)
 }
 
+type MockProfileRepository struct {
+	mock.Mock
+	repositories.ProfileRepository
+}
+
+func (m *MockProfileRepository) Remove(ctx context.Context, svrAddr addr.Addr) error {
+	args := m.Called(ctx, svrAddr)
+	return args.Error(0)
+}
+
+type MockSessionRepository struct {
+	mock.Mock
+	repositories.SessionRepository
+}
+
+func (m *MockSessionRepository) EndActive(ctx context.Context, svrAddr addr.Addr, endedAt time.Time) (int, error) {
+	args := m.Called(ctx, svrAddr, endedAt)
+	return args.Int(0), args.Error(1)
+}
+
 // func TestCleanServersUseCase_RemoveErrors(t *testing.T) {
 //	ctx := context.TODO()
 //	logger := zerolog.Nop()
@@ -90,10 +111,18 @@ func TestServerCleaner_Clean_OK(t *testing.T) {
 	serverRepo.On("Filter", ctx, mock.Anything).Return(outdatedServers, nil).Once()
 	serverRepo.On("Remove", ctx, mock.Anything, mock.Anything).Return(nil).Times(2)
 
+	profileRepo := new(MockProfileRepository)
+	profileRepo.On("Remove", ctx, mock.Anything).Return(nil).Times(2)
+
+	sessionRepo := new(MockSessionRepository)
+	sessionRepo.On("EndActive", ctx, mock.Anything, clock.Now()).Return(1, nil).Times(2)
+
 	cleaner := servercleaner.New(
 		manager,
 		options,
 		serverRepo,
+		profileRepo,
+		sessionRepo,
 		clock,
 		collector,
 		&logger,
@@ -113,6 +142,8 @@ func TestServerCleaner_Clean_OK(t *testing.T) {
 	)
 	for _, svr := range outdatedServers {
 		serverRepo.AssertCalled(t, "Remove", ctx, svr, mock.Anything)
+		profileRepo.AssertCalled(t, "Remove", ctx, svr.Addr)
+		sessionRepo.AssertCalled(t, "EndActive", ctx, svr.Addr, clock.Now())
 	}
 
 	cleanerRemovalsWithServersValue := testutil.ToFloat64(collector.CleanerRemovals.WithLabelValues("servers"))
@@ -135,10 +166,15 @@ func TestServerCleaner_Clean_NothingToClean(t *testing.T) {
 	serverRepo := new(MockServerRepository)
 	serverRepo.On("Filter", ctx, mock.Anything).Return([]server.Server{}, nil).Once()
 
+	profileRepo := new(MockProfileRepository)
+	sessionRepo := new(MockSessionRepository)
+
 	cleaner := servercleaner.New(
 		manager,
 		options,
 		serverRepo,
+		profileRepo,
+		sessionRepo,
 		clock,
 		collector,
 		&logger,
@@ -168,18 +204,31 @@ func TestServerCleaner_Clean_RepoErrors(t *testing.T) {
 	svr1 := serverfactory.BuildRandom()
 	svr2 := serverfactory.BuildRandom()
 	svr3 := serverfactory.BuildRandom()
-	outdatedServers := []server.Server{svr1, svr2, svr3}
+	svr4 := serverfactory.BuildRandom()
+	outdatedServers := []server.Server{svr1, svr2, svr3, svr4}
 
 	serverRepo := new(MockServerRepository)
 	serverRepo.On("Filter", ctx, mock.Anything).Return(outdatedServers, nil).Once()
 	serverRepo.On("Remove", ctx, svr1, mock.Anything).Return(nil).Once()
 	serverRepo.On("Remove", ctx, svr2, mock.Anything).Return(nil).Once()
 	serverRepo.On("Remove", ctx, svr3, mock.Anything).Return(errors.New("error")).Once()
+	serverRepo.On("Remove", ctx, svr4, mock.Anything).Return(nil).Once()
+
+	profileRepo := new(MockProfileRepository)
+	profileRepo.On("Remove", ctx, svr1.Addr).Return(nil).Once()
+	profileRepo.On("Remove", ctx, svr2.Addr).Return(errors.New("error")).Once()
+	profileRepo.On("Remove", ctx, svr4.Addr).Return(nil).Once()
+
+	sessionRepo := new(MockSessionRepository)
+	sessionRepo.On("EndActive", ctx, svr1.Addr, clock.Now()).Return(0, nil).Once()
+	sessionRepo.On("EndActive", ctx, svr4.Addr, clock.Now()).Return(0, errors.New("error")).Once()
 
 	cleaner := servercleaner.New(
 		manager,
 		options,
 		serverRepo,
+		profileRepo,
+		sessionRepo,
 		clock,
 		collector,
 		&logger,
@@ -187,10 +236,59 @@ func TestServerCleaner_Clean_RepoErrors(t *testing.T) {
 	cleaner.Clean(ctx)
 
 	serverRepo.AssertExpectations(t)
-	serverRepo.AssertNumberOfCalls(t, "Remove", 3)
+	serverRepo.AssertNumberOfCalls(t, "Remove", 4)
+	profileRepo.AssertExpectations(t)
+	profileRepo.AssertNotCalled(t, "Remove", ctx, svr3.Addr)
+	sessionRepo.AssertExpectations(t)
+	sessionRepo.AssertNumberOfCalls(t, "EndActive", 2)
 
 	cleanerRemovalsWithServersValue := testutil.ToFloat64(collector.CleanerRemovals.WithLabelValues("servers"))
-	assert.InDelta(t, float64(2), cleanerRemovalsWithServersValue, 1e-9)
+	assert.InDelta(t, float64(1), cleanerRemovalsWithServersValue, 1e-9)
 	cleanerErrorsWithServersValue := testutil.ToFloat64(collector.CleanerErrors.WithLabelValues("servers"))
-	assert.InDelta(t, float64(1), cleanerErrorsWithServersValue, 1e-9)
+	assert.InDelta(t, float64(3), cleanerErrorsWithServersValue, 1e-9)
+}
+
+func TestServerCleaner_Clean_KeepsProfileOfRecentServer(t *testing.T) {
+	ctx := context.TODO()
+
+	manager := cleanup.NewManager()
+	collector := metrics.New()
+	clock := clockwork.NewFakeClock()
+	logger := zerolog.Nop()
+	options := servercleaner.Opts{
+		Retention: time.Hour * 24,
+	}
+
+	svr := serverfactory.BuildRandom()
+
+	serverRepo := new(MockServerRepository)
+	serverRepo.On("Filter", ctx, mock.Anything).Return([]server.Server{svr}, nil).Once()
+	serverRepo.On("Remove", ctx, svr, mock.Anything).
+		Run(func(args mock.Arguments) {
+			// the server has been refreshed since it was picked for cleanup
+			onConflict := args.Get(2).(func(*server.Server) bool) //nolint: forcetypeassert
+			recent := svr
+			recent.RefreshedAt = clock.Now()
+			assert.False(t, onConflict(&recent))
+		}).
+		Return(nil).Once()
+
+	profileRepo := new(MockProfileRepository)
+	sessionRepo := new(MockSessionRepository)
+
+	cleaner := servercleaner.New(
+		manager,
+		options,
+		serverRepo,
+		profileRepo,
+		sessionRepo,
+		clock,
+		collector,
+		&logger,
+	)
+	cleaner.Clean(ctx)
+
+	serverRepo.AssertExpectations(t)
+	profileRepo.AssertNotCalled(t, "Remove", mock.Anything, mock.Anything)
+	sessionRepo.AssertNotCalled(t, "EndActive", mock.Anything, mock.Anything, mock.Anything)
 }
@@ -19,6 +19,7 @@ const (
 	Port
 	PortRetry
 	NoPort
+	Federated
 )
 
 const NoStatus = DiscoveryStatus(0)
@@ -34,6 +35,7 @@ func Members() []DiscoveryStatus {
 		Port,
 		PortRetry,
 		NoPort,
+		Federated,
 	}
 }
 
@@ -61,6 +63,8 @@ func (ds DiscoveryStatus) BitString() string {
 		return "port_retry"
 	case NoPort:
 		return "no_port"
+	case Federated:
+		return "federated"
 	}
 	return fmt.Sprintf("%d", ds)
 }
@@ -36,7 +36,7 @@ func TestDiscoveryStatus_Bits(t *testing.T) {
 		},
 		{
 			name:   "All statuses",
-			status: ds.Master | ds.Info | ds.Details | ds.DetailsRetry | ds.NoDetails | ds.Port | ds.PortRetry | ds.NoPort,
+			status: ds.Master | ds.Info | ds.Details | ds.DetailsRetry | ds.NoDetails | ds.Port | ds.PortRetry | ds.NoPort | ds.Federated, //nolint:lll
 			want: []ds.DiscoveryStatus{
 				ds.Master,
 				ds.Info,
@@ -46,6 +46,7 @@ func TestDiscoveryStatus_Bits(t *testing.T) {
 				ds.Port,
 				ds.PortRetry,
 				ds.NoPort,
+				ds.Federated,
 			},
 		},
 	}
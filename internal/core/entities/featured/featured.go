@@ -0,0 +1,83 @@
+package featured
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+)
+
+var NC = time.Time{} // no constraint
+
+// Featured is a server promoted by the admins to the top of server lists.
+// The servers with the lower priority value come first.
+// The optional window limits the time the server is promoted for.
+type Featured struct {
+	Addr     addr.Addr `json:"addr"`
+	Priority int       `json:"priority"`
+	Since    time.Time `json:"since"`
+	Until    time.Time `json:"until"`
+}
+
+var Blank Featured //nolint: gochecknoglobals
+
+func New(addr addr.Addr, priority int, since, until time.Time) Featured {
+	return Featured{
+		Addr:     addr,
+		Priority: priority,
+		Since:    since,
+		Until:    until,
+	}
+}
+
+// IsActive tells whether the server is promoted at the given time.
+// The window start is inclusive, whereas the end is exclusive.
+func (f Featured) IsActive(now time.Time) bool {
+	if !f.Since.IsZero() && now.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !now.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// Ranking tells the position of a featured server among the other featured servers
+type Ranking struct {
+	ranks map[addr.Addr]int
+}
+
+func NewRanking(items []Featured) Ranking {
+	sorted := slices.Clone(items)
+	slices.SortStableFunc(sorted, func(a, b Featured) int {
+		return cmp.Compare(a.Priority, b.Priority)
+	})
+	ranks := make(map[addr.Addr]int, len(sorted))
+	for i, item := range sorted {
+		ranks[item.Addr] = i
+	}
+	return Ranking{ranks: ranks}
+}
+
+func (r Ranking) IsFeatured(svrAddr addr.Addr) bool {
+	_, ok := r.ranks[svrAddr]
+	return ok
+}
+
+// Compare orders featured servers before the others, in the order of their priority.
+// The servers that are not featured are considered equal.
+func (r Ranking) Compare(a, b addr.Addr) int {
+	rankA, okA := r.ranks[a]
+	rankB, okB := r.ranks[b]
+	switch {
+	case okA && okB:
+		return cmp.Compare(rankA, rankB)
+	case okA:
+		return -1
+	case okB:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,59 @@
+package featured_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/featured"
+)
+
+func TestFeatured_IsActive(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		since time.Time
+		until time.Time
+		want  bool
+	}{
+		{"no window", featured.NC, featured.NC, true},
+		{"window has started", now.Add(-time.Hour), featured.NC, true},
+		{"window starts now", now, featured.NC, true},
+		{"window has not started", now.Add(time.Hour), featured.NC, false},
+		{"window has not ended", featured.NC, now.Add(time.Hour), true},
+		{"window ends now", featured.NC, now, false},
+		{"window has ended", featured.NC, now.Add(-time.Hour), false},
+		{"within window", now.Add(-time.Hour), now.Add(time.Hour), true},
+		{"after window", now.Add(-time.Hour * 2), now.Add(-time.Hour), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := featured.New(addr.MustNewFromDotted("1.1.1.1", 10480), 0, tt.since, tt.until)
+			assert.Equal(t, tt.want, item.IsActive(now))
+		})
+	}
+}
+
+func TestRanking_Compare(t *testing.T) {
+	addr1 := addr.MustNewFromDotted("1.1.1.1", 10480)
+	addr2 := addr.MustNewFromDotted("2.2.2.2", 10480)
+	addr3 := addr.MustNewFromDotted("3.3.3.3", 10480)
+	addr4 := addr.MustNewFromDotted("4.4.4.4", 10480)
+	addr5 := addr.MustNewFromDotted("5.5.5.5", 10480)
+
+	ranking := featured.NewRanking([]featured.Featured{
+		featured.New(addr4, 10, featured.NC, featured.NC),
+		featured.New(addr2, 5, featured.NC, featured.NC),
+	})
+
+	assert.True(t, ranking.IsFeatured(addr2))
+	assert.True(t, ranking.IsFeatured(addr4))
+	assert.False(t, ranking.IsFeatured(addr1))
+
+	addrs := []addr.Addr{addr1, addr2, addr3, addr4, addr5}
+	slices.SortStableFunc(addrs, ranking.Compare)
+	assert.Equal(t, []addr.Addr{addr2, addr4, addr1, addr3, addr5}, addrs)
+}
@@ -0,0 +1,128 @@
+package history
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+)
+
+// Sample is the state of a server at a point in time
+type Sample struct {
+	Addr       addr.Addr
+	At         time.Time
+	Online     bool
+	Players    int
+	MaxPlayers int
+	MapName    string
+	GameType   string
+}
+
+// Point aggregates the samples of a server taken within a time bucket.
+// The map, the game type and the max players are the ones last seen while the server was online.
+type Point struct {
+	At            time.Time `json:"at"`
+	Samples       int       `json:"samples"`
+	OnlineSamples int       `json:"online"`
+	PlayersSum    int       `json:"players_sum"`
+	PlayersMax    int       `json:"players_max"`
+	MaxPlayers    int       `json:"max_players"`
+	MapName       string    `json:"map,omitempty"`
+	GameType      string    `json:"gametype,omitempty"`
+}
+
+func NewPoint(at time.Time) Point {
+	return Point{At: at}
+}
+
+// Add accounts the sample in the point
+func (p *Point) Add(s Sample) {
+	p.Samples++
+	if !s.Online {
+		return
+	}
+	p.OnlineSamples++
+	p.PlayersSum += s.Players
+	p.PlayersMax = max(p.PlayersMax, s.Players)
+	p.MaxPlayers = s.MaxPlayers
+	p.MapName = s.MapName
+	p.GameType = s.GameType
+}
+
+// Merge accounts the other point that follows this point in time
+func (p *Point) Merge(other Point) {
+	p.Samples += other.Samples
+	p.PlayersSum += other.PlayersSum
+	p.PlayersMax = max(p.PlayersMax, other.PlayersMax)
+	if other.OnlineSamples > 0 {
+		p.OnlineSamples += other.OnlineSamples
+		p.MaxPlayers = other.MaxPlayers
+		p.MapName = other.MapName
+		p.GameType = other.GameType
+	}
+}
+
+// PlayersAvg is the average number of players while the server was online
+func (p Point) PlayersAvg() float64 {
+	if p.OnlineSamples == 0 {
+		return 0
+	}
+	return float64(p.PlayersSum) / float64(p.OnlineSamples)
+}
+
+// Uptime is the share of the samples that have found the server online
+func (p Point) Uptime() float64 {
+	if p.Samples == 0 {
+		return 0
+	}
+	return float64(p.OnlineSamples) / float64(p.Samples)
+}
+
+// Resolution is the granularity the history of a server is kept at.
+// The samples are aggregated into the buckets of the resolution interval
+// and the buckets are kept for the resolution retention.
+type Resolution struct {
+	Name      string
+	Interval  time.Duration
+	Retention time.Duration
+}
+
+var ( //nolint: gochecknoglobals
+	Raw         = Resolution{Name: "raw", Interval: time.Minute, Retention: time.Hour * 24}
+	FiveMinutes = Resolution{Name: "5m", Interval: time.Minute * 5, Retention: time.Hour * 24 * 30}
+	Hourly      = Resolution{Name: "1h", Interval: time.Hour, Retention: time.Hour * 24 * 365}
+)
+
+// Resolutions lists the resolutions from the finest to the coarsest
+var Resolutions = []Resolution{Raw, FiveMinutes, Hourly} //nolint: gochecknoglobals
+
+var ErrOutOfRetention = errors.New("the time is beyond the retention of any resolution")
+
+// Bucket returns the start of the resolution bucket the time falls in
+func (r Resolution) Bucket(at time.Time) time.Time {
+	return at.Truncate(r.Interval)
+}
+
+// ResolutionFor picks the finest resolution that still keeps the history since the given time
+func ResolutionFor(since, now time.Time) (Resolution, error) {
+	for _, res := range Resolutions {
+		if !since.Before(now.Add(-res.Retention)) {
+			return res, nil
+		}
+	}
+	return Resolution{}, ErrOutOfRetention
+}
+
+// Downsample merges the points, which are expected to be ordered by time,
+// into the buckets of the given step aligned to the start time.
+func Downsample(points []Point, start time.Time, step time.Duration) []Point {
+	result := make([]Point, 0, len(points))
+	for _, pt := range points {
+		bucket := start.Add(pt.At.Sub(start) / step * step)
+		if len(result) == 0 || !result[len(result)-1].At.Equal(bucket) {
+			result = append(result, NewPoint(bucket))
+		}
+		result[len(result)-1].Merge(pt)
+	}
+	return result
+}
@@ -0,0 +1,109 @@
+package history_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/history"
+)
+
+func TestPoint_AddAndMerge(t *testing.T) {
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	pt := history.NewPoint(at)
+	assert.InDelta(t, 0.0, pt.Uptime(), 1e-9)
+	assert.InDelta(t, 0.0, pt.PlayersAvg(), 1e-9)
+
+	pt.Add(history.Sample{At: at, Online: true, Players: 4, MaxPlayers: 16, MapName: "A-Bomb Nightclub", GameType: "VIP"})
+	pt.Add(history.Sample{At: at.Add(time.Second * 20)})
+	pt.Add(history.Sample{At: at.Add(time.Second * 40), Online: true, Players: 8, MaxPlayers: 12, MapName: "Sellers"})
+
+	assert.Equal(t, 3, pt.Samples)
+	assert.Equal(t, 2, pt.OnlineSamples)
+	assert.InDelta(t, 2.0/3.0, pt.Uptime(), 1e-9)
+	assert.InDelta(t, 6.0, pt.PlayersAvg(), 1e-9)
+	assert.Equal(t, 8, pt.PlayersMax)
+	assert.Equal(t, 12, pt.MaxPlayers)
+	assert.Equal(t, "Sellers", pt.MapName)
+
+	offline := history.NewPoint(at.Add(time.Minute))
+	offline.Add(history.Sample{At: at.Add(time.Minute)})
+	pt.Merge(offline)
+	assert.Equal(t, 4, pt.Samples)
+	assert.InDelta(t, 0.5, pt.Uptime(), 1e-9)
+	// the last online state is kept
+	assert.Equal(t, "Sellers", pt.MapName)
+	assert.Equal(t, 12, pt.MaxPlayers)
+
+	busy := history.NewPoint(at.Add(time.Minute * 2))
+	busy.Add(history.Sample{Online: true, Players: 14, MaxPlayers: 16, MapName: "Old Granite Hotel"})
+	pt.Merge(busy)
+	assert.Equal(t, 5, pt.Samples)
+	assert.Equal(t, 3, pt.OnlineSamples)
+	assert.Equal(t, 14, pt.PlayersMax)
+	assert.InDelta(t, 26.0/3.0, pt.PlayersAvg(), 1e-9)
+	assert.Equal(t, "Old Granite Hotel", pt.MapName)
+	assert.Equal(t, at, pt.At)
+}
+
+func TestResolutionFor(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		since   time.Time
+		want    history.Resolution
+		wantErr error
+	}{
+		{"within a day", now.Add(-time.Hour), history.Raw, nil},
+		{"exactly a day", now.Add(-time.Hour * 24), history.Raw, nil},
+		{"within a month", now.Add(-time.Hour * 25), history.FiveMinutes, nil},
+		{"within a year", now.Add(-time.Hour * 24 * 31), history.Hourly, nil},
+		{"beyond a year", now.Add(-time.Hour * 24 * 366), history.Resolution{}, history.ErrOutOfRetention},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := history.ResolutionFor(tt.since, now)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	points := make([]history.Point, 0)
+	for i := range 7 {
+		pt := history.NewPoint(start.Add(time.Minute * 5 * time.Duration(i)))
+		pt.Add(history.Sample{Online: i != 3, Players: i})
+		points = append(points, pt)
+	}
+
+	got := history.Downsample(points, start, time.Minute*15)
+	require.Len(t, got, 3)
+
+	assert.Equal(t, start, got[0].At)
+	assert.Equal(t, 3, got[0].Samples)
+	assert.Equal(t, 2, got[0].PlayersMax)
+	assert.InDelta(t, 1.0, got[0].PlayersAvg(), 1e-9)
+
+	assert.Equal(t, start.Add(time.Minute*15), got[1].At)
+	assert.Equal(t, 3, got[1].Samples)
+	assert.Equal(t, 2, got[1].OnlineSamples)
+	assert.InDelta(t, 4.5, got[1].PlayersAvg(), 1e-9)
+
+	assert.Equal(t, start.Add(time.Minute*30), got[2].At)
+	assert.Equal(t, 1, got[2].Samples)
+	assert.Equal(t, 6, got[2].PlayersMax)
+
+	assert.Empty(t, history.Downsample(nil, start, time.Minute))
+}
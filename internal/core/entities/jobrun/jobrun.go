@@ -0,0 +1,45 @@
+package jobrun
+
+import (
+	"time"
+)
+
+type Outcome string
+
+const (
+	OutcomeSuccess Outcome = "success"
+	OutcomeFailure Outcome = "failure"
+)
+
+// Run is a single run of a scheduled job.
+// ScheduledAt is the time the run was due, which is shared by all replicas running the job,
+// so that the next run is scheduled after it regardless of the replica that has run the job.
+type Run struct {
+	Job         string    `json:"job"`
+	ScheduledAt time.Time `json:"scheduled_at"`
+	StartedAt   time.Time `json:"started_at"`
+	FinishedAt  time.Time `json:"finished_at"`
+	Outcome     Outcome   `json:"outcome"`
+	Error       string    `json:"error,omitempty"`
+}
+
+var Blank Run //nolint: gochecknoglobals
+
+func New(job string, scheduledAt, startedAt, finishedAt time.Time, err error) Run {
+	run := Run{
+		Job:         job,
+		ScheduledAt: scheduledAt,
+		StartedAt:   startedAt,
+		FinishedAt:  finishedAt,
+		Outcome:     OutcomeSuccess,
+	}
+	if err != nil {
+		run.Outcome = OutcomeFailure
+		run.Error = err.Error()
+	}
+	return run
+}
+
+func (r Run) Duration() time.Duration {
+	return r.FinishedAt.Sub(r.StartedAt)
+}
@@ -0,0 +1,97 @@
+package location
+
+import (
+	"math"
+	"net"
+)
+
+// Location is where the server is hosted, as resolved from its IP address.
+// The location remembers the address it was resolved for,
+// so that it can be resolved again once the server changes its address.
+type Location struct {
+	IP          net.IP
+	Country     string       // ISO 3166-1 alpha-2 country code, e.g. DE
+	Continent   string       // two-letter continent code, e.g. EU
+	ASN         int          // number of the autonomous system the address belongs to
+	Coordinates *Coordinates // approximate coordinates of the address, only resolved with a city database
+}
+
+// Coordinates are the latitude and the longitude of a location, in degrees
+type Coordinates struct {
+	Latitude  float64
+	Longitude float64
+}
+
+var Blank Location //nolint: gochecknoglobals
+
+func New(ip net.IP, country, continent string, asn int) Location {
+	return Location{
+		IP:        ip,
+		Country:   country,
+		Continent: continent,
+		ASN:       asn,
+	}
+}
+
+// WithCoordinates returns the location with its coordinates known
+func (l Location) WithCoordinates(latitude, longitude float64) Location {
+	l.Coordinates = &Coordinates{Latitude: latitude, Longitude: longitude}
+	return l
+}
+
+// IsResolvedFor tells whether the location has been resolved for the given address
+func (l Location) IsResolvedFor(ip net.IP) bool {
+	return l.IP != nil && l.IP.Equal(ip)
+}
+
+// IsKnown tells whether the country of the address has been resolved
+func (l Location) IsKnown() bool {
+	return l.Country != ""
+}
+
+// Proximity is an estimate of the distance between two locations in kilometers,
+// with the lesser values meaning the locations are closer to each other
+type Proximity int
+
+// The distances assumed for the locations whose coordinates are unknown,
+// so that they can be compared with the distances between the coordinates
+const (
+	SameCountry   Proximity = 500
+	SameContinent Proximity = 2500
+	Remote        Proximity = 10000
+	// Unknown is the proximity of the locations either of which is unknown
+	Unknown Proximity = math.MaxInt32
+)
+
+// earthRadius is the mean radius of the Earth in kilometers
+const earthRadius = 6371
+
+// ProximityTo estimates how close the other location is to this one.
+// The distance between the coordinates is used when both of the locations have them,
+// otherwise the distance is assumed from whether the locations share the country or the continent.
+func (l Location) ProximityTo(other Location) Proximity {
+	switch {
+	case !l.IsKnown() || !other.IsKnown():
+		return Unknown
+	case l.Coordinates != nil && other.Coordinates != nil:
+		return Proximity(math.Round(l.Coordinates.DistanceTo(*other.Coordinates)))
+	case l.Country == other.Country:
+		return SameCountry
+	case l.Continent != "" && l.Continent == other.Continent:
+		return SameContinent
+	default:
+		return Remote
+	}
+}
+
+// DistanceTo returns the great-circle distance to the other coordinates in kilometers
+func (c Coordinates) DistanceTo(other Coordinates) float64 {
+	lat1, lat2 := radians(c.Latitude), radians(other.Latitude)
+	dLat, dLon := lat2-lat1, radians(other.Longitude-c.Longitude)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
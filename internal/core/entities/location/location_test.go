@@ -0,0 +1,77 @@
+package location_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergeii/swat4master/internal/core/entities/location"
+)
+
+func TestLocation_IsResolvedFor(t *testing.T) {
+	loc := location.New(net.ParseIP("1.1.1.1"), "AU", "OC", 13335)
+	assert.True(t, loc.IsResolvedFor(net.ParseIP("1.1.1.1")))
+	assert.True(t, loc.IsResolvedFor(net.IPv4(1, 1, 1, 1).To4()))
+	assert.False(t, loc.IsResolvedFor(net.ParseIP("2.2.2.2")))
+	assert.False(t, location.Blank.IsResolvedFor(net.ParseIP("1.1.1.1")))
+	assert.False(t, location.Blank.IsResolvedFor(nil))
+}
+
+func TestLocation_ProximityTo(t *testing.T) {
+	berlin := location.New(net.ParseIP("1.1.1.1"), "DE", "EU", 0)
+	munich := location.New(net.ParseIP("2.2.2.2"), "DE", "EU", 0)
+	paris := location.New(net.ParseIP("3.3.3.3"), "FR", "EU", 0)
+	dallas := location.New(net.ParseIP("4.4.4.4"), "US", "NA", 0)
+	nowhere := location.New(net.ParseIP("5.5.5.5"), "", "", 0)
+	noContinent := location.New(net.ParseIP("6.6.6.6"), "XK", "", 0)
+
+	tests := []struct {
+		name  string
+		this  location.Location
+		other location.Location
+		want  location.Proximity
+	}{
+		{"same country", berlin, munich, location.SameCountry},
+		{"same continent", berlin, paris, location.SameContinent},
+		{"different continents", paris, dallas, location.Remote},
+		{"unknown other location", berlin, nowhere, location.Unknown},
+		{"unknown this location", nowhere, berlin, location.Unknown},
+		{"blank location", location.Blank, location.Blank, location.Unknown},
+		{"unknown continent", noContinent, berlin, location.Remote},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.this.ProximityTo(tt.other))
+		})
+	}
+}
+
+func TestLocation_ProximityTo_Coordinates(t *testing.T) {
+	strasbourg := location.New(net.ParseIP("1.1.1.1"), "FR", "EU", 0).WithCoordinates(48.5734, 7.7521)
+	kehl := location.New(net.ParseIP("2.2.2.2"), "DE", "EU", 0).WithCoordinates(48.5726, 7.8157)
+	marseille := location.New(net.ParseIP("3.3.3.3"), "FR", "EU", 0).WithCoordinates(43.2965, 5.3698)
+	paris := location.New(net.ParseIP("4.4.4.4"), "FR", "EU", 0)
+	nowhere := location.New(net.ParseIP("5.5.5.5"), "", "", 0).WithCoordinates(0, 0)
+
+	tests := []struct {
+		name  string
+		this  location.Location
+		other location.Location
+		want  location.Proximity
+	}{
+		{"neighbour across the border", strasbourg, kehl, 5},
+		{"far within the same country", strasbourg, marseille, 615},
+		{"same location", strasbourg, strasbourg, 0},
+		{"coordinates of other location unknown", strasbourg, paris, location.SameCountry},
+		{"coordinates of this location unknown", paris, kehl, location.SameContinent},
+		{"unknown location with coordinates", nowhere, strasbourg, location.Unknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.this.ProximityTo(tt.other))
+		})
+	}
+
+	assert.Less(t, strasbourg.ProximityTo(kehl), strasbourg.ProximityTo(marseille))
+}
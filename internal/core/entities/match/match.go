@@ -0,0 +1,93 @@
+package match
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/details"
+)
+
+type Winner string
+
+const (
+	WinnerUnknown  Winner = ""
+	WinnerSwat     Winner = "swat"
+	WinnerSuspects Winner = "suspects"
+	WinnerTie      Winner = "tie"
+)
+
+// Match is the result of a round played on a server.
+// The result is taken from the last snapshot of the server details seen before the round was over.
+type Match struct {
+	ID         string              `json:"id"`
+	Addr       addr.Addr           `json:"addr"`
+	Info       details.Info        `json:"info"`
+	Players    []details.Player    `json:"players"`
+	Objectives []details.Objective `json:"objectives"`
+	Winner     Winner              `json:"winner"`
+	EndedAt    time.Time           `json:"ended_at"`
+}
+
+var Blank Match //nolint: gochecknoglobals
+
+// Detect compares two successive details snapshots of a server and
+// tells whether a round has been over in between.
+// A new round is detected when the map or the game type has changed,
+// when the round counter has changed, or when the team scores have been reset.
+// Rounds played by no one are not worth recording, hence ignored.
+func Detect(svrAddr addr.Addr, prev, curr details.Details, at time.Time) (Match, bool) {
+	if len(prev.Players) == 0 || !isNewRound(prev.Info, curr.Info) {
+		return Blank, false
+	}
+	return Match{
+		ID:         uuid.NewString(),
+		Addr:       svrAddr,
+		Info:       prev.Info,
+		Players:    prev.Players,
+		Objectives: prev.Objectives,
+		Winner:     decideWinner(prev.Info, curr.Info),
+		EndedAt:    at,
+	}, true
+}
+
+func isNewRound(prev, curr details.Info) bool {
+	switch {
+	case prev.MapName != curr.MapName, prev.GameType != curr.GameType:
+		return true
+	case prev.Round != curr.Round:
+		return true
+	case curr.SwatScore == 0 && curr.SuspectsScore == 0 && (prev.SwatScore != 0 || prev.SuspectsScore != 0):
+		return true
+	}
+	return false
+}
+
+// decideWinner prefers the won round counters, which are only comparable on the same map,
+// and falls back to the final team scores
+func decideWinner(prev, curr details.Info) Winner {
+	if prev.MapName == curr.MapName && prev.GameType == curr.GameType {
+		swatWon := curr.SwatWon > prev.SwatWon
+		suspectsWon := curr.SuspectsWon > prev.SuspectsWon
+		switch {
+		case swatWon && suspectsWon:
+			return WinnerTie
+		case swatWon:
+			return WinnerSwat
+		case suspectsWon:
+			return WinnerSuspects
+		}
+	}
+	// CO-OP rounds have no team scores
+	if prev.SwatScore == 0 && prev.SuspectsScore == 0 {
+		return WinnerUnknown
+	}
+	switch {
+	case prev.SwatScore > prev.SuspectsScore:
+		return WinnerSwat
+	case prev.SuspectsScore > prev.SwatScore:
+		return WinnerSuspects
+	}
+	return WinnerTie
+}
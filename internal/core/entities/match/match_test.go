@@ -0,0 +1,157 @@
+package match_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/details"
+	"github.com/sergeii/swat4master/internal/core/entities/match"
+)
+
+func TestDetect(t *testing.T) {
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	svrAddr := addr.MustNewFromDotted("1.1.1.1", 10480)
+	players := []details.Player{{Name: "Serge", Score: 10}}
+
+	base := details.Info{
+		MapName:       "A-Bomb Nightclub",
+		GameType:      "VIP Escort",
+		Round:         2,
+		NumRounds:     5,
+		SwatScore:     40,
+		SuspectsScore: 60,
+		SwatWon:       1,
+		SuspectsWon:   0,
+	}
+
+	tests := []struct {
+		name       string
+		prev       details.Details
+		curr       func(details.Info) details.Info
+		wantOK     bool
+		wantWinner match.Winner
+	}{
+		{
+			"same round",
+			details.Details{Info: base, Players: players},
+			func(i details.Info) details.Info {
+				i.SwatScore = 45
+				return i
+			},
+			false,
+			match.WinnerUnknown,
+		},
+		{
+			"round counter has changed, round won counter decides",
+			details.Details{Info: base, Players: players},
+			func(i details.Info) details.Info {
+				i.Round = 3
+				i.SwatScore, i.SuspectsScore = 0, 0
+				i.SwatWon = 2
+				return i
+			},
+			true,
+			match.WinnerSwat,
+		},
+		{
+			"scores have been reset, scores decide",
+			details.Details{Info: base, Players: players},
+			func(i details.Info) details.Info {
+				i.SwatScore, i.SuspectsScore = 0, 0
+				return i
+			},
+			true,
+			match.WinnerSuspects,
+		},
+		{
+			"map has changed",
+			details.Details{Info: base, Players: players},
+			func(i details.Info) details.Info {
+				i.MapName = "Food Wall Restaurant"
+				i.Round = 1
+				i.SwatWon = 0
+				return i
+			},
+			true,
+			match.WinnerSuspects,
+		},
+		{
+			"game type has changed",
+			details.Details{Info: base, Players: players},
+			func(i details.Info) details.Info {
+				i.GameType = "Barricaded Suspects"
+				return i
+			},
+			true,
+			match.WinnerSuspects,
+		},
+		{
+			"tied scores",
+			details.Details{
+				Info: func() details.Info {
+					i := base
+					i.SuspectsScore = 40
+					return i
+				}(),
+				Players: players,
+			},
+			func(i details.Info) details.Info {
+				i.MapName = "Food Wall Restaurant"
+				return i
+			},
+			true,
+			match.WinnerTie,
+		},
+		{
+			"co-op round",
+			details.Details{
+				Info: details.Info{
+					MapName:    "Fairfax Residence",
+					GameType:   "CO-OP",
+					TocReports: "5/13",
+				},
+				Players:    players,
+				Objectives: []details.Objective{{Name: "obj_Rescue_All_Hostages", Status: details.ObjCompleted}},
+			},
+			func(i details.Info) details.Info {
+				i.MapName = "Qwik Fuel Convenience Store"
+				return i
+			},
+			true,
+			match.WinnerUnknown,
+		},
+		{
+			"no players in the finished round",
+			details.Details{Info: base},
+			func(i details.Info) details.Info {
+				i.Round = 3
+				return i
+			},
+			false,
+			match.WinnerUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			curr := details.Details{Info: tt.curr(tt.prev.Info), Players: players}
+			got, ok := match.Detect(svrAddr, tt.prev, curr, at)
+			require.Equal(t, tt.wantOK, ok)
+			if !ok {
+				assert.Equal(t, match.Blank, got)
+				return
+			}
+			assert.NotEmpty(t, got.ID)
+			assert.Equal(t, svrAddr, got.Addr)
+			assert.Equal(t, tt.prev.Info, got.Info)
+			assert.Equal(t, tt.prev.Players, got.Players)
+			assert.Equal(t, tt.prev.Objectives, got.Objectives)
+			assert.Equal(t, tt.wantWinner, got.Winner)
+			assert.Equal(t, at, got.EndedAt)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package portoffset
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Stats counts how many times each query port offset has been discovered
+type Stats map[int]int
+
+// Learned is the discovered query port offsets of the servers
+// sharing the IP address, the network of the address, and of all servers.
+// The servers of a hosting provider usually share both the network and the way the ports are assigned,
+// so the offsets of a provider's other servers are a good guess for a new one.
+type Learned struct {
+	IP      Stats
+	Network Stats
+	Global  Stats
+}
+
+// Likely returns the offset the server's IP address is known to use, if any
+func (l Learned) Likely() (int, bool) {
+	ranked := l.IP.Ranked()
+	if len(ranked) == 0 {
+		return 0, false
+	}
+	return ranked[0], true
+}
+
+// Ranked returns at most limit offsets most often discovered on the server's network,
+// followed by the offsets most often discovered globally
+func (l Learned) Ranked(limit int) []int {
+	ranked := make([]int, 0, limit)
+	for _, offset := range append(l.Network.Ranked(), l.Global.Ranked()...) {
+		if len(ranked) >= limit {
+			break
+		}
+		if !slices.Contains(ranked, offset) {
+			ranked = append(ranked, offset)
+		}
+	}
+	return ranked
+}
+
+// Ranked returns the offsets ordered by the number of times they have been discovered.
+// The lower offsets come first in case of a tie.
+func (s Stats) Ranked() []int {
+	offsets := slices.Collect(maps.Keys(s))
+	slices.SortFunc(offsets, func(a, b int) int {
+		if byCount := cmp.Compare(s[b], s[a]); byCount != 0 {
+			return byCount
+		}
+		return cmp.Compare(a, b)
+	})
+	return offsets
+}
+
+// Network returns the /24 network of the IP address
+func Network(ip [4]byte) string {
+	return fmt.Sprintf("%d.%d.%d.0/24", ip[0], ip[1], ip[2])
+}
@@ -0,0 +1,45 @@
+package portoffset_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergeii/swat4master/internal/core/entities/portoffset"
+)
+
+func TestStats_Ranked(t *testing.T) {
+	stats := portoffset.Stats{1: 10, 2: 3, 4: 10, 10: 20}
+	assert.Equal(t, []int{10, 1, 4, 2}, stats.Ranked())
+	assert.Empty(t, portoffset.Stats{}.Ranked())
+	assert.Empty(t, portoffset.Stats(nil).Ranked())
+}
+
+func TestLearned_Likely(t *testing.T) {
+	learned := portoffset.Learned{
+		Network: portoffset.Stats{3: 5},
+		Global:  portoffset.Stats{1: 100},
+	}
+	_, ok := learned.Likely()
+	assert.False(t, ok)
+
+	learned.IP = portoffset.Stats{2: 1, 5: 3}
+	offset, ok := learned.Likely()
+	assert.True(t, ok)
+	assert.Equal(t, 5, offset)
+}
+
+func TestLearned_Ranked(t *testing.T) {
+	learned := portoffset.Learned{
+		IP:      portoffset.Stats{7: 1},
+		Network: portoffset.Stats{10: 5, 1: 2},
+		Global:  portoffset.Stats{1: 100, 2: 50, 3: 10},
+	}
+	assert.Equal(t, []int{10, 1}, learned.Ranked(2))
+	assert.Equal(t, []int{10, 1, 2, 3}, learned.Ranked(5))
+	assert.Empty(t, portoffset.Learned{}.Ranked(3))
+}
+
+func TestNetwork(t *testing.T) {
+	assert.Equal(t, "81.19.209.0/24", portoffset.Network([4]byte{81, 19, 209, 212}))
+}
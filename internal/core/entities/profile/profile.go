@@ -0,0 +1,34 @@
+package profile
+
+import (
+	"time"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+)
+
+// Profile is the extra information about the server provided by its verified owner
+type Profile struct {
+	Addr        addr.Addr `json:"addr"`
+	Description string    `json:"description"`
+	Website     string    `json:"website"`
+	Discord     string    `json:"discord"`
+	Region      string    `json:"region"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+var Blank Profile //nolint: gochecknoglobals
+
+func New(
+	addr addr.Addr,
+	description, website, discord, region string,
+	updatedAt time.Time,
+) Profile {
+	return Profile{
+		Addr:        addr,
+		Description: description,
+		Website:     website,
+		Discord:     discord,
+		Region:      region,
+		UpdatedAt:   updatedAt,
+	}
+}
@@ -0,0 +1,170 @@
+package server
+
+import (
+	"time"
+)
+
+// Outage is a period of time the server was unavailable.
+// An outage that has not ended yet is ongoing.
+type Outage struct {
+	Start time.Time
+	End   time.Time
+}
+
+// IsOngoing tells whether the server is still unavailable
+func (o Outage) IsOngoing() bool {
+	return o.End.IsZero()
+}
+
+// Duration returns the length of the outage, counting an ongoing outage up to now
+func (o Outage) Duration(now time.Time) time.Duration {
+	return o.end(now).Sub(o.Start)
+}
+
+func (o Outage) end(now time.Time) time.Time {
+	if o.IsOngoing() {
+		return now
+	}
+	return o.End
+}
+
+const (
+	// AvailabilityRetention is how far back the outages of a server are accounted for
+	AvailabilityRetention = time.Hour * 24 * 30
+	// maxOutages caps the number of the outages kept for a flapping server
+	maxOutages = 100
+)
+
+// Availability accounts for the outages of the server since it was first observed,
+// so that it can tell how reliably the server stays online.
+type Availability struct {
+	Since   time.Time
+	Outages []Outage
+}
+
+// IsKnown tells whether the availability of the server has been observed at least once
+func (a Availability) IsKnown() bool {
+	return !a.Since.IsZero()
+}
+
+// IsDown tells whether the server is currently going through an outage
+func (a Availability) IsDown() bool {
+	return len(a.Outages) > 0 && a.Outages[len(a.Outages)-1].IsOngoing()
+}
+
+// GoDown returns the availability with an outage started at the given time,
+// unless the server is already down
+func (a Availability) GoDown(at, now time.Time) Availability {
+	next := a.observe(at)
+	if next.IsDown() {
+		return next
+	}
+	outages := make([]Outage, 0, len(next.Outages)+1)
+	outages = append(outages, next.Outages...)
+	outages = append(outages, Outage{Start: at})
+	next.Outages = outages
+	return next.prune(now)
+}
+
+// GoUp returns the availability with the ongoing outage ended at the given time
+func (a Availability) GoUp(at, now time.Time) Availability {
+	next := a.observe(at)
+	if !next.IsDown() {
+		return next
+	}
+	outages := make([]Outage, len(next.Outages))
+	copy(outages, next.Outages)
+	last := &outages[len(outages)-1]
+	// the server could have been seen shortly before its outage was noticed
+	last.End = at
+	if at.Before(last.Start) {
+		last.End = last.Start
+	}
+	next.Outages = outages
+	return next.prune(now)
+}
+
+// Uptime returns the share of time the server was available within the window preceding now.
+// The window is cut to the moment the server was first observed.
+func (a Availability) Uptime(window time.Duration, now time.Time) float64 {
+	from := a.windowStart(window, now)
+	total := now.Sub(from)
+	if total <= 0 {
+		return 1
+	}
+	down := a.downtime(from, now)
+	return 1 - float64(down)/float64(total)
+}
+
+// RecentOutages returns the outages that overlap with the window preceding now
+func (a Availability) RecentOutages(window time.Duration, now time.Time) []Outage {
+	from := a.windowStart(window, now)
+	recent := make([]Outage, 0, len(a.Outages))
+	for _, o := range a.Outages {
+		if o.end(now).After(from) {
+			recent = append(recent, o)
+		}
+	}
+	return recent
+}
+
+// MeanTimeBetweenOutages returns the average time the server stays available
+// before going through an outage within the retention period.
+// The result is zero for a server that has had no outages.
+func (a Availability) MeanTimeBetweenOutages(now time.Time) time.Duration {
+	from := a.windowStart(AvailabilityRetention, now)
+	count := 0
+	for _, o := range a.Outages {
+		if !o.Start.Before(from) {
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	uptime := now.Sub(from) - a.downtime(from, now)
+	return uptime / time.Duration(count)
+}
+
+func (a Availability) observe(at time.Time) Availability {
+	if !a.IsKnown() {
+		a.Since = at
+	}
+	return a
+}
+
+func (a Availability) prune(now time.Time) Availability {
+	cutoff := now.Add(-AvailabilityRetention)
+	first := 0
+	for first < len(a.Outages) && !a.Outages[first].IsOngoing() && a.Outages[first].End.Before(cutoff) {
+		first++
+	}
+	first = max(first, len(a.Outages)-maxOutages)
+	a.Outages = a.Outages[first:]
+	return a
+}
+
+func (a Availability) windowStart(window time.Duration, now time.Time) time.Time {
+	from := now.Add(-window)
+	if a.Since.After(from) {
+		return a.Since
+	}
+	return from
+}
+
+func (a Availability) downtime(from, to time.Time) time.Duration {
+	var down time.Duration
+	for _, o := range a.Outages {
+		start, end := o.Start, o.end(to)
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			down += end.Sub(start)
+		}
+	}
+	return down
+}
@@ -0,0 +1,198 @@
+package server_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergeii/swat4master/internal/core/entities/server"
+)
+
+func TestAvailability_GoDownGoUp(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var avail server.Availability
+	assert.False(t, avail.IsKnown())
+	assert.False(t, avail.IsDown())
+
+	avail = avail.GoUp(since, since)
+	assert.True(t, avail.IsKnown())
+	assert.False(t, avail.IsDown())
+	assert.Equal(t, since, avail.Since)
+	assert.Empty(t, avail.Outages)
+
+	wentDown := since.Add(time.Hour)
+	avail = avail.GoDown(wentDown, wentDown.Add(time.Minute*3))
+	assert.True(t, avail.IsDown())
+	assert.Equal(t, []server.Outage{{Start: wentDown}}, avail.Outages)
+
+	// the server is already down, the outage is not restarted
+	avail = avail.GoDown(wentDown.Add(time.Minute*10), wentDown.Add(time.Minute*10))
+	assert.Equal(t, []server.Outage{{Start: wentDown}}, avail.Outages)
+
+	cameUp := wentDown.Add(time.Minute * 30)
+	avail = avail.GoUp(cameUp, cameUp)
+	assert.False(t, avail.IsDown())
+	assert.Equal(t, []server.Outage{{Start: wentDown, End: cameUp}}, avail.Outages)
+	assert.Equal(t, since, avail.Since)
+}
+
+func TestAvailability_GoUp_BeforeOutageStart(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	avail := server.Availability{}.GoDown(now, now)
+	avail = avail.GoUp(now.Add(-time.Second), now.Add(time.Minute))
+
+	assert.Equal(t, []server.Outage{{Start: now, End: now}}, avail.Outages)
+}
+
+func TestAvailability_IsImmutable(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	down := server.Availability{}.GoDown(now, now)
+	up := down.GoUp(now.Add(time.Minute), now.Add(time.Minute))
+
+	assert.True(t, down.IsDown())
+	assert.False(t, up.IsDown())
+}
+
+func TestAvailability_Prune(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	avail := server.Availability{}
+	for i := range 150 {
+		start := now.Add(time.Hour * time.Duration(i))
+		avail = avail.GoDown(start, start)
+		avail = avail.GoUp(start.Add(time.Minute), start.Add(time.Minute))
+	}
+	assert.Len(t, avail.Outages, 100)
+	assert.Equal(t, now.Add(time.Hour*50), avail.Outages[0].Start)
+
+	later := now.Add(server.AvailabilityRetention + time.Hour*120)
+	avail = avail.GoDown(later, later)
+	assert.Len(t, avail.Outages, 31)
+	assert.Equal(t, now.Add(time.Hour*120), avail.Outages[0].Start)
+	assert.True(t, avail.IsDown())
+}
+
+func TestAvailability_Uptime(t *testing.T) {
+	now := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		avail  server.Availability
+		window time.Duration
+		want   float64
+	}{
+		{
+			"no outages",
+			server.Availability{Since: now.Add(-time.Hour * 48)},
+			time.Hour * 24,
+			1,
+		},
+		{
+			"outage within window",
+			server.Availability{
+				Since: now.Add(-time.Hour * 48),
+				Outages: []server.Outage{
+					{Start: now.Add(-time.Hour * 12), End: now.Add(-time.Hour * 6)},
+				},
+			},
+			time.Hour * 24,
+			0.75,
+		},
+		{
+			"outage partially before window",
+			server.Availability{
+				Since: now.Add(-time.Hour * 48),
+				Outages: []server.Outage{
+					{Start: now.Add(-time.Hour * 30), End: now.Add(-time.Hour * 18)},
+				},
+			},
+			time.Hour * 24,
+			0.75,
+		},
+		{
+			"ongoing outage",
+			server.Availability{
+				Since: now.Add(-time.Hour * 48),
+				Outages: []server.Outage{
+					{Start: now.Add(-time.Hour * 12)},
+				},
+			},
+			time.Hour * 24,
+			0.5,
+		},
+		{
+			"window is cut to first observation",
+			server.Availability{
+				Since: now.Add(-time.Hour * 4),
+				Outages: []server.Outage{
+					{Start: now.Add(-time.Hour * 2), End: now.Add(-time.Hour)},
+				},
+			},
+			time.Hour * 24,
+			0.75,
+		},
+		{
+			"outage outside window",
+			server.Availability{
+				Since: now.Add(-time.Hour * 48),
+				Outages: []server.Outage{
+					{Start: now.Add(-time.Hour * 40), End: now.Add(-time.Hour * 30)},
+				},
+			},
+			time.Hour * 24,
+			1,
+		},
+		{
+			"observed just now",
+			server.Availability{Since: now},
+			time.Hour * 24,
+			1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, tt.avail.Uptime(tt.window, now), 0.0001)
+		})
+	}
+}
+
+func TestAvailability_RecentOutages(t *testing.T) {
+	now := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	avail := server.Availability{
+		Since: now.Add(-time.Hour * 72),
+		Outages: []server.Outage{
+			{Start: now.Add(-time.Hour * 60), End: now.Add(-time.Hour * 50)},
+			{Start: now.Add(-time.Hour * 30), End: now.Add(-time.Hour * 20)},
+			{Start: now.Add(-time.Hour)},
+		},
+	}
+
+	recent := avail.RecentOutages(time.Hour*24, now)
+	assert.Equal(t, avail.Outages[1:], recent)
+
+	assert.Equal(t, time.Hour*10, recent[0].Duration(now))
+	assert.Equal(t, time.Hour, recent[1].Duration(now))
+	assert.True(t, recent[1].IsOngoing())
+}
+
+func TestAvailability_MeanTimeBetweenOutages(t *testing.T) {
+	now := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	noOutages := server.Availability{Since: now.Add(-time.Hour * 72)}
+	assert.Equal(t, time.Duration(0), noOutages.MeanTimeBetweenOutages(now))
+
+	avail := server.Availability{
+		Since: now.Add(-time.Hour * 72),
+		Outages: []server.Outage{
+			{Start: now.Add(-time.Hour * 60), End: now.Add(-time.Hour * 50)},
+			{Start: now.Add(-time.Hour * 30), End: now.Add(-time.Hour * 20)},
+			{Start: now.Add(-time.Hour * 2)},
+		},
+	}
+	// 72h observed, 22h down, 3 outages
+	assert.Equal(t, time.Hour*50/3, avail.MeanTimeBetweenOutages(now))
+}
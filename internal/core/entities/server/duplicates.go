@@ -0,0 +1,89 @@
+package server
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/pkg/swat/styles"
+)
+
+// Fingerprint identifies the game hosted by the server, so that the same game
+// seen at different addresses can be told apart from the other servers.
+// The fingerprint is made of the hostname, the map, the players in the game
+// and the way the server answers its queries.
+// The servers with no players are not fingerprinted,
+// because the empty servers running the same defaults are hardly distinguishable.
+func (gs *Server) Fingerprint() (string, bool) {
+	if len(gs.Details.Players) == 0 {
+		return "", false
+	}
+	names := make([]string, 0, len(gs.Details.Players))
+	for _, player := range gs.Details.Players {
+		names = append(names, styles.Clean(player.Name))
+	}
+	slices.Sort(names)
+	return gs.fingerprint(names...), true
+}
+
+// LooseFingerprint is the fingerprint of the server that leaves out the players.
+// It's only good enough to tell whether the already linked duplicates still host the same game.
+func (gs *Server) LooseFingerprint() string {
+	return gs.fingerprint()
+}
+
+func (gs *Server) fingerprint(extra ...string) string {
+	parts := []string{
+		strings.ToLower(styles.Clean(gs.Info.Hostname)),
+		gs.Info.MapName,
+		strconv.Itoa(gs.QueryPort - gs.Addr.Port),
+		gs.QueryProtocol,
+	}
+	parts = append(parts, extra...)
+	digest := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(digest[:])
+}
+
+// IsDuplicate tells whether the server has been found to duplicate another server
+func (gs *Server) IsDuplicate() bool {
+	return gs.DuplicateOf != addr.Blank
+}
+
+// LinkDuplicates links the server with the primary server it duplicates, if any,
+// and with the servers that duplicate this one.
+// Returns false if the links have not changed.
+func (gs *Server) LinkDuplicates(primary addr.Addr, duplicates []addr.Addr) bool {
+	if gs.DuplicateOf == primary && slices.Equal(gs.Duplicates, duplicates) {
+		return false
+	}
+	gs.DuplicateOf = primary
+	gs.Duplicates = duplicates
+	return true
+}
+
+// CompareDuplicates ranks the duplicate servers, so that the server to keep listed goes first.
+// The servers reporting to the master are preferred, then the servers observed earlier.
+func CompareDuplicates(a, b Server) int {
+	return cmp.Or(
+		compareBool(b.HasDiscoveryStatus(ds.Master), a.HasDiscoveryStatus(ds.Master)),
+		compareBool(a.Availability.Since.IsZero(), b.Availability.Since.IsZero()),
+		a.Availability.Since.Compare(b.Availability.Since),
+		strings.Compare(a.Addr.String(), b.Addr.String()),
+	)
+}
+
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
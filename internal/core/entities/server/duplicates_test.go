@@ -0,0 +1,155 @@
+package server_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/internal/core/entities/server"
+	"github.com/sergeii/swat4master/internal/testutils/factories/serverfactory"
+)
+
+func TestServer_Fingerprint(t *testing.T) {
+	players := []map[string]string{{"player": "Serge"}, {"player": "Mosquito"}}
+	svr := serverfactory.Build(
+		serverfactory.WithAddress("1.1.1.1", 10480),
+		serverfactory.WithQueryPort(10481),
+		serverfactory.WithInfo(map[string]string{"hostname": "[c=FF0000]Swat4[\\c] Server", "mapname": "Food Wall"}),
+		serverfactory.WithPlayers(players),
+	)
+	fp, ok := svr.Fingerprint()
+	assert.True(t, ok)
+	assert.NotEmpty(t, fp)
+
+	tests := []struct {
+		name      string
+		opts      []serverfactory.BuildOption
+		wantEqual bool
+	}{
+		{
+			"same game at another address",
+			[]serverfactory.BuildOption{
+				serverfactory.WithAddress("2.2.2.2", 10580),
+				serverfactory.WithQueryPort(10581),
+				serverfactory.WithInfo(map[string]string{"hostname": "SWAT4 SERVER", "mapname": "Food Wall"}),
+				serverfactory.WithPlayers([]map[string]string{{"player": "Mosquito"}, {"player": "[c=00FF00]Serge"}}),
+			},
+			true,
+		},
+		{
+			"different query port offset",
+			[]serverfactory.BuildOption{
+				serverfactory.WithAddress("2.2.2.2", 10480),
+				serverfactory.WithQueryPort(10484),
+				serverfactory.WithInfo(map[string]string{"hostname": "Swat4 Server", "mapname": "Food Wall"}),
+				serverfactory.WithPlayers(players),
+			},
+			false,
+		},
+		{
+			"different map",
+			[]serverfactory.BuildOption{
+				serverfactory.WithAddress("2.2.2.2", 10480),
+				serverfactory.WithQueryPort(10481),
+				serverfactory.WithInfo(map[string]string{"hostname": "Swat4 Server", "mapname": "Qwik Fuel"}),
+				serverfactory.WithPlayers(players),
+			},
+			false,
+		},
+		{
+			"different players",
+			[]serverfactory.BuildOption{
+				serverfactory.WithAddress("2.2.2.2", 10480),
+				serverfactory.WithQueryPort(10481),
+				serverfactory.WithInfo(map[string]string{"hostname": "Swat4 Server", "mapname": "Food Wall"}),
+				serverfactory.WithPlayers([]map[string]string{{"player": "Serge"}}),
+			},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := serverfactory.Build(tt.opts...)
+			otherFP, ok := other.Fingerprint()
+			assert.True(t, ok)
+			assert.Equal(t, tt.wantEqual, otherFP == fp)
+		})
+	}
+}
+
+func TestServer_Fingerprint_NoPlayers(t *testing.T) {
+	svr := serverfactory.Build()
+	_, ok := svr.Fingerprint()
+	assert.False(t, ok)
+
+	other := serverfactory.Build(
+		serverfactory.WithAddress("2.2.2.2", 10480),
+		serverfactory.WithPlayers([]map[string]string{{"player": "Serge"}}),
+	)
+	// the loose fingerprint ignores the players
+	assert.Equal(t, svr.LooseFingerprint(), other.LooseFingerprint())
+}
+
+func TestServer_LinkDuplicates(t *testing.T) {
+	primary := addr.MustNewFromDotted("1.1.1.1", 10480)
+	dup := addr.MustNewFromDotted("2.2.2.2", 10480)
+
+	svr := serverfactory.Build(serverfactory.WithAddress("3.3.3.3", 10480))
+	assert.False(t, svr.IsDuplicate())
+	assert.False(t, svr.LinkDuplicates(addr.Blank, nil))
+
+	assert.True(t, svr.LinkDuplicates(primary, nil))
+	assert.True(t, svr.IsDuplicate())
+	assert.Equal(t, primary, svr.DuplicateOf)
+	assert.False(t, svr.LinkDuplicates(primary, nil))
+
+	assert.True(t, svr.LinkDuplicates(addr.Blank, []addr.Addr{dup}))
+	assert.False(t, svr.IsDuplicate())
+	assert.Equal(t, []addr.Addr{dup}, svr.Duplicates)
+	assert.False(t, svr.LinkDuplicates(addr.Blank, []addr.Addr{dup}))
+
+	assert.True(t, svr.LinkDuplicates(addr.Blank, nil))
+	assert.Empty(t, svr.Duplicates)
+}
+
+func TestCompareDuplicates(t *testing.T) {
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	added := serverfactory.Build(
+		serverfactory.WithAddress("1.1.1.1", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Details),
+		serverfactory.WithAvailability(server.Availability{Since: since}),
+	)
+	reported := serverfactory.Build(
+		serverfactory.WithAddress("2.2.2.2", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Master|ds.Details),
+		serverfactory.WithAvailability(server.Availability{Since: since.Add(time.Hour)}),
+	)
+	older := serverfactory.Build(
+		serverfactory.WithAddress("3.3.3.3", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Details),
+		serverfactory.WithAvailability(server.Availability{Since: since.Add(-time.Hour)}),
+	)
+	unknown := serverfactory.Build(
+		serverfactory.WithAddress("4.4.4.4", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Details),
+	)
+	same := serverfactory.Build(
+		serverfactory.WithAddress("1.0.0.1", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Details),
+		serverfactory.WithAvailability(server.Availability{Since: since}),
+	)
+
+	servers := []server.Server{unknown, added, older, same, reported}
+	slices.SortFunc(servers, server.CompareDuplicates)
+
+	got := make([]addr.Addr, 0, len(servers))
+	for _, svr := range servers {
+		got = append(got, svr.Addr)
+	}
+	assert.Equal(t, []addr.Addr{reported.Addr, older.Addr, same.Addr, added.Addr, unknown.Addr}, got)
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"time"
+)
+
+// Latency is the rolling round trip time of the server queries.
+// The average and the jitter are smoothed the same way as in RFC 3550,
+// so a single slow query does not throw off the server's latency.
+type Latency struct {
+	Last    time.Duration
+	Average time.Duration
+	Jitter  time.Duration
+	Samples int
+}
+
+const (
+	latencyAvgGain    = 8
+	latencyJitterGain = 16
+)
+
+// Observe returns the latency with the new round trip time taken into account
+func (l Latency) Observe(rtt time.Duration) Latency {
+	if l.Samples == 0 {
+		return Latency{
+			Last:    rtt,
+			Average: rtt,
+			Samples: 1,
+		}
+	}
+	diff := rtt - l.Last
+	if diff < 0 {
+		diff = -diff
+	}
+	return Latency{
+		Last:    rtt,
+		Average: l.Average + (rtt-l.Average)/latencyAvgGain,
+		Jitter:  l.Jitter + (diff-l.Jitter)/latencyJitterGain,
+		Samples: l.Samples + 1,
+	}
+}
+
+// IsKnown tells whether the server has been queried at least once
+func (l Latency) IsKnown() bool {
+	return l.Samples > 0
+}
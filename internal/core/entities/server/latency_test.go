@@ -0,0 +1,50 @@
+package server_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergeii/swat4master/internal/core/entities/server"
+)
+
+func TestLatency_Observe(t *testing.T) {
+	var latency server.Latency
+	assert.False(t, latency.IsKnown())
+
+	latency = latency.Observe(time.Millisecond * 80)
+	assert.True(t, latency.IsKnown())
+	assert.Equal(t, server.Latency{
+		Last:    time.Millisecond * 80,
+		Average: time.Millisecond * 80,
+		Samples: 1,
+	}, latency)
+
+	latency = latency.Observe(time.Millisecond * 160)
+	assert.Equal(t, server.Latency{
+		Last:    time.Millisecond * 160,
+		Average: time.Millisecond * 90,
+		Jitter:  time.Millisecond * 5,
+		Samples: 2,
+	}, latency)
+
+	latency = latency.Observe(time.Millisecond * 80)
+	assert.Equal(t, server.Latency{
+		Last:    time.Millisecond * 80,
+		Average: time.Microsecond * 88750,
+		Jitter:  time.Nanosecond * 9687500,
+		Samples: 3,
+	}, latency)
+}
+
+func TestLatency_Observe_Stable(t *testing.T) {
+	var latency server.Latency
+	for range 10 {
+		latency = latency.Observe(time.Millisecond * 50)
+	}
+	assert.Equal(t, time.Millisecond*50, latency.Last)
+	assert.Equal(t, time.Millisecond*50, latency.Average)
+	assert.Equal(t, time.Duration(0), latency.Jitter)
+	assert.Equal(t, 10, latency.Samples)
+}
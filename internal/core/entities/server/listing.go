@@ -0,0 +1,99 @@
+package server
+
+import (
+	"time"
+
+	"github.com/sergeii/swat4master/internal/core/entities/details"
+	"github.com/sergeii/swat4master/pkg/swat/styles"
+)
+
+const (
+	// ReportFreshness is how long the info of a heartbeat is good for comparing with a query response.
+	// Whatever the server claims in an older heartbeat may have legitimately changed since.
+	ReportFreshness = time.Minute
+	// MismatchThreshold is the number of consecutive query responses disagreeing with the heartbeats
+	// for the server to be flagged as mismatched
+	MismatchThreshold = 3
+	// playerTolerance accounts for the players joining and leaving between a heartbeat and a query
+	playerTolerance = 2
+)
+
+// Report is what the server has claimed about itself in its latest heartbeat
+type Report struct {
+	Hostname   string
+	NumPlayers int
+	MaxPlayers int
+	ReportedAt time.Time
+}
+
+// Listing tells how far the server listed by the master can be trusted.
+// A server is unverified until it has been reached by a probe at least once,
+// as anyone is able to send a heartbeat on behalf of a server.
+// Every heartbeat is then compared with the query response that follows it,
+// so that a server reporting info it does not have, such as a fake player count, gets flagged.
+type Listing struct {
+	VerifiedAt time.Time
+	Report     Report
+	Mismatches int // consecutive query responses disagreeing with the heartbeats
+}
+
+// IsVerified tells whether the server has ever been reached by a probe
+func (l Listing) IsVerified() bool {
+	return !l.VerifiedAt.IsZero()
+}
+
+// IsMismatched tells whether the heartbeats of the server consistently disagree with its query responses
+func (l Listing) IsMismatched() bool {
+	return l.Mismatches >= MismatchThreshold
+}
+
+// IsTrusted tells whether the server is verified and is not mismatched
+func (l Listing) IsTrusted() bool {
+	return l.IsVerified() && !l.IsMismatched()
+}
+
+// WithReport returns the listing with the info of the latest heartbeat
+func (l Listing) WithReport(info details.Info, now time.Time) Listing {
+	l.Report = Report{
+		Hostname:   info.Hostname,
+		NumPlayers: info.NumPlayers,
+		MaxPlayers: info.MaxPlayers,
+		ReportedAt: now,
+	}
+	return l
+}
+
+// Verify returns the listing verified by the query response.
+// The pending heartbeat, if still fresh, is compared with the response and is then discarded,
+// so that every heartbeat is only accounted for once.
+func (l Listing) Verify(info details.Info, now time.Time) Listing {
+	if !l.IsVerified() {
+		l.VerifiedAt = now
+	}
+	if l.Report.ReportedAt.IsZero() {
+		return l
+	}
+	if now.Sub(l.Report.ReportedAt) <= ReportFreshness {
+		if l.Report.agrees(info) {
+			l.Mismatches = 0
+		} else {
+			l.Mismatches++
+		}
+	}
+	l.Report = Report{}
+	return l
+}
+
+func (r Report) agrees(info details.Info) bool {
+	if styles.Clean(r.Hostname) != styles.Clean(info.Hostname) || r.MaxPlayers != info.MaxPlayers {
+		return false
+	}
+	return abs(r.NumPlayers-info.NumPlayers) <= playerTolerance
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,135 @@
+package server_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergeii/swat4master/internal/core/entities/details"
+	"github.com/sergeii/swat4master/internal/core/entities/server"
+)
+
+func TestListing_Verify(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	var listing server.Listing
+	assert.False(t, listing.IsVerified())
+	assert.False(t, listing.IsTrusted())
+
+	info := details.Info{Hostname: "Swat4 Server", NumPlayers: 10, MaxPlayers: 16}
+	listing = listing.WithReport(info, now)
+	assert.False(t, listing.IsVerified())
+
+	listing = listing.Verify(info, now.Add(time.Second))
+	assert.True(t, listing.IsVerified())
+	assert.True(t, listing.IsTrusted())
+	assert.Equal(t, now.Add(time.Second), listing.VerifiedAt)
+	assert.Equal(t, server.Report{}, listing.Report)
+
+	// the server is verified only once
+	listing = listing.Verify(info, now.Add(time.Minute))
+	assert.Equal(t, now.Add(time.Second), listing.VerifiedAt)
+}
+
+func TestListing_Mismatches(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	queried := details.Info{Hostname: "[c=FF0000]Swat4 Server", NumPlayers: 2, MaxPlayers: 16}
+
+	tests := []struct {
+		name     string
+		reported details.Info
+		want     bool
+	}{
+		{
+			"same info",
+			details.Info{Hostname: "[c=FF0000]Swat4 Server", NumPlayers: 2, MaxPlayers: 16},
+			false,
+		},
+		{
+			"players have joined in the meantime",
+			details.Info{Hostname: "Swat4 Server", NumPlayers: 4, MaxPlayers: 16},
+			false,
+		},
+		{
+			"fake player count",
+			details.Info{Hostname: "Swat4 Server", NumPlayers: 14, MaxPlayers: 16},
+			true,
+		},
+		{
+			"different player limit",
+			details.Info{Hostname: "Swat4 Server", NumPlayers: 2, MaxPlayers: 32},
+			true,
+		},
+		{
+			"different hostname",
+			details.Info{Hostname: "Another Swat4 Server", NumPlayers: 2, MaxPlayers: 16},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listing := server.Listing{VerifiedAt: now.Add(-time.Hour)}
+			for i := range server.MismatchThreshold {
+				at := now.Add(time.Minute * time.Duration(i))
+				listing = listing.WithReport(tt.reported, at).Verify(queried, at.Add(time.Second))
+			}
+			assert.Equal(t, tt.want, listing.IsMismatched())
+			assert.Equal(t, !tt.want, listing.IsTrusted())
+		})
+	}
+}
+
+func TestListing_Mismatches_Reset(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	queried := details.Info{Hostname: "Swat4 Server", NumPlayers: 0, MaxPlayers: 16}
+	fake := details.Info{Hostname: "Swat4 Server", NumPlayers: 16, MaxPlayers: 16}
+
+	listing := server.Listing{VerifiedAt: now}
+	for range server.MismatchThreshold - 1 {
+		listing = listing.WithReport(fake, now).Verify(queried, now)
+	}
+	assert.Equal(t, server.MismatchThreshold-1, listing.Mismatches)
+
+	// the queries that follow no heartbeat are not accounted for
+	listing = listing.Verify(queried, now)
+	assert.Equal(t, server.MismatchThreshold-1, listing.Mismatches)
+
+	// a stale heartbeat is not compared
+	listing = listing.WithReport(fake, now).Verify(queried, now.Add(server.ReportFreshness+time.Second))
+	assert.Equal(t, server.MismatchThreshold-1, listing.Mismatches)
+	assert.Equal(t, server.Report{}, listing.Report)
+
+	// an agreeing heartbeat resets the count
+	listing = listing.WithReport(queried, now).Verify(queried, now)
+	assert.Equal(t, 0, listing.Mismatches)
+
+	for range server.MismatchThreshold {
+		listing = listing.WithReport(fake, now).Verify(queried, now)
+	}
+	assert.True(t, listing.IsMismatched())
+
+	// the server is no longer flagged once it stops reporting false info
+	listing = listing.WithReport(queried, now).Verify(queried, now)
+	assert.False(t, listing.IsMismatched())
+}
+
+func TestServer_VerifyListing(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	queried := details.Info{Hostname: "Swat4 Server", NumPlayers: 0, MaxPlayers: 16}
+	fake := details.Info{Hostname: "Swat4 Server", NumPlayers: 16, MaxPlayers: 16}
+
+	svr := server.Server{}
+	for range server.MismatchThreshold - 1 {
+		svr.ReportListing(fake, now)
+		assert.False(t, svr.VerifyListing(queried, now))
+	}
+
+	svr.ReportListing(fake, now)
+	assert.True(t, svr.VerifyListing(queried, now))
+
+	// the server is only flagged once
+	svr.ReportListing(fake, now)
+	assert.False(t, svr.VerifyListing(queried, now))
+	assert.True(t, svr.Listing.IsMismatched())
+}
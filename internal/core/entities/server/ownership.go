@@ -0,0 +1,99 @@
+package server
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/sergeii/swat4master/pkg/swat/styles"
+)
+
+// Claim is an attempt to prove the ownership of the server.
+// The claim is confirmed once the issued token shows up in any of the server's query fields,
+// such as the hostname, before the claim expires.
+type Claim struct {
+	Token     string
+	KeyHash   string
+	ExpiresAt time.Time
+}
+
+// IsPending tells whether the claim is yet to be confirmed
+func (c Claim) IsPending(now time.Time) bool {
+	return c.Token != "" && now.Before(c.ExpiresAt)
+}
+
+// MaxPendingClaims is the number of claims of the server that can be pending at the same time.
+// No more claims are issued until some of the pending ones expire,
+// so that the server cannot be claimed over and over.
+const MaxPendingClaims = 3
+
+// Ownership tells who is allowed to edit the server's profile.
+// The owner is identified by the key issued along with the confirmed claim.
+// Outstanding claims do not affect the verified owner until either of them is confirmed.
+// Several claims can be pending at once, so that someone else's claim does not replace the owner's one.
+type Ownership struct {
+	KeyHash    string
+	VerifiedAt time.Time
+	Claims     []Claim
+}
+
+// IsVerified tells whether the server has a verified owner
+func (o Ownership) IsVerified() bool {
+	return !o.VerifiedAt.IsZero()
+}
+
+// Authorize tells whether the key belongs to the verified owner of the server
+func (o Ownership) Authorize(key string) bool {
+	if !o.IsVerified() || key == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(HashOwnerKey(key)), []byte(o.KeyHash)) == 1
+}
+
+// Pending returns the claims yet to be confirmed
+func (o Ownership) Pending(now time.Time) []Claim {
+	pending := make([]Claim, 0, len(o.Claims))
+	for _, claim := range o.Claims {
+		if claim.IsPending(now) {
+			pending = append(pending, claim)
+		}
+	}
+	return pending
+}
+
+// WithClaim returns the ownership with the claim added to the pending ones, dropping the expired claims.
+// Returns false if there are too many pending claims already, in which case the ownership is left intact.
+func (o Ownership) WithClaim(claim Claim, now time.Time) (Ownership, bool) {
+	pending := o.Pending(now)
+	if len(pending) >= MaxPendingClaims {
+		return o, false
+	}
+	o.Claims = append(pending, claim)
+	return o, true
+}
+
+// Confirm returns the ownership verified by any of the pending claims,
+// provided the claim token is found in any of the fields.
+// The fields are matched regardless of the case and the text styles.
+func (o Ownership) Confirm(fields map[string]string, now time.Time) (Ownership, bool) {
+	for _, claim := range o.Pending(now) {
+		token := strings.ToLower(claim.Token)
+		for _, value := range fields {
+			if strings.Contains(strings.ToLower(styles.Clean(value)), token) {
+				return Ownership{
+					KeyHash:    claim.KeyHash,
+					VerifiedAt: now,
+				}, true
+			}
+		}
+	}
+	return o, false
+}
+
+// HashOwnerKey returns the digest of the owner key, so that the key itself is never stored
+func HashOwnerKey(key string) string {
+	digest := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(digest[:])
+}
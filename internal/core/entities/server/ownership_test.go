@@ -0,0 +1,124 @@
+package server_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/server"
+)
+
+func TestOwnership_Confirm(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	claim := server.Claim{
+		Token:     "s4m-1a2b3c4d",
+		KeyHash:   server.HashOwnerKey("secret"),
+		ExpiresAt: now.Add(time.Hour),
+	}
+
+	var own server.Ownership
+	assert.False(t, own.IsVerified())
+	assert.False(t, own.Authorize("secret"))
+
+	own, ok := own.WithClaim(claim, now)
+	assert.True(t, ok)
+	assert.Equal(t, []server.Claim{claim}, own.Pending(now))
+	assert.False(t, own.IsVerified())
+	// the key is not valid until the claim is confirmed
+	assert.False(t, own.Authorize("secret"))
+
+	own, confirmed := own.Confirm(map[string]string{"hostname": "My Server"}, now)
+	assert.False(t, confirmed)
+	assert.False(t, own.IsVerified())
+
+	own, confirmed = own.Confirm(map[string]string{"hostname": "[c=FFFFFF]My Server [b]S4M-1A2B3C4D"}, now)
+	assert.True(t, confirmed)
+	assert.True(t, own.IsVerified())
+	assert.Equal(t, now, own.VerifiedAt)
+	assert.Empty(t, own.Pending(now))
+	assert.True(t, own.Authorize("secret"))
+	assert.False(t, own.Authorize("other"))
+	assert.False(t, own.Authorize(""))
+
+	// the claim is already confirmed
+	_, confirmed = own.Confirm(map[string]string{"hostname": "My Server s4m-1a2b3c4d"}, now)
+	assert.False(t, confirmed)
+}
+
+func TestOwnership_ClaimDoesNotAffectVerifiedOwner(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	own := server.Ownership{
+		KeyHash:    server.HashOwnerKey("owner"),
+		VerifiedAt: now.Add(-time.Hour * 24),
+	}
+
+	own, _ = own.WithClaim(server.Claim{
+		Token:     "s4m-1a2b3c4d",
+		KeyHash:   server.HashOwnerKey("newcomer"),
+		ExpiresAt: now.Add(time.Hour),
+	}, now)
+	assert.True(t, own.Authorize("owner"))
+	assert.False(t, own.Authorize("newcomer"))
+
+	// the claim has expired before the token showed up
+	own, confirmed := own.Confirm(map[string]string{"hostname": "s4m-1a2b3c4d"}, now.Add(time.Hour))
+	assert.False(t, confirmed)
+	assert.True(t, own.Authorize("owner"))
+	assert.False(t, own.Authorize("newcomer"))
+
+	own, _ = own.WithClaim(server.Claim{
+		Token:     "s4m-5e6f7a8b",
+		KeyHash:   server.HashOwnerKey("newcomer"),
+		ExpiresAt: now.Add(time.Hour * 2),
+	}, now.Add(time.Hour))
+	// the expired claim is dropped
+	assert.Len(t, own.Claims, 1)
+	own, confirmed = own.Confirm(map[string]string{"hostname": "s4m-5e6f7a8b"}, now.Add(time.Hour))
+	assert.True(t, confirmed)
+	assert.False(t, own.Authorize("owner"))
+	assert.True(t, own.Authorize("newcomer"))
+}
+
+func TestOwnership_SeveralPendingClaims(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	var own server.Ownership
+	for i, token := range []string{"s4m-00000001", "s4m-00000002", "s4m-00000003"} {
+		var ok bool
+		own, ok = own.WithClaim(server.Claim{
+			Token:     token,
+			KeyHash:   server.HashOwnerKey(token),
+			ExpiresAt: now.Add(time.Hour + time.Minute*time.Duration(i)),
+		}, now)
+		require.True(t, ok)
+	}
+	assert.Len(t, own.Pending(now), server.MaxPendingClaims)
+
+	// no more claims are accepted while the others are pending
+	refused, ok := own.WithClaim(server.Claim{
+		Token:     "s4m-00000004",
+		KeyHash:   server.HashOwnerKey("s4m-00000004"),
+		ExpiresAt: now.Add(time.Hour),
+	}, now)
+	assert.False(t, ok)
+	assert.Equal(t, own, refused)
+
+	// the first claim has expired, so there is room for another one
+	later := now.Add(time.Hour)
+	own, ok = own.WithClaim(server.Claim{
+		Token:     "s4m-00000004",
+		KeyHash:   server.HashOwnerKey("s4m-00000004"),
+		ExpiresAt: later.Add(time.Hour),
+	}, later)
+	require.True(t, ok)
+	assert.Len(t, own.Claims, server.MaxPendingClaims)
+
+	// any of the pending claims can be confirmed, not only the latest one
+	own, confirmed := own.Confirm(map[string]string{"hostname": "My Server s4m-00000002"}, later)
+	assert.True(t, confirmed)
+	assert.True(t, own.Authorize("s4m-00000002"))
+	assert.False(t, own.Authorize("s4m-00000004"))
+	assert.Empty(t, own.Claims)
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"time"
+
+	"github.com/sergeii/swat4master/internal/core/entities/details"
+)
+
+// RefreshPolicy bounds the interval between the refreshes of a server's details
+type RefreshPolicy struct {
+	MinInterval time.Duration
+	MaxInterval time.Duration
+}
+
+// Schedule tracks how often the server's details change,
+// so that busy servers are refreshed more often than the idle ones.
+type Schedule struct {
+	// Volatility ranges from 0 (the details never change) to 1 (the details change on every refresh)
+	Volatility    float64
+	ChangedAt     time.Time
+	NextRefreshAt time.Time
+}
+
+const (
+	volatilityGain = 4
+
+	playersChangeWeight = 0.5
+	roundChangeWeight   = 0.5
+)
+
+// Observe returns the schedule with the latest refresh of the server's details taken into account
+func (s Schedule) Observe(prev, curr details.Info, now time.Time, policy RefreshPolicy) Schedule {
+	score := 0.0
+	if s.ChangedAt.IsZero() {
+		// a server that is seen for the first time is considered volatile
+		score = 1
+	} else {
+		if prev.NumPlayers != curr.NumPlayers {
+			score += playersChangeWeight
+		}
+		if prev.Round != curr.Round || prev.MapName != curr.MapName {
+			score += roundChangeWeight
+		}
+	}
+
+	next := s
+	if s.ChangedAt.IsZero() {
+		next.Volatility = score
+	} else {
+		next.Volatility = s.Volatility + (score-s.Volatility)/volatilityGain
+	}
+	if score > 0 {
+		next.ChangedAt = now
+	}
+	next.NextRefreshAt = now.Add(next.interval(now, policy))
+
+	return next
+}
+
+// IsDue tells whether the server's details should be refreshed at the given time
+func (s Schedule) IsDue(now time.Time) bool {
+	return !s.NextRefreshAt.After(now)
+}
+
+// interval spreads the refreshes between the policy bounds according to the volatility.
+// A server is never refreshed later than it has been quiet for,
+// so that a server which has just changed is refreshed soon again.
+func (s Schedule) interval(now time.Time, policy RefreshPolicy) time.Duration {
+	minInterval, maxInterval := policy.MinInterval, max(policy.MinInterval, policy.MaxInterval)
+	interval := minInterval + time.Duration(float64(maxInterval-minInterval)*(1-s.Volatility))
+	if quiet := now.Sub(s.ChangedAt); quiet < interval {
+		interval = max(quiet, minInterval)
+	}
+	return interval
+}
@@ -0,0 +1,79 @@
+package server_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergeii/swat4master/internal/core/entities/details"
+	"github.com/sergeii/swat4master/internal/core/entities/server"
+)
+
+func TestSchedule_Observe(t *testing.T) {
+	policy := server.RefreshPolicy{MinInterval: time.Second * 5, MaxInterval: time.Second * 65}
+	t0 := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	idle := details.Info{NumPlayers: 4, MapName: "A-Bomb Nightclub", Round: 1}
+
+	var sched server.Schedule
+	assert.True(t, sched.IsDue(t0))
+
+	// the first refresh is always considered a change
+	sched = sched.Observe(details.Info{}, idle, t0, policy)
+	assert.Equal(t, server.Schedule{
+		Volatility:    1,
+		ChangedAt:     t0,
+		NextRefreshAt: t0.Add(time.Second * 5),
+	}, sched)
+	assert.False(t, sched.IsDue(t0.Add(time.Second*4)))
+	assert.True(t, sched.IsDue(t0.Add(time.Second*5)))
+
+	// no changes, but the server has been quiet only for 5 seconds
+	sched = sched.Observe(idle, idle, t0.Add(time.Second*5), policy)
+	assert.Equal(t, server.Schedule{
+		Volatility:    0.75,
+		ChangedAt:     t0,
+		NextRefreshAt: t0.Add(time.Second * 10),
+	}, sched)
+
+	sched = sched.Observe(idle, idle, t0.Add(time.Second*10), policy)
+	assert.Equal(t, server.Schedule{
+		Volatility:    0.5625,
+		ChangedAt:     t0,
+		NextRefreshAt: t0.Add(time.Second * 20),
+	}, sched)
+
+	// a player has joined, so the server is refreshed soon again
+	joined := idle
+	joined.NumPlayers = 5
+	sched = sched.Observe(idle, joined, t0.Add(time.Second*20), policy)
+	assert.Equal(t, server.Schedule{
+		Volatility:    0.546875,
+		ChangedAt:     t0.Add(time.Second * 20),
+		NextRefreshAt: t0.Add(time.Second * 25),
+	}, sched)
+}
+
+func TestSchedule_Observe_Bounds(t *testing.T) {
+	policy := server.RefreshPolicy{MinInterval: time.Second * 5, MaxInterval: time.Minute}
+	t0 := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	info := details.Info{MapName: "A-Bomb Nightclub", Round: 1}
+
+	// the server has not changed for hours
+	sched := server.Schedule{Volatility: 0, ChangedAt: t0.Add(-time.Hour * 3)}
+	sched = sched.Observe(info, info, t0, policy)
+	assert.Equal(t, t0.Add(time.Minute), sched.NextRefreshAt)
+
+	// the round changes on every refresh
+	sched = server.Schedule{Volatility: 1, ChangedAt: t0.Add(-time.Hour)}
+	nextRound := info
+	nextRound.Round = 2
+	sched = sched.Observe(info, nextRound, t0, policy)
+	assert.Equal(t, t0.Add(time.Second*5), sched.NextRefreshAt)
+
+	// the max interval below the min interval is ignored
+	policy = server.RefreshPolicy{MinInterval: time.Second * 10, MaxInterval: time.Second}
+	sched = server.Schedule{Volatility: 0, ChangedAt: t0.Add(-time.Hour)}
+	sched = sched.Observe(info, info, t0, policy)
+	assert.Equal(t, t0.Add(time.Second*10), sched.NextRefreshAt)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/sergeii/swat4master/internal/core/entities/addr"
 	"github.com/sergeii/swat4master/internal/core/entities/details"
 	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/internal/core/entities/location"
 )
 
 var ErrInvalidQueryPort = errors.New("invalid port number")
@@ -19,6 +20,22 @@ type Server struct { //nolint: recvcheck
 	DiscoveryStatus ds.DiscoveryStatus
 	Info            details.Info
 	Details         details.Details
+	Location        location.Location
+
+	// the outcome of the latest successful query
+	QueryProtocol string // gs1/vanilla, gs1/am, gs1/gs1, qr2
+	Latency       Latency
+	QueriedAt     time.Time
+
+	Schedule Schedule
+
+	Availability Availability
+	Ownership    Ownership
+	Listing      Listing
+
+	// the server is likely the same game server as the primary one, seen at another address
+	DuplicateOf addr.Addr
+	Duplicates  []addr.Addr
 
 	RefreshedAt time.Time
 	Version     int // lamport clock counter
@@ -26,6 +43,16 @@ type Server struct { //nolint: recvcheck
 
 var Blank Server //nolint: gochecknoglobals
 
+// QueryFields are the fields a query filters the servers by.
+// Besides the reported info, a server can be filtered by its location,
+// although the location fields are never reported by the server itself.
+type QueryFields struct {
+	details.Info
+	Country   string `param:"country"`
+	Continent string `param:"continent"`
+	ASN       int    `param:"asn"`
+}
+
 func New(ip net.IP, port, queryPort int) (Server, error) {
 	svrAddr, err := addr.New(ip, port)
 	if err != nil {
@@ -91,6 +118,79 @@ func (gs *Server) UpdateDetails(det details.Details) {
 	gs.Info = det.Info
 }
 
+// RecordQuery keeps the outcome of a successful query of the server
+func (gs *Server) RecordQuery(protocol string, rtt time.Duration, queriedAt time.Time) {
+	gs.QueryProtocol = protocol
+	gs.Latency = gs.Latency.Observe(rtt)
+	gs.QueriedAt = queriedAt
+}
+
+// Reschedule plans the next refresh of the server's details,
+// comparing the details the server had before the latest refresh with the current ones
+func (gs *Server) Reschedule(prev details.Info, now time.Time, policy RefreshPolicy) {
+	gs.Schedule = gs.Schedule.Observe(prev, gs.Info, now, policy)
+}
+
+// MarkUnavailable records an outage of the server started at the given time
+func (gs *Server) MarkUnavailable(at, now time.Time) {
+	gs.Availability = gs.Availability.GoDown(at, now)
+}
+
+// MarkAvailable ends the ongoing outage of the server at the given time
+func (gs *Server) MarkAvailable(at, now time.Time) {
+	gs.Availability = gs.Availability.GoUp(at, now)
+}
+
+// ClaimOwnership starts a claim of the server's ownership.
+// Returns false if the server has too many pending claims to start another one.
+func (gs *Server) ClaimOwnership(claim Claim, now time.Time) bool {
+	var ok bool
+	gs.Ownership, ok = gs.Ownership.WithClaim(claim, now)
+	return ok
+}
+
+// ConfirmOwnership verifies the owner of the server if the claim token is found in the query fields.
+// Returns true if the ownership has been confirmed.
+func (gs *Server) ConfirmOwnership(fields map[string]string, now time.Time) bool {
+	var confirmed bool
+	gs.Ownership, confirmed = gs.Ownership.Confirm(fields, now)
+	return confirmed
+}
+
+// ReportListing keeps the info the server has claimed in a heartbeat,
+// so that it can be compared with the query response that follows
+func (gs *Server) ReportListing(info details.Info, now time.Time) {
+	gs.Listing = gs.Listing.WithReport(info, now)
+}
+
+// VerifyListing verifies the server reached by a probe, comparing the query response with the latest heartbeat.
+// Returns true if the server has just been flagged as mismatched.
+func (gs *Server) VerifyListing(info details.Info, now time.Time) bool {
+	wasMismatched := gs.Listing.IsMismatched()
+	gs.Listing = gs.Listing.Verify(info, now)
+	return !wasMismatched && gs.Listing.IsMismatched()
+}
+
+// NeedsLocation tells whether the server's location is yet to be resolved for its current address
+func (gs *Server) NeedsLocation() bool {
+	return !gs.Location.IsResolvedFor(gs.Addr.GetIP())
+}
+
+func (gs *Server) UpdateLocation(loc location.Location) {
+	gs.Location = loc
+}
+
+// QueryFields returns the server's info along with the pseudo fields
+// that the server listings can be filtered by, such as the country.
+func (gs *Server) QueryFields() QueryFields {
+	return QueryFields{
+		Info:      gs.Info,
+		Country:   gs.Location.Country,
+		Continent: gs.Location.Continent,
+		ASN:       gs.Location.ASN,
+	}
+}
+
 func (gs *Server) Refresh(updatedAt time.Time) {
 	gs.RefreshedAt = updatedAt
 }
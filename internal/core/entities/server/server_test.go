@@ -3,6 +3,7 @@ package server_test
 import (
 	"net"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -10,6 +11,7 @@ import (
 	"github.com/sergeii/swat4master/internal/core/entities/addr"
 	"github.com/sergeii/swat4master/internal/core/entities/details"
 	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/internal/core/entities/location"
 	"github.com/sergeii/swat4master/internal/core/entities/server"
 	"github.com/sergeii/swat4master/internal/testutils/factories/infofactory"
 )
@@ -351,3 +353,72 @@ func TestServer_HasDiscoveryStatusStatus(t *testing.T) {
 	assert.False(t, svr.HasNoDiscoveryStatus(ds.NoDetails|ds.NoPort|ds.Details))
 	assert.False(t, svr.HasNoDiscoveryStatus(ds.Details|ds.Master|ds.Info))
 }
+
+func TestServer_RecordQuery(t *testing.T) {
+	svr := server.MustNew(net.ParseIP("1.1.1.1"), 10480, 10481)
+	assert.Empty(t, svr.QueryProtocol)
+	assert.False(t, svr.Latency.IsKnown())
+	assert.True(t, svr.QueriedAt.IsZero())
+
+	firstQueriedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	svr.RecordQuery("gs1/am", time.Millisecond*100, firstQueriedAt)
+	assert.Equal(t, "gs1/am", svr.QueryProtocol)
+	assert.Equal(t, time.Millisecond*100, svr.Latency.Last)
+	assert.Equal(t, time.Millisecond*100, svr.Latency.Average)
+	assert.Equal(t, firstQueriedAt, svr.QueriedAt)
+
+	secondQueriedAt := firstQueriedAt.Add(time.Minute)
+	svr.RecordQuery("qr2", time.Millisecond*20, secondQueriedAt)
+	assert.Equal(t, "qr2", svr.QueryProtocol)
+	assert.Equal(t, time.Millisecond*20, svr.Latency.Last)
+	assert.Equal(t, time.Millisecond*90, svr.Latency.Average)
+	assert.Equal(t, time.Millisecond*5, svr.Latency.Jitter)
+	assert.Equal(t, 2, svr.Latency.Samples)
+	assert.Equal(t, secondQueriedAt, svr.QueriedAt)
+}
+
+func TestServer_Reschedule(t *testing.T) {
+	policy := server.RefreshPolicy{MinInterval: time.Second * 5, MaxInterval: time.Minute}
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	svr := server.MustNew(net.ParseIP("1.1.1.1"), 10480, 10481)
+	assert.True(t, svr.Schedule.IsDue(now))
+
+	prev := svr.Info
+	svr.UpdateInfo(details.Info{Hostname: "Swat4 Server", NumPlayers: 10})
+	svr.Reschedule(prev, now, policy)
+	assert.Equal(t, now, svr.Schedule.ChangedAt)
+	assert.Equal(t, now.Add(time.Second*5), svr.Schedule.NextRefreshAt)
+	assert.False(t, svr.Schedule.IsDue(now))
+}
+
+func TestServer_UpdateLocation(t *testing.T) {
+	svr := server.MustNew(net.ParseIP("1.1.1.1"), 10480, 10481)
+	assert.True(t, svr.NeedsLocation())
+
+	svr.UpdateLocation(location.New(net.ParseIP("1.1.1.1"), "AU", "OC", 13335))
+	assert.False(t, svr.NeedsLocation())
+	assert.True(t, svr.Location.IsKnown())
+
+	// the address is not found in the databases, but it's still resolved
+	svr.UpdateLocation(location.New(net.ParseIP("1.1.1.1"), "", "", 0))
+	assert.False(t, svr.NeedsLocation())
+	assert.False(t, svr.Location.IsKnown())
+
+	// the location was resolved for another address
+	svr.UpdateLocation(location.New(net.ParseIP("2.2.2.2"), "DE", "EU", 24940))
+	assert.True(t, svr.NeedsLocation())
+}
+
+func TestServer_QueryFields(t *testing.T) {
+	svr := server.MustNew(net.ParseIP("1.1.1.1"), 10480, 10481)
+	svr.UpdateInfo(details.Info{Hostname: "Swat4 Server", NumPlayers: 10})
+	svr.UpdateLocation(location.New(net.ParseIP("1.1.1.1"), "DE", "EU", 24940))
+
+	fields := svr.QueryFields()
+	assert.Equal(t, "Swat4 Server", fields.Hostname)
+	assert.Equal(t, 10, fields.NumPlayers)
+	assert.Equal(t, "DE", fields.Country)
+	assert.Equal(t, "EU", fields.Continent)
+	assert.Equal(t, 24940, fields.ASN)
+}
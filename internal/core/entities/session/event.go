@@ -0,0 +1,77 @@
+package session
+
+import (
+	"time"
+
+	"github.com/sergeii/swat4master/internal/core/entities/details"
+)
+
+type EventKind string
+
+const (
+	EventJoin       EventKind = "join"
+	EventLeave      EventKind = "leave"
+	EventTeamChange EventKind = "team"
+)
+
+// Event is a change in the player list of a server between two details refreshes.
+// For a leave event the player carries the last known stats of the player,
+// for the other events - the current ones.
+type Event struct {
+	Kind     EventKind
+	Player   details.Player
+	PrevTeam details.PlayerTeam
+	At       time.Time
+}
+
+// Diff compares the previous and the current player lists of a server
+// and produces the events that have led from the former to the latter.
+// The players are matched by name. Should the name occur more than once in a list,
+// only the first player with that name is considered.
+// The events are ordered as follows: leaves, team changes and then joins,
+// each group in the order of the player lists.
+func Diff(prev, curr []details.Player, at time.Time) []Event {
+	prevByName := indexPlayers(prev)
+	currByName := indexPlayers(curr)
+
+	events := make([]Event, 0)
+	for _, player := range uniquePlayers(prev) {
+		if _, ok := currByName[player.Name]; !ok {
+			events = append(events, Event{Kind: EventLeave, Player: player, PrevTeam: player.Team, At: at})
+		}
+	}
+	for _, player := range uniquePlayers(curr) {
+		before, ok := prevByName[player.Name]
+		if ok && before.Team != player.Team {
+			events = append(events, Event{Kind: EventTeamChange, Player: player, PrevTeam: before.Team, At: at})
+		}
+	}
+	for _, player := range uniquePlayers(curr) {
+		if _, ok := prevByName[player.Name]; !ok {
+			events = append(events, Event{Kind: EventJoin, Player: player, PrevTeam: player.Team, At: at})
+		}
+	}
+
+	return events
+}
+
+func indexPlayers(players []details.Player) map[string]details.Player {
+	index := make(map[string]details.Player, len(players))
+	for _, player := range uniquePlayers(players) {
+		index[player.Name] = player
+	}
+	return index
+}
+
+func uniquePlayers(players []details.Player) []details.Player {
+	seen := make(map[string]struct{}, len(players))
+	unique := make([]details.Player, 0, len(players))
+	for _, player := range players {
+		if _, ok := seen[player.Name]; ok {
+			continue
+		}
+		seen[player.Name] = struct{}{}
+		unique = append(unique, player)
+	}
+	return unique
+}
@@ -0,0 +1,102 @@
+package session_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergeii/swat4master/internal/core/entities/details"
+	"github.com/sergeii/swat4master/internal/core/entities/session"
+)
+
+func TestDiff(t *testing.T) {
+	at := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		prev []details.Player
+		curr []details.Player
+		want []session.Event
+	}{
+		{
+			"no players",
+			nil,
+			nil,
+			[]session.Event{},
+		},
+		{
+			"same players",
+			[]details.Player{{Name: "Serge", Score: 1}},
+			[]details.Player{{Name: "Serge", Score: 5}},
+			[]session.Event{},
+		},
+		{
+			"players join an empty server",
+			nil,
+			[]details.Player{{Name: "Serge"}, {Name: "Mosquito", Team: details.TeamSuspects}},
+			[]session.Event{
+				{Kind: session.EventJoin, Player: details.Player{Name: "Serge"}, At: at},
+				{
+					Kind:     session.EventJoin,
+					Player:   details.Player{Name: "Mosquito", Team: details.TeamSuspects},
+					PrevTeam: details.TeamSuspects,
+					At:       at,
+				},
+			},
+		},
+		{
+			"players leave with their last stats",
+			[]details.Player{{Name: "Serge", Score: 10}, {Name: "Mosquito"}},
+			nil,
+			[]session.Event{
+				{Kind: session.EventLeave, Player: details.Player{Name: "Serge", Score: 10}, At: at},
+				{Kind: session.EventLeave, Player: details.Player{Name: "Mosquito"}, At: at},
+			},
+		},
+		{
+			"leaves, team changes and joins",
+			[]details.Player{{Name: "Serge"}, {Name: "Bob"}, {Name: "Mosquito", Team: details.TeamSuspects}},
+			[]details.Player{{Name: "Alice"}, {Name: "Mosquito"}, {Name: "Serge"}},
+			[]session.Event{
+				{Kind: session.EventLeave, Player: details.Player{Name: "Bob"}, At: at},
+				{
+					Kind:     session.EventTeamChange,
+					Player:   details.Player{Name: "Mosquito"},
+					PrevTeam: details.TeamSuspects,
+					At:       at,
+				},
+				{Kind: session.EventJoin, Player: details.Player{Name: "Alice"}, At: at},
+			},
+		},
+		{
+			"names are case sensitive",
+			[]details.Player{{Name: "serge"}},
+			[]details.Player{{Name: "Serge"}},
+			[]session.Event{
+				{Kind: session.EventLeave, Player: details.Player{Name: "serge"}, At: at},
+				{Kind: session.EventJoin, Player: details.Player{Name: "Serge"}, At: at},
+			},
+		},
+		{
+			"only the first of the players sharing the name is considered",
+			[]details.Player{{Name: "Player"}},
+			[]details.Player{{Name: "Player", Team: details.TeamSuspects}, {Name: "Player"}},
+			[]session.Event{
+				{
+					Kind:     session.EventTeamChange,
+					Player:   details.Player{Name: "Player", Team: details.TeamSuspects},
+					PrevTeam: details.TeamSwat,
+					At:       at,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := session.Diff(tt.prev, tt.curr, at)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package session
+
+import (
+	"strings"
+
+	"github.com/sergeii/swat4master/pkg/swat/styles"
+)
+
+// Match tells how closely a player name matches a search query, the closer match going first
+type Match int
+
+const (
+	MatchExact Match = iota
+	MatchPrefix
+	MatchSubstring
+	MatchFuzzy
+)
+
+func (m Match) String() string {
+	switch m {
+	case MatchExact:
+		return "exact"
+	case MatchPrefix:
+		return "prefix"
+	case MatchSubstring:
+		return "substring"
+	case MatchFuzzy:
+		return "fuzzy"
+	default:
+		return "unknown"
+	}
+}
+
+const (
+	// FuzzyMinLength is the shortest query that is matched approximately,
+	// as the shorter queries would match nearly any name
+	FuzzyMinLength = 3
+	// fuzzyRunesPerTypo is the length of the query that is allowed to have one typo
+	fuzzyRunesPerTypo = 4
+)
+
+// NormalizeName brings the player name to the form the names are searched in:
+// with no text styles and in lower case
+func NormalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(styles.Clean(name)))
+}
+
+// MatchName tells whether the player name matches the search query and how closely.
+// Both the query and the name are expected to be normalized.
+// Besides the exact, prefix and substring matches, the name matches a long enough query
+// if the name starts with the query misspelled by as few as a typo per every few characters.
+func MatchName(query, name string) (Match, bool) {
+	switch {
+	case query == "":
+		return MatchFuzzy, false
+	case name == query:
+		return MatchExact, true
+	case strings.HasPrefix(name, query):
+		return MatchPrefix, true
+	case strings.Contains(name, query):
+		return MatchSubstring, true
+	}
+
+	queryRunes := []rune(query)
+	if len(queryRunes) < FuzzyMinLength {
+		return MatchFuzzy, false
+	}
+	nameRunes := []rune(name)
+	maxTypos := max(1, len(queryRunes)/fuzzyRunesPerTypo)
+	// a typo may have shortened or lengthened the name's head compared with the query
+	for _, size := range []int{len(queryRunes), len(queryRunes) - 1, len(queryRunes) + 1} {
+		if size > len(nameRunes) {
+			continue
+		}
+		if editDistance(queryRunes, nameRunes[:size]) <= maxTypos {
+			return MatchFuzzy, true
+		}
+	}
+	return MatchFuzzy, false
+}
+
+// editDistance is the Levenshtein distance between the strings
+func editDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
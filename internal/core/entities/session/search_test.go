@@ -0,0 +1,48 @@
+package session_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergeii/swat4master/internal/core/entities/session"
+)
+
+func TestNormalizeName(t *testing.T) {
+	assert.Equal(t, "serge", session.NormalizeName("[c=FF0000][b]Serge[\\b]"))
+	assert.Equal(t, "mosquito", session.NormalizeName("  MoSQuito "))
+	assert.Equal(t, "", session.NormalizeName("[c=FF0000][\\c]"))
+}
+
+func TestMatchName(t *testing.T) {
+	tests := []struct {
+		query string
+		name  string
+		want  session.Match
+		ok    bool
+	}{
+		{"serge", "serge", session.MatchExact, true},
+		{"ser", "serge", session.MatchPrefix, true},
+		{"erg", "serge", session.MatchSubstring, true},
+		{"sarge", "serge", session.MatchFuzzy, true},
+		{"sreg", "serge", session.MatchFuzzy, false},
+		{"mosqito", "mosquito|swat", session.MatchFuzzy, true},
+		{"mosqutio", "mosquito", session.MatchFuzzy, true},
+		{"mosuqtio", "mosquito", session.MatchFuzzy, false},
+		{"sre", "serge", session.MatchFuzzy, true},
+		{"xy", "xz", session.MatchFuzzy, false},
+		{"serge", "serg", session.MatchFuzzy, true},
+		{"serge", "ser", session.MatchFuzzy, false},
+		{"serge", "mosquito", session.MatchFuzzy, false},
+		{"", "serge", session.MatchFuzzy, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query+" "+tt.name, func(t *testing.T) {
+			got, ok := session.MatchName(tt.query, tt.name)
+			assert.Equal(t, tt.ok, ok)
+			if ok {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package session
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/details"
+)
+
+// Session is the time a player has spent on a server.
+// The player stats are updated on every details refresh while the session is active,
+// so that the session is left with the final score of the player once it has ended.
+type Session struct {
+	ID        string             `json:"id"`
+	Addr      addr.Addr          `json:"addr"`
+	Name      string             `json:"name"`
+	Team      details.PlayerTeam `json:"team"`
+	Score     int                `json:"score"`
+	Kills     int                `json:"kills"`
+	Arrests   int                `json:"arrests"`
+	StartedAt time.Time          `json:"started_at"`
+	EndedAt   time.Time          `json:"ended_at"`
+}
+
+var Blank Session //nolint: gochecknoglobals
+
+func New(svrAddr addr.Addr, player details.Player, startedAt time.Time) Session {
+	sess := Session{
+		ID:        uuid.NewString(),
+		Addr:      svrAddr,
+		Name:      player.Name,
+		StartedAt: startedAt,
+	}
+	sess.Track(player)
+	return sess
+}
+
+// Track updates the session with the current team and stats of the player
+func (s *Session) Track(player details.Player) {
+	s.Team = player.Team
+	s.Score = player.Score
+	s.Kills = player.Kills
+	s.Arrests = player.Arrests
+}
+
+// End closes the session at the given time
+func (s *Session) End(endedAt time.Time) {
+	s.EndedAt = endedAt
+}
+
+func (s Session) IsActive() bool {
+	return s.EndedAt.IsZero()
+}
+
+// Duration tells how long the player has been on the server.
+// For an active session the duration is counted up to now.
+func (s Session) Duration(now time.Time) time.Duration {
+	if s.IsActive() {
+		return now.Sub(s.StartedAt)
+	}
+	return s.EndedAt.Sub(s.StartedAt)
+}
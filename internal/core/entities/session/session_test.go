@@ -0,0 +1,43 @@
+package session_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/details"
+	"github.com/sergeii/swat4master/internal/core/entities/session"
+)
+
+func TestSession_Lifecycle(t *testing.T) {
+	startedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	svrAddr := addr.MustNewFromDotted("1.1.1.1", 10480)
+
+	sess := session.New(svrAddr, details.Player{Name: "Serge", Score: 1, Team: details.TeamSuspects}, startedAt)
+	assert.NotEmpty(t, sess.ID)
+	assert.Equal(t, svrAddr, sess.Addr)
+	assert.Equal(t, "Serge", sess.Name)
+	assert.Equal(t, details.PlayerTeam(details.TeamSuspects), sess.Team)
+	assert.Equal(t, 1, sess.Score)
+	assert.True(t, sess.IsActive())
+	assert.Equal(t, time.Minute*5, sess.Duration(startedAt.Add(time.Minute*5)))
+
+	sess.Track(details.Player{Name: "Serge", Score: 12, Kills: 3, Arrests: 2, Team: details.TeamSwat})
+	assert.Equal(t, details.PlayerTeam(details.TeamSwat), sess.Team)
+	assert.Equal(t, 12, sess.Score)
+	assert.Equal(t, 3, sess.Kills)
+	assert.Equal(t, 2, sess.Arrests)
+
+	sess.End(startedAt.Add(time.Minute * 10))
+	assert.False(t, sess.IsActive())
+	assert.Equal(t, time.Minute*10, sess.Duration(startedAt.Add(time.Hour)))
+}
+
+func TestSession_NewSessionsHaveUniqueIDs(t *testing.T) {
+	svrAddr := addr.MustNewFromDotted("1.1.1.1", 10480)
+	sess1 := session.New(svrAddr, details.Player{Name: "Serge"}, time.Now())
+	sess2 := session.New(svrAddr, details.Player{Name: "Serge"}, time.Now())
+	assert.NotEqual(t, sess1.ID, sess2.ID)
+}
@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/featured"
+)
+
+var ErrFeaturedNotFound = errors.New("the requested featured server was not found")
+
+type FeaturedRepository interface {
+	Add(context.Context, featured.Featured) error
+	Get(context.Context, addr.Addr) (featured.Featured, error)
+	Remove(context.Context, addr.Addr) error
+	List(context.Context) ([]featured.Featured, error)
+}
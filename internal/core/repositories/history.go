@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/history"
+)
+
+type HistoryRepository interface {
+	// Record accounts the samples in every resolution of the history of their servers
+	Record(context.Context, ...history.Sample) error
+	// Range returns the history points of the server kept at the resolution
+	// whose buckets overlap the time range, the end of the range being exclusive.
+	// The points are ordered by time.
+	Range(context.Context, addr.Addr, history.Resolution, time.Time, time.Time) ([]history.Point, error)
+}
@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sergeii/swat4master/internal/core/entities/jobrun"
+)
+
+var ErrJobRunNotFound = errors.New("the requested job has never run")
+
+type JobRunRepository interface {
+	// Record adds the run to the history of its job
+	Record(context.Context, jobrun.Run) error
+	// Last returns the latest run of the job
+	Last(context.Context, string) (jobrun.Run, error)
+	// List returns at most limit latest runs of the job, the latest run first
+	List(context.Context, string, int) ([]jobrun.Run, error)
+	// Jobs returns the names of the jobs that have ever run
+	Jobs(context.Context) ([]string, error)
+}
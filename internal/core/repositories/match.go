@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/match"
+)
+
+var ErrMatchNotFound = errors.New("the requested match was not found")
+
+type MatchRepository interface {
+	// Add records the result of a match played on a server
+	Add(context.Context, match.Match) error
+	// Get returns the match by its id
+	Get(context.Context, string) (match.Match, error)
+	// ListByServer returns at most limit latest matches played on the server, the latest match first
+	ListByServer(context.Context, addr.Addr, int) ([]match.Match, error)
+}
@@ -0,0 +1,12 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/sergeii/swat4master/internal/core/entities/portoffset"
+)
+
+type PortOffsetRepository interface {
+	Record(ctx context.Context, ip [4]byte, offset int) error
+	Get(ctx context.Context, ip [4]byte) (portoffset.Learned, error)
+}
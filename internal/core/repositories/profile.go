@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/profile"
+)
+
+var ErrProfileNotFound = errors.New("the requested server profile was not found")
+
+type ProfileRepository interface {
+	Save(context.Context, profile.Profile) error
+	Get(context.Context, addr.Addr) (profile.Profile, error)
+	Remove(context.Context, addr.Addr) error
+}
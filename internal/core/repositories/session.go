@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/session"
+)
+
+type SessionRepository interface {
+	// Save adds the sessions or updates the existing ones.
+	// An ended session is no longer returned among the active sessions of its server.
+	Save(context.Context, ...session.Session) error
+	// Active returns the sessions of the players currently playing on the server
+	Active(context.Context, addr.Addr) ([]session.Session, error)
+	// EndActive ends the active sessions on the server at the given time,
+	// returning the number of the sessions ended
+	EndActive(context.Context, addr.Addr, time.Time) (int, error)
+	// ListByServer returns at most limit latest sessions on the server, the latest session first
+	ListByServer(context.Context, addr.Addr, int) ([]session.Session, error)
+	// ListByPlayer returns at most limit latest sessions of the player, the latest session first.
+	// The player name is matched in its normalized form (see session.NormalizeName).
+	ListByPlayer(context.Context, string, int) ([]session.Session, error)
+	// FindActive returns the active sessions of the players whose names start with the prefix,
+	// in the order of the names. The names are matched in their normalized form (see session.NormalizeName).
+	// An empty prefix matches every player currently playing on any server.
+	FindActive(context.Context, string) ([]session.Session, error)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/sergeii/swat4master/internal/core/entities/probe"
 	"github.com/sergeii/swat4master/internal/core/entities/server"
 	"github.com/sergeii/swat4master/internal/core/repositories"
+	"github.com/sergeii/swat4master/internal/geoip"
 	"github.com/sergeii/swat4master/internal/metrics"
 )
 
@@ -29,6 +30,7 @@ type UseCaseOptions struct {
 type UseCase struct {
 	serverRepo repositories.ServerRepository
 	probeRepo  repositories.ProbeRepository
+	locator    geoip.Locator
 	ops        UseCaseOptions
 	metrics    *metrics.Collector
 	logger     *zerolog.Logger
@@ -37,6 +39,7 @@ type UseCase struct {
 func New(
 	serverRepo repositories.ServerRepository,
 	probeRepo repositories.ProbeRepository,
+	locator geoip.Locator,
 	opts UseCaseOptions,
 	metrics *metrics.Collector,
 	logger *zerolog.Logger,
@@ -44,6 +47,7 @@ func New(
 	return UseCase{
 		serverRepo: serverRepo,
 		probeRepo:  probeRepo,
+		locator:    locator,
 		ops:        opts,
 		metrics:    metrics,
 		logger:     logger,
@@ -51,7 +55,18 @@ func New(
 }
 
 func (uc UseCase) Execute(ctx context.Context, publicAddr addr.PublicAddr) (server.Server, error) {
-	svr, err := uc.getOrCreateServer(ctx, publicAddr.ToAddr())
+	return uc.ExecuteWithSource(ctx, publicAddr, ds.NoStatus)
+}
+
+// ExecuteWithSource works the same way as Execute,
+// but servers created in the process are also tagged with the provided source status
+// (e.g. ds.Federated), so that they can be told apart from the servers reported directly.
+func (uc UseCase) ExecuteWithSource(
+	ctx context.Context,
+	publicAddr addr.PublicAddr,
+	source ds.DiscoveryStatus,
+) (server.Server, error) {
+	svr, err := uc.getOrCreateServer(ctx, publicAddr.ToAddr(), source)
 	if err != nil {
 		return server.Blank, err
 	}
@@ -63,12 +78,16 @@ func (uc UseCase) Execute(ctx context.Context, publicAddr addr.PublicAddr) (serv
 	return svr, nil
 }
 
-func (uc UseCase) getOrCreateServer(ctx context.Context, address addr.Addr) (server.Server, error) {
+func (uc UseCase) getOrCreateServer(
+	ctx context.Context,
+	address addr.Addr,
+	source ds.DiscoveryStatus,
+) (server.Server, error) {
 	svr, err := uc.serverRepo.Get(ctx, address)
 	if err != nil {
 		switch {
 		case errors.Is(err, repositories.ErrServerNotFound):
-			newSvr, createErr := uc.createServerFromAddress(ctx, address)
+			newSvr, createErr := uc.createServerFromAddress(ctx, address, source)
 			if createErr != nil {
 				uc.logger.Error().
 					Err(err).Stringer("addr", address).
@@ -90,12 +109,24 @@ func (uc UseCase) getOrCreateServer(ctx context.Context, address addr.Addr) (ser
 func (uc UseCase) createServerFromAddress(
 	ctx context.Context,
 	address addr.Addr,
+	source ds.DiscoveryStatus,
 ) (server.Server, error) {
 	svr, err := server.NewFromAddr(address, address.Port+1)
 	if err != nil {
 		return server.Blank, err
 	}
 
+	if source.HasStatus() {
+		svr.UpdateDiscoveryStatus(source)
+	}
+
+	if loc, err := uc.locator.Locate(address.GetIP()); err != nil {
+		// it's not critical, the server is still added with no location
+		uc.logger.Warn().Err(err).Stringer("addr", address).Msg("Failed to resolve server location")
+	} else {
+		svr.UpdateLocation(loc)
+	}
+
 	if svr, err = uc.serverRepo.Add(ctx, svr, func(_ *server.Server) bool {
 		// a server with exactly same address was created in the process,
 		// we cannot proceed further
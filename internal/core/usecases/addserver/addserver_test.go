@@ -2,6 +2,8 @@ package addserver_test
 
 import (
 	"context"
+	"errors"
+	"net"
 	"testing"
 	"time"
 
@@ -13,6 +15,7 @@ import (
 
 	"github.com/sergeii/swat4master/internal/core/entities/addr"
 	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/internal/core/entities/location"
 	"github.com/sergeii/swat4master/internal/core/entities/probe"
 	"github.com/sergeii/swat4master/internal/core/entities/server"
 	"github.com/sergeii/swat4master/internal/core/repositories"
@@ -64,6 +67,15 @@ func (m *MockProbeRepository) AddBetween(
 	return args.Error(0)
 }
 
+type MockLocator struct {
+	mock.Mock
+}
+
+func (m *MockLocator) Locate(ip net.IP) (location.Location, error) {
+	args := m.Called(ip)
+	return args.Get(0).(location.Location), args.Error(1) //nolint: forcetypeassert
+}
+
 func TestAddServerUseCase_ServerExists(t *testing.T) {
 	ctx := context.TODO()
 
@@ -146,10 +158,12 @@ func TestAddServerUseCase_ServerExists(t *testing.T) {
 			probeRepo := new(MockProbeRepository)
 			probeRepo.On("AddBetween", ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
+			locator := new(MockLocator)
+
 			ucOpts := addserver.UseCaseOptions{
 				MaxProbeRetries: 3,
 			}
-			uc := addserver.New(serverRepo, probeRepo, ucOpts, collector, &logger)
+			uc := addserver.New(serverRepo, probeRepo, locator, ucOpts, collector, &logger)
 			addedSvr, err := uc.Execute(ctx, addr.MustNewPublicAddr(svr.Addr))
 
 			if tt.wantErr != nil {
@@ -166,6 +180,8 @@ func TestAddServerUseCase_ServerExists(t *testing.T) {
 			}
 
 			serverRepo.AssertCalled(t, "Get", ctx, svr.Addr)
+			// existing servers are located when they report
+			locator.AssertNotCalled(t, "Locate", mock.Anything)
 
 			probesProducedMetricValue := testutil.ToFloat64(collector.DiscoveryQueueProduced)
 			if tt.wantProbe {
@@ -211,15 +227,21 @@ func TestAddServerUseCase_ServerDoesNotExist(t *testing.T) {
 	probeRepo := new(MockProbeRepository)
 	probeRepo.On("AddBetween", ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
+	newLocation := location.New(newSvr.Addr.GetIP(), "AU", "OC", 13335)
+	locator := new(MockLocator)
+	locator.On("Locate", newSvr.Addr.GetIP()).Return(newLocation, nil)
+
 	ucOpts := addserver.UseCaseOptions{
 		MaxProbeRetries: 3,
 	}
-	uc := addserver.New(serverRepo, probeRepo, ucOpts, collector, &logger)
+	uc := addserver.New(serverRepo, probeRepo, locator, ucOpts, collector, &logger)
 	_, err := uc.Execute(ctx, addr.MustNewPublicAddr(newSvr.Addr))
 	require.ErrorIs(t, err, addserver.ErrServerDiscoveryInProgress)
 
+	wantSvr := server.MustNewFromAddr(newSvr.Addr, 10481)
+	wantSvr.UpdateLocation(newLocation)
 	serverRepo.AssertCalled(t, "Get", ctx, newSvr.Addr)
-	serverRepo.AssertCalled(t, "Add", ctx, server.MustNewFromAddr(newSvr.Addr, 10481), mock.Anything)
+	serverRepo.AssertCalled(t, "Add", ctx, wantSvr, mock.Anything)
 	serverRepo.AssertCalled(
 		t,
 		"Update",
@@ -242,3 +264,76 @@ func TestAddServerUseCase_ServerDoesNotExist(t *testing.T) {
 	probesProducedMetricValue := testutil.ToFloat64(collector.DiscoveryQueueProduced)
 	assert.InDelta(t, float64(1), probesProducedMetricValue, 1e-9)
 }
+
+func TestAddServerUseCase_ServerDoesNotExist_LocationFails(t *testing.T) {
+	ctx := context.TODO()
+	logger := zerolog.Nop()
+	collector := metrics.New()
+
+	newSvr := serverfactory.Build(serverfactory.WithAddress("1.1.1.1", 10480))
+
+	serverRepo := new(MockServerRepository)
+	serverRepo.On("Get", ctx, newSvr.Addr).Return(server.Blank, repositories.ErrServerNotFound)
+	serverRepo.On("Add", ctx, mock.Anything, mock.Anything).Return(newSvr, nil)
+	serverRepo.On("Update", ctx, mock.Anything, mock.Anything).Return(newSvr, nil)
+
+	probeRepo := new(MockProbeRepository)
+	probeRepo.On("AddBetween", ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	locator := new(MockLocator)
+	locator.On("Locate", newSvr.Addr.GetIP()).Return(location.Blank, errors.New("error"))
+
+	ucOpts := addserver.UseCaseOptions{
+		MaxProbeRetries: 3,
+	}
+	uc := addserver.New(serverRepo, probeRepo, locator, ucOpts, collector, &logger)
+	_, err := uc.Execute(ctx, addr.MustNewPublicAddr(newSvr.Addr))
+	require.ErrorIs(t, err, addserver.ErrServerDiscoveryInProgress)
+
+	// the server is added regardless
+	serverRepo.AssertCalled(t, "Add", ctx, server.MustNewFromAddr(newSvr.Addr, 10481), mock.Anything)
+}
+
+func TestAddServerUseCase_ServerDoesNotExist_WithSource(t *testing.T) {
+	ctx := context.TODO()
+	logger := zerolog.Nop()
+	collector := metrics.New()
+
+	newSvr := serverfactory.Build(serverfactory.WithAddress("1.1.1.1", 10480))
+
+	serverRepo := new(MockServerRepository)
+	serverRepo.On("Get", ctx, newSvr.Addr).Return(server.Blank, repositories.ErrServerNotFound)
+	serverRepo.On("Add", ctx, mock.Anything, mock.Anything).Return(newSvr, nil)
+	serverRepo.On("Update", ctx, mock.Anything, mock.Anything).Return(newSvr, nil)
+
+	probeRepo := new(MockProbeRepository)
+	probeRepo.On("AddBetween", ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	locator := new(MockLocator)
+	locator.On("Locate", mock.Anything).Return(location.Blank, nil)
+
+	ucOpts := addserver.UseCaseOptions{
+		MaxProbeRetries: 3,
+	}
+	uc := addserver.New(serverRepo, probeRepo, locator, ucOpts, collector, &logger)
+	_, err := uc.ExecuteWithSource(ctx, addr.MustNewPublicAddr(newSvr.Addr), ds.Federated)
+	require.ErrorIs(t, err, addserver.ErrServerDiscoveryInProgress)
+
+	serverRepo.AssertCalled(
+		t,
+		"Add",
+		ctx,
+		mock.MatchedBy(func(addedSvr server.Server) bool {
+			return addedSvr.DiscoveryStatus == ds.Federated
+		}),
+		mock.Anything,
+	)
+	probeRepo.AssertCalled(
+		t,
+		"AddBetween",
+		ctx,
+		probe.New(newSvr.Addr, 10480, probe.GoalPort, 3),
+		repositories.NC,
+		repositories.NC,
+	)
+}
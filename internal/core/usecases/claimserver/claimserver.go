@@ -0,0 +1,110 @@
+package claimserver
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/internal/core/entities/server"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+	"github.com/sergeii/swat4master/pkg/random"
+)
+
+var (
+	ErrServerNotFound      = errors.New("server not found")
+	ErrServerHasNoDetails  = errors.New("server has no details")
+	ErrTooManyClaims       = errors.New("server has too many pending claims")
+	ErrUnableToClaimServer = errors.New("unable to claim server")
+)
+
+const (
+	// ClaimTTL is how long the owner has to put the claim token in the server's query fields
+	ClaimTTL = time.Hour
+
+	tokenPrefix = "s4m-"
+	tokenSize   = 4
+	keySize     = 32
+)
+
+type UseCase struct {
+	serverRepo repositories.ServerRepository
+	clock      clockwork.Clock
+}
+
+func New(
+	serverRepo repositories.ServerRepository,
+	clock clockwork.Clock,
+) UseCase {
+	return UseCase{
+		serverRepo: serverRepo,
+		clock:      clock,
+	}
+}
+
+type Response struct {
+	Token     string
+	Key       string
+	ExpiresAt time.Time
+}
+
+var NoResponse = Response{}
+
+// Execute issues a claim of the server's ownership.
+// The returned key is not stored and is only given once,
+// it becomes valid as soon as the claim token is found in the server's query fields.
+// Only the servers that are queried for their details are able to confirm the claim.
+// No claim is issued while the server has too many pending claims.
+func (uc UseCase) Execute(ctx context.Context, publicAddr addr.PublicAddr) (Response, error) {
+	svr, err := uc.serverRepo.Get(ctx, publicAddr.ToAddr())
+	if err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrServerNotFound):
+			return NoResponse, ErrServerNotFound
+		default:
+			return NoResponse, fmt.Errorf("claimserver: repo: %w", ErrUnableToClaimServer)
+		}
+	}
+
+	if !svr.HasDiscoveryStatus(ds.Details) {
+		return NoResponse, ErrServerHasNoDetails
+	}
+
+	token := tokenPrefix + hex.EncodeToString(random.RandBytes(tokenSize))
+	key := hex.EncodeToString(random.RandBytes(keySize))
+	now := uc.clock.Now()
+	claim := server.Claim{
+		Token:     token,
+		KeyHash:   server.HashOwnerKey(key),
+		ExpiresAt: now.Add(ClaimTTL),
+	}
+
+	if !svr.ClaimOwnership(claim, now) {
+		return NoResponse, ErrTooManyClaims
+	}
+	claimed := true
+	if _, err := uc.serverRepo.Update(ctx, svr, func(s *server.Server) bool {
+		claimed = s.ClaimOwnership(claim, now)
+		return claimed
+	}); err != nil {
+		if errors.Is(err, repositories.ErrServerNotFound) {
+			return NoResponse, ErrServerNotFound
+		}
+		return NoResponse, fmt.Errorf("claimserver: repo: %w", ErrUnableToClaimServer)
+	}
+	// the claims of the newer version of the server have run out in the meantime
+	if !claimed {
+		return NoResponse, ErrTooManyClaims
+	}
+
+	return Response{
+		Token:     token,
+		Key:       key,
+		ExpiresAt: claim.ExpiresAt,
+	}, nil
+}
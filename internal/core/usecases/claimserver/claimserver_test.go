@@ -0,0 +1,181 @@
+package claimserver_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/internal/core/entities/server"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+	"github.com/sergeii/swat4master/internal/core/usecases/claimserver"
+	"github.com/sergeii/swat4master/internal/testutils/factories/serverfactory"
+)
+
+type MockServerRepository struct {
+	mock.Mock
+	repositories.ServerRepository
+}
+
+func (m *MockServerRepository) Get(ctx context.Context, addr addr.Addr) (server.Server, error) {
+	args := m.Called(ctx, addr)
+	return args.Get(0).(server.Server), args.Error(1) //nolint: forcetypeassert
+}
+
+func (m *MockServerRepository) Update(
+	ctx context.Context,
+	svr server.Server,
+	onConflict func(*server.Server) bool,
+) (server.Server, error) {
+	args := m.Called(ctx, svr, onConflict)
+	return args.Get(0).(server.Server), args.Error(1) //nolint: forcetypeassert
+}
+
+func TestClaimServerUseCase_OK(t *testing.T) {
+	ctx := context.TODO()
+	clock := clockwork.NewFakeClock()
+
+	svr := serverfactory.Build(serverfactory.WithDiscoveryStatus(ds.Info | ds.Details))
+
+	repo := new(MockServerRepository)
+	repo.On("Get", ctx, svr.Addr).Return(svr, nil)
+	repo.On("Update", ctx, mock.Anything, mock.Anything).Return(svr, nil)
+
+	uc := claimserver.New(repo, clock)
+	resp, err := uc.Execute(ctx, addr.MustNewPublicAddr(svr.Addr))
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(resp.Token, "s4m-"))
+	assert.Len(t, resp.Token, 12)
+	assert.Len(t, resp.Key, 64)
+	assert.Equal(t, clock.Now().Add(claimserver.ClaimTTL), resp.ExpiresAt)
+
+	updated := repo.Calls[1].Arguments.Get(1).(server.Server) //nolint: forcetypeassert
+	assert.Equal(t, []server.Claim{
+		{
+			Token:     resp.Token,
+			KeyHash:   server.HashOwnerKey(resp.Key),
+			ExpiresAt: resp.ExpiresAt,
+		},
+	}, updated.Ownership.Claims)
+	assert.False(t, updated.Ownership.IsVerified())
+
+	// the claim is reapplied to the newer version of the server
+	onConflict := repo.Calls[1].Arguments.Get(2).(func(*server.Server) bool) //nolint: forcetypeassert
+	newer := svr
+	assert.True(t, onConflict(&newer))
+	require.Len(t, newer.Ownership.Claims, 1)
+	assert.Equal(t, resp.Token, newer.Ownership.Claims[0].Token)
+
+	// another claim yields different credentials
+	another, err := uc.Execute(ctx, addr.MustNewPublicAddr(svr.Addr))
+	require.NoError(t, err)
+	assert.NotEqual(t, resp.Token, another.Token)
+	assert.NotEqual(t, resp.Key, another.Key)
+}
+
+func TestClaimServerUseCase_Errors(t *testing.T) {
+	ctx := context.TODO()
+	clock := clockwork.NewFakeClock()
+
+	withDetails := serverfactory.Build(serverfactory.WithDiscoveryStatus(ds.Info | ds.Details))
+	noDetails := serverfactory.Build(serverfactory.WithDiscoveryStatus(ds.Master | ds.Info))
+
+	tests := []struct {
+		name      string
+		svr       server.Server
+		getErr    error
+		updateErr error
+		wantErr   error
+	}{
+		{"not found", server.Blank, repositories.ErrServerNotFound, nil, claimserver.ErrServerNotFound},
+		{"get error", server.Blank, errors.New("error"), nil, claimserver.ErrUnableToClaimServer},
+		{"no details", noDetails, nil, nil, claimserver.ErrServerHasNoDetails},
+		{"gone", withDetails, nil, repositories.ErrServerNotFound, claimserver.ErrServerNotFound},
+		{"update error", withDetails, nil, errors.New("error"), claimserver.ErrUnableToClaimServer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svrAddr := addr.MustNewFromDotted("1.1.1.1", 10480)
+			repo := new(MockServerRepository)
+			repo.On("Get", ctx, svrAddr).Return(tt.svr, tt.getErr)
+			repo.On("Update", ctx, mock.Anything, mock.Anything).Return(server.Blank, tt.updateErr)
+
+			uc := claimserver.New(repo, clock)
+			resp, err := uc.Execute(ctx, addr.MustNewPublicAddr(svrAddr))
+			require.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, claimserver.NoResponse, resp)
+		})
+	}
+}
+
+func TestClaimServerUseCase_TooManyClaims(t *testing.T) {
+	ctx := context.TODO()
+	clock := clockwork.NewFakeClock()
+
+	svr := serverfactory.Build(serverfactory.WithDiscoveryStatus(ds.Info | ds.Details))
+	for i := range server.MaxPendingClaims {
+		svr.ClaimOwnership(server.Claim{
+			Token:     fmt.Sprintf("s4m-%08d", i),
+			KeyHash:   server.HashOwnerKey("secret"),
+			ExpiresAt: clock.Now().Add(time.Minute * time.Duration(i+1)),
+		}, clock.Now())
+	}
+
+	repo := new(MockServerRepository)
+	repo.On("Get", ctx, svr.Addr).Return(svr, nil)
+	repo.On("Update", ctx, mock.Anything, mock.Anything).Return(svr, nil)
+
+	uc := claimserver.New(repo, clock)
+	resp, err := uc.Execute(ctx, addr.MustNewPublicAddr(svr.Addr))
+	require.ErrorIs(t, err, claimserver.ErrTooManyClaims)
+	assert.Equal(t, claimserver.NoResponse, resp)
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+
+	// one of the pending claims has expired
+	clock.Advance(time.Minute)
+	resp, err = uc.Execute(ctx, addr.MustNewPublicAddr(svr.Addr))
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.Token)
+}
+
+func TestClaimServerUseCase_TooManyClaimsOnConflict(t *testing.T) {
+	ctx := context.TODO()
+	clock := clockwork.NewFakeClock()
+
+	svr := serverfactory.Build(serverfactory.WithDiscoveryStatus(ds.Info | ds.Details))
+	// the newer version of the server has been claimed by others in the meantime
+	newer := svr
+	for i := range server.MaxPendingClaims {
+		newer.ClaimOwnership(server.Claim{
+			Token:     fmt.Sprintf("s4m-%08d", i),
+			KeyHash:   server.HashOwnerKey("secret"),
+			ExpiresAt: clock.Now().Add(time.Hour),
+		}, clock.Now())
+	}
+
+	repo := new(MockServerRepository)
+	repo.On("Get", ctx, svr.Addr).Return(svr, nil)
+	repo.On("Update", ctx, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			onConflict := args.Get(2).(func(*server.Server) bool) //nolint: forcetypeassert
+			resolved := newer
+			assert.False(t, onConflict(&resolved))
+		}).
+		Return(newer, nil)
+
+	uc := claimserver.New(repo, clock)
+	resp, err := uc.Execute(ctx, addr.MustNewPublicAddr(svr.Addr))
+	require.ErrorIs(t, err, claimserver.ErrTooManyClaims)
+	assert.Equal(t, claimserver.NoResponse, resp)
+}
@@ -0,0 +1,64 @@
+package featureserver
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/featured"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+)
+
+var (
+	ErrInvalidWindow         = errors.New("featuring window ends before it starts")
+	ErrUnableToFeatureServer = errors.New("unable to add featured server to repository")
+)
+
+type UseCase struct {
+	featuredRepo repositories.FeaturedRepository
+}
+
+func New(
+	featuredRepo repositories.FeaturedRepository,
+) UseCase {
+	return UseCase{
+		featuredRepo: featuredRepo,
+	}
+}
+
+type Request struct {
+	addr     addr.PublicAddr
+	priority int
+	since    time.Time
+	until    time.Time
+}
+
+func NewRequest(
+	publicAddr addr.PublicAddr,
+	priority int,
+	since time.Time,
+	until time.Time,
+) Request {
+	return Request{
+		addr:     publicAddr,
+		priority: priority,
+		since:    since,
+		until:    until,
+	}
+}
+
+// Execute adds the server to the featured list, or updates it if the server is already featured.
+// The server is not required to be known to the master at the time it is featured.
+func (uc UseCase) Execute(ctx context.Context, req Request) (featured.Featured, error) {
+	if !req.since.IsZero() && !req.until.IsZero() && !req.until.After(req.since) {
+		return featured.Blank, ErrInvalidWindow
+	}
+
+	item := featured.New(req.addr.ToAddr(), req.priority, req.since, req.until)
+	if err := uc.featuredRepo.Add(ctx, item); err != nil {
+		return featured.Blank, ErrUnableToFeatureServer
+	}
+
+	return item, nil
+}
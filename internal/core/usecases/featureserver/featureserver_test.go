@@ -0,0 +1,91 @@
+package featureserver_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/featured"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+	"github.com/sergeii/swat4master/internal/core/usecases/featureserver"
+)
+
+type MockFeaturedRepository struct {
+	mock.Mock
+	repositories.FeaturedRepository
+}
+
+func (m *MockFeaturedRepository) Add(ctx context.Context, item featured.Featured) error {
+	args := m.Called(ctx, item)
+	return args.Error(0)
+}
+
+func TestFeatureServerUseCase_OK(t *testing.T) {
+	ctx := context.TODO()
+	since := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	until := since.Add(time.Hour)
+
+	tests := []struct {
+		name  string
+		since time.Time
+		until time.Time
+	}{
+		{"no window", featured.NC, featured.NC},
+		{"window start only", since, featured.NC},
+		{"window end only", featured.NC, until},
+		{"window", since, until},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svrAddr := addr.MustNewFromDotted("1.1.1.1", 10480)
+			want := featured.New(svrAddr, 5, tt.since, tt.until)
+
+			repo := new(MockFeaturedRepository)
+			repo.On("Add", ctx, want).Return(nil)
+
+			uc := featureserver.New(repo)
+			req := featureserver.NewRequest(addr.MustNewPublicAddr(svrAddr), 5, tt.since, tt.until)
+			item, err := uc.Execute(ctx, req)
+			require.NoError(t, err)
+			assert.Equal(t, want, item)
+
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestFeatureServerUseCase_InvalidWindow(t *testing.T) {
+	ctx := context.TODO()
+	since := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, until := range []time.Time{since, since.Add(-time.Second)} {
+		repo := new(MockFeaturedRepository)
+
+		uc := featureserver.New(repo)
+		pubAddr := addr.MustNewPublicAddr(addr.MustNewFromDotted("1.1.1.1", 10480))
+		req := featureserver.NewRequest(pubAddr, 0, since, until)
+		_, err := uc.Execute(ctx, req)
+		require.ErrorIs(t, err, featureserver.ErrInvalidWindow)
+
+		repo.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+	}
+}
+
+func TestFeatureServerUseCase_RepoError(t *testing.T) {
+	ctx := context.TODO()
+
+	repo := new(MockFeaturedRepository)
+	repo.On("Add", ctx, mock.Anything).Return(errors.New("error"))
+
+	uc := featureserver.New(repo)
+	pubAddr := addr.MustNewPublicAddr(addr.MustNewFromDotted("1.1.1.1", 10480))
+	req := featureserver.NewRequest(pubAddr, 0, featured.NC, featured.NC)
+	_, err := uc.Execute(ctx, req)
+	require.ErrorIs(t, err, featureserver.ErrUnableToFeatureServer)
+}
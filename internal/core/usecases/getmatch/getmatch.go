@@ -0,0 +1,37 @@
+package getmatch
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sergeii/swat4master/internal/core/entities/match"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+)
+
+var (
+	ErrMatchNotFound       = errors.New("match not found")
+	ErrUnableToObtainMatch = errors.New("unable to obtain match from repository")
+)
+
+type UseCase struct {
+	matchRepo repositories.MatchRepository
+}
+
+func New(matchRepo repositories.MatchRepository) UseCase {
+	return UseCase{
+		matchRepo: matchRepo,
+	}
+}
+
+func (uc UseCase) Execute(ctx context.Context, id string) (match.Match, error) {
+	m, err := uc.matchRepo.Get(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrMatchNotFound):
+			return match.Blank, ErrMatchNotFound
+		default:
+			return match.Blank, ErrUnableToObtainMatch
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,64 @@
+package getmatch_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/match"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+	"github.com/sergeii/swat4master/internal/core/usecases/getmatch"
+)
+
+type MockMatchRepository struct {
+	mock.Mock
+	repositories.MatchRepository
+}
+
+func (m *MockMatchRepository) Get(ctx context.Context, id string) (match.Match, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(match.Match), args.Error(1) //nolint: forcetypeassert
+}
+
+func TestGetMatchUseCase_OK(t *testing.T) {
+	ctx := context.TODO()
+	m := match.Match{ID: "foo", Addr: addr.MustNewFromDotted("1.1.1.1", 10480), Winner: match.WinnerSwat}
+
+	repo := new(MockMatchRepository)
+	repo.On("Get", ctx, "foo").Return(m, nil)
+
+	uc := getmatch.New(repo)
+	got, err := uc.Execute(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, m, got)
+	repo.AssertExpectations(t)
+}
+
+func TestGetMatchUseCase_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		repoErr error
+		wantErr error
+	}{
+		{"not found", repositories.ErrMatchNotFound, getmatch.ErrMatchNotFound},
+		{"repo error", errors.New("error"), getmatch.ErrUnableToObtainMatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TODO()
+
+			repo := new(MockMatchRepository)
+			repo.On("Get", ctx, "foo").Return(match.Blank, tt.repoErr)
+
+			uc := getmatch.New(repo)
+			_, err := uc.Execute(ctx, "foo")
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package getprofile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/profile"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+)
+
+var (
+	ErrProfileNotFound       = errors.New("profile not found")
+	ErrUnableToObtainProfile = errors.New("unable to obtain profile from repository")
+)
+
+type UseCase struct {
+	profileRepo repositories.ProfileRepository
+}
+
+func New(
+	profileRepo repositories.ProfileRepository,
+) UseCase {
+	return UseCase{
+		profileRepo: profileRepo,
+	}
+}
+
+func (uc UseCase) Execute(ctx context.Context, publicAddr addr.PublicAddr) (profile.Profile, error) {
+	item, err := uc.profileRepo.Get(ctx, publicAddr.ToAddr())
+	if err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrProfileNotFound):
+			return profile.Blank, ErrProfileNotFound
+		default:
+			return profile.Blank, fmt.Errorf("getprofile: repo: %w", ErrUnableToObtainProfile)
+		}
+	}
+	return item, nil
+}
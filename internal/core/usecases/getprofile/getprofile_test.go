@@ -0,0 +1,66 @@
+package getprofile_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/profile"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+	"github.com/sergeii/swat4master/internal/core/usecases/getprofile"
+)
+
+type MockProfileRepository struct {
+	mock.Mock
+	repositories.ProfileRepository
+}
+
+func (m *MockProfileRepository) Get(ctx context.Context, addr addr.Addr) (profile.Profile, error) {
+	args := m.Called(ctx, addr)
+	return args.Get(0).(profile.Profile), args.Error(1) //nolint: forcetypeassert
+}
+
+func TestGetProfileUseCase_OK(t *testing.T) {
+	ctx := context.TODO()
+	svrAddr := addr.MustNewFromDotted("1.1.1.1", 10480)
+	item := profile.New(svrAddr, "Best server", "", "", "EU", time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+
+	repo := new(MockProfileRepository)
+	repo.On("Get", ctx, svrAddr).Return(item, nil)
+
+	uc := getprofile.New(repo)
+	got, err := uc.Execute(ctx, addr.MustNewPublicAddr(svrAddr))
+	require.NoError(t, err)
+	assert.Equal(t, item, got)
+}
+
+func TestGetProfileUseCase_Errors(t *testing.T) {
+	ctx := context.TODO()
+	svrAddr := addr.MustNewFromDotted("1.1.1.1", 10480)
+
+	tests := []struct {
+		name    string
+		repoErr error
+		wantErr error
+	}{
+		{"not found", repositories.ErrProfileNotFound, getprofile.ErrProfileNotFound},
+		{"repo error", errors.New("error"), getprofile.ErrUnableToObtainProfile},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockProfileRepository)
+			repo.On("Get", ctx, svrAddr).Return(profile.Blank, tt.repoErr)
+
+			uc := getprofile.New(repo)
+			_, err := uc.Execute(ctx, addr.MustNewPublicAddr(svrAddr))
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
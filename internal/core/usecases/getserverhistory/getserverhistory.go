@@ -0,0 +1,124 @@
+package getserverhistory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/history"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+)
+
+const (
+	// MaxPoints limits the number of points a single history request may produce
+	MaxPoints = 1000
+	// DefaultSpan is the length of the range that ends now, when the range is not requested
+	DefaultSpan = time.Hour * 24
+)
+
+var (
+	ErrInvalidRange          = errors.New("the start of the range must precede its end")
+	ErrRangeOutOfRetention   = errors.New("the range starts beyond the kept history")
+	ErrTooManyPoints         = errors.New("the step is too small for the range")
+	ErrUnableToObtainHistory = errors.New("unable to obtain server history from repository")
+)
+
+type UseCase struct {
+	historyRepo repositories.HistoryRepository
+	clock       clockwork.Clock
+}
+
+func New(historyRepo repositories.HistoryRepository, clock clockwork.Clock) UseCase {
+	return UseCase{
+		historyRepo: historyRepo,
+		clock:       clock,
+	}
+}
+
+type Request struct {
+	addr addr.Addr
+	from time.Time
+	to   time.Time
+	step time.Duration
+}
+
+// NewRequest creates a request for the history of the server within the time range.
+// The zero end of the range stands for now, and the zero start - for the default span before the end.
+// The zero step lets the use case pick the step that fits the range.
+func NewRequest(svrAddr addr.Addr, from, to time.Time, step time.Duration) Request {
+	return Request{
+		addr: svrAddr,
+		from: from,
+		to:   to,
+		step: step,
+	}
+}
+
+type Response struct {
+	From       time.Time
+	To         time.Time
+	Resolution history.Resolution
+	Step       time.Duration
+	Points     []history.Point
+}
+
+var NoResponse = Response{}
+
+// Execute returns the history of the server aggregated by the step.
+// The history is read from the finest resolution that still keeps the start of the range.
+// The step is never finer than the resolution and is always a multiple of its interval.
+func (uc UseCase) Execute(ctx context.Context, req Request) (Response, error) {
+	now := uc.clock.Now()
+	from, to := req.from, req.to
+	if to.IsZero() {
+		to = now
+	}
+	if from.IsZero() {
+		from = to.Add(-DefaultSpan)
+	}
+	if !from.Before(to) {
+		return NoResponse, ErrInvalidRange
+	}
+
+	res, err := history.ResolutionFor(from, now)
+	if err != nil {
+		return NoResponse, ErrRangeOutOfRetention
+	}
+
+	step, err := pickStep(to.Sub(from), req.step, res)
+	if err != nil {
+		return NoResponse, err
+	}
+
+	points, err := uc.historyRepo.Range(ctx, req.addr, res, from, to)
+	if err != nil {
+		return NoResponse, fmt.Errorf("getserverhistory: repo: %w", ErrUnableToObtainHistory)
+	}
+
+	return Response{
+		From:       from,
+		To:         to,
+		Resolution: res,
+		Step:       step,
+		Points:     history.Downsample(points, res.Bucket(from), step),
+	}, nil
+}
+
+func pickStep(span, step time.Duration, res history.Resolution) (time.Duration, error) {
+	auto := step == 0
+	if auto {
+		// the finest step that still keeps the range within the most points
+		step = (span + MaxPoints - 1) / MaxPoints
+	}
+	// round the step up to a multiple of the resolution interval
+	intervals := (step + res.Interval - 1) / res.Interval
+	step = max(intervals, 1) * res.Interval
+	if !auto && span/step > MaxPoints {
+		return 0, ErrTooManyPoints
+	}
+	return step, nil
+}
@@ -0,0 +1,144 @@
+package getserverhistory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/history"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+	"github.com/sergeii/swat4master/internal/core/usecases/getserverhistory"
+)
+
+type MockHistoryRepository struct {
+	mock.Mock
+	repositories.HistoryRepository
+}
+
+func (m *MockHistoryRepository) Range(
+	ctx context.Context,
+	svrAddr addr.Addr,
+	res history.Resolution,
+	from, to time.Time,
+) ([]history.Point, error) {
+	args := m.Called(ctx, svrAddr, res, from, to)
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
+	return args.Get(0).([]history.Point), nil //nolint: forcetypeassert
+}
+
+func makePoints(start time.Time, interval time.Duration, count int) []history.Point {
+	points := make([]history.Point, 0, count)
+	for i := range count {
+		pt := history.NewPoint(start.Add(interval * time.Duration(i)))
+		pt.Add(history.Sample{Online: true, Players: i})
+		points = append(points, pt)
+	}
+	return points
+}
+
+func TestGetServerHistoryUseCase_Defaults(t *testing.T) {
+	ctx := context.TODO()
+	clock := clockwork.NewFakeClockAt(time.Date(2025, 1, 1, 12, 0, 30, 0, time.UTC))
+	svrAddr := addr.MustNewFromDotted("1.1.1.1", 10480)
+	now := clock.Now()
+	from := now.Add(-time.Hour * 24)
+
+	repo := new(MockHistoryRepository)
+	repo.On("Range", ctx, svrAddr, history.Raw, from, now).
+		Return(makePoints(history.Raw.Bucket(from), time.Minute, 10), nil)
+
+	uc := getserverhistory.New(repo, clock)
+	resp, err := uc.Execute(ctx, getserverhistory.NewRequest(svrAddr, time.Time{}, time.Time{}, 0))
+	require.NoError(t, err)
+
+	assert.Equal(t, from, resp.From)
+	assert.Equal(t, now, resp.To)
+	assert.Equal(t, history.Raw, resp.Resolution)
+	// a day split into at most 1000 points, rounded up to whole minutes
+	assert.Equal(t, time.Minute*2, resp.Step)
+	require.Len(t, resp.Points, 5)
+	assert.Equal(t, 2, resp.Points[0].Samples)
+	assert.Equal(t, history.Raw.Bucket(from), resp.Points[0].At)
+	repo.AssertExpectations(t)
+}
+
+func TestGetServerHistoryUseCase_ResolutionAndStep(t *testing.T) {
+	clock := clockwork.NewFakeClockAt(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))
+	now := clock.Now()
+
+	tests := []struct {
+		name     string
+		from     time.Time
+		to       time.Time
+		step     time.Duration
+		wantRes  history.Resolution
+		wantStep time.Duration
+	}{
+		{"an hour ago", now.Add(-time.Hour), now, time.Minute * 5, history.Raw, time.Minute * 5},
+		{"step finer than resolution", now.Add(-time.Hour * 48), now, time.Minute, history.FiveMinutes, time.Minute * 5},
+		{"step rounded up", now.Add(-time.Hour * 48), now, time.Minute * 7, history.FiveMinutes, time.Minute * 10},
+		{"last week", now.Add(-time.Hour * 24 * 7), now, time.Hour, history.FiveMinutes, time.Hour},
+		{"last year", now.Add(-time.Hour * 24 * 300), now.Add(-time.Hour * 24 * 290), 0, history.Hourly, time.Hour},
+		{"auto step", now.Add(-time.Hour * 24 * 30), now, 0, history.FiveMinutes, time.Minute * 45},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TODO()
+			svrAddr := addr.MustNewFromDotted("1.1.1.1", 10480)
+
+			repo := new(MockHistoryRepository)
+			repo.On("Range", ctx, svrAddr, tt.wantRes, tt.from, tt.to).Return([]history.Point{}, nil)
+
+			uc := getserverhistory.New(repo, clock)
+			resp, err := uc.Execute(ctx, getserverhistory.NewRequest(svrAddr, tt.from, tt.to, tt.step))
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRes, resp.Resolution)
+			assert.Equal(t, tt.wantStep, resp.Step)
+			assert.Empty(t, resp.Points)
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetServerHistoryUseCase_Errors(t *testing.T) {
+	clock := clockwork.NewFakeClockAt(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))
+	now := clock.Now()
+
+	tests := []struct {
+		name    string
+		from    time.Time
+		to      time.Time
+		step    time.Duration
+		wantErr error
+	}{
+		{"empty range", now, now, 0, getserverhistory.ErrInvalidRange},
+		{"reversed range", now, now.Add(-time.Hour), 0, getserverhistory.ErrInvalidRange},
+		{"beyond retention", now.Add(-time.Hour * 24 * 400), now, 0, getserverhistory.ErrRangeOutOfRetention},
+		{"too many points", now.Add(-time.Hour * 24), now, time.Minute, getserverhistory.ErrTooManyPoints},
+		{"repo error", now.Add(-time.Hour), now, 0, getserverhistory.ErrUnableToObtainHistory},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TODO()
+			svrAddr := addr.MustNewFromDotted("1.1.1.1", 10480)
+
+			repo := new(MockHistoryRepository)
+			repo.On("Range", ctx, svrAddr, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("error"))
+
+			uc := getserverhistory.New(repo, clock)
+			_, err := uc.Execute(ctx, getserverhistory.NewRequest(svrAddr, tt.from, tt.to, tt.step))
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
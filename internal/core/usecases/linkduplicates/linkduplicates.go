@@ -0,0 +1,176 @@
+package linkduplicates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/internal/core/entities/filterset"
+	"github.com/sergeii/swat4master/internal/core/entities/server"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+)
+
+var ErrUnableToObtainServers = errors.New("unable to obtain servers from repository")
+
+type UseCaseOptions struct {
+	ServerLiveness time.Duration
+}
+
+type UseCase struct {
+	serverRepo repositories.ServerRepository
+	opts       UseCaseOptions
+	logger     *zerolog.Logger
+}
+
+func New(
+	serverRepo repositories.ServerRepository,
+	opts UseCaseOptions,
+	logger *zerolog.Logger,
+) UseCase {
+	return UseCase{
+		serverRepo: serverRepo,
+		opts:       opts,
+		logger:     logger,
+	}
+}
+
+type Response struct {
+	Duplicates int
+	Updated    int
+	Errors     int
+}
+
+var NoResponse = Response{}
+
+type link struct {
+	primary    addr.Addr
+	duplicates []addr.Addr
+}
+
+// Execute links the servers that are likely the same game server seen at different addresses,
+// such as a server reporting from one interface and added by its address on another,
+// or a server that has changed its port after a restart.
+// Of every group of duplicates, the server with the highest priority is kept as the primary one,
+// whereas the rest are linked to it as its duplicates.
+// The servers that are no longer active or no longer duplicate anything are unlinked.
+func (uc UseCase) Execute(ctx context.Context, now time.Time) (Response, error) {
+	servers, err := uc.serverRepo.Filter(ctx, filterset.NewServerFilterSet())
+	if err != nil {
+		return NoResponse, fmt.Errorf("linkduplicates: repo: %w", ErrUnableToObtainServers)
+	}
+
+	active := make(map[addr.Addr]server.Server, len(servers))
+	for _, svr := range servers {
+		if svr.HasDiscoveryStatus(ds.Details) && now.Sub(svr.RefreshedAt) <= uc.opts.ServerLiveness {
+			active[svr.Addr] = svr
+		}
+	}
+
+	links := uc.detect(active)
+
+	resp := Response{}
+	for _, svr := range servers {
+		want := links[svr.Addr]
+		if want.primary != addr.Blank {
+			resp.Duplicates++
+		}
+		if !svr.LinkDuplicates(want.primary, want.duplicates) {
+			continue
+		}
+		if _, err := uc.serverRepo.Update(ctx, svr, func(s *server.Server) bool {
+			return s.LinkDuplicates(want.primary, want.duplicates)
+		}); err != nil {
+			if !errors.Is(err, repositories.ErrServerNotFound) {
+				uc.logger.Warn().Err(err).Stringer("server", svr).Msg("Failed to link server duplicates")
+				resp.Errors++
+			}
+			continue
+		}
+		resp.Updated++
+	}
+
+	return resp, nil
+}
+
+// detect groups the active servers sharing the fingerprint.
+// The already linked duplicates remain linked for as long as they host the same game as their primary server,
+// so that the link is not broken whenever the player lists of the servers are briefly different.
+func (uc UseCase) detect(active map[addr.Addr]server.Server) map[addr.Addr]link {
+	groups := make(map[string][]server.Server)
+	for _, svr := range active {
+		if fp, ok := svr.Fingerprint(); ok {
+			groups[fp] = append(groups[fp], svr)
+		}
+	}
+
+	primaries := make(map[addr.Addr]addr.Addr)
+	for _, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		slices.SortFunc(group, server.CompareDuplicates)
+		for _, dup := range group[1:] {
+			primaries[dup.Addr] = group[0].Addr
+		}
+	}
+
+	for _, svr := range active {
+		if !svr.IsDuplicate() {
+			continue
+		}
+		if _, linked := primaries[svr.Addr]; linked {
+			continue
+		}
+		primary, ok := active[svr.DuplicateOf]
+		if !ok || primary.LooseFingerprint() != svr.LooseFingerprint() {
+			continue
+		}
+		primaries[svr.Addr] = primary.Addr
+	}
+
+	links := make(map[addr.Addr]link)
+	for dup := range primaries {
+		primary, ok := resolvePrimary(primaries, dup)
+		if !ok {
+			continue
+		}
+		links[dup] = link{primary: primary}
+		root := links[primary]
+		root.duplicates = append(root.duplicates, dup)
+		links[primary] = root
+	}
+
+	for key, lnk := range links {
+		slices.SortFunc(lnk.duplicates, func(a, b addr.Addr) int {
+			return strings.Compare(a.String(), b.String())
+		})
+		links[key] = lnk
+	}
+
+	return links
+}
+
+// resolvePrimary follows the chain of links up to the server that duplicates nothing.
+// Returns false if the chain loops.
+func resolvePrimary(primaries map[addr.Addr]addr.Addr, dup addr.Addr) (addr.Addr, bool) {
+	seen := map[addr.Addr]struct{}{dup: {}}
+	current := primaries[dup]
+	for {
+		next, linked := primaries[current]
+		if !linked {
+			return current, true
+		}
+		if _, looped := seen[current]; looped {
+			return addr.Blank, false
+		}
+		seen[current] = struct{}{}
+		current = next
+	}
+}
@@ -0,0 +1,241 @@
+package linkduplicates_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/internal/core/entities/filterset"
+	"github.com/sergeii/swat4master/internal/core/entities/server"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+	"github.com/sergeii/swat4master/internal/core/usecases/linkduplicates"
+	"github.com/sergeii/swat4master/internal/testutils/factories/serverfactory"
+)
+
+type MockServerRepository struct {
+	mock.Mock
+	repositories.ServerRepository
+}
+
+func (m *MockServerRepository) Filter(ctx context.Context, fs filterset.ServerFilterSet) ([]server.Server, error) {
+	args := m.Called(ctx, fs)
+	return args.Get(0).([]server.Server), args.Error(1) //nolint: forcetypeassert
+}
+
+func (m *MockServerRepository) Update(
+	ctx context.Context,
+	svr server.Server,
+	onConflict func(*server.Server) bool,
+) (server.Server, error) {
+	args := m.Called(ctx, svr, onConflict)
+	return args.Get(0).(server.Server), args.Error(1) //nolint: forcetypeassert
+}
+
+func updatedServers(repo *MockServerRepository) map[addr.Addr]server.Server {
+	updated := make(map[addr.Addr]server.Server)
+	for _, call := range repo.Calls {
+		if call.Method != "Update" {
+			continue
+		}
+		svr := call.Arguments.Get(1).(server.Server) //nolint: forcetypeassert
+		updated[svr.Addr] = svr
+	}
+	return updated
+}
+
+func buildServer(ip string, status ds.DiscoveryStatus, refreshedAt time.Time, players ...string) server.Server {
+	params := make([]map[string]string, 0, len(players))
+	for _, name := range players {
+		params = append(params, map[string]string{"player": name})
+	}
+	return serverfactory.Build(
+		serverfactory.WithAddress(ip, 10480),
+		serverfactory.WithDiscoveryStatus(status),
+		serverfactory.WithRefreshedAt(refreshedAt),
+		serverfactory.WithPlayers(params),
+	)
+}
+
+func TestLinkDuplicatesUseCase_OK(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	logger := zerolog.Nop()
+
+	reported := buildServer("1.1.1.1", ds.Master|ds.Info|ds.Details, now, "Serge", "Mosquito")
+	// the same server added by the address of another interface
+	added := buildServer("2.2.2.2", ds.Info|ds.Details, now, "Mosquito", "Serge")
+	other := buildServer("3.3.3.3", ds.Info|ds.Details, now, "Serge")
+	// the server has been linked before, its player list is not up-to-date though
+	linked := buildServer("4.4.4.4", ds.Info|ds.Details, now)
+	linked.DuplicateOf = reported.Addr
+	// the server no longer hosts the same game as its primary server
+	unlinked := serverfactory.Build(
+		serverfactory.WithAddress("5.5.5.5", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Info|ds.Details),
+		serverfactory.WithRefreshedAt(now),
+		serverfactory.WithInfo(map[string]string{"hostname": "Another Swat4 Server"}),
+	)
+	unlinked.DuplicateOf = other.Addr
+	other.Duplicates = []addr.Addr{unlinked.Addr}
+	stale := buildServer("6.6.6.6", ds.Info|ds.Details, now.Add(-time.Hour), "Serge", "Mosquito")
+	undiscovered := buildServer("7.7.7.7", ds.Master, now, "Serge", "Mosquito")
+
+	serverRepo := new(MockServerRepository)
+	serverRepo.On("Filter", ctx, filterset.NewServerFilterSet()).
+		Return([]server.Server{added, reported, other, linked, unlinked, stale, undiscovered}, nil)
+	serverRepo.On("Update", ctx, mock.Anything, mock.Anything).Return(server.Blank, nil)
+
+	opts := linkduplicates.UseCaseOptions{ServerLiveness: time.Minute * 3}
+	uc := linkduplicates.New(serverRepo, opts, &logger)
+	resp, err := uc.Execute(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, linkduplicates.Response{Duplicates: 2, Updated: 4}, resp)
+
+	updated := updatedServers(serverRepo)
+	assert.Len(t, updated, 4)
+
+	svr := updated[reported.Addr]
+	assert.False(t, svr.IsDuplicate())
+	assert.Equal(t, []addr.Addr{added.Addr, linked.Addr}, svr.Duplicates)
+
+	svr = updated[added.Addr]
+	assert.Equal(t, reported.Addr, svr.DuplicateOf)
+	assert.Empty(t, svr.Duplicates)
+
+	svr = updated[other.Addr]
+	assert.False(t, svr.IsDuplicate())
+	assert.Empty(t, svr.Duplicates)
+
+	svr = updated[unlinked.Addr]
+	assert.False(t, svr.IsDuplicate())
+}
+
+func TestLinkDuplicatesUseCase_PreferReportedServer(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	logger := zerolog.Nop()
+
+	added := buildServer("1.1.1.1", ds.Info|ds.Details, now, "Serge")
+	added.MarkAvailable(now.Add(-time.Hour*24), now.Add(-time.Hour*24))
+	// the server has been restarted and now reports on another port
+	reported := serverfactory.Build(
+		serverfactory.WithAddress("1.1.1.1", 10580),
+		serverfactory.WithQueryPort(10581),
+		serverfactory.WithDiscoveryStatus(ds.Master|ds.Info|ds.Details),
+		serverfactory.WithRefreshedAt(now),
+		serverfactory.WithPlayers([]map[string]string{{"player": "Serge"}}),
+	)
+	reported.MarkAvailable(now.Add(-time.Minute), now.Add(-time.Minute))
+
+	serverRepo := new(MockServerRepository)
+	serverRepo.On("Filter", ctx, filterset.NewServerFilterSet()).Return([]server.Server{added, reported}, nil)
+	serverRepo.On("Update", ctx, mock.Anything, mock.Anything).Return(server.Blank, nil)
+
+	opts := linkduplicates.UseCaseOptions{ServerLiveness: time.Minute * 3}
+	uc := linkduplicates.New(serverRepo, opts, &logger)
+	resp, err := uc.Execute(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, resp.Duplicates)
+
+	updated := updatedServers(serverRepo)
+	assert.Equal(t, reported.Addr, updated[added.Addr].DuplicateOf)
+	assert.Equal(t, []addr.Addr{added.Addr}, updated[reported.Addr].Duplicates)
+}
+
+func TestLinkDuplicatesUseCase_NothingChanged(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	logger := zerolog.Nop()
+
+	primary := buildServer("1.1.1.1", ds.Master|ds.Info|ds.Details, now, "Serge")
+	dup := buildServer("2.2.2.2", ds.Info|ds.Details, now, "Serge")
+	primary.Duplicates = []addr.Addr{dup.Addr}
+	dup.DuplicateOf = primary.Addr
+
+	serverRepo := new(MockServerRepository)
+	serverRepo.On("Filter", ctx, filterset.NewServerFilterSet()).Return([]server.Server{primary, dup}, nil)
+
+	opts := linkduplicates.UseCaseOptions{ServerLiveness: time.Minute * 3}
+	uc := linkduplicates.New(serverRepo, opts, &logger)
+	resp, err := uc.Execute(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, linkduplicates.Response{Duplicates: 1}, resp)
+
+	serverRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLinkDuplicatesUseCase_ResolveConflict(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	logger := zerolog.Nop()
+
+	primary := buildServer("1.1.1.1", ds.Master|ds.Info|ds.Details, now, "Serge")
+	dup := buildServer("2.2.2.2", ds.Info|ds.Details, now, "Serge")
+
+	serverRepo := new(MockServerRepository)
+	serverRepo.On("Filter", ctx, filterset.NewServerFilterSet()).Return([]server.Server{dup, primary}, nil)
+	serverRepo.On("Update", ctx, mock.Anything, mock.Anything).Return(server.Blank, nil)
+
+	opts := linkduplicates.UseCaseOptions{ServerLiveness: time.Minute * 3}
+	uc := linkduplicates.New(serverRepo, opts, &logger)
+	_, err := uc.Execute(ctx, now)
+	require.NoError(t, err)
+
+	onConflict := serverRepo.Calls[1].Arguments.Get(2).(func(*server.Server) bool) //nolint: forcetypeassert
+
+	// the server has been refreshed in the meantime
+	refreshed := dup
+	refreshed.Refresh(now.Add(time.Second))
+	assert.True(t, onConflict(&refreshed))
+	assert.Equal(t, primary.Addr, refreshed.DuplicateOf)
+
+	// the server has already been linked by a concurrent run
+	linked := dup
+	linked.DuplicateOf = primary.Addr
+	assert.False(t, onConflict(&linked))
+}
+
+func TestLinkDuplicatesUseCase_RepoErrors(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	logger := zerolog.Nop()
+	opts := linkduplicates.UseCaseOptions{ServerLiveness: time.Minute * 3}
+
+	t.Run("filter", func(t *testing.T) {
+		serverRepo := new(MockServerRepository)
+		serverRepo.On("Filter", ctx, filterset.NewServerFilterSet()).
+			Return([]server.Server{}, errors.New("error"))
+
+		uc := linkduplicates.New(serverRepo, opts, &logger)
+		_, err := uc.Execute(ctx, now)
+		require.ErrorIs(t, err, linkduplicates.ErrUnableToObtainServers)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		primary := buildServer("1.1.1.1", ds.Master|ds.Info|ds.Details, now, "Serge")
+		gone := buildServer("2.2.2.2", ds.Info|ds.Details, now, "Serge")
+
+		serverRepo := new(MockServerRepository)
+		serverRepo.On("Filter", ctx, filterset.NewServerFilterSet()).
+			Return([]server.Server{primary, gone}, nil)
+		serverRepo.On("Update", ctx, mock.MatchedBy(func(s server.Server) bool {
+			return s.Addr == gone.Addr
+		}), mock.Anything).Return(server.Blank, repositories.ErrServerNotFound)
+		serverRepo.On("Update", ctx, mock.MatchedBy(func(s server.Server) bool {
+			return s.Addr == primary.Addr
+		}), mock.Anything).Return(server.Blank, errors.New("error"))
+
+		uc := linkduplicates.New(serverRepo, opts, &logger)
+		resp, err := uc.Execute(ctx, now)
+		require.NoError(t, err)
+		assert.Equal(t, linkduplicates.Response{Duplicates: 1, Errors: 1}, resp)
+	})
+}
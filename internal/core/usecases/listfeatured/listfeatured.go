@@ -0,0 +1,68 @@
+package listfeatured
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/jonboulle/clockwork"
+
+	"github.com/sergeii/swat4master/internal/core/entities/featured"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+)
+
+var ErrUnableToObtainFeatured = errors.New("unable to obtain featured servers from repository")
+
+type UseCase struct {
+	featuredRepo repositories.FeaturedRepository
+	clock        clockwork.Clock
+}
+
+func New(
+	featuredRepo repositories.FeaturedRepository,
+	clock clockwork.Clock,
+) UseCase {
+	return UseCase{
+		featuredRepo: featuredRepo,
+		clock:        clock,
+	}
+}
+
+type Request struct {
+	activeOnly bool
+}
+
+func NewRequest(activeOnly bool) Request {
+	return Request{
+		activeOnly: activeOnly,
+	}
+}
+
+// Execute returns the featured servers ordered by their priority.
+// Unless all servers are requested, the servers outside of their featuring window are left out.
+func (uc UseCase) Execute(ctx context.Context, req Request) ([]featured.Featured, error) {
+	items, err := uc.featuredRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listfeatured: repo: %w", ErrUnableToObtainFeatured)
+	}
+
+	now := uc.clock.Now()
+	result := make([]featured.Featured, 0, len(items))
+	for _, item := range items {
+		if req.activeOnly && !item.IsActive(now) {
+			continue
+		}
+		result = append(result, item)
+	}
+
+	slices.SortFunc(result, func(a, b featured.Featured) int {
+		return cmp.Or(
+			cmp.Compare(a.Priority, b.Priority),
+			cmp.Compare(a.Addr.String(), b.Addr.String()),
+		)
+	})
+
+	return result, nil
+}
@@ -0,0 +1,67 @@
+package listfeatured_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/featured"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+	"github.com/sergeii/swat4master/internal/core/usecases/listfeatured"
+)
+
+type MockFeaturedRepository struct {
+	mock.Mock
+	repositories.FeaturedRepository
+}
+
+func (m *MockFeaturedRepository) List(ctx context.Context) ([]featured.Featured, error) {
+	args := m.Called(ctx)
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
+	return args.Get(0).([]featured.Featured), nil //nolint: forcetypeassert
+}
+
+func TestListFeaturedUseCase_OK(t *testing.T) {
+	ctx := context.TODO()
+	clock := clockwork.NewFakeClock()
+	now := clock.Now()
+
+	active1 := featured.New(addr.MustNewFromDotted("1.1.1.1", 10480), 10, featured.NC, featured.NC)
+	active2 := featured.New(addr.MustNewFromDotted("2.2.2.2", 10480), 5, now.Add(-time.Hour), now.Add(time.Hour))
+	active3 := featured.New(addr.MustNewFromDotted("3.3.3.3", 10480), 5, featured.NC, featured.NC)
+	upcoming := featured.New(addr.MustNewFromDotted("4.4.4.4", 10480), 1, now.Add(time.Hour), featured.NC)
+	expired := featured.New(addr.MustNewFromDotted("5.5.5.5", 10480), 1, featured.NC, now.Add(-time.Hour))
+
+	repo := new(MockFeaturedRepository)
+	repo.On("List", ctx).Return([]featured.Featured{active1, upcoming, active3, expired, active2}, nil)
+
+	uc := listfeatured.New(repo, clock)
+
+	items, err := uc.Execute(ctx, listfeatured.NewRequest(true))
+	require.NoError(t, err)
+	assert.Equal(t, []featured.Featured{active2, active3, active1}, items)
+
+	items, err = uc.Execute(ctx, listfeatured.NewRequest(false))
+	require.NoError(t, err)
+	assert.Equal(t, []featured.Featured{upcoming, expired, active2, active3, active1}, items)
+}
+
+func TestListFeaturedUseCase_RepoError(t *testing.T) {
+	ctx := context.TODO()
+
+	repo := new(MockFeaturedRepository)
+	repo.On("List", ctx).Return(nil, errors.New("error"))
+
+	uc := listfeatured.New(repo, clockwork.NewFakeClock())
+	_, err := uc.Execute(ctx, listfeatured.NewRequest(true))
+	require.ErrorIs(t, err, listfeatured.ErrUnableToObtainFeatured)
+}
@@ -0,0 +1,49 @@
+package listjobruns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sergeii/swat4master/internal/core/entities/jobrun"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+)
+
+var (
+	ErrJobNotFound           = errors.New("job has never run")
+	ErrUnableToObtainJobRuns = errors.New("unable to obtain job runs from repository")
+)
+
+type UseCase struct {
+	jobRunRepo repositories.JobRunRepository
+}
+
+func New(jobRunRepo repositories.JobRunRepository) UseCase {
+	return UseCase{
+		jobRunRepo: jobRunRepo,
+	}
+}
+
+type Request struct {
+	job   string
+	limit int
+}
+
+func NewRequest(job string, limit int) Request {
+	return Request{
+		job:   job,
+		limit: limit,
+	}
+}
+
+// Execute returns the latest runs of the job, the latest run first
+func (uc UseCase) Execute(ctx context.Context, req Request) ([]jobrun.Run, error) {
+	runs, err := uc.jobRunRepo.List(ctx, req.job, req.limit)
+	if err != nil {
+		return nil, fmt.Errorf("listjobruns: repo: %w", ErrUnableToObtainJobRuns)
+	}
+	if len(runs) == 0 {
+		return nil, ErrJobNotFound
+	}
+	return runs, nil
+}
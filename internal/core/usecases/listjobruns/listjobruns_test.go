@@ -0,0 +1,62 @@
+package listjobruns_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/jobrun"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+	"github.com/sergeii/swat4master/internal/core/usecases/listjobruns"
+)
+
+type MockJobRunRepository struct {
+	mock.Mock
+	repositories.JobRunRepository
+}
+
+func (m *MockJobRunRepository) List(ctx context.Context, job string, limit int) ([]jobrun.Run, error) {
+	args := m.Called(ctx, job, limit)
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
+	return args.Get(0).([]jobrun.Run), nil //nolint: forcetypeassert
+}
+
+func TestListJobRunsUseCase_OK(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Now()
+
+	run1 := jobrun.New("refresher", now, now, now, nil)
+	run2 := jobrun.New("refresher", now.Add(time.Second), now.Add(time.Second), now.Add(time.Second), nil)
+
+	repo := new(MockJobRunRepository)
+	repo.On("List", ctx, "refresher", 10).Return([]jobrun.Run{run2, run1}, nil)
+
+	uc := listjobruns.New(repo)
+	runs, err := uc.Execute(ctx, listjobruns.NewRequest("refresher", 10))
+	require.NoError(t, err)
+	assert.Equal(t, []jobrun.Run{run2, run1}, runs)
+	repo.AssertExpectations(t)
+}
+
+func TestListJobRunsUseCase_Errors(t *testing.T) {
+	ctx := context.TODO()
+
+	repo := new(MockJobRunRepository)
+	repo.On("List", ctx, "unknown", 10).Return([]jobrun.Run{}, nil)
+	repo.On("List", ctx, "broken", 10).Return(nil, errors.New("error"))
+
+	uc := listjobruns.New(repo)
+
+	_, err := uc.Execute(ctx, listjobruns.NewRequest("unknown", 10))
+	require.ErrorIs(t, err, listjobruns.ErrJobNotFound)
+
+	_, err = uc.Execute(ctx, listjobruns.NewRequest("broken", 10))
+	require.ErrorIs(t, err, listjobruns.ErrUnableToObtainJobRuns)
+}
@@ -0,0 +1,44 @@
+package listjobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sergeii/swat4master/internal/core/entities/jobrun"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+)
+
+var ErrUnableToObtainJobs = errors.New("unable to obtain jobs from repository")
+
+type UseCase struct {
+	jobRunRepo repositories.JobRunRepository
+}
+
+func New(jobRunRepo repositories.JobRunRepository) UseCase {
+	return UseCase{
+		jobRunRepo: jobRunRepo,
+	}
+}
+
+// Execute returns the last run of every job that has ever run, ordered by the job name
+func (uc UseCase) Execute(ctx context.Context) ([]jobrun.Run, error) {
+	jobs, err := uc.jobRunRepo.Jobs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listjobs: repo: %w", ErrUnableToObtainJobs)
+	}
+
+	result := make([]jobrun.Run, 0, len(jobs))
+	for _, job := range jobs {
+		last, lastErr := uc.jobRunRepo.Last(ctx, job)
+		if lastErr != nil {
+			if errors.Is(lastErr, repositories.ErrJobRunNotFound) {
+				continue
+			}
+			return nil, fmt.Errorf("listjobs: repo: %w", ErrUnableToObtainJobs)
+		}
+		result = append(result, last)
+	}
+
+	return result, nil
+}
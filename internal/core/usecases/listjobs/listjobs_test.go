@@ -0,0 +1,72 @@
+package listjobs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/jobrun"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+	"github.com/sergeii/swat4master/internal/core/usecases/listjobs"
+)
+
+type MockJobRunRepository struct {
+	mock.Mock
+	repositories.JobRunRepository
+}
+
+func (m *MockJobRunRepository) Jobs(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
+	return args.Get(0).([]string), nil //nolint: forcetypeassert
+}
+
+func (m *MockJobRunRepository) Last(ctx context.Context, job string) (jobrun.Run, error) {
+	args := m.Called(ctx, job)
+	return args.Get(0).(jobrun.Run), args.Error(1) //nolint: forcetypeassert
+}
+
+func TestListJobsUseCase_OK(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Now()
+
+	cleaner := jobrun.New("cleaner", now, now, now, nil)
+	reviver := jobrun.New("reviver", now, now, now, errors.New("error"))
+
+	repo := new(MockJobRunRepository)
+	repo.On("Jobs", ctx).Return([]string{"cleaner", "refresher", "reviver"}, nil)
+	repo.On("Last", ctx, "cleaner").Return(cleaner, nil)
+	repo.On("Last", ctx, "refresher").Return(jobrun.Blank, repositories.ErrJobRunNotFound)
+	repo.On("Last", ctx, "reviver").Return(reviver, nil)
+
+	uc := listjobs.New(repo)
+	runs, err := uc.Execute(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []jobrun.Run{cleaner, reviver}, runs)
+}
+
+func TestListJobsUseCase_RepoError(t *testing.T) {
+	ctx := context.TODO()
+
+	repo := new(MockJobRunRepository)
+	repo.On("Jobs", ctx).Return(nil, errors.New("error"))
+
+	uc := listjobs.New(repo)
+	_, err := uc.Execute(ctx)
+	require.ErrorIs(t, err, listjobs.ErrUnableToObtainJobs)
+
+	repo = new(MockJobRunRepository)
+	repo.On("Jobs", ctx).Return([]string{"cleaner"}, nil)
+	repo.On("Last", ctx, "cleaner").Return(jobrun.Blank, errors.New("error"))
+
+	uc = listjobs.New(repo)
+	_, err = uc.Execute(ctx)
+	require.ErrorIs(t, err, listjobs.ErrUnableToObtainJobs)
+}
@@ -0,0 +1,44 @@
+package listmatches
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/match"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+)
+
+var ErrUnableToObtainMatches = errors.New("unable to obtain matches from repository")
+
+type UseCase struct {
+	matchRepo repositories.MatchRepository
+}
+
+func New(matchRepo repositories.MatchRepository) UseCase {
+	return UseCase{
+		matchRepo: matchRepo,
+	}
+}
+
+type Request struct {
+	addr  addr.Addr
+	limit int
+}
+
+func NewRequest(svrAddr addr.Addr, limit int) Request {
+	return Request{
+		addr:  svrAddr,
+		limit: limit,
+	}
+}
+
+// Execute returns the latest matches played on the server, the latest match first
+func (uc UseCase) Execute(ctx context.Context, req Request) ([]match.Match, error) {
+	matches, err := uc.matchRepo.ListByServer(ctx, req.addr, req.limit)
+	if err != nil {
+		return nil, fmt.Errorf("listmatches: repo: %w", ErrUnableToObtainMatches)
+	}
+	return matches, nil
+}
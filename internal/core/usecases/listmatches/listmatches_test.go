@@ -0,0 +1,56 @@
+package listmatches_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/match"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+	"github.com/sergeii/swat4master/internal/core/usecases/listmatches"
+)
+
+type MockMatchRepository struct {
+	mock.Mock
+	repositories.MatchRepository
+}
+
+func (m *MockMatchRepository) ListByServer(ctx context.Context, svrAddr addr.Addr, limit int) ([]match.Match, error) {
+	args := m.Called(ctx, svrAddr, limit)
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
+	return args.Get(0).([]match.Match), nil //nolint: forcetypeassert
+}
+
+func TestListMatchesUseCase_OK(t *testing.T) {
+	ctx := context.TODO()
+	svrAddr := addr.MustNewFromDotted("1.1.1.1", 10480)
+	items := []match.Match{{ID: "bar", Addr: svrAddr}, {ID: "foo", Addr: svrAddr}}
+
+	repo := new(MockMatchRepository)
+	repo.On("ListByServer", ctx, svrAddr, 10).Return(items, nil)
+
+	uc := listmatches.New(repo)
+	got, err := uc.Execute(ctx, listmatches.NewRequest(svrAddr, 10))
+	require.NoError(t, err)
+	assert.Equal(t, items, got)
+	repo.AssertExpectations(t)
+}
+
+func TestListMatchesUseCase_RepoError(t *testing.T) {
+	ctx := context.TODO()
+	svrAddr := addr.MustNewFromDotted("1.1.1.1", 10480)
+
+	repo := new(MockMatchRepository)
+	repo.On("ListByServer", ctx, svrAddr, 10).Return(nil, errors.New("error"))
+
+	uc := listmatches.New(repo)
+	_, err := uc.Execute(ctx, listmatches.NewRequest(svrAddr, 10))
+	require.ErrorIs(t, err, listmatches.ErrUnableToObtainMatches)
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/jonboulle/clockwork"
 
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
 	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
 	"github.com/sergeii/swat4master/internal/core/entities/filterset"
 	"github.com/sergeii/swat4master/internal/core/entities/server"
@@ -36,6 +37,8 @@ type Request struct {
 	query           query.Query
 	recentness      time.Duration
 	discoveryStatus ds.DiscoveryStatus
+	excludeStatus   ds.DiscoveryStatus
+	onlyTrusted     bool
 }
 
 func NewRequest(
@@ -50,23 +53,77 @@ func NewRequest(
 	}
 }
 
+// WithoutStatus excludes the servers having any of the given discovery status bits
+func (req Request) WithoutStatus(status ds.DiscoveryStatus) Request {
+	req.excludeStatus |= status
+	return req
+}
+
+// OnlyTrusted excludes the servers never reached by a probe,
+// as well as the servers whose heartbeats keep disagreeing with their query responses
+func (req Request) OnlyTrusted() Request {
+	req.onlyTrusted = true
+	return req
+}
+
 func (uc UseCase) Execute(ctx context.Context, req Request) ([]server.Server, error) {
 	fs := filterset.NewServerFilterSet().
 		ActiveAfter(uc.clock.Now().Add(-req.recentness)).
-		WithStatus(req.discoveryStatus)
+		WithStatus(req.discoveryStatus).
+		NoStatus(req.excludeStatus)
 
 	recent, err := uc.serverRepo.Filter(ctx, fs)
 	if err != nil {
 		return nil, fmt.Errorf("listservers: repo: %w", ErrUnableToObtainServers)
 	}
 
-	filtered := make([]server.Server, 0, len(recent))
+	matched := make([]server.Server, 0, len(recent))
 	for _, svr := range recent {
-		info := svr.Info
-		if req.query.Match(&info) {
-			filtered = append(filtered, svr)
+		if req.onlyTrusted && !svr.Listing.IsTrusted() {
+			continue
+		}
+		fields := svr.QueryFields()
+		if req.query.Match(&fields) {
+			matched = append(matched, svr)
+		}
+	}
+
+	return hideDuplicates(matched), nil
+}
+
+// hideDuplicates drops the duplicates listed under their primary servers.
+// A duplicate whose primary has been filtered out is listed in its place,
+// unless a better ranked duplicate of the same primary is there to take it.
+func hideDuplicates(servers []server.Server) []server.Server {
+	primaries := make(map[addr.Addr]struct{}, len(servers))
+	for _, svr := range servers {
+		if !svr.IsDuplicate() {
+			primaries[svr.Addr] = struct{}{}
+		}
+	}
+
+	standIns := make(map[addr.Addr]server.Server)
+	for _, svr := range servers {
+		if !svr.IsDuplicate() {
+			continue
+		}
+		if _, ok := primaries[svr.DuplicateOf]; ok {
+			continue
+		}
+		if other, ok := standIns[svr.DuplicateOf]; !ok || server.CompareDuplicates(svr, other) < 0 {
+			standIns[svr.DuplicateOf] = svr
+		}
+	}
+
+	listed := make([]server.Server, 0, len(primaries)+len(standIns))
+	for _, svr := range servers {
+		if svr.IsDuplicate() {
+			if standIn, ok := standIns[svr.DuplicateOf]; !ok || standIn.Addr != svr.Addr {
+				continue
+			}
 		}
+		listed = append(listed, svr)
 	}
 
-	return filtered, nil
+	return listed
 }
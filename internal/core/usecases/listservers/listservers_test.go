@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
 	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
 	"github.com/sergeii/swat4master/internal/core/entities/filterset"
 	"github.com/sergeii/swat4master/internal/core/entities/server"
@@ -104,6 +105,26 @@ func TestListServersUseCase_FilterParams(t *testing.T) {
 	}
 }
 
+func TestListServersUseCase_FilterParams_WithoutStatus(t *testing.T) {
+	ctx := context.TODO()
+	clock := clockwork.NewFakeClock()
+
+	mockRepo := new(MockServerRepository)
+	mockRepo.On("Filter", ctx, mock.Anything).Return([]server.Server{}, nil)
+
+	uc := listservers.New(mockRepo, clock)
+	ucRequest := listservers.NewRequest(query.Blank, time.Hour, ds.Details).WithoutStatus(ds.DetailsRetry)
+
+	_, err := uc.Execute(ctx, ucRequest)
+	require.NoError(t, err)
+
+	mockRepo.AssertCalled(t, "Filter", ctx, mock.MatchedBy(func(fs filterset.ServerFilterSet) bool {
+		withStatus, withStatusIsSet := fs.GetWithStatus()
+		noStatus, noStatusIsSet := fs.GetNoStatus()
+		return withStatusIsSet && withStatus == ds.Details && noStatusIsSet && noStatus == ds.DetailsRetry
+	}))
+}
+
 func TestListServersUseCase_FilterByQuery(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -485,6 +506,106 @@ func TestListServersUseCase_FilterByQuery(t *testing.T) {
 	}
 }
 
+func TestListServersUseCase_HidesDuplicates(t *testing.T) {
+	ctx := context.TODO()
+	clock := clockwork.NewFakeClock()
+
+	primary := serverfactory.Build(
+		serverfactory.WithAddress("1.1.1.1", 10480),
+		serverfactory.WithInfo(map[string]string{"hostname": "Primary Swat4 Server"}),
+	)
+	duplicate := serverfactory.Build(
+		serverfactory.WithAddress("2.2.2.2", 10480),
+		serverfactory.WithInfo(map[string]string{"hostname": "Duplicate Swat4 Server"}),
+	)
+	duplicate.DuplicateOf = primary.Addr
+	primary.Duplicates = []addr.Addr{duplicate.Addr}
+
+	mockRepo := new(MockServerRepository)
+	mockRepo.On("Filter", ctx, mock.Anything).Return([]server.Server{primary, duplicate}, nil)
+
+	uc := listservers.New(mockRepo, clock)
+	ucRequest := listservers.NewRequest(query.Blank, time.Hour, ds.Info)
+
+	result, err := uc.Execute(ctx, ucRequest)
+	require.NoError(t, err)
+
+	require.Len(t, result, 1)
+	assert.Equal(t, primary.Addr, result[0].Addr)
+}
+
+func TestListServersUseCase_ListsDuplicateInPlaceOfFilteredPrimary(t *testing.T) {
+	ctx := context.TODO()
+	clock := clockwork.NewFakeClock()
+
+	primary := serverfactory.Build(
+		serverfactory.WithAddress("1.1.1.1", 10480),
+		serverfactory.WithInfo(map[string]string{"hostname": "Primary Swat4 Server", "password": "1"}),
+	)
+	earlier := serverfactory.Build(
+		serverfactory.WithAddress("2.2.2.2", 10480),
+		serverfactory.WithInfo(map[string]string{"hostname": "Earlier Duplicate Swat4 Server"}),
+		serverfactory.WithAvailability(server.Availability{Since: clock.Now().Add(-time.Hour)}),
+	)
+	later := serverfactory.Build(
+		serverfactory.WithAddress("3.3.3.3", 10480),
+		serverfactory.WithInfo(map[string]string{"hostname": "Later Duplicate Swat4 Server"}),
+		serverfactory.WithAvailability(server.Availability{Since: clock.Now()}),
+	)
+	earlier.DuplicateOf = primary.Addr
+	later.DuplicateOf = primary.Addr
+	primary.Duplicates = []addr.Addr{earlier.Addr, later.Addr}
+
+	mockRepo := new(MockServerRepository)
+	mockRepo.On("Filter", ctx, mock.Anything).Return([]server.Server{later, primary, earlier}, nil)
+
+	uc := listservers.New(mockRepo, clock)
+
+	// the primary is listed when it matches the query
+	result, err := uc.Execute(ctx, listservers.NewRequest(query.Blank, time.Hour, ds.Info))
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, primary.Addr, result[0].Addr)
+
+	// the best ranked duplicate takes the place of the primary filtered out by the query
+	noPassword := query.MustNew([]filter.Filter{filter.MustNew("password", "!=", 1)})
+	result, err = uc.Execute(ctx, listservers.NewRequest(noPassword, time.Hour, ds.Info))
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, earlier.Addr, result[0].Addr)
+}
+
+func TestListServersUseCase_OnlyTrusted(t *testing.T) {
+	ctx := context.TODO()
+	clock := clockwork.NewFakeClock()
+
+	verified := serverfactory.Build(
+		serverfactory.WithAddress("1.1.1.1", 10480),
+		serverfactory.WithListing(server.Listing{VerifiedAt: clock.Now()}),
+	)
+	unverified := serverfactory.Build(
+		serverfactory.WithAddress("2.2.2.2", 10480),
+	)
+	mismatched := serverfactory.Build(
+		serverfactory.WithAddress("3.3.3.3", 10480),
+		serverfactory.WithListing(server.Listing{VerifiedAt: clock.Now(), Mismatches: server.MismatchThreshold}),
+	)
+
+	mockRepo := new(MockServerRepository)
+	mockRepo.On("Filter", ctx, mock.Anything).Return([]server.Server{verified, unverified, mismatched}, nil)
+
+	uc := listservers.New(mockRepo, clock)
+
+	result, err := uc.Execute(ctx, listservers.NewRequest(query.Blank, time.Hour, ds.Master))
+	require.NoError(t, err)
+	assert.Len(t, result, 3)
+
+	result, err = uc.Execute(ctx, listservers.NewRequest(query.Blank, time.Hour, ds.Master).OnlyTrusted())
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, verified.Addr, result[0].Addr)
+}
+
 func TestListServersUseCase_Errors(t *testing.T) {
 	tests := []struct {
 		name    string
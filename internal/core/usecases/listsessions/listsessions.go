@@ -0,0 +1,61 @@
+package listsessions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/session"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+)
+
+var ErrUnableToObtainSessions = errors.New("unable to obtain player sessions from repository")
+
+type UseCase struct {
+	sessionRepo repositories.SessionRepository
+}
+
+func New(sessionRepo repositories.SessionRepository) UseCase {
+	return UseCase{
+		sessionRepo: sessionRepo,
+	}
+}
+
+// Request selects the sessions either of a server or of a player
+type Request struct {
+	addr   addr.Addr
+	player string
+	limit  int
+}
+
+func NewServerRequest(svrAddr addr.Addr, limit int) Request {
+	return Request{
+		addr:  svrAddr,
+		limit: limit,
+	}
+}
+
+func NewPlayerRequest(player string, limit int) Request {
+	return Request{
+		player: player,
+		limit:  limit,
+	}
+}
+
+// Execute returns the latest sessions of the server or the player, the latest session first
+func (uc UseCase) Execute(ctx context.Context, req Request) ([]session.Session, error) {
+	var sessions []session.Session
+	var err error
+
+	if req.player != "" {
+		sessions, err = uc.sessionRepo.ListByPlayer(ctx, req.player, req.limit)
+	} else {
+		sessions, err = uc.sessionRepo.ListByServer(ctx, req.addr, req.limit)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("listsessions: repo: %w", ErrUnableToObtainSessions)
+	}
+
+	return sessions, nil
+}
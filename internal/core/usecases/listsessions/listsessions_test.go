@@ -0,0 +1,85 @@
+package listsessions_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/details"
+	"github.com/sergeii/swat4master/internal/core/entities/session"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+	"github.com/sergeii/swat4master/internal/core/usecases/listsessions"
+)
+
+type MockSessionRepository struct {
+	mock.Mock
+	repositories.SessionRepository
+}
+
+func (m *MockSessionRepository) ListByServer(
+	ctx context.Context,
+	svrAddr addr.Addr,
+	limit int,
+) ([]session.Session, error) {
+	args := m.Called(ctx, svrAddr, limit)
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
+	return args.Get(0).([]session.Session), nil //nolint: forcetypeassert
+}
+
+func (m *MockSessionRepository) ListByPlayer(ctx context.Context, name string, limit int) ([]session.Session, error) {
+	args := m.Called(ctx, name, limit)
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
+	return args.Get(0).([]session.Session), nil //nolint: forcetypeassert
+}
+
+func TestListSessionsUseCase_OK(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Now()
+	svrAddr := addr.MustNewFromDotted("1.1.1.1", 10480)
+
+	sess1 := session.New(svrAddr, details.Player{Name: "Serge"}, now)
+	sess2 := session.New(svrAddr, details.Player{Name: "Mosquito"}, now.Add(time.Minute))
+
+	repo := new(MockSessionRepository)
+	repo.On("ListByServer", ctx, svrAddr, 10).Return([]session.Session{sess2, sess1}, nil)
+	repo.On("ListByPlayer", ctx, "Serge", 5).Return([]session.Session{sess1}, nil)
+
+	uc := listsessions.New(repo)
+
+	sessions, err := uc.Execute(ctx, listsessions.NewServerRequest(svrAddr, 10))
+	require.NoError(t, err)
+	assert.Equal(t, []session.Session{sess2, sess1}, sessions)
+
+	sessions, err = uc.Execute(ctx, listsessions.NewPlayerRequest("Serge", 5))
+	require.NoError(t, err)
+	assert.Equal(t, []session.Session{sess1}, sessions)
+
+	repo.AssertExpectations(t)
+}
+
+func TestListSessionsUseCase_RepoError(t *testing.T) {
+	ctx := context.TODO()
+	svrAddr := addr.MustNewFromDotted("1.1.1.1", 10480)
+
+	repo := new(MockSessionRepository)
+	repo.On("ListByServer", ctx, svrAddr, 10).Return(nil, errors.New("error"))
+	repo.On("ListByPlayer", ctx, "Serge", 10).Return(nil, errors.New("error"))
+
+	uc := listsessions.New(repo)
+
+	_, err := uc.Execute(ctx, listsessions.NewServerRequest(svrAddr, 10))
+	require.ErrorIs(t, err, listsessions.ErrUnableToObtainSessions)
+
+	_, err = uc.Execute(ctx, listsessions.NewPlayerRequest("Serge", 10))
+	require.ErrorIs(t, err, listsessions.ErrUnableToObtainSessions)
+}
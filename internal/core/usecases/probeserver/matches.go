@@ -0,0 +1,36 @@
+package probeserver
+
+import (
+	"context"
+
+	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/internal/core/entities/match"
+	"github.com/sergeii/swat4master/internal/core/entities/server"
+)
+
+// trackMatches records the result of the round that has been over since the previous snapshot of the server.
+// Both snapshots are required to have details, so that the rounds are never detected
+// against the details that are either missing or have gone stale while the server was unavailable.
+// Failing to record the match does not fail the probe.
+func (uc UseCase) trackMatches(ctx context.Context, prev, curr server.Server) {
+	if !prev.HasDiscoveryStatus(ds.Details) || !curr.HasDiscoveryStatus(ds.Details) {
+		return
+	}
+
+	m, ok := match.Detect(curr.Addr, prev.Details, curr.Details, uc.clock.Now())
+	if !ok {
+		return
+	}
+
+	if err := uc.matchRepo.Add(ctx, m); err != nil {
+		uc.metrics.DiscoveryMatchErrors.Inc()
+		uc.logger.Error().Err(err).Stringer("server", curr).Msg("Unable to record match result")
+		return
+	}
+
+	uc.metrics.DiscoveryMatches.Inc()
+	uc.logger.Debug().
+		Stringer("server", curr).Str("map", m.Info.MapName).Str("winner", string(m.Winner)).
+		Int("players", len(m.Players)).
+		Msg("Recorded match result")
+}
@@ -36,26 +36,32 @@ func NewRequest(prb probe.Probe, prober probers.Prober, probeTimeout time.Durati
 }
 
 type UseCase struct {
-	serverRepo repositories.ServerRepository
-	probeRepo  repositories.ProbeRepository
-	metrics    *metrics.Collector
-	clock      clockwork.Clock
-	logger     *zerolog.Logger
+	serverRepo  repositories.ServerRepository
+	probeRepo   repositories.ProbeRepository
+	sessionRepo repositories.SessionRepository
+	matchRepo   repositories.MatchRepository
+	metrics     *metrics.Collector
+	clock       clockwork.Clock
+	logger      *zerolog.Logger
 }
 
 func New(
 	serverRepo repositories.ServerRepository,
 	probeRepo repositories.ProbeRepository,
+	sessionRepo repositories.SessionRepository,
+	matchRepo repositories.MatchRepository,
 	metrics *metrics.Collector,
 	clock clockwork.Clock,
 	logger *zerolog.Logger,
 ) UseCase {
 	return UseCase{
-		serverRepo: serverRepo,
-		probeRepo:  probeRepo,
-		metrics:    metrics,
-		clock:      clock,
-		logger:     logger,
+		serverRepo:  serverRepo,
+		probeRepo:   probeRepo,
+		sessionRepo: sessionRepo,
+		matchRepo:   matchRepo,
+		metrics:     metrics,
+		clock:       clock,
+		logger:      logger,
 	}
 }
 
@@ -69,7 +75,7 @@ func (uc UseCase) Execute(ctx context.Context, req Request) error {
 		return err
 	}
 
-	result, probeErr := req.Prober.Probe(ctx, svr.Addr, req.Probe.Port, req.ProbeTimeout)
+	result, probeErr := req.Prober.Probe(ctx, svr, req.Probe.Port, req.ProbeTimeout)
 
 	if probeErr != nil {
 		uc.logger.Warn().
@@ -79,12 +85,17 @@ func (uc UseCase) Execute(ctx context.Context, req Request) error {
 		return uc.retry(ctx, req.Prober, req.Probe, svr)
 	}
 
+	// the probe result is compared against the server as it was before the update,
+	// which is the fresher stored copy in case the server has been updated concurrently
+	prev := svr
 	svr = req.Prober.HandleSuccess(result, svr)
 
-	if _, updateErr := uc.serverRepo.Update(ctx, svr, func(s *server.Server) bool {
+	updated, updateErr := uc.serverRepo.Update(ctx, svr, func(s *server.Server) bool {
+		prev = *s
 		*s = req.Prober.HandleSuccess(result, *s)
 		return true
-	}); updateErr != nil {
+	})
+	if updateErr != nil {
 		uc.logger.Error().
 			Err(updateErr).
 			Stringer("addr", req.Probe.Addr).Int("port", req.Probe.Port).Stringer("goal", req.Probe.Goal).
@@ -92,6 +103,9 @@ func (uc UseCase) Execute(ctx context.Context, req Request) error {
 		return updateErr
 	}
 
+	uc.trackSessions(ctx, updated)
+	uc.trackMatches(ctx, prev, updated)
+
 	uc.logger.Debug().
 		Stringer("server", svr).Int("port", req.Probe.Port).
 		Stringer("goal", req.Probe.Goal).Int("retries", req.Probe.Retries).
@@ -167,10 +181,11 @@ func (uc UseCase) fail(
 ) error {
 	svr = prober.HandleFailure(svr)
 
-	if _, updateErr := uc.serverRepo.Update(ctx, svr, func(s *server.Server) bool {
+	updated, updateErr := uc.serverRepo.Update(ctx, svr, func(s *server.Server) bool {
 		*s = prober.HandleFailure(*s)
 		return true
-	}); updateErr != nil {
+	})
+	if updateErr != nil {
 		uc.logger.Error().
 			Err(updateErr).
 			Stringer("server", svr).Int("port", prb.Port).Stringer("goal", prb.Goal).
@@ -178,5 +193,7 @@ func (uc UseCase) fail(
 		return updateErr
 	}
 
+	uc.trackSessions(ctx, updated)
+
 	return nil
 }
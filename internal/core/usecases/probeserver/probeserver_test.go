@@ -14,8 +14,12 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/details"
+	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/internal/core/entities/match"
 	"github.com/sergeii/swat4master/internal/core/entities/probe"
 	"github.com/sergeii/swat4master/internal/core/entities/server"
+	"github.com/sergeii/swat4master/internal/core/entities/session"
 	"github.com/sergeii/swat4master/internal/core/repositories"
 	"github.com/sergeii/swat4master/internal/core/usecases/probeserver"
 	"github.com/sergeii/swat4master/internal/metrics"
@@ -57,6 +61,31 @@ func (m *MockProbeRepository) AddBetween(
 	return args.Error(0)
 }
 
+type MockSessionRepository struct {
+	mock.Mock
+	repositories.SessionRepository
+}
+
+func (m *MockSessionRepository) Active(ctx context.Context, svrAddr addr.Addr) ([]session.Session, error) {
+	args := m.Called(ctx, svrAddr)
+	return args.Get(0).([]session.Session), args.Error(1) //nolint: forcetypeassert
+}
+
+func (m *MockSessionRepository) Save(ctx context.Context, sessions ...session.Session) error {
+	args := m.Called(ctx, sessions)
+	return args.Error(0)
+}
+
+type MockMatchRepository struct {
+	mock.Mock
+	repositories.MatchRepository
+}
+
+func (m *MockMatchRepository) Add(ctx context.Context, item match.Match) error {
+	args := m.Called(ctx, item)
+	return args.Error(0)
+}
+
 type MockProber struct {
 	mock.Mock
 	probers.Prober
@@ -68,11 +97,11 @@ type MockProberProbeResult struct {
 
 func (p *MockProber) Probe(
 	ctx context.Context,
-	addr addr.Addr,
+	svr server.Server,
 	queryPort int,
 	timeout time.Duration,
 ) (any, error) {
-	args := p.Called(ctx, addr, queryPort, timeout)
+	args := p.Called(ctx, svr.Addr, queryPort, timeout)
 	return args.Get(0).(MockProberProbeResult), args.Error(1) //nolint: forcetypeassert
 }
 
@@ -111,7 +140,11 @@ func TestProbeServerUseCase_Success(t *testing.T) {
 	proberMock.On("Probe", ctx, svr.Addr, svr.QueryPort, mock.Anything).Return(probeResult, nil)
 	proberMock.On("HandleSuccess", probeResult, svr).Return(svr)
 
-	uc := probeserver.New(serverRepo, probeRepo, collector, clock, &logger)
+	sessionRepo := new(MockSessionRepository)
+	matchRepo := new(MockMatchRepository)
+	sessionRepo.On("Active", ctx, svr.Addr).Return([]session.Session{}, nil)
+
+	uc := probeserver.New(serverRepo, probeRepo, sessionRepo, matchRepo, collector, clock, &logger)
 
 	ucReq := probeserver.NewRequest(prb, proberMock, time.Millisecond*12345)
 	err := uc.Execute(ctx, ucReq)
@@ -123,6 +156,8 @@ func TestProbeServerUseCase_Success(t *testing.T) {
 	serverRepo.AssertExpectations(t)
 	proberMock.AssertExpectations(t)
 	probeRepo.AssertExpectations(t)
+	sessionRepo.AssertExpectations(t)
+	sessionRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
 }
 
 func TestProbeServerUseCase_RetryOnFailure(t *testing.T) {
@@ -174,7 +209,10 @@ func TestProbeServerUseCase_RetryOnFailure(t *testing.T) {
 			proberMock.On("Probe", ctx, svr.Addr, svr.QueryPort, mock.Anything).Return(MockProberProbeResult{}, probeError)
 			proberMock.On("HandleRetry", svr).Return(svr)
 
-			uc := probeserver.New(serverRepo, probeRepo, collector, clock, &logger)
+			sessionRepo := new(MockSessionRepository)
+			matchRepo := new(MockMatchRepository)
+
+			uc := probeserver.New(serverRepo, probeRepo, sessionRepo, matchRepo, collector, clock, &logger)
 
 			ucReq := probeserver.NewRequest(prb, proberMock, time.Millisecond*12345)
 			err := uc.Execute(ctx, ucReq)
@@ -230,7 +268,11 @@ func TestProbeServerUseCase_FailOnOutOfRetries(t *testing.T) {
 	proberMock.On("Probe", ctx, svr.Addr, svr.QueryPort, mock.Anything).Return(MockProberProbeResult{}, probeError)
 	proberMock.On("HandleFailure", svr).Return(svr)
 
-	uc := probeserver.New(serverRepo, probeRepo, collector, clock, &logger)
+	sessionRepo := new(MockSessionRepository)
+	matchRepo := new(MockMatchRepository)
+	sessionRepo.On("Active", ctx, svr.Addr).Return([]session.Session{}, nil)
+
+	uc := probeserver.New(serverRepo, probeRepo, sessionRepo, matchRepo, collector, clock, &logger)
 
 	ucReq := probeserver.NewRequest(prb, proberMock, time.Millisecond*12345)
 	err := uc.Execute(ctx, ucReq)
@@ -242,4 +284,219 @@ func TestProbeServerUseCase_FailOnOutOfRetries(t *testing.T) {
 	serverRepo.AssertExpectations(t)
 	proberMock.AssertExpectations(t)
 	probeRepo.AssertExpectations(t)
+	sessionRepo.AssertExpectations(t)
+}
+
+func TestProbeServerUseCase_TrackSessions(t *testing.T) {
+	ctx := context.TODO()
+	clock := clockwork.NewFakeClock()
+	logger := zerolog.Nop()
+	collector := metrics.New()
+
+	svr := serverfactory.Build(
+		serverfactory.WithAddress("1.1.1.1", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Details|ds.Info),
+		serverfactory.WithPlayers([]map[string]string{
+			{"player": "Serge", "score": "15", "kills": "3", "arrests": "1", "team": "1"},
+			{"player": "Mosquito", "score": "5", "team": "0"},
+			{"player": "Alice", "score": "0", "team": "0"},
+		}),
+	)
+	prb := probe.New(svr.Addr, svr.QueryPort, probe.GoalDetails, 3)
+	probeResult := MockProberProbeResult{Success: true}
+
+	startedAt := clock.Now().Add(-time.Hour)
+	serge := session.New(svr.Addr, details.Player{Name: "Serge", Score: 10, Team: details.TeamSwat}, startedAt)
+	mosquito := session.New(svr.Addr, details.Player{Name: "Mosquito", Score: 1}, startedAt)
+	bob := session.New(svr.Addr, details.Player{Name: "Bob", Score: 7, Kills: 2, Team: details.TeamSuspects}, startedAt)
+
+	serverRepo := new(MockServerRepository)
+	serverRepo.On("Get", ctx, svr.Addr).Return(svr, nil)
+	serverRepo.On("Update", ctx, svr, mock.Anything).Return(svr, nil)
+
+	probeRepo := new(MockProbeRepository)
+
+	proberMock := new(MockProber)
+	proberMock.On("Probe", ctx, svr.Addr, svr.QueryPort, mock.Anything).Return(probeResult, nil)
+	proberMock.On("HandleSuccess", probeResult, svr).Return(svr)
+
+	sessionRepo := new(MockSessionRepository)
+	matchRepo := new(MockMatchRepository)
+	sessionRepo.On("Active", ctx, svr.Addr).Return([]session.Session{serge, mosquito, bob}, nil)
+	sessionRepo.On("Save", ctx, mock.Anything).Return(nil)
+
+	uc := probeserver.New(serverRepo, probeRepo, sessionRepo, matchRepo, collector, clock, &logger)
+
+	err := uc.Execute(ctx, probeserver.NewRequest(prb, proberMock, time.Millisecond*12345))
+	require.NoError(t, err)
+
+	sessionRepo.AssertExpectations(t)
+	saved := sessionRepo.Calls[1].Arguments.Get(1).([]session.Session) //nolint: forcetypeassert
+	require.Len(t, saved, 4)
+
+	// Bob has left with the last known stats
+	assert.Equal(t, bob.ID, saved[0].ID)
+	assert.False(t, saved[0].IsActive())
+	assert.Equal(t, clock.Now(), saved[0].EndedAt)
+	assert.Equal(t, 7, saved[0].Score)
+	assert.Equal(t, 2, saved[0].Kills)
+
+	// Alice has joined
+	assert.NotEmpty(t, saved[1].ID)
+	assert.Equal(t, "Alice", saved[1].Name)
+	assert.True(t, saved[1].IsActive())
+	assert.Equal(t, clock.Now(), saved[1].StartedAt)
+
+	// Serge has changed the team, both Serge and Mosquito have their stats updated
+	assert.Equal(t, serge.ID, saved[2].ID)
+	assert.True(t, saved[2].IsActive())
+	assert.Equal(t, details.PlayerTeam(details.TeamSuspects), saved[2].Team)
+	assert.Equal(t, 15, saved[2].Score)
+	assert.Equal(t, 3, saved[2].Kills)
+	assert.Equal(t, 1, saved[2].Arrests)
+	assert.Equal(t, startedAt, saved[2].StartedAt)
+	assert.Equal(t, mosquito.ID, saved[3].ID)
+	assert.Equal(t, 5, saved[3].Score)
+
+	assert.InDelta(t, float64(1), testutil.ToFloat64(collector.DiscoverySessionEvents.WithLabelValues("join")), 1e-9)
+	assert.InDelta(t, float64(1), testutil.ToFloat64(collector.DiscoverySessionEvents.WithLabelValues("leave")), 1e-9)
+	assert.InDelta(t, float64(1), testutil.ToFloat64(collector.DiscoverySessionEvents.WithLabelValues("team")), 1e-9)
+}
+
+func TestProbeServerUseCase_FailEndsSessions(t *testing.T) {
+	ctx := context.TODO()
+	clock := clockwork.NewFakeClock()
+	logger := zerolog.Nop()
+	collector := metrics.New()
+
+	svr := serverfactory.Build(
+		serverfactory.WithAddress("1.1.1.1", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Details|ds.Info),
+		serverfactory.WithPlayers([]map[string]string{
+			{"player": "Serge", "score": "15", "team": "1"},
+			{"player": "Mosquito", "score": "5", "team": "0"},
+		}),
+	)
+	failed := svr
+	failed.ClearDiscoveryStatus(ds.Details | ds.Info)
+	failed.UpdateDiscoveryStatus(ds.NoDetails)
+	prb := probe.Probe{
+		Addr:       svr.Addr,
+		Port:       svr.QueryPort,
+		Goal:       probe.GoalDetails,
+		Retries:    3,
+		MaxRetries: 3,
+	}
+
+	startedAt := clock.Now().Add(-time.Hour)
+	serge := session.New(svr.Addr, details.Player{Name: "Serge", Score: 15, Team: details.TeamSuspects}, startedAt)
+	mosquito := session.New(svr.Addr, details.Player{Name: "Mosquito", Score: 5}, startedAt)
+
+	serverRepo := new(MockServerRepository)
+	serverRepo.On("Get", ctx, svr.Addr).Return(svr, nil)
+	serverRepo.On("Update", ctx, failed, mock.Anything).Return(failed, nil)
+
+	probeRepo := new(MockProbeRepository)
+
+	proberMock := new(MockProber)
+	proberMock.On("Probe", ctx, svr.Addr, svr.QueryPort, mock.Anything).
+		Return(MockProberProbeResult{}, errors.New("probing error"))
+	proberMock.On("HandleFailure", svr).Return(failed)
+
+	sessionRepo := new(MockSessionRepository)
+	matchRepo := new(MockMatchRepository)
+	sessionRepo.On("Active", ctx, svr.Addr).Return([]session.Session{serge, mosquito}, nil)
+	sessionRepo.On("Save", ctx, mock.Anything).Return(nil)
+
+	uc := probeserver.New(serverRepo, probeRepo, sessionRepo, matchRepo, collector, clock, &logger)
+
+	err := uc.Execute(ctx, probeserver.NewRequest(prb, proberMock, time.Millisecond*12345))
+	require.ErrorIs(t, err, probeserver.ErrOutOfRetries)
+
+	sessionRepo.AssertExpectations(t)
+	saved := sessionRepo.Calls[1].Arguments.Get(1).([]session.Session) //nolint: forcetypeassert
+	require.Len(t, saved, 2)
+
+	// the players of the server that has lost its details are considered to have left
+	assert.ElementsMatch(t, []string{serge.ID, mosquito.ID}, []string{saved[0].ID, saved[1].ID})
+	for _, sess := range saved {
+		assert.False(t, sess.IsActive())
+		assert.Equal(t, clock.Now(), sess.EndedAt)
+	}
+	assert.InDelta(t, float64(2), testutil.ToFloat64(collector.DiscoverySessionEvents.WithLabelValues("leave")), 1e-9)
+}
+
+func TestProbeServerUseCase_TrackMatches(t *testing.T) {
+	ctx := context.TODO()
+	clock := clockwork.NewFakeClock()
+	logger := zerolog.Nop()
+	collector := metrics.New()
+
+	info := map[string]string{
+		"hostname":      "Swat4 Server",
+		"hostport":      "10480",
+		"mapname":       "A-Bomb Nightclub",
+		"gamever":       "1.1",
+		"gamevariant":   "SWAT 4",
+		"gametype":      "VIP Escort",
+		"round":         "1",
+		"numrounds":     "5",
+		"swatscore":     "50",
+		"suspectsscore": "20",
+	}
+	players := []map[string]string{
+		{"player": "Serge", "score": "15", "kills": "3", "team": "0"},
+	}
+	prevSvr := serverfactory.Build(
+		serverfactory.WithAddress("1.1.1.1", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Details|ds.Info),
+		serverfactory.WithInfo(info),
+		serverfactory.WithPlayers(players),
+	)
+	info["round"] = "2"
+	info["swatscore"] = "0"
+	info["suspectsscore"] = "0"
+	info["swatwon"] = "1"
+	currSvr := serverfactory.Build(
+		serverfactory.WithAddress("1.1.1.1", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Details|ds.Info),
+		serverfactory.WithInfo(info),
+		serverfactory.WithPlayers(players),
+	)
+	prb := probe.New(prevSvr.Addr, prevSvr.QueryPort, probe.GoalDetails, 3)
+	probeResult := MockProberProbeResult{Success: true}
+
+	serverRepo := new(MockServerRepository)
+	serverRepo.On("Get", ctx, prevSvr.Addr).Return(prevSvr, nil)
+	serverRepo.On("Update", ctx, currSvr, mock.Anything).Return(currSvr, nil)
+
+	probeRepo := new(MockProbeRepository)
+
+	proberMock := new(MockProber)
+	proberMock.On("Probe", ctx, prevSvr.Addr, prevSvr.QueryPort, mock.Anything).Return(probeResult, nil)
+	proberMock.On("HandleSuccess", probeResult, prevSvr).Return(currSvr)
+
+	sessionRepo := new(MockSessionRepository)
+	sessionRepo.On("Active", ctx, prevSvr.Addr).Return([]session.Session{}, nil)
+	sessionRepo.On("Save", ctx, mock.Anything).Return(nil)
+
+	matchRepo := new(MockMatchRepository)
+	matchRepo.On("Add", ctx, mock.Anything).Return(nil)
+
+	uc := probeserver.New(serverRepo, probeRepo, sessionRepo, matchRepo, collector, clock, &logger)
+
+	err := uc.Execute(ctx, probeserver.NewRequest(prb, proberMock, time.Millisecond*12345))
+	require.NoError(t, err)
+
+	matchRepo.AssertExpectations(t)
+	recorded := matchRepo.Calls[0].Arguments.Get(1).(match.Match) //nolint: forcetypeassert
+	assert.NotEmpty(t, recorded.ID)
+	assert.Equal(t, prevSvr.Addr, recorded.Addr)
+	assert.Equal(t, 1, recorded.Info.Round)
+	assert.Equal(t, 50, recorded.Info.SwatScore)
+	assert.Equal(t, 20, recorded.Info.SuspectsScore)
+	assert.Equal(t, match.WinnerSwat, recorded.Winner)
+	assert.Equal(t, prevSvr.Details.Players, recorded.Players)
+	assert.Equal(t, clock.Now(), recorded.EndedAt)
+	assert.InDelta(t, float64(1), testutil.ToFloat64(collector.DiscoveryMatches), 1e-9)
 }
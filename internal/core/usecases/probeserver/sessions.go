@@ -0,0 +1,81 @@
+package probeserver
+
+import (
+	"context"
+
+	"github.com/sergeii/swat4master/internal/core/entities/details"
+	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/internal/core/entities/server"
+	"github.com/sergeii/swat4master/internal/core/entities/session"
+)
+
+// trackSessions brings the player sessions on the server in line with its current player list.
+// The previous player list is the one recorded with the active sessions on the server,
+// so that the players of a server that has lost its details are all considered to have left.
+// Failing to track the sessions does not fail the probe.
+func (uc UseCase) trackSessions(ctx context.Context, svr server.Server) {
+	var players []details.Player
+	if svr.HasDiscoveryStatus(ds.Details) {
+		players = svr.Details.Players
+	}
+
+	active, err := uc.sessionRepo.Active(ctx, svr.Addr)
+	if err != nil {
+		uc.metrics.DiscoverySessionErrors.Inc()
+		uc.logger.Error().Err(err).Stringer("server", svr).Msg("Unable to obtain active player sessions")
+		return
+	}
+
+	// Nothing to track for an empty server that has had no players before
+	if len(active) == 0 && len(players) == 0 {
+		return
+	}
+
+	activeByName := make(map[string]session.Session, len(active))
+	prev := make([]details.Player, 0, len(active))
+	for _, sess := range active {
+		activeByName[sess.Name] = sess
+		prev = append(prev, details.Player{
+			Name:    sess.Name,
+			Team:    sess.Team,
+			Score:   sess.Score,
+			Kills:   sess.Kills,
+			Arrests: sess.Arrests,
+		})
+	}
+
+	now := uc.clock.Now()
+	changes := make([]session.Session, 0, len(players)+len(active))
+	for _, event := range session.Diff(prev, players, now) {
+		uc.metrics.DiscoverySessionEvents.WithLabelValues(string(event.Kind)).Inc()
+		uc.logger.Debug().
+			Stringer("server", svr).Str("player", event.Player.Name).Str("event", string(event.Kind)).
+			Stringer("team", event.Player.Team).Stringer("prev", event.PrevTeam).
+			Msg("Observed player session event")
+		switch event.Kind { // nolint: exhaustive
+		case session.EventJoin:
+			changes = append(changes, session.New(svr.Addr, event.Player, event.At))
+		case session.EventLeave:
+			sess := activeByName[event.Player.Name]
+			sess.End(event.At)
+			changes = append(changes, sess)
+		}
+	}
+
+	// Keep the stats of the players that are still on the server up to date
+	for _, player := range players {
+		sess, ok := activeByName[player.Name]
+		if !ok {
+			continue
+		}
+		sess.Track(player)
+		changes = append(changes, sess)
+		// only the first of the players sharing the name is tracked
+		delete(activeByName, player.Name)
+	}
+
+	if err := uc.sessionRepo.Save(ctx, changes...); err != nil {
+		uc.metrics.DiscoverySessionErrors.Inc()
+		uc.logger.Error().Err(err).Stringer("server", svr).Msg("Unable to save player sessions")
+	}
+}
@@ -0,0 +1,86 @@
+package recordhistory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/internal/core/entities/filterset"
+	"github.com/sergeii/swat4master/internal/core/entities/history"
+	"github.com/sergeii/swat4master/internal/core/entities/server"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+)
+
+var (
+	ErrUnableToObtainServers = errors.New("unable to obtain servers from repository")
+	ErrUnableToRecordHistory = errors.New("unable to record server history")
+)
+
+type UseCase struct {
+	serverRepo  repositories.ServerRepository
+	historyRepo repositories.HistoryRepository
+}
+
+func New(
+	serverRepo repositories.ServerRepository,
+	historyRepo repositories.HistoryRepository,
+) UseCase {
+	return UseCase{
+		serverRepo:  serverRepo,
+		historyRepo: historyRepo,
+	}
+}
+
+type Response struct {
+	Online  int
+	Offline int
+}
+
+var NoResponse = Response{}
+
+// Execute takes a sample of every server that is either online or has gone offline recently.
+// A server is online as long as its details are known.
+func (uc UseCase) Execute(ctx context.Context, now time.Time) (Response, error) {
+	servers, err := uc.serverRepo.Filter(ctx, filterset.NewServerFilterSet())
+	if err != nil {
+		return NoResponse, fmt.Errorf("recordhistory: repo: %w", ErrUnableToObtainServers)
+	}
+
+	resp := Response{}
+	samples := make([]history.Sample, 0, len(servers))
+	for _, svr := range servers {
+		if !svr.HasAnyDiscoveryStatus(ds.Details | ds.NoDetails) {
+			continue
+		}
+		sample := newSample(svr, now)
+		if sample.Online {
+			resp.Online++
+		} else {
+			resp.Offline++
+		}
+		samples = append(samples, sample)
+	}
+
+	if err := uc.historyRepo.Record(ctx, samples...); err != nil {
+		return NoResponse, fmt.Errorf("recordhistory: repo: %w", ErrUnableToRecordHistory)
+	}
+
+	return resp, nil
+}
+
+func newSample(svr server.Server, now time.Time) history.Sample {
+	if !svr.HasDiscoveryStatus(ds.Details) {
+		return history.Sample{Addr: svr.Addr, At: now}
+	}
+	return history.Sample{
+		Addr:       svr.Addr,
+		At:         now,
+		Online:     true,
+		Players:    svr.Details.Info.NumPlayers,
+		MaxPlayers: svr.Details.Info.MaxPlayers,
+		MapName:    svr.Details.Info.MapName,
+		GameType:   svr.Details.Info.GameType,
+	}
+}
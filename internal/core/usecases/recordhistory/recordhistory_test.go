@@ -0,0 +1,115 @@
+package recordhistory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/internal/core/entities/filterset"
+	"github.com/sergeii/swat4master/internal/core/entities/history"
+	"github.com/sergeii/swat4master/internal/core/entities/server"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+	"github.com/sergeii/swat4master/internal/core/usecases/recordhistory"
+	"github.com/sergeii/swat4master/internal/testutils/factories/serverfactory"
+)
+
+type MockServerRepository struct {
+	mock.Mock
+	repositories.ServerRepository
+}
+
+func (m *MockServerRepository) Filter(ctx context.Context, fs filterset.ServerFilterSet) ([]server.Server, error) {
+	args := m.Called(ctx, fs)
+	return args.Get(0).([]server.Server), args.Error(1) //nolint: forcetypeassert
+}
+
+type MockHistoryRepository struct {
+	mock.Mock
+	repositories.HistoryRepository
+}
+
+func (m *MockHistoryRepository) Record(ctx context.Context, samples ...history.Sample) error {
+	args := m.Called(ctx, samples)
+	return args.Error(0)
+}
+
+func TestRecordHistoryUseCase_OK(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	online := serverfactory.Build(
+		serverfactory.WithAddress("1.1.1.1", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Master|ds.Info|ds.Details|ds.Port),
+		serverfactory.WithInfo(map[string]string{
+			"hostname":    "Swat4 Server",
+			"hostport":    "10480",
+			"mapname":     "Food Wall Restaurant",
+			"gamever":     "1.1",
+			"gamevariant": "SWAT 4",
+			"gametype":    "Barricaded Suspects",
+			"numplayers":  "7",
+			"maxplayers":  "12",
+		}),
+	)
+	offline := serverfactory.Build(
+		serverfactory.WithAddress("2.2.2.2", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Master|ds.NoDetails),
+	)
+	undiscovered := serverfactory.Build(
+		serverfactory.WithAddress("3.3.3.3", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Master|ds.PortRetry),
+	)
+
+	serverRepo := new(MockServerRepository)
+	serverRepo.On("Filter", ctx, filterset.NewServerFilterSet()).
+		Return([]server.Server{online, offline, undiscovered}, nil)
+
+	historyRepo := new(MockHistoryRepository)
+	historyRepo.On("Record", ctx, mock.Anything).Return(nil)
+
+	uc := recordhistory.New(serverRepo, historyRepo)
+	resp, err := uc.Execute(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, resp.Online)
+	assert.Equal(t, 1, resp.Offline)
+
+	historyRepo.AssertCalled(t, "Record", ctx, []history.Sample{
+		{
+			Addr:       online.Addr,
+			At:         now,
+			Online:     true,
+			Players:    7,
+			MaxPlayers: 12,
+			MapName:    "Food Wall Restaurant",
+			GameType:   "Barricaded Suspects",
+		},
+		{Addr: offline.Addr, At: now},
+	})
+}
+
+func TestRecordHistoryUseCase_Errors(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Now()
+
+	svr := serverfactory.Build(serverfactory.WithDiscoveryStatus(ds.Details))
+
+	serverRepo := new(MockServerRepository)
+	serverRepo.On("Filter", ctx, mock.Anything).Return([]server.Server{}, errors.New("error")).Once()
+	historyRepo := new(MockHistoryRepository)
+
+	uc := recordhistory.New(serverRepo, historyRepo)
+	_, err := uc.Execute(ctx, now)
+	require.ErrorIs(t, err, recordhistory.ErrUnableToObtainServers)
+
+	serverRepo.On("Filter", ctx, mock.Anything).Return([]server.Server{svr}, nil)
+	historyRepo.On("Record", ctx, mock.Anything).Return(errors.New("error"))
+
+	_, err = uc.Execute(ctx, now)
+	require.ErrorIs(t, err, recordhistory.ErrUnableToRecordHistory)
+}
@@ -43,11 +43,13 @@ func New(
 }
 
 type Request struct {
+	Now      time.Time
 	Deadline time.Time
 }
 
-func NewRequest(deadline time.Time) Request {
+func NewRequest(now, deadline time.Time) Request {
 	return Request{
+		Now:      now,
 		Deadline: deadline,
 	}
 }
@@ -68,6 +70,10 @@ func (uc UseCase) Execute(ctx context.Context, req Request) (Response, error) {
 
 	probeCount := 0
 	for _, svr := range serversWithDetails {
+		// servers that rarely change are refreshed less often, according to their schedule
+		if !svr.Schedule.IsDue(req.Now) {
+			continue
+		}
 		if err := uc.addProbe(ctx, svr.Addr, svr.QueryPort, req.Deadline); err != nil {
 			uc.logger.Warn().
 				Err(err).Stringer("server", svr).
@@ -65,7 +65,7 @@ func TestRefreshServersUseCase_Success(t *testing.T) {
 	}
 	uc := refreshservers.New(serverRepo, probeRepo, ucOpts, collector, &logger)
 
-	req := refreshservers.NewRequest(deadline)
+	req := refreshservers.NewRequest(now, deadline)
 	resp, err := uc.Execute(ctx, req)
 
 	require.NoError(t, err)
@@ -101,6 +101,71 @@ func TestRefreshServersUseCase_Success(t *testing.T) {
 	assert.InDelta(t, float64(2), probesProducedMetricValue, 1e-9)
 }
 
+func TestRefreshServersUseCase_OnlyDueServers(t *testing.T) {
+	ctx := context.TODO()
+	logger := zerolog.Nop()
+	collector := metrics.New()
+
+	now := time.Now()
+	deadline := now.Add(time.Second * 5)
+
+	neverRefreshed := serverfactory.BuildRandom()
+	due := serverfactory.Build(
+		serverfactory.WithRandomAddress(),
+		serverfactory.WithSchedule(server.Schedule{NextRefreshAt: now.Add(-time.Second)}),
+	)
+	dueNow := serverfactory.Build(
+		serverfactory.WithRandomAddress(),
+		serverfactory.WithSchedule(server.Schedule{NextRefreshAt: now}),
+	)
+	notDue := serverfactory.Build(
+		serverfactory.WithRandomAddress(),
+		serverfactory.WithSchedule(server.Schedule{NextRefreshAt: now.Add(time.Second)}),
+	)
+
+	serverRepo := new(MockServerRepository)
+	serverRepo.On("Filter", ctx, mock.Anything).Return([]server.Server{neverRefreshed, due, dueNow, notDue}, nil).Once()
+
+	probeRepo := new(MockProbeRepository)
+	probeRepo.On("AddBetween", ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil).Times(3)
+
+	ucOpts := refreshservers.UseCaseOptions{
+		MaxProbeRetries: 3,
+	}
+	uc := refreshservers.New(serverRepo, probeRepo, ucOpts, collector, &logger)
+
+	req := refreshservers.NewRequest(now, deadline)
+	resp, err := uc.Execute(ctx, req)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, resp.Count)
+
+	serverRepo.AssertExpectations(t)
+	probeRepo.AssertExpectations(t)
+
+	for _, svr := range []server.Server{neverRefreshed, due, dueNow} {
+		probeRepo.AssertCalled(
+			t,
+			"AddBetween",
+			ctx,
+			probe.New(svr.Addr, svr.QueryPort, probe.GoalDetails, 3),
+			repositories.NC,
+			deadline,
+		)
+	}
+	probeRepo.AssertNotCalled(
+		t,
+		"AddBetween",
+		ctx,
+		probe.New(notDue.Addr, notDue.QueryPort, probe.GoalDetails, 3),
+		repositories.NC,
+		deadline,
+	)
+
+	probesProducedMetricValue := testutil.ToFloat64(collector.DiscoveryQueueProduced)
+	assert.InDelta(t, float64(3), probesProducedMetricValue, 1e-9)
+}
+
 func TestRefreshServersUseCase_FilterError(t *testing.T) {
 	ctx := context.TODO()
 	logger := zerolog.Nop()
@@ -118,7 +183,7 @@ func TestRefreshServersUseCase_FilterError(t *testing.T) {
 	}
 	uc := refreshservers.New(serverRepo, probeRepo, ucOpts, collector, &logger)
 
-	req := refreshservers.NewRequest(time.Now())
+	req := refreshservers.NewRequest(time.Now(), time.Now())
 	resp, err := uc.Execute(ctx, req)
 
 	require.ErrorIs(t, err, filterErr)
@@ -155,7 +220,7 @@ func TestRefreshServersUseCase_AddProbeError(t *testing.T) {
 	}
 	uc := refreshservers.New(serverRepo, probeRepo, ucOpts, collector, &logger)
 
-	req := refreshservers.NewRequest(time.Now())
+	req := refreshservers.NewRequest(time.Now(), time.Now())
 	resp, err := uc.Execute(ctx, req)
 
 	require.NoError(t, err)
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/jonboulle/clockwork"
 	"github.com/rs/zerolog"
 
 	"github.com/sergeii/swat4master/internal/core/entities/addr"
@@ -22,17 +23,26 @@ var (
 type UseCase struct {
 	serverRepo   repositories.ServerRepository
 	instanceRepo repositories.InstanceRepository
+	profileRepo  repositories.ProfileRepository
+	sessionRepo  repositories.SessionRepository
+	clock        clockwork.Clock
 	logger       *zerolog.Logger
 }
 
 func New(
 	serverRepo repositories.ServerRepository,
 	instanceRepo repositories.InstanceRepository,
+	profileRepo repositories.ProfileRepository,
+	sessionRepo repositories.SessionRepository,
+	clock clockwork.Clock,
 	logger *zerolog.Logger,
 ) UseCase {
 	return UseCase{
 		serverRepo:   serverRepo,
 		instanceRepo: instanceRepo,
+		profileRepo:  profileRepo,
+		sessionRepo:  sessionRepo,
+		clock:        clock,
 		logger:       logger,
 	}
 }
@@ -69,6 +79,14 @@ func (uc UseCase) Execute(ctx context.Context, req Request) error {
 	}); err != nil {
 		return err
 	}
+	// the ownership of the server is gone, so is the profile edited by the owner
+	if err = uc.profileRepo.Remove(ctx, svr.Addr); err != nil {
+		return err
+	}
+	// the players are gone along with the server
+	if _, err = uc.sessionRepo.EndActive(ctx, svr.Addr, uc.clock.Now()); err != nil {
+		return err
+	}
 	if err = uc.instanceRepo.Remove(ctx, inst.ID); err != nil {
 		return err
 	}
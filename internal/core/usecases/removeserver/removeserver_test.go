@@ -2,8 +2,11 @@ package removeserver_test
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/jonboulle/clockwork"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -55,6 +58,26 @@ func (m *MockInstanceRepository) Remove(ctx context.Context, instanceID instance
 	return args.Error(0)
 }
 
+type MockProfileRepository struct {
+	mock.Mock
+	repositories.ProfileRepository
+}
+
+func (m *MockProfileRepository) Remove(ctx context.Context, svrAddr addr.Addr) error {
+	args := m.Called(ctx, svrAddr)
+	return args.Error(0)
+}
+
+type MockSessionRepository struct {
+	mock.Mock
+	repositories.SessionRepository
+}
+
+func (m *MockSessionRepository) EndActive(ctx context.Context, svrAddr addr.Addr, endedAt time.Time) (int, error) {
+	args := m.Called(ctx, svrAddr, endedAt)
+	return args.Int(0), args.Error(1)
+}
+
 func TestRemoveServerUseCase_Success(t *testing.T) {
 	ctx := context.TODO()
 	logger := zerolog.Nop()
@@ -71,12 +94,53 @@ func TestRemoveServerUseCase_Success(t *testing.T) {
 	instanceRepo.On("Get", ctx, instID).Return(inst, nil)
 	instanceRepo.On("Remove", ctx, instID).Return(nil)
 
-	uc := removeserver.New(serverRepo, instanceRepo, &logger)
+	profileRepo := new(MockProfileRepository)
+	profileRepo.On("Remove", ctx, svr.Addr).Return(nil)
+
+	clock := clockwork.NewFakeClock()
+	sessionRepo := new(MockSessionRepository)
+	sessionRepo.On("EndActive", ctx, svr.Addr, clock.Now()).Return(2, nil)
+
+	uc := removeserver.New(serverRepo, instanceRepo, profileRepo, sessionRepo, clock, &logger)
 	err := uc.Execute(ctx, removeserver.NewRequest(b(DEADBEEF), svr.Addr))
 	require.NoError(t, err)
 
 	serverRepo.AssertExpectations(t)
 	instanceRepo.AssertExpectations(t)
+	profileRepo.AssertExpectations(t)
+	sessionRepo.AssertExpectations(t)
+}
+
+func TestRemoveServerUseCase_EndSessionsError(t *testing.T) {
+	ctx := context.TODO()
+	logger := zerolog.Nop()
+
+	svr := serverfactory.BuildRandom()
+	instID := instance.MustNewID(b(DEADBEEF))
+	inst := instance.MustNew(instID, svr.Addr.GetIP(), svr.Addr.Port)
+
+	serverRepo := new(MockServerRepository)
+	serverRepo.On("Get", ctx, svr.Addr).Return(svr, nil)
+	serverRepo.On("Remove", ctx, svr, mock.Anything).Return(nil)
+
+	instanceRepo := new(MockInstanceRepository)
+	instanceRepo.On("Get", ctx, instID).Return(inst, nil)
+
+	profileRepo := new(MockProfileRepository)
+	profileRepo.On("Remove", ctx, svr.Addr).Return(nil)
+
+	clock := clockwork.NewFakeClock()
+	sessionErr := errors.New("session error")
+	sessionRepo := new(MockSessionRepository)
+	sessionRepo.On("EndActive", ctx, svr.Addr, clock.Now()).Return(0, sessionErr)
+
+	uc := removeserver.New(serverRepo, instanceRepo, profileRepo, sessionRepo, clock, &logger)
+	err := uc.Execute(ctx, removeserver.NewRequest(b(DEADBEEF), svr.Addr))
+	require.ErrorIs(t, err, sessionErr)
+
+	serverRepo.AssertExpectations(t)
+	sessionRepo.AssertExpectations(t)
+	instanceRepo.AssertNotCalled(t, "Remove", mock.Anything, mock.Anything)
 }
 
 func TestRemoveServerUseCase_ServerAlreadyDeleted(t *testing.T) {
@@ -90,7 +154,9 @@ func TestRemoveServerUseCase_ServerAlreadyDeleted(t *testing.T) {
 
 	instanceRepo := new(MockInstanceRepository)
 
-	uc := removeserver.New(serverRepo, instanceRepo, &logger)
+	uc := removeserver.New(
+		serverRepo, instanceRepo, new(MockProfileRepository), new(MockSessionRepository), clockwork.NewFakeClock(), &logger,
+	)
 	ucReq := removeserver.NewRequest(b(DEADBEEF), svr.Addr)
 
 	err := uc.Execute(ctx, ucReq)
@@ -116,7 +182,9 @@ func TestRemoveServerUseCase_InstanceAlreadyDeleted(t *testing.T) {
 	instanceRepo := new(MockInstanceRepository)
 	instanceRepo.On("Get", ctx, instID).Return(instance.Blank, repositories.ErrInstanceNotFound)
 
-	uc := removeserver.New(serverRepo, instanceRepo, &logger)
+	uc := removeserver.New(
+		serverRepo, instanceRepo, new(MockProfileRepository), new(MockSessionRepository), clockwork.NewFakeClock(), &logger,
+	)
 	err := uc.Execute(ctx, removeserver.NewRequest(b(DEADBEEF), svr.Addr))
 	require.ErrorIs(t, err, removeserver.ErrInstanceNotFound)
 
@@ -142,7 +210,9 @@ func TestRemoveServerUseCase_InstanceAddrDoesNotMatch(t *testing.T) {
 	instanceRepo.On("Get", ctx, instID).Return(inst, nil)
 	instanceRepo.On("Remove", ctx, instID).Return(nil)
 
-	uc := removeserver.New(serverRepo, instanceRepo, &logger)
+	uc := removeserver.New(
+		serverRepo, instanceRepo, new(MockProfileRepository), new(MockSessionRepository), clockwork.NewFakeClock(), &logger,
+	)
 	err := uc.Execute(ctx, removeserver.NewRequest(b(DEADBEEF), svr.Addr))
 	require.ErrorIs(t, err, removeserver.ErrInstanceAddrMismatch)
 
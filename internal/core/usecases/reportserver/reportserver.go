@@ -13,9 +13,11 @@ import (
 	"github.com/sergeii/swat4master/internal/core/entities/details"
 	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
 	"github.com/sergeii/swat4master/internal/core/entities/instance"
+	"github.com/sergeii/swat4master/internal/core/entities/location"
 	"github.com/sergeii/swat4master/internal/core/entities/probe"
 	"github.com/sergeii/swat4master/internal/core/entities/server"
 	"github.com/sergeii/swat4master/internal/core/repositories"
+	"github.com/sergeii/swat4master/internal/geoip"
 	"github.com/sergeii/swat4master/internal/metrics"
 )
 
@@ -29,6 +31,7 @@ type UseCase struct {
 	serverRepo   repositories.ServerRepository
 	instanceRepo repositories.InstanceRepository
 	probeRepo    repositories.ProbeRepository
+	locator      geoip.Locator
 	opts         UseCaseOptions
 	metrics      *metrics.Collector
 	validate     *validator.Validate
@@ -40,6 +43,7 @@ func New(
 	serverRepo repositories.ServerRepository,
 	instanceRepo repositories.InstanceRepository,
 	probeRepo repositories.ProbeRepository,
+	locator geoip.Locator,
 	opts UseCaseOptions,
 	validate *validator.Validate,
 	metrics *metrics.Collector,
@@ -50,6 +54,7 @@ func New(
 		serverRepo:   serverRepo,
 		instanceRepo: instanceRepo,
 		probeRepo:    probeRepo,
+		locator:      locator,
 		opts:         opts,
 		metrics:      metrics,
 		validate:     validate,
@@ -107,16 +112,25 @@ func (uc UseCase) Execute(ctx context.Context, req Request) error {
 	}
 
 	now := uc.clock.Now()
+	loc, located := uc.locate(svr)
 
 	svr.UpdateInfo(info)
+	svr.ReportListing(info, now)
 	svr.Refresh(now)
 	svr.UpdateDiscoveryStatus(ds.Master | ds.Info)
+	if located {
+		svr.UpdateLocation(loc)
+	}
 
 	if svr, err = uc.serverRepo.Add(ctx, svr, func(existing *server.Server) bool {
 		// in case the server was already reported, update its info and status
 		existing.UpdateInfo(info)
+		existing.ReportListing(info, now)
 		existing.Refresh(now)
 		existing.UpdateDiscoveryStatus(ds.Master | ds.Info)
+		if located && existing.NeedsLocation() {
+			existing.UpdateLocation(loc)
+		}
 		return true
 	}); err != nil {
 		uc.logger.Error().
@@ -153,6 +167,20 @@ func (uc UseCase) Execute(ctx context.Context, req Request) error {
 	return nil
 }
 
+// locate resolves the location of the server, unless it's already been resolved for the server's address.
+// Failing to resolve the location is not critical, so the server is then reported with no location.
+func (uc UseCase) locate(svr server.Server) (location.Location, bool) {
+	if !svr.NeedsLocation() {
+		return location.Blank, false
+	}
+	loc, err := uc.locator.Locate(svr.Addr.GetIP())
+	if err != nil {
+		uc.logger.Warn().Err(err).Stringer("server", svr).Msg("Failed to resolve server location")
+		return location.Blank, false
+	}
+	return loc, true
+}
+
 func (uc UseCase) maybeDiscoverPort(ctx context.Context, pending server.Server) error {
 	var err error
 	// the server has either already go its port discovered
@@ -2,6 +2,8 @@ package reportserver_test
 
 import (
 	"context"
+	"errors"
+	"net"
 	"testing"
 	"time"
 
@@ -16,6 +18,7 @@ import (
 	"github.com/sergeii/swat4master/internal/core/entities/details"
 	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
 	"github.com/sergeii/swat4master/internal/core/entities/instance"
+	"github.com/sergeii/swat4master/internal/core/entities/location"
 	"github.com/sergeii/swat4master/internal/core/entities/probe"
 	"github.com/sergeii/swat4master/internal/core/entities/server"
 	"github.com/sergeii/swat4master/internal/core/repositories"
@@ -78,6 +81,15 @@ func (m *MockProbeRepository) Add(ctx context.Context, prb probe.Probe) error {
 	return args.Error(0)
 }
 
+type MockLocator struct {
+	mock.Mock
+}
+
+func (m *MockLocator) Locate(ip net.IP) (location.Location, error) {
+	args := m.Called(ip)
+	return args.Get(0).(location.Location), args.Error(1) //nolint: forcetypeassert
+}
+
 func TestReportServerUseCase_ReportNewServer(t *testing.T) {
 	ctx := context.TODO()
 	logger := zerolog.Nop()
@@ -104,10 +116,16 @@ func TestReportServerUseCase_ReportNewServer(t *testing.T) {
 	probeRepo := new(MockProbeRepository)
 	probeRepo.On("Add", ctx, mock.Anything).Return(nil)
 
+	svrLocation := location.New(svrAddr.GetIP(), "AU", "OC", 13335)
+	locator := new(MockLocator)
+	locator.On("Locate", svrAddr.GetIP()).Return(svrLocation, nil)
+
 	ucOpts := reportserver.UseCaseOptions{
 		MaxProbeRetries: 3,
 	}
-	uc := reportserver.New(serverRepo, instanceRepo, probeRepo, ucOpts, validate, collector, clock, &logger)
+	uc := reportserver.New(
+		serverRepo, instanceRepo, probeRepo, locator, ucOpts, validate, collector, clock, &logger,
+	)
 
 	req := reportserver.NewRequest(svrAddr, svrQueryPort, b(DEADBEEF), svrParams)
 	err := uc.Execute(ctx, req)
@@ -124,7 +142,12 @@ func TestReportServerUseCase_ReportNewServer(t *testing.T) {
 			hasStatus := createdServer.HasDiscoveryStatus(ds.Master | ds.Info)
 			hasInfo := createdServer.Info == svrInfo
 			hasRefreshedAt := createdServer.RefreshedAt.Equal(passedTime)
-			return hasAddr && hasQueryPort && hasStatus && hasInfo && hasRefreshedAt
+			hasLocation := createdServer.Location.Country == "AU" && createdServer.Location.ASN == 13335
+			// the reported server is unverified until it's been probed
+			hasReport := !createdServer.Listing.IsVerified() &&
+				createdServer.Listing.Report.NumPlayers == svrInfo.NumPlayers &&
+				createdServer.Listing.Report.ReportedAt.Equal(passedTime)
+			return hasAddr && hasQueryPort && hasStatus && hasInfo && hasRefreshedAt && hasLocation && hasReport
 		}),
 		mock.Anything,
 	)
@@ -238,10 +261,15 @@ func TestReportServerUseCase_ReportExistingServer(t *testing.T) {
 
 			updatedParams := testutils.GenExtraServerParams(map[string]string{"mapname": "The Wolcott Projects"})
 
+			locator := new(MockLocator)
+			locator.On("Locate", mock.Anything).Return(location.Blank, nil)
+
 			ucOpts := reportserver.UseCaseOptions{
 				MaxProbeRetries: 3,
 			}
-			uc := reportserver.New(serverRepo, instanceRepo, probeRepo, ucOpts, validate, collector, clock, &logger)
+			uc := reportserver.New(
+				serverRepo, instanceRepo, probeRepo, locator, ucOpts, validate, collector, clock, &logger,
+			)
 			req := reportserver.NewRequest(svr.Addr, svr.QueryPort, b(DEADBEEF), updatedParams)
 			err := uc.Execute(ctx, req)
 			require.NoError(t, err)
@@ -352,11 +380,14 @@ func TestReportServerUseCase_InvalidFields(t *testing.T) {
 
 			instanceRepo := new(MockInstanceRepository)
 			probeRepo := new(MockProbeRepository)
+			locator := new(MockLocator)
 
 			ucOpts := reportserver.UseCaseOptions{
 				MaxProbeRetries: 3,
 			}
-			uc := reportserver.New(serverRepo, instanceRepo, probeRepo, ucOpts, validate, collector, clock, &logger)
+			uc := reportserver.New(
+				serverRepo, instanceRepo, probeRepo, locator, ucOpts, validate, collector, clock, &logger,
+			)
 			req := reportserver.NewRequest(svrAddr, svrQueryPort, b(DEADBEEF), tt.params)
 			err := uc.Execute(ctx, req)
 			require.ErrorIs(t, err, reportserver.ErrInvalidRequestPayload)
@@ -366,9 +397,150 @@ func TestReportServerUseCase_InvalidFields(t *testing.T) {
 			instanceRepo.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
 			probeRepo.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
 			serverRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+			locator.AssertNotCalled(t, "Locate", mock.Anything)
 
 			probesProducedMetricValue := testutil.ToFloat64(collector.DiscoveryQueueProduced)
 			assert.InDelta(t, float64(0), probesProducedMetricValue, 1e-9)
 		})
 	}
 }
+
+func TestReportServerUseCase_ResolveLocation(t *testing.T) {
+	svrAddr := addr.MustNewFromDotted("1.1.1.1", 10480)
+	svrLocation := location.New(svrAddr.GetIP(), "AU", "OC", 13335)
+
+	tests := []struct {
+		name         string
+		existing     location.Location
+		locateErr    error
+		wantLocate   bool
+		wantLocation location.Location
+	}{
+		{
+			"location is resolved for server with no location",
+			location.Blank,
+			nil,
+			true,
+			svrLocation,
+		},
+		{
+			"location is resolved again for server with changed address",
+			location.New(net.ParseIP("2.2.2.2"), "DE", "EU", 24940),
+			nil,
+			true,
+			svrLocation,
+		},
+		{
+			"location is not resolved again for same address",
+			location.New(svrAddr.GetIP(), "NZ", "OC", 0),
+			nil,
+			false,
+			location.New(svrAddr.GetIP(), "NZ", "OC", 0),
+		},
+		{
+			"server is reported regardless of location error",
+			location.Blank,
+			errors.New("error"),
+			true,
+			location.Blank,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TODO()
+			logger := zerolog.Nop()
+			validate := validation.MustNew()
+			clock := clockwork.NewFakeClock()
+			collector := metrics.New()
+
+			svr := serverfactory.Build(
+				serverfactory.WithAddress("1.1.1.1", 10480),
+				serverfactory.WithQueryPort(10481),
+				serverfactory.WithDiscoveryStatus(ds.Master|ds.Info|ds.Port),
+			)
+			svr.UpdateLocation(tt.existing)
+
+			serverRepo := new(MockServerRepository)
+			serverRepo.On("Get", ctx, svrAddr).Return(svr, nil)
+			serverRepo.On("Add", ctx, mock.Anything, mock.Anything).Return(nil)
+
+			instanceRepo := new(MockInstanceRepository)
+			instanceRepo.On("Add", ctx, mock.Anything).Return(nil)
+
+			probeRepo := new(MockProbeRepository)
+
+			locator := new(MockLocator)
+			locator.On("Locate", svrAddr.GetIP()).Return(svrLocation, tt.locateErr)
+
+			uc := reportserver.New(
+				serverRepo, instanceRepo, probeRepo, locator,
+				reportserver.UseCaseOptions{MaxProbeRetries: 3}, validate, collector, clock, &logger,
+			)
+			req := reportserver.NewRequest(svrAddr, 10481, b(DEADBEEF), testutils.GenServerParams())
+			err := uc.Execute(ctx, req)
+			require.NoError(t, err)
+
+			if tt.wantLocate {
+				locator.AssertCalled(t, "Locate", svrAddr.GetIP())
+			} else {
+				locator.AssertNotCalled(t, "Locate", mock.Anything)
+			}
+
+			added := serverRepo.Calls[1].Arguments.Get(1).(server.Server) //nolint: forcetypeassert
+			if tt.wantLocation.IP == nil {
+				assert.Equal(t, tt.wantLocation, added.Location)
+			} else {
+				assert.True(t, added.Location.IsResolvedFor(tt.wantLocation.IP))
+				assert.Equal(t, tt.wantLocation.Country, added.Location.Country)
+				assert.Equal(t, tt.wantLocation.ASN, added.Location.ASN)
+			}
+		})
+	}
+}
+
+func TestReportServerUseCase_ResolveLocationOnConflict(t *testing.T) {
+	ctx := context.TODO()
+	logger := zerolog.Nop()
+	validate := validation.MustNew()
+	clock := clockwork.NewFakeClock()
+	collector := metrics.New()
+
+	svrAddr := addr.MustNewFromDotted("1.1.1.1", 10480)
+	svrLocation := location.New(svrAddr.GetIP(), "AU", "OC", 13335)
+
+	serverRepo := new(MockServerRepository)
+	serverRepo.On("Get", ctx, svrAddr).Return(server.Blank, repositories.ErrServerNotFound)
+	serverRepo.On("Add", ctx, mock.Anything, mock.Anything).Return(nil)
+	serverRepo.On("Update", ctx, mock.Anything, mock.Anything).Return(nil)
+
+	instanceRepo := new(MockInstanceRepository)
+	instanceRepo.On("Add", ctx, mock.Anything).Return(nil)
+
+	probeRepo := new(MockProbeRepository)
+	probeRepo.On("Add", ctx, mock.Anything).Return(nil)
+
+	locator := new(MockLocator)
+	locator.On("Locate", svrAddr.GetIP()).Return(svrLocation, nil)
+
+	uc := reportserver.New(
+		serverRepo, instanceRepo, probeRepo, locator,
+		reportserver.UseCaseOptions{MaxProbeRetries: 3}, validate, collector, clock, &logger,
+	)
+	req := reportserver.NewRequest(svrAddr, 10481, b(DEADBEEF), testutils.GenServerParams())
+	err := uc.Execute(ctx, req)
+	require.NoError(t, err)
+
+	onConflict := serverRepo.Calls[1].Arguments.Get(2).(func(*server.Server) bool) //nolint: forcetypeassert
+
+	// the server has been added concurrently with no location
+	unlocated := server.MustNewFromAddr(svrAddr, 10481)
+	assert.True(t, onConflict(&unlocated))
+	assert.Equal(t, "AU", unlocated.Location.Country)
+
+	// the server has been located concurrently
+	located := server.MustNewFromAddr(svrAddr, 10481)
+	located.UpdateLocation(location.New(svrAddr.GetIP(), "NZ", "OC", 0))
+	assert.True(t, onConflict(&located))
+	assert.Equal(t, "NZ", located.Location.Country)
+}
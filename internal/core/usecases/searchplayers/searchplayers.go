@@ -0,0 +1,147 @@
+package searchplayers
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/server"
+	"github.com/sergeii/swat4master/internal/core/entities/session"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+)
+
+var (
+	ErrUnableToObtainSessions = errors.New("unable to obtain player sessions from repository")
+	ErrUnableToObtainServers  = errors.New("unable to obtain servers from repository")
+)
+
+type UseCase struct {
+	sessionRepo repositories.SessionRepository
+	serverRepo  repositories.ServerRepository
+	clock       clockwork.Clock
+}
+
+func New(
+	sessionRepo repositories.SessionRepository,
+	serverRepo repositories.ServerRepository,
+	clock clockwork.Clock,
+) UseCase {
+	return UseCase{
+		sessionRepo: sessionRepo,
+		serverRepo:  serverRepo,
+		clock:       clock,
+	}
+}
+
+type Request struct {
+	name       string
+	limit      int
+	recentness time.Duration
+}
+
+func NewRequest(name string, limit int, recentness time.Duration) Request {
+	return Request{
+		name:       name,
+		limit:      limit,
+		recentness: recentness,
+	}
+}
+
+// Player is a player found playing on a server
+type Player struct {
+	Session session.Session
+	Server  server.Server
+	Match   session.Match
+}
+
+// Execute looks up the players currently playing on the live servers by name.
+// The players whose names start with the query are looked up in the index of the active sessions.
+// Should there be too few of them, the rest of the online players are matched approximately.
+// Every matching player is considered, because some of them may be playing on the servers that are no longer live.
+// The closest matches go first.
+func (uc UseCase) Execute(ctx context.Context, req Request) ([]Player, error) {
+	query := session.NormalizeName(req.name)
+	if query == "" || req.limit <= 0 {
+		return []Player{}, nil
+	}
+
+	found, err := uc.sessionRepo.FindActive(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("searchplayers: repo: %w", ErrUnableToObtainSessions)
+	}
+	candidates := match(query, found, nil)
+
+	if len(candidates) < req.limit && len([]rune(query)) >= session.FuzzyMinLength {
+		online, err := uc.sessionRepo.FindActive(ctx, "")
+		if err != nil {
+			return nil, fmt.Errorf("searchplayers: repo: %w", ErrUnableToObtainSessions)
+		}
+		candidates = append(candidates, match(query, online, candidates)...)
+	}
+
+	slices.SortStableFunc(candidates, func(a, b Player) int {
+		return cmp.Or(
+			cmp.Compare(a.Match, b.Match),
+			cmp.Compare(session.NormalizeName(a.Session.Name), session.NormalizeName(b.Session.Name)),
+		)
+	})
+
+	return uc.attachServers(ctx, candidates, req)
+}
+
+// match selects the sessions with the player names matching the query, leaving out the already matched ones
+func match(query string, sessions []session.Session, matched []Player) []Player {
+	seen := make(map[string]struct{}, len(matched))
+	for _, player := range matched {
+		seen[player.Session.ID] = struct{}{}
+	}
+	players := make([]Player, 0, len(sessions))
+	for _, sess := range sessions {
+		if _, ok := seen[sess.ID]; ok {
+			continue
+		}
+		if m, ok := session.MatchName(query, session.NormalizeName(sess.Name)); ok {
+			players = append(players, Player{Session: sess, Match: m})
+		}
+	}
+	return players
+}
+
+// attachServers pairs the players with the servers they are playing on.
+// The players on the servers that are no longer live or are duplicates of other servers are left out,
+// so are the players beyond the limit.
+func (uc UseCase) attachServers(ctx context.Context, candidates []Player, req Request) ([]Player, error) {
+	activeAfter := uc.clock.Now().Add(-req.recentness)
+	servers := make(map[addr.Addr]*server.Server)
+
+	result := make([]Player, 0, min(len(candidates), req.limit))
+	for _, player := range candidates {
+		if len(result) >= req.limit {
+			break
+		}
+		svr, ok := servers[player.Session.Addr]
+		if !ok {
+			obtained, err := uc.serverRepo.Get(ctx, player.Session.Addr)
+			switch {
+			case err == nil:
+				svr = &obtained
+			case !errors.Is(err, repositories.ErrServerNotFound):
+				return nil, fmt.Errorf("searchplayers: repo: %w", ErrUnableToObtainServers)
+			}
+			servers[player.Session.Addr] = svr
+		}
+		if svr == nil || svr.IsDuplicate() || svr.RefreshedAt.Before(activeAfter) {
+			continue
+		}
+		player.Server = *svr
+		result = append(result, player)
+	}
+
+	return result, nil
+}
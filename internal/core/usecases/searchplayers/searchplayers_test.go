@@ -0,0 +1,195 @@
+package searchplayers_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/details"
+	"github.com/sergeii/swat4master/internal/core/entities/server"
+	"github.com/sergeii/swat4master/internal/core/entities/session"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+	"github.com/sergeii/swat4master/internal/core/usecases/searchplayers"
+	"github.com/sergeii/swat4master/internal/testutils/factories/serverfactory"
+)
+
+type MockSessionRepository struct {
+	mock.Mock
+	repositories.SessionRepository
+}
+
+func (m *MockSessionRepository) FindActive(ctx context.Context, prefix string) ([]session.Session, error) {
+	args := m.Called(ctx, prefix)
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
+	return args.Get(0).([]session.Session), nil //nolint: forcetypeassert
+}
+
+type MockServerRepository struct {
+	mock.Mock
+	repositories.ServerRepository
+}
+
+func (m *MockServerRepository) Get(ctx context.Context, svrAddr addr.Addr) (server.Server, error) {
+	args := m.Called(ctx, svrAddr)
+	return args.Get(0).(server.Server), args.Error(1) //nolint: forcetypeassert
+}
+
+func playerNames(players []searchplayers.Player) []string {
+	names := make([]string, 0, len(players))
+	for _, player := range players {
+		names = append(names, player.Session.Name)
+	}
+	return names
+}
+
+func TestSearchPlayersUseCase_OK(t *testing.T) {
+	ctx := context.TODO()
+	clock := clockwork.NewFakeClockAt(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	now := clock.Now()
+
+	live := serverfactory.Build(serverfactory.WithAddress("1.1.1.1", 10480), serverfactory.WithRefreshedAt(now))
+	stale := serverfactory.Build(
+		serverfactory.WithAddress("2.2.2.2", 10480),
+		serverfactory.WithRefreshedAt(now.Add(-time.Hour)),
+	)
+	dup := serverfactory.Build(serverfactory.WithAddress("3.3.3.3", 10480), serverfactory.WithRefreshedAt(now))
+	dup.DuplicateOf = live.Addr
+	gone := addr.MustNewFromDotted("4.4.4.4", 10480)
+
+	startedAt := now.Add(-time.Minute * 10)
+	exact := session.New(live.Addr, details.Player{Name: "[c=FF0000]Serge"}, startedAt)
+	prefix := session.New(live.Addr, details.Player{Name: "Sergeant"}, startedAt)
+	substring := session.New(live.Addr, details.Player{Name: "|MYT|Serge"}, startedAt)
+	fuzzy := session.New(live.Addr, details.Player{Name: "Sarge"}, startedAt)
+	other := session.New(live.Addr, details.Player{Name: "Mosquito"}, startedAt)
+	onStale := session.New(stale.Addr, details.Player{Name: "serge"}, startedAt)
+	onDup := session.New(dup.Addr, details.Player{Name: "serge"}, startedAt)
+	onGone := session.New(gone, details.Player{Name: "serge"}, startedAt)
+
+	sessionRepo := new(MockSessionRepository)
+	sessionRepo.On("FindActive", ctx, "serge").
+		Return([]session.Session{prefix, exact, onStale, onDup, onGone}, nil)
+	sessionRepo.On("FindActive", ctx, "").
+		Return([]session.Session{substring, prefix, fuzzy, other, exact}, nil)
+
+	serverRepo := new(MockServerRepository)
+	serverRepo.On("Get", ctx, live.Addr).Return(live, nil)
+	serverRepo.On("Get", ctx, stale.Addr).Return(stale, nil)
+	serverRepo.On("Get", ctx, dup.Addr).Return(dup, nil)
+	serverRepo.On("Get", ctx, gone).Return(server.Blank, repositories.ErrServerNotFound)
+
+	uc := searchplayers.New(sessionRepo, serverRepo, clock)
+	players, err := uc.Execute(ctx, searchplayers.NewRequest(" [b]SERGE ", 10, time.Minute*3))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"[c=FF0000]Serge", "Sergeant", "|MYT|Serge", "Sarge"}, playerNames(players))
+	assert.Equal(t, session.MatchExact, players[0].Match)
+	assert.Equal(t, session.MatchPrefix, players[1].Match)
+	assert.Equal(t, session.MatchSubstring, players[2].Match)
+	assert.Equal(t, session.MatchFuzzy, players[3].Match)
+	for _, player := range players {
+		assert.Equal(t, live.Addr, player.Server.Addr)
+	}
+	// every server is only obtained once
+	serverRepo.AssertNumberOfCalls(t, "Get", 4)
+}
+
+func TestSearchPlayersUseCase_Limit(t *testing.T) {
+	ctx := context.TODO()
+	clock := clockwork.NewFakeClockAt(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	now := clock.Now()
+
+	live := serverfactory.Build(serverfactory.WithAddress("1.1.1.1", 10480), serverfactory.WithRefreshedAt(now))
+	first := session.New(live.Addr, details.Player{Name: "Serge"}, now)
+	second := session.New(live.Addr, details.Player{Name: "Sergeant"}, now)
+
+	sessionRepo := new(MockSessionRepository)
+	sessionRepo.On("FindActive", ctx, "serge").Return([]session.Session{second, first}, nil)
+
+	serverRepo := new(MockServerRepository)
+	serverRepo.On("Get", ctx, live.Addr).Return(live, nil)
+
+	uc := searchplayers.New(sessionRepo, serverRepo, clock)
+	players, err := uc.Execute(ctx, searchplayers.NewRequest("serge", 1, time.Minute*3))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Serge"}, playerNames(players))
+
+	// the prefix matches are enough to fill the limit
+	sessionRepo.AssertNumberOfCalls(t, "FindActive", 1)
+}
+
+func TestSearchPlayersUseCase_ShortQuery(t *testing.T) {
+	ctx := context.TODO()
+	clock := clockwork.NewFakeClock()
+
+	sessionRepo := new(MockSessionRepository)
+	sessionRepo.On("FindActive", ctx, "se").Return([]session.Session{}, nil)
+	serverRepo := new(MockServerRepository)
+
+	uc := searchplayers.New(sessionRepo, serverRepo, clock)
+	players, err := uc.Execute(ctx, searchplayers.NewRequest("Se", 10, time.Minute*3))
+	require.NoError(t, err)
+	assert.Empty(t, players)
+
+	// the short queries are not matched approximately
+	sessionRepo.AssertNumberOfCalls(t, "FindActive", 1)
+}
+
+func TestSearchPlayersUseCase_EmptyQuery(t *testing.T) {
+	ctx := context.TODO()
+	sessionRepo := new(MockSessionRepository)
+	serverRepo := new(MockServerRepository)
+
+	uc := searchplayers.New(sessionRepo, serverRepo, clockwork.NewFakeClock())
+	players, err := uc.Execute(ctx, searchplayers.NewRequest("[c=FF0000] ", 10, time.Minute*3))
+	require.NoError(t, err)
+	assert.Empty(t, players)
+
+	sessionRepo.AssertNotCalled(t, "FindActive", mock.Anything, mock.Anything)
+}
+
+func TestSearchPlayersUseCase_RepoErrors(t *testing.T) {
+	ctx := context.TODO()
+	clock := clockwork.NewFakeClock()
+	svrAddr := addr.MustNewFromDotted("1.1.1.1", 10480)
+	sess := session.New(svrAddr, details.Player{Name: "Serge"}, clock.Now())
+
+	t.Run("find by prefix", func(t *testing.T) {
+		sessionRepo := new(MockSessionRepository)
+		sessionRepo.On("FindActive", ctx, "serge").Return(nil, errors.New("error"))
+
+		uc := searchplayers.New(sessionRepo, new(MockServerRepository), clock)
+		_, err := uc.Execute(ctx, searchplayers.NewRequest("serge", 10, time.Minute*3))
+		require.ErrorIs(t, err, searchplayers.ErrUnableToObtainSessions)
+	})
+
+	t.Run("find all", func(t *testing.T) {
+		sessionRepo := new(MockSessionRepository)
+		sessionRepo.On("FindActive", ctx, "serge").Return([]session.Session{}, nil)
+		sessionRepo.On("FindActive", ctx, "").Return(nil, errors.New("error"))
+
+		uc := searchplayers.New(sessionRepo, new(MockServerRepository), clock)
+		_, err := uc.Execute(ctx, searchplayers.NewRequest("serge", 10, time.Minute*3))
+		require.ErrorIs(t, err, searchplayers.ErrUnableToObtainSessions)
+	})
+
+	t.Run("get server", func(t *testing.T) {
+		sessionRepo := new(MockSessionRepository)
+		sessionRepo.On("FindActive", ctx, "serge").Return([]session.Session{sess}, nil)
+		serverRepo := new(MockServerRepository)
+		serverRepo.On("Get", ctx, svrAddr).Return(server.Blank, errors.New("error"))
+
+		uc := searchplayers.New(sessionRepo, serverRepo, clock)
+		_, err := uc.Execute(ctx, searchplayers.NewRequest("serge", 1, time.Minute*3))
+		require.ErrorIs(t, err, searchplayers.ErrUnableToObtainServers)
+	})
+}
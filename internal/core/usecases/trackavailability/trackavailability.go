@@ -0,0 +1,116 @@
+package trackavailability
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/internal/core/entities/filterset"
+	"github.com/sergeii/swat4master/internal/core/entities/server"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+)
+
+var ErrUnableToObtainServers = errors.New("unable to obtain servers from repository")
+
+type UseCaseOptions struct {
+	ServerLiveness time.Duration
+}
+
+type UseCase struct {
+	serverRepo repositories.ServerRepository
+	opts       UseCaseOptions
+	logger     *zerolog.Logger
+}
+
+func New(
+	serverRepo repositories.ServerRepository,
+	opts UseCaseOptions,
+	logger *zerolog.Logger,
+) UseCase {
+	return UseCase{
+		serverRepo: serverRepo,
+		opts:       opts,
+		logger:     logger,
+	}
+}
+
+type Response struct {
+	WentDown int
+	CameUp   int
+	Errors   int
+}
+
+var NoResponse = Response{}
+
+// Execute opens an outage for every server that has either stopped sending heartbeats
+// for longer than the liveness period or has failed its details probes,
+// and closes the outage once the server is seen again.
+// Only the servers that change their state are updated.
+func (uc UseCase) Execute(ctx context.Context, now time.Time) (Response, error) {
+	servers, err := uc.serverRepo.Filter(ctx, filterset.NewServerFilterSet())
+	if err != nil {
+		return NoResponse, fmt.Errorf("trackavailability: repo: %w", ErrUnableToObtainServers)
+	}
+
+	resp := Response{}
+	for _, svr := range servers {
+		if !svr.HasAnyDiscoveryStatus(ds.Master | ds.Details | ds.NoDetails) {
+			continue
+		}
+		wasDown := svr.Availability.IsDown()
+		if !uc.observe(&svr, now) {
+			continue
+		}
+		if _, err := uc.serverRepo.Update(ctx, svr, func(s *server.Server) bool {
+			return uc.observe(s, now)
+		}); err != nil {
+			if !errors.Is(err, repositories.ErrServerNotFound) {
+				uc.logger.Warn().Err(err).Stringer("server", svr).Msg("Failed to update server availability")
+				resp.Errors++
+			}
+			continue
+		}
+		if wasDown {
+			resp.CameUp++
+		} else if svr.Availability.IsDown() {
+			resp.WentDown++
+		}
+	}
+
+	return resp, nil
+}
+
+// observe updates the availability of the server according to its current state.
+// Returns false if the availability has not changed.
+func (uc UseCase) observe(svr *server.Server, now time.Time) bool {
+	down, at := uc.isDown(*svr, now)
+	switch {
+	case down && !svr.Availability.IsDown():
+		svr.MarkUnavailable(at, now)
+	case !down && (svr.Availability.IsDown() || !svr.Availability.IsKnown()):
+		svr.MarkAvailable(at, now)
+	default:
+		return false
+	}
+	return true
+}
+
+// isDown tells whether the server is unavailable and since when.
+// The server that is available is considered so since its latest heartbeat.
+func (uc UseCase) isDown(svr server.Server, now time.Time) (bool, time.Time) {
+	if svr.RefreshedAt.IsZero() {
+		return true, now
+	}
+	// the server has gone silent, so it's been unavailable since its last heartbeat
+	if now.Sub(svr.RefreshedAt) > uc.opts.ServerLiveness {
+		return true, svr.RefreshedAt
+	}
+	if svr.HasDiscoveryStatus(ds.NoDetails) {
+		return true, now
+	}
+	return false, svr.RefreshedAt
+}
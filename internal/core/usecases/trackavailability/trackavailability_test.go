@@ -0,0 +1,210 @@
+package trackavailability_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/internal/core/entities/filterset"
+	"github.com/sergeii/swat4master/internal/core/entities/server"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+	"github.com/sergeii/swat4master/internal/core/usecases/trackavailability"
+	"github.com/sergeii/swat4master/internal/testutils/factories/serverfactory"
+)
+
+type MockServerRepository struct {
+	mock.Mock
+	repositories.ServerRepository
+}
+
+func (m *MockServerRepository) Filter(ctx context.Context, fs filterset.ServerFilterSet) ([]server.Server, error) {
+	args := m.Called(ctx, fs)
+	return args.Get(0).([]server.Server), args.Error(1) //nolint: forcetypeassert
+}
+
+func (m *MockServerRepository) Update(
+	ctx context.Context,
+	svr server.Server,
+	onConflict func(*server.Server) bool,
+) (server.Server, error) {
+	args := m.Called(ctx, svr, onConflict)
+	return args.Get(0).(server.Server), args.Error(1) //nolint: forcetypeassert
+}
+
+func updatedServers(repo *MockServerRepository) map[addr.Addr]server.Server {
+	updated := make(map[addr.Addr]server.Server)
+	for _, call := range repo.Calls {
+		if call.Method != "Update" {
+			continue
+		}
+		svr := call.Arguments.Get(1).(server.Server) //nolint: forcetypeassert
+		updated[svr.Addr] = svr
+	}
+	return updated
+}
+
+func TestTrackAvailabilityUseCase_OK(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	logger := zerolog.Nop()
+
+	unknown := serverfactory.Build(
+		serverfactory.WithAddress("1.1.1.1", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Master|ds.Info|ds.Details),
+		serverfactory.WithRefreshedAt(now.Add(-time.Minute)),
+	)
+	stable := serverfactory.Build(
+		serverfactory.WithAddress("2.2.2.2", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Master|ds.Info|ds.Details),
+		serverfactory.WithRefreshedAt(now.Add(-time.Minute)),
+	)
+	stable.MarkAvailable(now.Add(-time.Hour), now.Add(-time.Hour))
+	silent := serverfactory.Build(
+		serverfactory.WithAddress("3.3.3.3", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Master|ds.Info),
+		serverfactory.WithRefreshedAt(now.Add(-time.Minute*5)),
+	)
+	silent.MarkAvailable(now.Add(-time.Hour), now.Add(-time.Hour))
+	failed := serverfactory.Build(
+		serverfactory.WithAddress("4.4.4.4", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Master|ds.NoDetails),
+		serverfactory.WithRefreshedAt(now.Add(-time.Minute)),
+	)
+	failed.MarkAvailable(now.Add(-time.Hour), now.Add(-time.Hour))
+	recovered := serverfactory.Build(
+		serverfactory.WithAddress("5.5.5.5", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Master|ds.Info|ds.Details),
+		serverfactory.WithRefreshedAt(now.Add(-time.Second*30)),
+	)
+	recovered.MarkUnavailable(now.Add(-time.Hour), now.Add(-time.Hour))
+	stillDown := serverfactory.Build(
+		serverfactory.WithAddress("6.6.6.6", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Master|ds.NoDetails),
+		serverfactory.WithRefreshedAt(now.Add(-time.Hour)),
+	)
+	stillDown.MarkUnavailable(now.Add(-time.Hour), now.Add(-time.Hour))
+	undiscovered := serverfactory.Build(
+		serverfactory.WithAddress("7.7.7.7", 10480),
+		serverfactory.WithDiscoveryStatus(ds.PortRetry),
+		serverfactory.WithRefreshedAt(now.Add(-time.Hour)),
+	)
+
+	serverRepo := new(MockServerRepository)
+	serverRepo.On("Filter", ctx, filterset.NewServerFilterSet()).
+		Return([]server.Server{unknown, stable, silent, failed, recovered, stillDown, undiscovered}, nil)
+	serverRepo.On("Update", ctx, mock.Anything, mock.Anything).Return(server.Blank, nil)
+
+	opts := trackavailability.UseCaseOptions{ServerLiveness: time.Minute * 3}
+	uc := trackavailability.New(serverRepo, opts, &logger)
+	resp, err := uc.Execute(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, resp.WentDown)
+	assert.Equal(t, 1, resp.CameUp)
+	assert.Equal(t, 0, resp.Errors)
+
+	updated := updatedServers(serverRepo)
+	assert.Len(t, updated, 4)
+
+	svr := updated[unknown.Addr]
+	assert.Equal(t, now.Add(-time.Minute), svr.Availability.Since)
+	assert.False(t, svr.Availability.IsDown())
+
+	svr = updated[silent.Addr]
+	assert.Equal(t, []server.Outage{{Start: now.Add(-time.Minute * 5)}}, svr.Availability.Outages)
+
+	svr = updated[failed.Addr]
+	assert.Equal(t, []server.Outage{{Start: now}}, svr.Availability.Outages)
+
+	svr = updated[recovered.Addr]
+	assert.Equal(t, []server.Outage{
+		{Start: now.Add(-time.Hour), End: now.Add(-time.Second * 30)},
+	}, svr.Availability.Outages)
+}
+
+func TestTrackAvailabilityUseCase_ResolveConflict(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	logger := zerolog.Nop()
+
+	svr := serverfactory.Build(
+		serverfactory.WithAddress("1.1.1.1", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Master|ds.Info),
+		serverfactory.WithRefreshedAt(now.Add(-time.Minute*5)),
+	)
+
+	serverRepo := new(MockServerRepository)
+	serverRepo.On("Filter", ctx, filterset.NewServerFilterSet()).Return([]server.Server{svr}, nil)
+	serverRepo.On("Update", ctx, mock.Anything, mock.Anything).Return(server.Blank, nil)
+
+	opts := trackavailability.UseCaseOptions{ServerLiveness: time.Minute * 3}
+	uc := trackavailability.New(serverRepo, opts, &logger)
+	_, err := uc.Execute(ctx, now)
+	require.NoError(t, err)
+
+	onConflict := serverRepo.Calls[1].Arguments.Get(2).(func(*server.Server) bool) //nolint: forcetypeassert
+
+	// the server has been refreshed in the meantime
+	refreshed := svr
+	refreshed.Refresh(now)
+	assert.True(t, onConflict(&refreshed))
+	assert.True(t, refreshed.Availability.IsKnown())
+	assert.False(t, refreshed.Availability.IsDown())
+
+	// the outage has already been recorded by a concurrent run
+	silent := svr
+	silent.MarkUnavailable(now.Add(-time.Minute*5), now)
+	assert.False(t, onConflict(&silent))
+}
+
+func TestTrackAvailabilityUseCase_RepoErrors(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	logger := zerolog.Nop()
+	opts := trackavailability.UseCaseOptions{ServerLiveness: time.Minute * 3}
+
+	t.Run("filter", func(t *testing.T) {
+		serverRepo := new(MockServerRepository)
+		serverRepo.On("Filter", ctx, filterset.NewServerFilterSet()).
+			Return([]server.Server{}, errors.New("error"))
+
+		uc := trackavailability.New(serverRepo, opts, &logger)
+		_, err := uc.Execute(ctx, now)
+		require.ErrorIs(t, err, trackavailability.ErrUnableToObtainServers)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		gone := serverfactory.Build(
+			serverfactory.WithAddress("1.1.1.1", 10480),
+			serverfactory.WithDiscoveryStatus(ds.Master|ds.Info),
+			serverfactory.WithRefreshedAt(now),
+		)
+		broken := serverfactory.Build(
+			serverfactory.WithAddress("2.2.2.2", 10480),
+			serverfactory.WithDiscoveryStatus(ds.Master|ds.Info),
+			serverfactory.WithRefreshedAt(now),
+		)
+
+		serverRepo := new(MockServerRepository)
+		serverRepo.On("Filter", ctx, filterset.NewServerFilterSet()).
+			Return([]server.Server{gone, broken}, nil)
+		serverRepo.On("Update", ctx, mock.MatchedBy(func(s server.Server) bool {
+			return s.Addr == gone.Addr
+		}), mock.Anything).Return(server.Blank, repositories.ErrServerNotFound)
+		serverRepo.On("Update", ctx, mock.MatchedBy(func(s server.Server) bool {
+			return s.Addr == broken.Addr
+		}), mock.Anything).Return(server.Blank, errors.New("error"))
+
+		uc := trackavailability.New(serverRepo, opts, &logger)
+		resp, err := uc.Execute(ctx, now)
+		require.NoError(t, err)
+		assert.Equal(t, trackavailability.Response{Errors: 1}, resp)
+	})
+}
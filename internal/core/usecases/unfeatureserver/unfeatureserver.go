@@ -0,0 +1,38 @@
+package unfeatureserver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+)
+
+var (
+	ErrServerNotFeatured       = errors.New("server is not featured")
+	ErrUnableToUnfeatureServer = errors.New("unable to remove featured server from repository")
+)
+
+type UseCase struct {
+	featuredRepo repositories.FeaturedRepository
+}
+
+func New(
+	featuredRepo repositories.FeaturedRepository,
+) UseCase {
+	return UseCase{
+		featuredRepo: featuredRepo,
+	}
+}
+
+func (uc UseCase) Execute(ctx context.Context, publicAddr addr.PublicAddr) error {
+	if err := uc.featuredRepo.Remove(ctx, publicAddr.ToAddr()); err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrFeaturedNotFound):
+			return ErrServerNotFeatured
+		default:
+			return ErrUnableToUnfeatureServer
+		}
+	}
+	return nil
+}
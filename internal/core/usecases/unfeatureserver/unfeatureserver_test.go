@@ -0,0 +1,55 @@
+package unfeatureserver_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+	"github.com/sergeii/swat4master/internal/core/usecases/unfeatureserver"
+)
+
+type MockFeaturedRepository struct {
+	mock.Mock
+	repositories.FeaturedRepository
+}
+
+func (m *MockFeaturedRepository) Remove(ctx context.Context, svrAddr addr.Addr) error {
+	args := m.Called(ctx, svrAddr)
+	return args.Error(0)
+}
+
+func TestUnfeatureServerUseCase(t *testing.T) {
+	tests := []struct {
+		name    string
+		repoErr error
+		wantErr error
+	}{
+		{"positive case", nil, nil},
+		{"server is not featured", repositories.ErrFeaturedNotFound, unfeatureserver.ErrServerNotFeatured},
+		{"repository error", errors.New("error"), unfeatureserver.ErrUnableToUnfeatureServer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TODO()
+			svrAddr := addr.MustNewFromDotted("1.1.1.1", 10480)
+
+			repo := new(MockFeaturedRepository)
+			repo.On("Remove", ctx, svrAddr).Return(tt.repoErr)
+
+			uc := unfeatureserver.New(repo)
+			err := uc.Execute(ctx, addr.MustNewPublicAddr(svrAddr))
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			repo.AssertExpectations(t)
+		})
+	}
+}
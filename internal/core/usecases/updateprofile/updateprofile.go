@@ -0,0 +1,86 @@
+package updateprofile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jonboulle/clockwork"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/profile"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+)
+
+var (
+	ErrServerNotFound        = errors.New("server not found")
+	ErrNotOwner              = errors.New("the key does not belong to the server owner")
+	ErrUnableToObtainServer  = errors.New("unable to obtain server from repository")
+	ErrUnableToUpdateProfile = errors.New("unable to save profile to repository")
+)
+
+type UseCase struct {
+	serverRepo  repositories.ServerRepository
+	profileRepo repositories.ProfileRepository
+	clock       clockwork.Clock
+}
+
+func New(
+	serverRepo repositories.ServerRepository,
+	profileRepo repositories.ProfileRepository,
+	clock clockwork.Clock,
+) UseCase {
+	return UseCase{
+		serverRepo:  serverRepo,
+		profileRepo: profileRepo,
+		clock:       clock,
+	}
+}
+
+type Request struct {
+	addr        addr.PublicAddr
+	key         string
+	description string
+	website     string
+	discord     string
+	region      string
+}
+
+func NewRequest(
+	publicAddr addr.PublicAddr,
+	key string,
+	description, website, discord, region string,
+) Request {
+	return Request{
+		addr:        publicAddr,
+		key:         key,
+		description: description,
+		website:     website,
+		discord:     discord,
+		region:      region,
+	}
+}
+
+// Execute replaces the profile of the server, provided the key belongs to the server's verified owner
+func (uc UseCase) Execute(ctx context.Context, req Request) (profile.Profile, error) {
+	svr, err := uc.serverRepo.Get(ctx, req.addr.ToAddr())
+	if err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrServerNotFound):
+			return profile.Blank, ErrServerNotFound
+		default:
+			return profile.Blank, fmt.Errorf("updateprofile: repo: %w", ErrUnableToObtainServer)
+		}
+	}
+
+	if !svr.Ownership.Authorize(req.key) {
+		return profile.Blank, ErrNotOwner
+	}
+
+	item := profile.New(svr.Addr, req.description, req.website, req.discord, req.region, uc.clock.Now())
+	if err := uc.profileRepo.Save(ctx, item); err != nil {
+		return profile.Blank, fmt.Errorf("updateprofile: repo: %w", ErrUnableToUpdateProfile)
+	}
+
+	return item, nil
+}
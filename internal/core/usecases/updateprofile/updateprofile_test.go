@@ -0,0 +1,142 @@
+package updateprofile_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/internal/core/entities/profile"
+	"github.com/sergeii/swat4master/internal/core/entities/server"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+	"github.com/sergeii/swat4master/internal/core/usecases/updateprofile"
+	"github.com/sergeii/swat4master/internal/testutils/factories/serverfactory"
+)
+
+type MockServerRepository struct {
+	mock.Mock
+	repositories.ServerRepository
+}
+
+func (m *MockServerRepository) Get(ctx context.Context, addr addr.Addr) (server.Server, error) {
+	args := m.Called(ctx, addr)
+	return args.Get(0).(server.Server), args.Error(1) //nolint: forcetypeassert
+}
+
+type MockProfileRepository struct {
+	mock.Mock
+	repositories.ProfileRepository
+}
+
+func (m *MockProfileRepository) Save(ctx context.Context, item profile.Profile) error {
+	args := m.Called(ctx, item)
+	return args.Error(0)
+}
+
+func buildOwnedServer(key string) server.Server {
+	svr := serverfactory.Build(serverfactory.WithDiscoveryStatus(ds.Info | ds.Details))
+	svr.Ownership = server.Ownership{
+		KeyHash:    server.HashOwnerKey(key),
+		VerifiedAt: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+	return svr
+}
+
+func TestUpdateProfileUseCase_OK(t *testing.T) {
+	ctx := context.TODO()
+	clock := clockwork.NewFakeClock()
+
+	svr := buildOwnedServer("secret")
+	want := profile.New(svr.Addr, "Best server", "https://example.com", "", "EU", clock.Now())
+
+	serverRepo := new(MockServerRepository)
+	serverRepo.On("Get", ctx, svr.Addr).Return(svr, nil)
+	profileRepo := new(MockProfileRepository)
+	profileRepo.On("Save", ctx, want).Return(nil)
+
+	uc := updateprofile.New(serverRepo, profileRepo, clock)
+	req := updateprofile.NewRequest(
+		addr.MustNewPublicAddr(svr.Addr), "secret", "Best server", "https://example.com", "", "EU",
+	)
+	got, err := uc.Execute(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	profileRepo.AssertExpectations(t)
+}
+
+func TestUpdateProfileUseCase_NotOwner(t *testing.T) {
+	ctx := context.TODO()
+	clock := clockwork.NewFakeClock()
+
+	unclaimed := serverfactory.Build(serverfactory.WithDiscoveryStatus(ds.Info | ds.Details))
+	pending := serverfactory.Build(serverfactory.WithDiscoveryStatus(ds.Info | ds.Details))
+	pending.ClaimOwnership(server.Claim{
+		Token:     "s4m-1a2b3c4d",
+		KeyHash:   server.HashOwnerKey("secret"),
+		ExpiresAt: clock.Now().Add(time.Hour),
+	}, clock.Now())
+
+	tests := []struct {
+		name string
+		svr  server.Server
+		key  string
+	}{
+		{"unclaimed server", unclaimed, "secret"},
+		{"claim is not confirmed", pending, "secret"},
+		{"wrong key", buildOwnedServer("secret"), "other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serverRepo := new(MockServerRepository)
+			serverRepo.On("Get", ctx, tt.svr.Addr).Return(tt.svr, nil)
+			profileRepo := new(MockProfileRepository)
+
+			uc := updateprofile.New(serverRepo, profileRepo, clock)
+			req := updateprofile.NewRequest(addr.MustNewPublicAddr(tt.svr.Addr), tt.key, "Hacked", "", "", "")
+			_, err := uc.Execute(ctx, req)
+			require.ErrorIs(t, err, updateprofile.ErrNotOwner)
+
+			profileRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestUpdateProfileUseCase_RepoErrors(t *testing.T) {
+	ctx := context.TODO()
+	clock := clockwork.NewFakeClock()
+	svr := buildOwnedServer("secret")
+
+	tests := []struct {
+		name    string
+		getErr  error
+		saveErr error
+		wantErr error
+	}{
+		{"server not found", repositories.ErrServerNotFound, nil, updateprofile.ErrServerNotFound},
+		{"get error", errors.New("error"), nil, updateprofile.ErrUnableToObtainServer},
+		{"save error", nil, errors.New("error"), updateprofile.ErrUnableToUpdateProfile},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serverRepo := new(MockServerRepository)
+			serverRepo.On("Get", ctx, svr.Addr).Return(svr, tt.getErr)
+			profileRepo := new(MockProfileRepository)
+			profileRepo.On("Save", ctx, mock.Anything).Return(tt.saveErr)
+
+			uc := updateprofile.New(serverRepo, profileRepo, clock)
+			req := updateprofile.NewRequest(addr.MustNewPublicAddr(svr.Addr), "secret", "", "", "", "")
+			_, err := uc.Execute(ctx, req)
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package federation
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/sergeii/swat4master/internal/browser"
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/internal/core/usecases/addserver"
+	"github.com/sergeii/swat4master/internal/metrics"
+	"github.com/sergeii/swat4master/pkg/gamespy/browsing/client"
+)
+
+const GameName = "swat4"
+
+var ErrInvalidServerEntry = errors.New("invalid server entry")
+
+type Opts struct {
+	Upstreams []string
+	Timeout   time.Duration
+}
+
+type Federator struct {
+	opts    Opts
+	uc      addserver.UseCase
+	metrics *metrics.Collector
+	logger  *zerolog.Logger
+	request client.Request
+}
+
+func New(
+	opts Opts,
+	uc addserver.UseCase,
+	metrics *metrics.Collector,
+	logger *zerolog.Logger,
+) *Federator {
+	f := &Federator{
+		opts:    opts,
+		uc:      uc,
+		metrics: metrics,
+		logger:  logger,
+		request: client.Request{
+			GameName: GameName,
+			// the hostname is not needed for discovery, but it makes logs easier to follow
+			Fields: []string{"hostname", "hostport"},
+		},
+	}
+	copy(f.request.GameKey[:], browser.GameEncKey)
+	return f
+}
+
+// Federate queries the configured upstream masters concurrently
+// and submits the servers listed by them for discovery.
+// Servers unknown to this master are tagged with the ds.Federated status.
+func (f *Federator) Federate(ctx context.Context) {
+	wg := &sync.WaitGroup{}
+	for _, upstream := range f.opts.Upstreams {
+		wg.Go(func() {
+			f.federateUpstream(ctx, upstream)
+		})
+	}
+	wg.Wait()
+}
+
+func (f *Federator) federateUpstream(ctx context.Context, upstream string) {
+	reqStarted := time.Now()
+
+	f.metrics.FederationRequests.WithLabelValues(upstream).Inc()
+
+	servers, err := client.Query(ctx, upstream, f.request, f.opts.Timeout)
+	if err != nil {
+		f.metrics.FederationErrors.WithLabelValues(upstream).Inc()
+		f.logger.Warn().
+			Err(err).Str("upstream", upstream).Dur("timeout", f.opts.Timeout).
+			Msg("Failed to obtain server list from upstream master")
+		return
+	}
+
+	f.metrics.FederationDurations.WithLabelValues(upstream).Observe(time.Since(reqStarted).Seconds())
+	f.metrics.FederationServers.WithLabelValues(upstream).Add(float64(len(servers)))
+
+	imported := 0
+	for _, item := range servers {
+		if f.importServer(ctx, upstream, item) {
+			imported++
+		}
+	}
+
+	if imported > 0 {
+		f.metrics.FederationImported.WithLabelValues(upstream).Add(float64(imported))
+	}
+
+	f.logger.Info().
+		Str("upstream", upstream).Int("listed", len(servers)).Int("imported", imported).
+		Msg("Federated servers from upstream master")
+}
+
+func (f *Federator) importServer(ctx context.Context, upstream string, item client.Server) bool {
+	svrAddr, err := parseServerAddr(item)
+	if err != nil {
+		f.logger.Debug().
+			Err(err).Str("upstream", upstream).Stringer("addr", item.Addr).
+			Msg("Skipping invalid upstream server")
+		return false
+	}
+
+	_, err = f.uc.ExecuteWithSource(ctx, svrAddr, ds.Federated)
+	switch {
+	case err == nil, errors.Is(err, addserver.ErrServerDiscoveryInProgress):
+		return true
+	case errors.Is(err, addserver.ErrServerHasNoQueryablePort):
+		f.logger.Debug().
+			Str("upstream", upstream).Stringer("addr", svrAddr.ToAddr()).
+			Msg("Upstream server has no queryable port")
+		return false
+	default:
+		f.logger.Warn().
+			Err(err).Str("upstream", upstream).Stringer("addr", svrAddr.ToAddr()).
+			Msg("Failed to import upstream server")
+		return false
+	}
+}
+
+// parseServerAddr obtains the game address of a listed server.
+// Masters announce servers with their query ports,
+// so the game port is taken from the hostport field instead.
+func parseServerAddr(item client.Server) (addr.PublicAddr, error) {
+	hostPort, err := strconv.Atoi(item.Fields["hostport"])
+	if err != nil {
+		return addr.BlankPublicAddr, ErrInvalidServerEntry
+	}
+
+	ip := item.Addr.Addr().As4()
+	svrAddr, err := addr.New(net.IP(ip[:]), hostPort)
+	if err != nil {
+		return addr.BlankPublicAddr, err
+	}
+
+	return addr.NewPublicAddr(svrAddr)
+}
@@ -0,0 +1,136 @@
+package geoip
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+
+	"github.com/oschwald/maxminddb-golang/v2"
+
+	"github.com/sergeii/swat4master/internal/core/entities/location"
+)
+
+var ErrInvalidIP = errors.New("invalid IP address")
+
+// Locator resolves the location of an IP address
+type Locator interface {
+	Locate(ip net.IP) (location.Location, error)
+}
+
+type Config struct {
+	// CountryDB is the path to a MaxMind DB file with countries, such as GeoLite2-Country or GeoLite2-City.
+	// A city database also resolves the coordinates, so that the distances are estimated more precisely.
+	CountryDB string
+	// ASNDB is the path to a MaxMind DB file with autonomous systems, such as GeoLite2-ASN
+	ASNDB string
+}
+
+type countryRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+	Continent struct {
+		Code string `maxminddb:"code"`
+	} `maxminddb:"continent"`
+	// only the city databases have the coordinates
+	Location struct {
+		Latitude  *float64 `maxminddb:"latitude"`
+		Longitude *float64 `maxminddb:"longitude"`
+	} `maxminddb:"location"`
+}
+
+type asnRecord struct {
+	Number int `maxminddb:"autonomous_system_number"`
+}
+
+// Reader resolves locations offline using local MaxMind DB files.
+// Either of the databases is optional. With no databases configured,
+// the reader resolves nothing, so that the locations are left blank.
+type Reader struct {
+	country *maxminddb.Reader
+	asn     *maxminddb.Reader
+}
+
+func New(cfg Config) (*Reader, error) {
+	r := &Reader{}
+	if cfg.CountryDB != "" {
+		db, err := maxminddb.Open(cfg.CountryDB)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open country database %s: %w", cfg.CountryDB, err)
+		}
+		r.country = db
+	}
+	if cfg.ASNDB != "" {
+		db, err := maxminddb.Open(cfg.ASNDB)
+		if err != nil {
+			r.Close() //nolint: errcheck
+			return nil, fmt.Errorf("failed to open ASN database %s: %w", cfg.ASNDB, err)
+		}
+		r.asn = db
+	}
+	return r, nil
+}
+
+// IsEnabled tells whether the reader has any database to resolve locations with
+func (r *Reader) IsEnabled() bool {
+	return r.country != nil || r.asn != nil
+}
+
+// Locate resolves the location of the address.
+// An address that is missing from the databases is resolved to a location with no country,
+// so that it's not looked up again until the address changes.
+// With no databases configured, the blank location is returned.
+func (r *Reader) Locate(ip net.IP) (location.Location, error) {
+	if !r.IsEnabled() {
+		return location.Blank, nil
+	}
+
+	ipAddr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return location.Blank, ErrInvalidIP
+	}
+	ipAddr = ipAddr.Unmap()
+
+	loc := location.New(ip, "", "", 0)
+
+	if r.country != nil {
+		var rec countryRecord
+		if err := r.country.Lookup(ipAddr).Decode(&rec); err != nil {
+			return location.Blank, fmt.Errorf("failed to look up country for %s: %w", ip, err)
+		}
+		loc.Country = rec.Country.ISOCode
+		// anycast and satellite addresses have no physical country
+		if loc.Country == "" {
+			loc.Country = rec.RegisteredCountry.ISOCode
+		}
+		loc.Continent = rec.Continent.Code
+		if rec.Location.Latitude != nil && rec.Location.Longitude != nil {
+			loc = loc.WithCoordinates(*rec.Location.Latitude, *rec.Location.Longitude)
+		}
+	}
+
+	if r.asn != nil {
+		var rec asnRecord
+		if err := r.asn.Lookup(ipAddr).Decode(&rec); err != nil {
+			return location.Blank, fmt.Errorf("failed to look up ASN for %s: %w", ip, err)
+		}
+		loc.ASN = rec.Number
+	}
+
+	return loc, nil
+}
+
+func (r *Reader) Close() error {
+	var errs []error
+	if r.country != nil {
+		errs = append(errs, r.country.Close())
+	}
+	if r.asn != nil {
+		errs = append(errs, r.asn.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,266 @@
+package geoip_test
+
+import (
+	"encoding/binary"
+	"math"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/location"
+	"github.com/sergeii/swat4master/internal/geoip"
+)
+
+const (
+	mmdbTypeString = 2
+	mmdbTypeDouble = 3
+	mmdbTypeUint16 = 5
+	mmdbTypeUint32 = 6
+	mmdbTypeMap    = 7
+)
+
+// encodeMMDBValue encodes the value in the MaxMind DB data format.
+// Only the types used by the city, the country and the ASN databases are supported.
+func encodeMMDBValue(value any) []byte {
+	control := func(typ, size int) []byte {
+		if size < 29 {
+			return []byte{byte(typ<<5 | size)}
+		}
+		return []byte{byte(typ<<5 | 29), byte(size - 29)}
+	}
+	switch v := value.(type) {
+	case string:
+		return append(control(mmdbTypeString, len(v)), v...)
+	case float64:
+		buf := binary.BigEndian.AppendUint64(nil, math.Float64bits(v))
+		return append(control(mmdbTypeDouble, len(buf)), buf...)
+	case uint16:
+		buf := binary.BigEndian.AppendUint16(nil, v)
+		return append(control(mmdbTypeUint16, len(buf)), buf...)
+	case int:
+		buf := binary.BigEndian.AppendUint32(nil, uint32(v)) //nolint: gosec
+		return append(control(mmdbTypeUint32, len(buf)), buf...)
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		encoded := control(mmdbTypeMap, len(v))
+		for _, key := range keys {
+			encoded = append(encoded, encodeMMDBValue(key)...)
+			encoded = append(encoded, encodeMMDBValue(v[key])...)
+		}
+		return encoded
+	default:
+		panic("unsupported value type")
+	}
+}
+
+// writeTestDB writes an IPv4 MaxMind DB file with the records assigned to the networks
+func writeTestDB(t *testing.T, records map[string]map[string]any) string {
+	t.Helper()
+
+	const emptyRecord = -1
+	// a record either points to another node, is empty, or points to the data
+	type record struct {
+		node int
+		data int
+	}
+	nodes := [][2]record{{{node: emptyRecord}, {node: emptyRecord}}}
+	data := make([]byte, 0)
+
+	for network, rec := range records {
+		prefix := netip.MustParsePrefix(network)
+		ipBits := binary.BigEndian.Uint32(prefix.Addr().AsSlice())
+		offset := len(data)
+		data = append(data, encodeMMDBValue(rec)...)
+		node := 0
+		for i := range prefix.Bits() {
+			bit := (ipBits >> (31 - i)) & 1
+			if i == prefix.Bits()-1 {
+				nodes[node][bit] = record{node: emptyRecord, data: offset + 1}
+				break
+			}
+			if nodes[node][bit].node == emptyRecord {
+				nodes = append(nodes, [2]record{{node: emptyRecord}, {node: emptyRecord}})
+				nodes[node][bit] = record{node: len(nodes) - 1}
+			}
+			node = nodes[node][bit].node
+		}
+	}
+
+	nodeCount := len(nodes)
+	encoded := make([]byte, 0)
+	for _, pair := range nodes {
+		for _, rec := range pair {
+			var value int
+			switch {
+			case rec.data > 0:
+				value = nodeCount + 16 + rec.data - 1
+			case rec.node == emptyRecord:
+				value = nodeCount
+			default:
+				value = rec.node
+			}
+			encoded = binary.BigEndian.AppendUint32(encoded, uint32(value)) //nolint: gosec
+		}
+	}
+	encoded = append(encoded, make([]byte, 16)...)
+	encoded = append(encoded, data...)
+	encoded = append(encoded, "\xab\xcd\xefMaxMind.com"...)
+	encoded = append(encoded, encodeMMDBValue(map[string]any{
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+		"database_type":               "Test",
+		"ip_version":                  uint16(4),
+		"node_count":                  nodeCount,
+		"record_size":                 uint16(32),
+	})...)
+
+	path := filepath.Join(t.TempDir(), "test.mmdb")
+	require.NoError(t, os.WriteFile(path, encoded, 0o600))
+
+	return path
+}
+
+func prepareTestDBs(t *testing.T) (string, string) {
+	countryDB := writeTestDB(t, map[string]map[string]any{
+		"1.1.1.0/24": {
+			"country":   map[string]any{"iso_code": "AU"},
+			"continent": map[string]any{"code": "OC"},
+		},
+		"81.0.0.0/8": {
+			"country":   map[string]any{"iso_code": "DE"},
+			"continent": map[string]any{"code": "EU"},
+		},
+		"5.9.0.0/16": {
+			"country":   map[string]any{"iso_code": "DE"},
+			"continent": map[string]any{"code": "EU"},
+			"location":  map[string]any{"latitude": 50.4777, "longitude": 12.3649},
+		},
+		"9.9.9.9/32": {
+			"registered_country": map[string]any{"iso_code": "US"},
+			"continent":          map[string]any{"code": "NA"},
+		},
+	})
+	asnDB := writeTestDB(t, map[string]map[string]any{
+		"1.1.1.0/24": {
+			"autonomous_system_number":       13335,
+			"autonomous_system_organization": "CLOUDFLARENET",
+		},
+		"81.19.0.0/16": {
+			"autonomous_system_number":       24940,
+			"autonomous_system_organization": "Hetzner Online GmbH",
+		},
+	})
+	return countryDB, asnDB
+}
+
+func TestReader_Locate_OK(t *testing.T) {
+	countryDB, asnDB := prepareTestDBs(t)
+
+	reader, err := geoip.New(geoip.Config{CountryDB: countryDB, ASNDB: asnDB})
+	require.NoError(t, err)
+	defer reader.Close() //nolint: errcheck
+
+	tests := []struct {
+		name string
+		ip   string
+		want location.Location
+	}{
+		{
+			"country and asn",
+			"1.1.1.1",
+			location.New(net.ParseIP("1.1.1.1"), "AU", "OC", 13335),
+		},
+		{
+			"asn of a narrower network",
+			"81.19.1.1",
+			location.New(net.ParseIP("81.19.1.1"), "DE", "EU", 24940),
+		},
+		{
+			"no asn",
+			"81.20.1.1",
+			location.New(net.ParseIP("81.20.1.1"), "DE", "EU", 0),
+		},
+		{
+			"city coordinates",
+			"5.9.1.1",
+			location.New(net.ParseIP("5.9.1.1"), "DE", "EU", 0).WithCoordinates(50.4777, 12.3649),
+		},
+		{
+			"registered country",
+			"9.9.9.9",
+			location.New(net.ParseIP("9.9.9.9"), "US", "NA", 0),
+		},
+		{
+			"unknown address",
+			"2.2.2.2",
+			location.New(net.ParseIP("2.2.2.2"), "", "", 0),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := reader.Locate(net.ParseIP(tt.ip))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, loc)
+			assert.True(t, loc.IsResolvedFor(net.ParseIP(tt.ip)))
+		})
+	}
+}
+
+func TestReader_Locate_CountryOnly(t *testing.T) {
+	countryDB, _ := prepareTestDBs(t)
+
+	reader, err := geoip.New(geoip.Config{CountryDB: countryDB})
+	require.NoError(t, err)
+	defer reader.Close() //nolint: errcheck
+
+	loc, err := reader.Locate(net.ParseIP("1.1.1.1"))
+	require.NoError(t, err)
+	assert.Equal(t, location.New(net.ParseIP("1.1.1.1"), "AU", "OC", 0), loc)
+}
+
+func TestReader_Locate_NoDatabases(t *testing.T) {
+	reader, err := geoip.New(geoip.Config{})
+	require.NoError(t, err)
+	defer reader.Close() //nolint: errcheck
+
+	assert.False(t, reader.IsEnabled())
+
+	loc, err := reader.Locate(net.ParseIP("1.1.1.1"))
+	require.NoError(t, err)
+	assert.Equal(t, location.Blank, loc)
+	assert.False(t, loc.IsResolvedFor(net.ParseIP("1.1.1.1")))
+}
+
+func TestReader_Locate_InvalidIP(t *testing.T) {
+	countryDB, _ := prepareTestDBs(t)
+
+	reader, err := geoip.New(geoip.Config{CountryDB: countryDB})
+	require.NoError(t, err)
+	defer reader.Close() //nolint: errcheck
+
+	_, err = reader.Locate(net.IP{1, 2, 3})
+	require.ErrorIs(t, err, geoip.ErrInvalidIP)
+}
+
+func TestNew_InvalidDatabase(t *testing.T) {
+	countryDB, _ := prepareTestDBs(t)
+
+	invalid := filepath.Join(t.TempDir(), "invalid.mmdb")
+	require.NoError(t, os.WriteFile(invalid, []byte("foo"), 0o600))
+
+	_, err := geoip.New(geoip.Config{CountryDB: filepath.Join(t.TempDir(), "missing.mmdb")})
+	require.Error(t, err)
+
+	_, err = geoip.New(geoip.Config{CountryDB: countryDB, ASNDB: invalid})
+	require.ErrorContains(t, err, "failed to open ASN database")
+}
@@ -19,16 +19,29 @@ type Collector struct {
 	ReporterReceived  prometheus.Counter
 	ReporterSent      prometheus.Counter
 	ReporterRemovals  prometheus.Counter
+	ReporterRelayed   prometheus.Counter
 	ReporterDurations *prometheus.HistogramVec
+	RelayForwarded    *prometheus.CounterVec
+	RelayDropped      *prometheus.CounterVec
+	RelayErrors       *prometheus.CounterVec
+	RelayReplies      *prometheus.CounterVec
 	BrowserRequests   prometheus.Counter
 	BrowserErrors     prometheus.Counter
 	BrowserReceived   prometheus.Counter
 	BrowserSent       prometheus.Counter
 	BrowserDurations  prometheus.Histogram
+	BrowserListed     *prometheus.CounterVec
 
 	CleanerRemovals *prometheus.CounterVec
 	CleanerErrors   *prometheus.CounterVec
 
+	SchedulerRuns      *prometheus.CounterVec
+	SchedulerSkipped   *prometheus.CounterVec
+	SchedulerDurations *prometheus.HistogramVec
+
+	DiscoveryWorkers          prometheus.Gauge
+	DiscoveryWorkersTarget    prometheus.Gauge
+	DiscoveryWorkersScaling   *prometheus.CounterVec
 	DiscoveryWorkersBusy      prometheus.Gauge
 	DiscoveryWorkersAvailable prometheus.Gauge
 	DiscoveryQueueProduced    prometheus.Counter
@@ -42,6 +55,19 @@ type Collector struct {
 	DiscoveryProbeErrors      *prometheus.CounterVec
 	DiscoveryProbeDurations   *prometheus.HistogramVec
 	DiscoveryQueryDurations   prometheus.Histogram
+	DiscoveryQueryProtocols   *prometheus.CounterVec
+	DiscoveryPortOffsetProbes *prometheus.CounterVec
+	DiscoveryPortOffsetHits   *prometheus.CounterVec
+	DiscoverySessionEvents    *prometheus.CounterVec
+	DiscoverySessionErrors    prometheus.Counter
+	DiscoveryMatches          prometheus.Counter
+	DiscoveryMatchErrors      prometheus.Counter
+
+	FederationRequests  *prometheus.CounterVec
+	FederationErrors    *prometheus.CounterVec
+	FederationServers   *prometheus.CounterVec
+	FederationImported  *prometheus.CounterVec
+	FederationDurations *prometheus.HistogramVec
 
 	ServerRepositorySize   prometheus.Gauge
 	InstanceRepositorySize prometheus.Gauge
@@ -81,10 +107,30 @@ func New() *Collector {
 			Name: "reporter_removals_total",
 			Help: "The total number of removals requests accepted by reporter",
 		}),
+		ReporterRelayed: promauto.With(registry).NewCounter(prometheus.CounterOpts{
+			Name: "reporter_relayed_total",
+			Help: "The total number of requests relayed to reporter by peer masters",
+		}),
 		ReporterDurations: promauto.With(registry).NewHistogramVec(prometheus.HistogramOpts{
 			Name: "reporter_duration_seconds",
 			Help: "Duration of reporting requests",
 		}, []string{"type"}),
+		RelayForwarded: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Name: "relay_forwarded_total",
+			Help: "The total number of reporting requests forwarded to peer masters",
+		}, []string{"peer"}),
+		RelayDropped: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Name: "relay_dropped_total",
+			Help: "The total number of reporting requests not forwarded to peer masters due to backpressure or backoff",
+		}, []string{"peer"}),
+		RelayErrors: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Name: "relay_errors_total",
+			Help: "The total number of errors occurred while forwarding reporting requests to peer masters",
+		}, []string{"peer"}),
+		RelayReplies: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Name: "relay_replies_total",
+			Help: "The total number of replies received from peer masters to forwarded reporting requests",
+		}, []string{"peer"}),
 		BrowserRequests: promauto.With(registry).NewCounter(prometheus.CounterOpts{
 			Name: "browser_requests_total",
 			Help: "The total number server browsing requests",
@@ -105,6 +151,10 @@ func New() *Collector {
 			Name: "browser_duration_seconds",
 			Help: "Duration of server browsing requests",
 		}),
+		BrowserListed: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Name: "browser_listed_servers_total",
+			Help: "The total number of servers included in server browsing responses",
+		}, []string{"admission"}),
 		CleanerRemovals: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
 			Name: "cleaner_removals_total",
 			Help: "The total number of inactive servers removed",
@@ -113,6 +163,30 @@ func New() *Collector {
 			Name: "cleaner_errors_total",
 			Help: "The total number of errors occurred during cleaner runs",
 		}, []string{"kind"}),
+		SchedulerRuns: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Name: "scheduler_runs_total",
+			Help: "The total number of scheduled job runs",
+		}, []string{"job", "outcome"}),
+		SchedulerSkipped: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Name: "scheduler_skipped_total",
+			Help: "The total number of due job runs left to another replica",
+		}, []string{"job"}),
+		SchedulerDurations: promauto.With(registry).NewHistogramVec(prometheus.HistogramOpts{
+			Name: "scheduler_duration_seconds",
+			Help: "Duration of scheduled job runs",
+		}, []string{"job"}),
+		DiscoveryWorkers: promauto.With(registry).NewGauge(prometheus.GaugeOpts{
+			Name: "discovery_workers",
+			Help: "The current number of discovery workers",
+		}),
+		DiscoveryWorkersTarget: promauto.With(registry).NewGauge(prometheus.GaugeOpts{
+			Name: "discovery_workers_target",
+			Help: "The number of discovery workers decided upon by the latest scaling decision",
+		}),
+		DiscoveryWorkersScaling: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Name: "discovery_workers_scaling_total",
+			Help: "The total number of times discovery workers have been scaled up or down",
+		}, []string{"direction"}),
 		DiscoveryWorkersBusy: promauto.With(registry).NewGauge(prometheus.GaugeOpts{
 			Name: "discovery_busy_workers",
 			Help: "The total number of busy discovery workers",
@@ -143,8 +217,20 @@ func New() *Collector {
 		}, []string{"goal"}),
 		DiscoveryQueryDurations: promauto.With(registry).NewHistogram(prometheus.HistogramOpts{
 			Name: "discovery_query_duration_seconds",
-			Help: "Duration of probe gs1 queries",
+			Help: "Duration of probe server queries",
 		}),
+		DiscoveryQueryProtocols: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Name: "discovery_query_protocols_total",
+			Help: "The total number of successful probe queries by the protocol the server has answered with",
+		}, []string{"protocol"}),
+		DiscoveryPortOffsetProbes: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Name: "discovery_port_offset_probes_total",
+			Help: "The total number of query ports probed during port discovery, by offset from the game port",
+		}, []string{"offset"}),
+		DiscoveryPortOffsetHits: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Name: "discovery_port_offset_hits_total",
+			Help: "The total number of query ports that have answered during port discovery, by offset from the game port",
+		}, []string{"offset"}),
 		DiscoveryProbes: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
 			Name: "discovery_probes_total",
 			Help: "The total number of performed discovery probes",
@@ -165,6 +251,42 @@ func New() *Collector {
 			Name: "discovery_probe_errors_total",
 			Help: "The total number of unexpected errors occurred during a discovery probe",
 		}, []string{"goal"}),
+		DiscoverySessionEvents: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Name: "discovery_session_events_total",
+			Help: "The total number of player joins, leaves and team changes observed on the probed servers",
+		}, []string{"kind"}),
+		DiscoverySessionErrors: promauto.With(registry).NewCounter(prometheus.CounterOpts{
+			Name: "discovery_session_errors_total",
+			Help: "The total number of errors occurred while tracking player sessions",
+		}),
+		DiscoveryMatches: promauto.With(registry).NewCounter(prometheus.CounterOpts{
+			Name: "discovery_matches_total",
+			Help: "The total number of match results recorded on the probed servers",
+		}),
+		DiscoveryMatchErrors: promauto.With(registry).NewCounter(prometheus.CounterOpts{
+			Name: "discovery_match_errors_total",
+			Help: "The total number of errors occurred while recording match results",
+		}),
+		FederationRequests: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Name: "federation_requests_total",
+			Help: "The total number of server list requests sent to upstream masters",
+		}, []string{"upstream"}),
+		FederationErrors: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Name: "federation_errors_total",
+			Help: "The total number of failed server list requests sent to upstream masters",
+		}, []string{"upstream"}),
+		FederationServers: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Name: "federation_servers_total",
+			Help: "The total number of servers listed by upstream masters",
+		}, []string{"upstream"}),
+		FederationImported: promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+			Name: "federation_imported_total",
+			Help: "The total number of upstream servers submitted for discovery",
+		}, []string{"upstream"}),
+		FederationDurations: promauto.With(registry).NewHistogramVec(prometheus.HistogramOpts{
+			Name: "federation_duration_seconds",
+			Help: "Duration of server list requests sent to upstream masters",
+		}, []string{"upstream"}),
 		ServerRepositorySize: promauto.With(registry).NewGauge(prometheus.GaugeOpts{
 			Name: "repo_servers_size",
 			Help: "The number of servers stored in the repository",
@@ -207,8 +329,13 @@ func (c *Collector) AddObserver(observer Observer) {
 	c.observers = append(c.observers, observer)
 }
 
+// Observe runs the observers concurrently and waits for all of them to complete
 func (c *Collector) Observe(ctx context.Context) {
+	wg := &sync.WaitGroup{}
 	for _, observer := range c.observers {
-		go observer.Observe(ctx, c)
+		wg.Go(func() {
+			observer.Observe(ctx, c)
+		})
 	}
+	wg.Wait()
 }
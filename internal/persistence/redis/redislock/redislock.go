@@ -25,29 +25,63 @@ func NewManager(client *redis.Client, logger *zerolog.Logger) *Manager {
 	}
 }
 
-func (m *Manager) Guard(ctx context.Context, key string, ttl time.Duration, op func(tx *redis.Tx) error) error {
+// Lease is the lock held until it is released or its time is up.
+// Unlike Guard, the lease keeps no transaction open while the lock is held,
+// so that the holder is free to do lengthy work under the lock.
+type Lease struct {
+	manager *Manager
+	key     string
+	token   string
+}
+
+// Acquire takes the lock for the given time
+func (m *Manager) Acquire(ctx context.Context, key string, ttl time.Duration) (*Lease, error) {
 	token := uuid.NewString()
 
 	_, err := m.client.SetArgs(ctx, key, token, redis.SetArgs{Mode: "NX", TTL: ttl}).Result()
+	// the lock is normally contended for, e.g. by the replicas competing for a scheduled job
 	if errors.Is(err, redis.Nil) {
-		m.logger.Info().Str("key", key).Msg("lock not acquired")
-		return ErrNotAcquired
+		m.logger.Debug().Str("key", key).Msg("lock not acquired")
+		return nil, ErrNotAcquired
 	}
 	if err != nil {
 		m.logger.Error().Err(err).Str("key", key).Msg("failed to take lock ownership")
-		return fmt.Errorf("guard: take lock ownership: %w", err)
+		return nil, fmt.Errorf("acquire: take lock ownership: %w", err)
 	}
-	defer func() {
-		if err := m.release(ctx, key, token); err != nil {
-			// The lock ownership has been lost while trying to release it
-			// Because that's what we wanted in the first place, this is absolutely acceptable
-			if !errors.Is(err, redis.TxFailedErr) {
-				m.logger.Warn().Str("key", key).Msg("lock ownership lost while releasing")
-			} else {
-				m.logger.Error().Err(err).Str("key", key).Msg("failed to release lock")
-			}
-		}
-	}()
+
+	return &Lease{
+		manager: m,
+		key:     key,
+		token:   token,
+	}, nil
+}
+
+// Release gives up the lock, unless the lease has expired and the lock has been taken by someone else
+func (l *Lease) Release(ctx context.Context) error {
+	err := l.manager.release(ctx, l.key, l.token)
+	// the lease has expired, and no one has taken the lock since
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	// the lock has changed hands while releasing it, which is as good as releasing it
+	if errors.Is(err, redis.TxFailedErr) {
+		l.manager.logger.Warn().Str("key", l.key).Msg("lock ownership lost while releasing")
+		return nil
+	}
+	if err != nil {
+		l.manager.logger.Error().Err(err).Str("key", l.key).Msg("failed to release lock")
+		return fmt.Errorf("lease: %w", err)
+	}
+	return nil
+}
+
+func (m *Manager) Guard(ctx context.Context, key string, ttl time.Duration, op func(tx *redis.Tx) error) error {
+	lease, err := m.Acquire(ctx, key, ttl)
+	if err != nil {
+		return err
+	}
+	// release the lock even if the operation has run out of time, so that it is not held until the lease expires
+	defer lease.Release(context.WithoutCancel(ctx)) //nolint: errcheck
 
 	// Make sure we own the lock for the entire duration of the operation
 	err = m.client.Watch(ctx, func(tx *redis.Tx) error {
@@ -58,7 +92,7 @@ func (m *Manager) Guard(ctx context.Context, key string, ttl time.Duration, op f
 			m.logger.Error().Err(err).Str("key", key).Msg("failed to check lock ownership")
 			return fmt.Errorf("guard: check lock ownership: %w", err)
 		}
-		if currToken != token {
+		if currToken != lease.token {
 			return ErrNotAcquired
 		}
 		return op(tx)
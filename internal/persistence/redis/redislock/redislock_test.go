@@ -264,3 +264,75 @@ func TestRedisLockManager_Guard_LockOwnershipLostAndReclaimed(t *testing.T) {
 	err = rdb.Get(ctx, "lock:foo").Err()
 	require.ErrorIs(t, err, redis.Nil)
 }
+
+func TestRedisLockManager_Guard_ReleasedOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	rdb := testredis.MakeClient(t)
+	logger := zerolog.Nop()
+
+	m := redislock.NewManager(rdb, &logger)
+
+	// the operation runs out of time while holding the lock
+	err := m.Guard(ctx, "lock:foo", time.Minute, func(*redis.Tx) error {
+		cancel()
+		return ctx.Err()
+	})
+	require.ErrorIs(t, err, context.Canceled)
+
+	// the lock is released nevertheless
+	err = rdb.Get(context.TODO(), "lock:foo").Err()
+	require.ErrorIs(t, err, redis.Nil)
+}
+
+func TestRedisLockManager_Acquire_OK(t *testing.T) {
+	ctx := context.TODO()
+	logger := zerolog.Nop()
+	mr := miniredis.RunT(t)
+	rdb := testredis.MakeClientFromMini(t, mr)
+
+	m := redislock.NewManager(rdb, &logger)
+
+	lease, err := m.Acquire(ctx, "lock:foo", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, mr.TTL("lock:foo"))
+
+	// the lock is held until released
+	_, err = m.Acquire(ctx, "lock:foo", time.Minute)
+	require.ErrorIs(t, err, redislock.ErrNotAcquired)
+
+	require.NoError(t, lease.Release(ctx))
+	assert.False(t, mr.Exists("lock:foo"))
+
+	// the lock can be taken again
+	another, err := m.Acquire(ctx, "lock:foo", time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, another.Release(ctx))
+}
+
+func TestRedisLockManager_Acquire_Expired(t *testing.T) {
+	ctx := context.TODO()
+	logger := zerolog.Nop()
+	mr := miniredis.RunT(t)
+	rdb := testredis.MakeClientFromMini(t, mr)
+
+	m := redislock.NewManager(rdb, &logger)
+
+	lease, err := m.Acquire(ctx, "lock:foo", time.Millisecond*50)
+	require.NoError(t, err)
+
+	// the lease has expired, so the lock is free to take
+	mr.FastForward(time.Millisecond * 100)
+	// releasing the expired lease is no error
+	require.NoError(t, lease.Release(ctx))
+
+	mr.FastForward(time.Millisecond * 100)
+	other, err := m.Acquire(ctx, "lock:foo", time.Minute)
+	require.NoError(t, err)
+
+	// the expired lease does not release the lock taken by someone else
+	require.NoError(t, lease.Release(ctx))
+	assert.True(t, mr.Exists("lock:foo"))
+
+	require.NoError(t, other.Release(ctx))
+	assert.False(t, mr.Exists("lock:foo"))
+}
@@ -0,0 +1,84 @@
+package featuredservers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/featured"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+)
+
+const itemsKey = "featured:items"
+
+type Repository struct {
+	client *redis.Client
+}
+
+func New(client *redis.Client) *Repository {
+	return &Repository{
+		client: client,
+	}
+}
+
+// Add adds the server to the featured list or replaces the existing entry
+func (r *Repository) Add(ctx context.Context, item featured.Featured) error {
+	encoded, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("failed to marshal featured item: %w", err)
+	}
+	if err = r.client.HSet(ctx, itemsKey, item.Addr.String(), encoded).Err(); err != nil {
+		return fmt.Errorf("failed to add featured item: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) Get(ctx context.Context, svrAddr addr.Addr) (featured.Featured, error) {
+	item, err := r.client.HGet(ctx, itemsKey, svrAddr.String()).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return featured.Blank, repositories.ErrFeaturedNotFound
+		}
+		return featured.Blank, fmt.Errorf("failed to retrieve featured item: %w", err)
+	}
+	return decodeFeatured(item)
+}
+
+func (r *Repository) Remove(ctx context.Context, svrAddr addr.Addr) error {
+	removed, err := r.client.HDel(ctx, itemsKey, svrAddr.String()).Result()
+	if err != nil {
+		return fmt.Errorf("failed to remove featured item: %w", err)
+	}
+	if removed == 0 {
+		return repositories.ErrFeaturedNotFound
+	}
+	return nil
+}
+
+func (r *Repository) List(ctx context.Context) ([]featured.Featured, error) {
+	items, err := r.client.HVals(ctx, itemsKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list featured items: %w", err)
+	}
+	result := make([]featured.Featured, 0, len(items))
+	for _, item := range items {
+		decoded, decodeErr := decodeFeatured(item)
+		if decodeErr != nil {
+			return nil, decodeErr
+		}
+		result = append(result, decoded)
+	}
+	return result, nil
+}
+
+func decodeFeatured(encoded string) (featured.Featured, error) {
+	var decoded featured.Featured
+	if err := json.Unmarshal([]byte(encoded), &decoded); err != nil {
+		return featured.Blank, fmt.Errorf("failed to unmarshal featured item: %w", err)
+	}
+	return decoded, nil
+}
@@ -0,0 +1,80 @@
+package featuredservers_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/featured"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+	"github.com/sergeii/swat4master/internal/persistence/redis/repositories/featuredservers"
+	tu "github.com/sergeii/swat4master/internal/testutils"
+	"github.com/sergeii/swat4master/internal/testutils/testredis"
+)
+
+func TestFeaturedRedisRepo_AddGetList(t *testing.T) {
+	ctx := context.TODO()
+	rdb := testredis.MakeClient(t)
+	repo := featuredservers.New(rdb)
+
+	since := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	until := since.Add(time.Hour * 24)
+
+	item1 := featured.New(addr.MustNewFromDotted("1.1.1.1", 10480), 1, since, until)
+	item2 := featured.New(addr.MustNewFromDotted("2.2.2.2", 10580), 2, featured.NC, featured.NC)
+
+	items, err := repo.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	tu.MustNoErr(repo.Add(ctx, item1))
+	tu.MustNoErr(repo.Add(ctx, item2))
+
+	got, err := repo.Get(ctx, item1.Addr)
+	require.NoError(t, err)
+	assert.Equal(t, item1.Addr, got.Addr)
+	assert.Equal(t, 1, got.Priority)
+	assert.True(t, since.Equal(got.Since))
+	assert.True(t, until.Equal(got.Until))
+
+	// adding the same server again replaces the entry
+	tu.MustNoErr(repo.Add(ctx, featured.New(item2.Addr, 0, featured.NC, until)))
+
+	items, err = repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	got, err = repo.Get(ctx, item2.Addr)
+	require.NoError(t, err)
+	assert.Equal(t, 0, got.Priority)
+	assert.True(t, got.Since.IsZero())
+	assert.True(t, until.Equal(got.Until))
+
+	_, err = repo.Get(ctx, addr.MustNewFromDotted("3.3.3.3", 10480))
+	require.ErrorIs(t, err, repositories.ErrFeaturedNotFound)
+}
+
+func TestFeaturedRedisRepo_Remove(t *testing.T) {
+	ctx := context.TODO()
+	rdb := testredis.MakeClient(t)
+	repo := featuredservers.New(rdb)
+
+	item := featured.New(addr.MustNewFromDotted("1.1.1.1", 10480), 1, featured.NC, featured.NC)
+	tu.MustNoErr(repo.Add(ctx, item))
+
+	require.NoError(t, repo.Remove(ctx, item.Addr))
+
+	_, err := repo.Get(ctx, item.Addr)
+	require.ErrorIs(t, err, repositories.ErrFeaturedNotFound)
+
+	err = repo.Remove(ctx, item.Addr)
+	require.ErrorIs(t, err, repositories.ErrFeaturedNotFound)
+
+	items, err := repo.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
@@ -0,0 +1,193 @@
+package histories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/history"
+)
+
+// maxAttempts is the number of times the samples are attempted to be recorded
+// should the stored points be updated concurrently
+const maxAttempts = 10
+
+var ErrConcurrentUpdates = errors.New("history points are updated concurrently")
+
+// The history of a server is kept in a sorted set per resolution,
+// with the aggregated points scored by the unix time of their buckets.
+// Every sample updates the point of its bucket in all resolutions at once,
+// so the history is downsampled as it is written.
+type Repository struct {
+	client *redis.Client
+	clock  clockwork.Clock
+}
+
+func New(client *redis.Client, c clockwork.Clock) *Repository {
+	return &Repository{
+		client: client,
+		clock:  c,
+	}
+}
+
+type bucketRef struct {
+	key string
+	res history.Resolution
+	at  time.Time
+}
+
+func (r *Repository) Record(ctx context.Context, samples ...history.Sample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	refs := make([]bucketRef, 0, len(samples)*len(history.Resolutions))
+	points := make(map[bucketRef]history.Point, cap(refs))
+	for _, sample := range samples {
+		for _, res := range history.Resolutions {
+			ref := bucketRef{key: historyKey(res, sample.Addr), res: res, at: res.Bucket(sample.At)}
+			pt, seen := points[ref]
+			if !seen {
+				refs = append(refs, ref)
+				pt = history.NewPoint(ref.at)
+			}
+			pt.Add(sample)
+			points[ref] = pt
+		}
+	}
+
+	keys := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		keys = append(keys, ref.key)
+	}
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+
+	// the stored points are merged with the new ones, unless they are changed in the meantime
+	for range maxAttempts {
+		err := r.client.Watch(ctx, func(tx *redis.Tx) error {
+			return r.merge(ctx, tx, refs, points)
+		}, keys...)
+		if errors.Is(err, redis.TxFailedErr) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to record history samples: %w", err)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("failed to record history samples: %w", ErrConcurrentUpdates)
+}
+
+// merge adds the new points up with the stored ones.
+// The transaction fails if the stored points have changed since they were read.
+func (r *Repository) merge(
+	ctx context.Context,
+	tx *redis.Tx,
+	refs []bucketRef,
+	points map[bucketRef]history.Point,
+) error {
+	stored, err := r.getBuckets(ctx, tx, refs)
+	if err != nil {
+		return err
+	}
+
+	now := r.clock.Now()
+	_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, ref := range refs {
+			pt := stored[i]
+			pt.Merge(points[ref])
+			encoded, err := json.Marshal(pt)
+			if err != nil {
+				return fmt.Errorf("failed to marshal history point: %w", err)
+			}
+			score := unixScore(ref.at)
+			cutoff := "(" + unixScore(now.Add(-ref.res.Retention))
+			pipe.ZRemRangeByScore(ctx, ref.key, score, score)
+			pipe.ZAdd(ctx, ref.key, redis.Z{Score: float64(ref.at.Unix()), Member: encoded})
+			pipe.ZRemRangeByScore(ctx, ref.key, "-inf", cutoff)
+			pipe.Expire(ctx, ref.key, ref.res.Retention)
+		}
+		return nil
+	})
+	return err
+}
+
+// getBuckets returns the stored points of the buckets, or the blank points for the buckets not stored yet
+func (r *Repository) getBuckets(ctx context.Context, tx *redis.Tx, refs []bucketRef) ([]history.Point, error) {
+	cmds := make([]*redis.StringSliceCmd, 0, len(refs))
+	_, err := tx.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, ref := range refs {
+			score := unixScore(ref.at)
+			cmds = append(cmds, pipe.ZRangeByScore(ctx, ref.key, &redis.ZRangeBy{Min: score, Max: score}))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve history points: %w", err)
+	}
+
+	result := make([]history.Point, 0, len(refs))
+	for i, cmd := range cmds {
+		pt := history.NewPoint(refs[i].at)
+		if items := cmd.Val(); len(items) > 0 {
+			if pt, err = decodePoint(items[0]); err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, pt)
+	}
+
+	return result, nil
+}
+
+func (r *Repository) Range(
+	ctx context.Context,
+	svrAddr addr.Addr,
+	res history.Resolution,
+	from, to time.Time,
+) ([]history.Point, error) {
+	items, err := r.client.ZRangeByScore(ctx, historyKey(res, svrAddr), &redis.ZRangeBy{
+		Min: unixScore(res.Bucket(from)),
+		Max: "(" + unixScore(to),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve history points: %w", err)
+	}
+
+	result := make([]history.Point, 0, len(items))
+	for _, item := range items {
+		pt, decodeErr := decodePoint(item)
+		if decodeErr != nil {
+			return nil, decodeErr
+		}
+		result = append(result, pt)
+	}
+
+	return result, nil
+}
+
+func historyKey(res history.Resolution, svrAddr addr.Addr) string {
+	return fmt.Sprintf("history:%s:%s", res.Name, svrAddr)
+}
+
+func unixScore(t time.Time) string {
+	return strconv.FormatInt(t.Unix(), 10)
+}
+
+func decodePoint(encoded string) (history.Point, error) {
+	var decoded history.Point
+	if err := json.Unmarshal([]byte(encoded), &decoded); err != nil {
+		return history.Point{}, fmt.Errorf("failed to unmarshal history point: %w", err)
+	}
+	return decoded, nil
+}
@@ -0,0 +1,158 @@
+package histories_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/history"
+	"github.com/sergeii/swat4master/internal/persistence/redis/repositories/histories"
+	tu "github.com/sergeii/swat4master/internal/testutils"
+	"github.com/sergeii/swat4master/internal/testutils/testredis"
+)
+
+func TestHistoriesRedisRepo_RecordAndRange(t *testing.T) {
+	ctx := context.TODO()
+	rdb := testredis.MakeClient(t)
+	clock := clockwork.NewFakeClockAt(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	repo := histories.New(rdb, clock)
+
+	svr1 := addr.MustNewFromDotted("1.1.1.1", 10480)
+	svr2 := addr.MustNewFromDotted("2.2.2.2", 10480)
+	start := clock.Now()
+
+	points, err := repo.Range(ctx, svr1, history.Raw, start.Add(-time.Hour), start)
+	require.NoError(t, err)
+	assert.Empty(t, points)
+
+	// a sample every minute for 12 minutes, the server is offline on the 4th minute
+	for i := range 12 {
+		at := start.Add(time.Minute*time.Duration(i) + time.Second*10)
+		tu.MustNoErr(repo.Record(
+			ctx,
+			history.Sample{Addr: svr1, At: at, Online: i != 4, Players: i, MaxPlayers: 16, MapName: "Sellers"},
+			history.Sample{Addr: svr2, At: at, Online: true, Players: 1, MaxPlayers: 10},
+		))
+	}
+	tu.MustNoErr(repo.Record(ctx))
+
+	points, err = repo.Range(ctx, svr1, history.Raw, start, start.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, points, 12)
+	assert.Equal(t, start, points[0].At.UTC())
+	assert.Equal(t, 1, points[0].Samples)
+	assert.Equal(t, 0, points[4].OnlineSamples)
+	assert.Equal(t, 11, points[11].PlayersMax)
+
+	// the end of the range is exclusive, whereas the start is rounded down to its bucket
+	points, err = repo.Range(ctx, svr1, history.Raw, start.Add(time.Minute*2+time.Second*30), start.Add(time.Minute*5))
+	require.NoError(t, err)
+	require.Len(t, points, 3)
+	assert.Equal(t, start.Add(time.Minute*2), points[0].At.UTC())
+
+	points, err = repo.Range(ctx, svr1, history.FiveMinutes, start, start.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, points, 3)
+	assert.Equal(t, 5, points[0].Samples)
+	assert.Equal(t, 4, points[0].OnlineSamples)
+	assert.InDelta(t, 1.5, points[0].PlayersAvg(), 1e-9) // (0+1+2+3) / 4
+	assert.Equal(t, 5, points[1].Samples)
+	assert.Equal(t, 9, points[1].PlayersMax)
+	assert.Equal(t, 2, points[2].Samples)
+	assert.Equal(t, start.Add(time.Minute*10), points[2].At.UTC())
+
+	points, err = repo.Range(ctx, svr1, history.Hourly, start, start.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, points, 1)
+	assert.Equal(t, 12, points[0].Samples)
+	assert.Equal(t, 11, points[0].OnlineSamples)
+	assert.Equal(t, 16, points[0].MaxPlayers)
+	assert.Equal(t, "Sellers", points[0].MapName)
+
+	points, err = repo.Range(ctx, svr2, history.Hourly, start, start.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, points, 1)
+	assert.Equal(t, 12, points[0].Samples)
+	assert.InDelta(t, 1.0, points[0].Uptime(), 1e-9)
+}
+
+func TestHistoriesRedisRepo_SamplesOfSameBucketInOneCall(t *testing.T) {
+	ctx := context.TODO()
+	rdb := testredis.MakeClient(t)
+	clock := clockwork.NewFakeClockAt(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	repo := histories.New(rdb, clock)
+
+	svrAddr := addr.MustNewFromDotted("1.1.1.1", 10480)
+	now := clock.Now()
+
+	tu.MustNoErr(repo.Record(
+		ctx,
+		history.Sample{Addr: svrAddr, At: now, Online: true, Players: 2},
+		history.Sample{Addr: svrAddr, At: now.Add(time.Second * 30), Online: true, Players: 4},
+	))
+
+	points, err := repo.Range(ctx, svrAddr, history.Raw, now, now.Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, points, 1)
+	assert.Equal(t, 2, points[0].Samples)
+	assert.InDelta(t, 3.0, points[0].PlayersAvg(), 1e-9)
+}
+
+func TestHistoriesRedisRepo_ConcurrentRecords(t *testing.T) {
+	ctx := context.TODO()
+	rdb := testredis.MakeClient(t)
+	clock := clockwork.NewFakeClockAt(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	repo := histories.New(rdb, clock)
+
+	svrAddr := addr.MustNewFromDotted("1.1.1.1", 10480)
+	now := clock.Now()
+
+	// the samples of the same bucket are recorded at the same time
+	wg := &sync.WaitGroup{}
+	for range 5 {
+		wg.Go(func() {
+			sample := history.Sample{Addr: svrAddr, At: now, Online: true, Players: 2}
+			assert.NoError(t, repo.Record(ctx, sample))
+		})
+	}
+	wg.Wait()
+
+	// none of the samples is lost
+	for _, res := range history.Resolutions {
+		points, err := repo.Range(ctx, svrAddr, res, now, now.Add(time.Minute))
+		require.NoError(t, err)
+		require.Len(t, points, 1)
+		assert.Equal(t, 5, points[0].Samples)
+		assert.Equal(t, 10, points[0].PlayersSum)
+	}
+}
+
+func TestHistoriesRedisRepo_Retention(t *testing.T) {
+	ctx := context.TODO()
+	rdb := testredis.MakeClient(t)
+	clock := clockwork.NewFakeClockAt(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	repo := histories.New(rdb, clock)
+
+	svrAddr := addr.MustNewFromDotted("1.1.1.1", 10480)
+	dayAgo := clock.Now().Add(-time.Hour * 25)
+
+	tu.MustNoErr(repo.Record(ctx, history.Sample{Addr: svrAddr, At: dayAgo, Online: true, Players: 5}))
+	tu.MustNoErr(repo.Record(ctx, history.Sample{Addr: svrAddr, At: clock.Now(), Online: true, Players: 1}))
+
+	// the raw sample is gone, but the rollups still have it
+	points, err := repo.Range(ctx, svrAddr, history.Raw, dayAgo, clock.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, points, 1)
+	assert.Equal(t, 1, points[0].PlayersMax)
+
+	points, err = repo.Range(ctx, svrAddr, history.FiveMinutes, dayAgo, clock.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, points, 2)
+	assert.Equal(t, 5, points[0].PlayersMax)
+}
@@ -0,0 +1,98 @@
+package jobruns
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/sergeii/swat4master/internal/core/entities/jobrun"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+)
+
+const (
+	jobsKey = "jobs:names"
+	// historySize is the number of the latest runs kept for every job
+	historySize = 100
+)
+
+type Repository struct {
+	client *redis.Client
+}
+
+func New(client *redis.Client) *Repository {
+	return &Repository{
+		client: client,
+	}
+}
+
+func (r *Repository) Record(ctx context.Context, run jobrun.Run) error {
+	encoded, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job run: %w", err)
+	}
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SAdd(ctx, jobsKey, run.Job)
+		pipe.LPush(ctx, runsKey(run.Job), encoded)
+		pipe.LTrim(ctx, runsKey(run.Job), 0, historySize-1)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record job run: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) Last(ctx context.Context, job string) (jobrun.Run, error) {
+	item, err := r.client.LIndex(ctx, runsKey(job), 0).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return jobrun.Blank, repositories.ErrJobRunNotFound
+		}
+		return jobrun.Blank, fmt.Errorf("failed to retrieve last job run: %w", err)
+	}
+	return decodeRun(item)
+}
+
+func (r *Repository) List(ctx context.Context, job string, limit int) ([]jobrun.Run, error) {
+	if limit <= 0 {
+		return []jobrun.Run{}, nil
+	}
+	items, err := r.client.LRange(ctx, runsKey(job), 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list job runs: %w", err)
+	}
+	result := make([]jobrun.Run, 0, len(items))
+	for _, item := range items {
+		decoded, decodeErr := decodeRun(item)
+		if decodeErr != nil {
+			return nil, decodeErr
+		}
+		result = append(result, decoded)
+	}
+	return result, nil
+}
+
+func (r *Repository) Jobs(ctx context.Context) ([]string, error) {
+	names, err := r.client.SMembers(ctx, jobsKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+func runsKey(job string) string {
+	return fmt.Sprintf("jobs:runs:%s", job)
+}
+
+func decodeRun(encoded string) (jobrun.Run, error) {
+	var decoded jobrun.Run
+	if err := json.Unmarshal([]byte(encoded), &decoded); err != nil {
+		return jobrun.Blank, fmt.Errorf("failed to unmarshal job run: %w", err)
+	}
+	return decoded, nil
+}
@@ -0,0 +1,82 @@
+package jobruns_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/jobrun"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+	"github.com/sergeii/swat4master/internal/persistence/redis/repositories/jobruns"
+	tu "github.com/sergeii/swat4master/internal/testutils"
+	"github.com/sergeii/swat4master/internal/testutils/testredis"
+)
+
+func TestJobRunsRedisRepo_RecordLastList(t *testing.T) {
+	ctx := context.TODO()
+	rdb := testredis.MakeClient(t)
+	repo := jobruns.New(rdb)
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	_, err := repo.Last(ctx, "refresher")
+	require.ErrorIs(t, err, repositories.ErrJobRunNotFound)
+
+	runs, err := repo.List(ctx, "refresher", 10)
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+
+	run1 := jobrun.New("refresher", now, now, now.Add(time.Millisecond*5), nil)
+	run2 := jobrun.New("refresher", now.Add(time.Second), now.Add(time.Second), now.Add(time.Second*2), errors.New("boom"))
+	run3 := jobrun.New("reviver", now, now, now.Add(time.Second), nil)
+	tu.MustNoErr(repo.Record(ctx, run1))
+	tu.MustNoErr(repo.Record(ctx, run2))
+	tu.MustNoErr(repo.Record(ctx, run3))
+
+	last, err := repo.Last(ctx, "refresher")
+	require.NoError(t, err)
+	assert.Equal(t, "refresher", last.Job)
+	assert.Equal(t, jobrun.OutcomeFailure, last.Outcome)
+	assert.Equal(t, "boom", last.Error)
+	assert.True(t, now.Add(time.Second).Equal(last.ScheduledAt))
+	assert.Equal(t, time.Second, last.Duration())
+
+	runs, err = repo.List(ctx, "refresher", 10)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, jobrun.OutcomeFailure, runs[0].Outcome)
+	assert.Equal(t, jobrun.OutcomeSuccess, runs[1].Outcome)
+	assert.Empty(t, runs[1].Error)
+	assert.Equal(t, time.Millisecond*5, runs[1].Duration())
+
+	runs, err = repo.List(ctx, "refresher", 1)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, jobrun.OutcomeFailure, runs[0].Outcome)
+
+	jobs, err := repo.Jobs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"refresher", "reviver"}, jobs)
+}
+
+func TestJobRunsRedisRepo_HistoryIsCapped(t *testing.T) {
+	ctx := context.TODO()
+	rdb := testredis.MakeClient(t)
+	repo := jobruns.New(rdb)
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := range 150 {
+		at := now.Add(time.Second * time.Duration(i))
+		tu.MustNoErr(repo.Record(ctx, jobrun.New("observer", at, at, at, nil)))
+	}
+
+	runs, err := repo.List(ctx, "observer", 1000)
+	require.NoError(t, err)
+	require.Len(t, runs, 100)
+	assert.True(t, now.Add(time.Second*149).Equal(runs[0].ScheduledAt))
+	assert.True(t, now.Add(time.Second*50).Equal(runs[99].ScheduledAt))
+}
@@ -0,0 +1,113 @@
+package matches
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/match"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+)
+
+// retention is the time the match results are kept for
+const retention = time.Hour * 24 * 90
+
+type Repository struct {
+	client *redis.Client
+	clock  clockwork.Clock
+}
+
+func New(client *redis.Client, c clockwork.Clock) *Repository {
+	return &Repository{
+		client: client,
+		clock:  c,
+	}
+}
+
+func (r *Repository) Add(ctx context.Context, m match.Match) error {
+	encoded, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to marshal match: %w", err)
+	}
+	cutoff := strconv.FormatInt(r.clock.Now().Add(-retention).UnixMilli(), 10)
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, itemKey(m.ID), encoded, retention)
+		pipe.ZAdd(ctx, serverKey(m.Addr), redis.Z{Score: float64(m.EndedAt.UnixMilli()), Member: m.ID})
+		pipe.ZRemRangeByScore(ctx, serverKey(m.Addr), "-inf", "("+cutoff)
+		pipe.Expire(ctx, serverKey(m.Addr), retention)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add match: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) Get(ctx context.Context, id string) (match.Match, error) {
+	item, err := r.client.Get(ctx, itemKey(id)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return match.Blank, repositories.ErrMatchNotFound
+		}
+		return match.Blank, fmt.Errorf("failed to retrieve match: %w", err)
+	}
+	return decodeMatch(item)
+}
+
+func (r *Repository) ListByServer(ctx context.Context, svrAddr addr.Addr, limit int) ([]match.Match, error) {
+	if limit <= 0 {
+		return []match.Match{}, nil
+	}
+	ids, err := r.client.ZRevRange(ctx, serverKey(svrAddr), 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list matches: %w", err)
+	}
+	if len(ids) == 0 {
+		return []match.Match{}, nil
+	}
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, itemKey(id))
+	}
+	items, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve matches: %w", err)
+	}
+	result := make([]match.Match, 0, len(items))
+	for _, item := range items {
+		// skip the expired matches
+		encoded, ok := item.(string)
+		if !ok {
+			continue
+		}
+		decoded, decodeErr := decodeMatch(encoded)
+		if decodeErr != nil {
+			return nil, decodeErr
+		}
+		result = append(result, decoded)
+	}
+	return result, nil
+}
+
+func itemKey(id string) string {
+	return fmt.Sprintf("matches:items:%s", id)
+}
+
+func serverKey(svrAddr addr.Addr) string {
+	return fmt.Sprintf("matches:servers:%s", svrAddr)
+}
+
+func decodeMatch(encoded string) (match.Match, error) {
+	var decoded match.Match
+	if err := json.Unmarshal([]byte(encoded), &decoded); err != nil {
+		return match.Blank, fmt.Errorf("failed to unmarshal match: %w", err)
+	}
+	return decoded, nil
+}
@@ -0,0 +1,93 @@
+package matches_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/details"
+	"github.com/sergeii/swat4master/internal/core/entities/match"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+	"github.com/sergeii/swat4master/internal/persistence/redis/repositories/matches"
+	tu "github.com/sergeii/swat4master/internal/testutils"
+	"github.com/sergeii/swat4master/internal/testutils/testredis"
+)
+
+func TestMatchesRedisRepo_AddGetList(t *testing.T) {
+	ctx := context.TODO()
+	rdb := testredis.MakeClient(t)
+	clock := clockwork.NewFakeClockAt(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	repo := matches.New(rdb, clock)
+
+	svr1 := addr.MustNewFromDotted("1.1.1.1", 10480)
+	svr2 := addr.MustNewFromDotted("2.2.2.2", 10480)
+	now := clock.Now()
+
+	_, err := repo.Get(ctx, "unknown")
+	require.ErrorIs(t, err, repositories.ErrMatchNotFound)
+
+	items, err := repo.ListByServer(ctx, svr1, 10)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	m1 := match.Match{
+		ID:         "m1",
+		Addr:       svr1,
+		Info:       details.Info{MapName: "A-Bomb Nightclub", SwatScore: 50, SuspectsScore: 20},
+		Players:    []details.Player{{Name: "Serge", Score: 15, Kills: 3}},
+		Objectives: []details.Objective{{Name: "obj_Neutralize_All_Enemies", Status: details.ObjCompleted}},
+		Winner:     match.WinnerSwat,
+		EndedAt:    now.Add(-time.Hour),
+	}
+	m2 := match.Match{ID: "m2", Addr: svr1, EndedAt: now.Add(-time.Minute)}
+	m3 := match.Match{ID: "m3", Addr: svr2, EndedAt: now}
+	for _, m := range []match.Match{m1, m2, m3} {
+		tu.MustNoErr(repo.Add(ctx, m))
+	}
+
+	got, err := repo.Get(ctx, "m1")
+	require.NoError(t, err)
+	assert.Equal(t, svr1, got.Addr)
+	assert.Equal(t, m1.Info, got.Info)
+	assert.Equal(t, m1.Players, got.Players)
+	assert.Equal(t, m1.Objectives, got.Objectives)
+	assert.Equal(t, match.WinnerSwat, got.Winner)
+	assert.True(t, m1.EndedAt.Equal(got.EndedAt))
+
+	items, err = repo.ListByServer(ctx, svr1, 10)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, "m2", items[0].ID)
+	assert.Equal(t, "m1", items[1].ID)
+
+	items, err = repo.ListByServer(ctx, svr1, 1)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "m2", items[0].ID)
+
+	items, err = repo.ListByServer(ctx, svr2, 10)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "m3", items[0].ID)
+}
+
+func TestMatchesRedisRepo_Retention(t *testing.T) {
+	ctx := context.TODO()
+	rdb := testredis.MakeClient(t)
+	clock := clockwork.NewFakeClockAt(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	repo := matches.New(rdb, clock)
+
+	svrAddr := addr.MustNewFromDotted("1.1.1.1", 10480)
+	tu.MustNoErr(repo.Add(ctx, match.Match{ID: "old", Addr: svrAddr, EndedAt: clock.Now().Add(-time.Hour * 24 * 100)}))
+	tu.MustNoErr(repo.Add(ctx, match.Match{ID: "recent", Addr: svrAddr, EndedAt: clock.Now().Add(-time.Hour)}))
+
+	items, err := repo.ListByServer(ctx, svrAddr, 10)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "recent", items[0].ID)
+}
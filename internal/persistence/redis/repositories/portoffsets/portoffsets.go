@@ -0,0 +1,104 @@
+package portoffsets
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/sergeii/swat4master/internal/core/entities/portoffset"
+)
+
+const (
+	globalKey    = "portoffsets:global"
+	networkKeyFn = "portoffsets:net:%s"
+	ipKeyFn      = "portoffsets:ip:%s"
+)
+
+// retention is the time the offsets of an ip or a network are kept for since they were last recorded.
+// The global offsets are shared by all servers, so they are kept for good.
+const retention = time.Hour * 24 * 30
+
+type Repository struct {
+	client *redis.Client
+}
+
+func New(client *redis.Client) *Repository {
+	return &Repository{
+		client: client,
+	}
+}
+
+// Record counts the discovered offset for the IP address, its network and globally
+func (r *Repository) Record(ctx context.Context, ip [4]byte, offset int) error {
+	field := strconv.Itoa(offset)
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys(ip) {
+			pipe.HIncrBy(ctx, key, field, 1)
+			if key != globalKey {
+				pipe.Expire(ctx, key, retention)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record port offset: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) Get(ctx context.Context, ip [4]byte) (portoffset.Learned, error) {
+	cmds := make([]*redis.MapStringStringCmd, 0, 3)
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys(ip) {
+			cmds = append(cmds, pipe.HGetAll(ctx, key))
+		}
+		return nil
+	})
+	if err != nil {
+		return portoffset.Learned{}, fmt.Errorf("failed to get port offsets: %w", err)
+	}
+
+	stats := make([]portoffset.Stats, 0, len(cmds))
+	for _, cmd := range cmds {
+		decoded, decodeErr := decodeStats(cmd.Val())
+		if decodeErr != nil {
+			return portoffset.Learned{}, decodeErr
+		}
+		stats = append(stats, decoded)
+	}
+
+	return portoffset.Learned{
+		IP:      stats[0],
+		Network: stats[1],
+		Global:  stats[2],
+	}, nil
+}
+
+// keys returns the keys of the ip, network and global stats, in this order
+func keys(ip [4]byte) []string {
+	return []string{
+		fmt.Sprintf(ipKeyFn, netip.AddrFrom4(ip)),
+		fmt.Sprintf(networkKeyFn, portoffset.Network(ip)),
+		globalKey,
+	}
+}
+
+func decodeStats(items map[string]string) (portoffset.Stats, error) {
+	stats := make(portoffset.Stats, len(items))
+	for field, value := range items {
+		offset, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode port offset %q: %w", field, err)
+		}
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode port offset count %q: %w", value, err)
+		}
+		stats[offset] = count
+	}
+	return stats, nil
+}
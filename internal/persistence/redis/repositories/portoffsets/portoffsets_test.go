@@ -0,0 +1,68 @@
+package portoffsets_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/portoffset"
+	"github.com/sergeii/swat4master/internal/persistence/redis/repositories/portoffsets"
+	tu "github.com/sergeii/swat4master/internal/testutils"
+	"github.com/sergeii/swat4master/internal/testutils/testredis"
+)
+
+func TestPortOffsetRedisRepo_RecordGet(t *testing.T) {
+	ctx := context.TODO()
+	rdb := testredis.MakeClient(t)
+	repo := portoffsets.New(rdb)
+
+	learned, err := repo.Get(ctx, [4]byte{1, 1, 1, 1})
+	require.NoError(t, err)
+	assert.Empty(t, learned.IP)
+	assert.Empty(t, learned.Network)
+	assert.Empty(t, learned.Global)
+
+	tu.MustNoErr(repo.Record(ctx, [4]byte{1, 1, 1, 1}, 1))
+	tu.MustNoErr(repo.Record(ctx, [4]byte{1, 1, 1, 1}, 1))
+	tu.MustNoErr(repo.Record(ctx, [4]byte{1, 1, 1, 2}, 10))
+	tu.MustNoErr(repo.Record(ctx, [4]byte{2, 2, 2, 2}, 2))
+	tu.MustNoErr(repo.Record(ctx, [4]byte{3, 3, 3, 3}, -1))
+
+	learned, err = repo.Get(ctx, [4]byte{1, 1, 1, 1})
+	require.NoError(t, err)
+	assert.Equal(t, portoffset.Learned{
+		IP:      portoffset.Stats{1: 2},
+		Network: portoffset.Stats{1: 2, 10: 1},
+		Global:  portoffset.Stats{1: 2, 10: 1, 2: 1, -1: 1},
+	}, learned)
+
+	// the server is new, but its network is known
+	learned, err = repo.Get(ctx, [4]byte{1, 1, 1, 3})
+	require.NoError(t, err)
+	assert.Empty(t, learned.IP)
+	assert.Equal(t, portoffset.Stats{1: 2, 10: 1}, learned.Network)
+}
+
+func TestPortOffsetRedisRepo_Record_Expires(t *testing.T) {
+	ctx := context.TODO()
+	mr := miniredis.RunT(t)
+	repo := portoffsets.New(testredis.MakeClientFromMini(t, mr))
+
+	tu.MustNoErr(repo.Record(ctx, [4]byte{1, 1, 1, 1}, 1))
+
+	assert.Equal(t, time.Hour*24*30, mr.TTL("portoffsets:ip:1.1.1.1"))
+	assert.Equal(t, time.Hour*24*30, mr.TTL("portoffsets:net:1.1.1.0/24"))
+	assert.Equal(t, time.Duration(0), mr.TTL("portoffsets:global"))
+
+	mr.FastForward(time.Hour * 24 * 31)
+
+	learned, err := repo.Get(ctx, [4]byte{1, 1, 1, 1})
+	require.NoError(t, err)
+	assert.Empty(t, learned.IP)
+	assert.Empty(t, learned.Network)
+	assert.Equal(t, portoffset.Stats{1: 1}, learned.Global)
+}
@@ -0,0 +1,61 @@
+package profiles
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/profile"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+)
+
+const itemsKey = "profiles:items"
+
+type Repository struct {
+	client *redis.Client
+}
+
+func New(client *redis.Client) *Repository {
+	return &Repository{
+		client: client,
+	}
+}
+
+// Save stores the profile of the server, replacing the existing one
+func (r *Repository) Save(ctx context.Context, item profile.Profile) error {
+	encoded, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("failed to marshal profile: %w", err)
+	}
+	if err = r.client.HSet(ctx, itemsKey, item.Addr.String(), encoded).Err(); err != nil {
+		return fmt.Errorf("failed to save profile: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) Get(ctx context.Context, svrAddr addr.Addr) (profile.Profile, error) {
+	item, err := r.client.HGet(ctx, itemsKey, svrAddr.String()).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return profile.Blank, repositories.ErrProfileNotFound
+		}
+		return profile.Blank, fmt.Errorf("failed to retrieve profile: %w", err)
+	}
+	var decoded profile.Profile
+	if err := json.Unmarshal([]byte(item), &decoded); err != nil {
+		return profile.Blank, fmt.Errorf("failed to unmarshal profile: %w", err)
+	}
+	return decoded, nil
+}
+
+// Remove deletes the profile of the server, if there is any
+func (r *Repository) Remove(ctx context.Context, svrAddr addr.Addr) error {
+	if err := r.client.HDel(ctx, itemsKey, svrAddr.String()).Err(); err != nil {
+		return fmt.Errorf("failed to remove profile: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package profiles_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/profile"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+	"github.com/sergeii/swat4master/internal/persistence/redis/repositories/profiles"
+	tu "github.com/sergeii/swat4master/internal/testutils"
+	"github.com/sergeii/swat4master/internal/testutils/testredis"
+)
+
+func TestProfilesRedisRepo_SaveGet(t *testing.T) {
+	ctx := context.TODO()
+	rdb := testredis.MakeClient(t)
+	repo := profiles.New(rdb)
+
+	updatedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	svrAddr := addr.MustNewFromDotted("1.1.1.1", 10480)
+
+	_, err := repo.Get(ctx, svrAddr)
+	require.ErrorIs(t, err, repositories.ErrProfileNotFound)
+
+	item := profile.New(svrAddr, "Best server", "https://example.com", "https://discord.gg/swat4", "EU", updatedAt)
+	tu.MustNoErr(repo.Save(ctx, item))
+
+	got, err := repo.Get(ctx, svrAddr)
+	require.NoError(t, err)
+	assert.Equal(t, svrAddr, got.Addr)
+	assert.Equal(t, "Best server", got.Description)
+	assert.Equal(t, "https://example.com", got.Website)
+	assert.Equal(t, "https://discord.gg/swat4", got.Discord)
+	assert.Equal(t, "EU", got.Region)
+	assert.True(t, updatedAt.Equal(got.UpdatedAt))
+
+	// saving the profile again replaces it
+	tu.MustNoErr(repo.Save(ctx, profile.New(svrAddr, "", "", "", "US", updatedAt.Add(time.Hour))))
+
+	got, err = repo.Get(ctx, svrAddr)
+	require.NoError(t, err)
+	assert.Empty(t, got.Description)
+	assert.Empty(t, got.Website)
+	assert.Equal(t, "US", got.Region)
+
+	_, err = repo.Get(ctx, addr.MustNewFromDotted("2.2.2.2", 10480))
+	require.ErrorIs(t, err, repositories.ErrProfileNotFound)
+}
+
+func TestProfilesRedisRepo_Remove(t *testing.T) {
+	ctx := context.TODO()
+	rdb := testredis.MakeClient(t)
+	repo := profiles.New(rdb)
+
+	updatedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	svrAddr := addr.MustNewFromDotted("1.1.1.1", 10480)
+	otherAddr := addr.MustNewFromDotted("2.2.2.2", 10480)
+
+	tu.MustNoErr(repo.Save(ctx, profile.New(svrAddr, "Best server", "", "", "EU", updatedAt)))
+	tu.MustNoErr(repo.Save(ctx, profile.New(otherAddr, "Other server", "", "", "US", updatedAt)))
+
+	tu.MustNoErr(repo.Remove(ctx, svrAddr))
+	_, err := repo.Get(ctx, svrAddr)
+	require.ErrorIs(t, err, repositories.ErrProfileNotFound)
+
+	// the other profile is intact
+	got, err := repo.Get(ctx, otherAddr)
+	require.NoError(t, err)
+	assert.Equal(t, "Other server", got.Description)
+
+	// removing the missing profile is no error
+	tu.MustNoErr(repo.Remove(ctx, svrAddr))
+}
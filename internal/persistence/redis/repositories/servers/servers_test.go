@@ -1375,6 +1375,7 @@ func TestServersRedisRepo_CountByStatus_OK(t *testing.T) {
 		ds.Port:         0,
 		ds.PortRetry:    0,
 		ds.NoPort:       2,
+		ds.Federated:    0,
 	}
 	assert.Equal(t, expected, countByStatus)
 
@@ -1394,6 +1395,7 @@ func TestServersRedisRepo_CountByStatus_OK(t *testing.T) {
 		ds.Port:         0,
 		ds.PortRetry:    0,
 		ds.NoPort:       2,
+		ds.Federated:    0,
 	}
 	assert.Equal(t, expected, countByStatus)
 
@@ -1421,6 +1423,7 @@ func TestServersRedisRepo_CountByStatus_OK(t *testing.T) {
 		ds.Port:         1,
 		ds.PortRetry:    0,
 		ds.NoPort:       1,
+		ds.Federated:    0,
 	}
 	assert.Equal(t, expected, countByStatus)
 
@@ -1442,6 +1445,7 @@ func TestServersRedisRepo_CountByStatus_OK(t *testing.T) {
 		ds.Port:         0,
 		ds.PortRetry:    0,
 		ds.NoPort:       0,
+		ds.Federated:    0,
 	}
 	assert.Equal(t, expected, countByStatus)
 }
@@ -1467,6 +1471,7 @@ func TestServersRedisRepo_CountByStatus_OnEmptyStorageEmptyCounts(t *testing.T)
 		ds.Port:         0,
 		ds.PortRetry:    0,
 		ds.NoPort:       0,
+		ds.Federated:    0,
 	}
 	assert.Equal(t, expected, countByStatus)
 }
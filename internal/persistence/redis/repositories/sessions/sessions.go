@@ -0,0 +1,230 @@
+package sessions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/session"
+)
+
+// retention is the time the sessions are kept for since they were last updated
+const retention = time.Hour * 24 * 30
+
+type Repository struct {
+	client *redis.Client
+	clock  clockwork.Clock
+}
+
+func New(client *redis.Client, c clockwork.Clock) *Repository {
+	return &Repository{
+		client: client,
+		clock:  c,
+	}
+}
+
+func (r *Repository) Save(ctx context.Context, sessions ...session.Session) error {
+	if len(sessions) == 0 {
+		return nil
+	}
+	cutoff := strconv.FormatInt(r.clock.Now().Add(-retention).UnixMilli(), 10)
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, sess := range sessions {
+			encoded, err := json.Marshal(sess)
+			if err != nil {
+				return fmt.Errorf("failed to marshal session: %w", err)
+			}
+			pipe.Set(ctx, itemKey(sess.ID), encoded, retention)
+			if sess.IsActive() {
+				pipe.HSet(ctx, activeKey(sess.Addr), sess.Name, sess.ID)
+				pipe.ZAdd(ctx, onlineKey, redis.Z{Member: onlineMember(sess)})
+			} else {
+				pipe.HDel(ctx, activeKey(sess.Addr), sess.Name)
+				pipe.ZRem(ctx, onlineKey, onlineMember(sess))
+			}
+			member := redis.Z{Score: float64(sess.StartedAt.UnixMilli()), Member: sess.ID}
+			for _, key := range []string{serverKey(sess.Addr), playerKey(sess.Name)} {
+				pipe.ZAdd(ctx, key, member)
+				pipe.ZRemRangeByScore(ctx, key, "-inf", "("+cutoff)
+				pipe.Expire(ctx, key, retention)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save sessions: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) Active(ctx context.Context, svrAddr addr.Addr) ([]session.Session, error) {
+	ids, err := r.client.HVals(ctx, activeKey(svrAddr)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve active sessions: %w", err)
+	}
+	return r.getMany(ctx, ids)
+}
+
+func (r *Repository) EndActive(ctx context.Context, svrAddr addr.Addr, endedAt time.Time) (int, error) {
+	active, err := r.Active(ctx, svrAddr)
+	if err != nil {
+		return 0, err
+	}
+	for i := range active {
+		active[i].End(endedAt)
+	}
+	if err := r.Save(ctx, active...); err != nil {
+		return 0, err
+	}
+	return len(active), nil
+}
+
+func (r *Repository) ListByServer(ctx context.Context, svrAddr addr.Addr, limit int) ([]session.Session, error) {
+	return r.listLatest(ctx, serverKey(svrAddr), limit)
+}
+
+func (r *Repository) ListByPlayer(ctx context.Context, name string, limit int) ([]session.Session, error) {
+	return r.listLatest(ctx, playerKey(name), limit)
+}
+
+// FindActive looks the players up in the index of the active sessions.
+// The index is a sorted set of the normalized player names with equal scores,
+// so that the names starting with the prefix are obtained with a lexicographical range query.
+// The index is scanned in batches, each batch starting right after the last entry of the previous one.
+// The sessions that have ended or expired without being removed from the index are pruned on the way.
+func (r *Repository) FindActive(ctx context.Context, prefix string) ([]session.Session, error) {
+	lexRange := &redis.ZRangeBy{Min: "-", Max: "+", Count: scanBatch}
+	if prefix = session.NormalizeName(prefix); prefix != "" {
+		// no valid utf-8 string contains the 0xff byte, so it sorts after any name starting with the prefix
+		lexRange.Min, lexRange.Max = "["+prefix, "["+prefix+"\xff"
+	}
+
+	active := make([]session.Session, 0)
+	for {
+		members, err := r.client.ZRangeByLex(ctx, onlineKey, lexRange).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up active sessions: %w", err)
+		}
+		found, err := r.activeAmong(ctx, members)
+		if err != nil {
+			return nil, err
+		}
+		active = append(active, found...)
+		if len(members) < scanBatch {
+			break
+		}
+		lexRange.Min = "(" + members[len(members)-1]
+	}
+
+	return active, nil
+}
+
+// activeAmong returns the active sessions for the entries of the index, pruning the stale entries
+func (r *Repository) activeAmong(ctx context.Context, members []string) ([]session.Session, error) {
+	ids := make([]string, 0, len(members))
+	for _, member := range members {
+		_, id, _ := strings.Cut(member, onlineSep)
+		ids = append(ids, id)
+	}
+	found, err := r.getMany(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	active := make([]session.Session, 0, len(found))
+	alive := make(map[string]struct{}, len(found))
+	for _, sess := range found {
+		if sess.IsActive() {
+			active = append(active, sess)
+			alive[onlineMember(sess)] = struct{}{}
+		}
+	}
+	stale := make([]any, 0)
+	for _, member := range members {
+		if _, ok := alive[member]; !ok {
+			stale = append(stale, member)
+		}
+	}
+	if len(stale) > 0 {
+		if err := r.client.ZRem(ctx, onlineKey, stale...).Err(); err != nil {
+			return nil, fmt.Errorf("failed to prune active sessions: %w", err)
+		}
+	}
+
+	return active, nil
+}
+
+func (r *Repository) listLatest(ctx context.Context, key string, limit int) ([]session.Session, error) {
+	if limit <= 0 {
+		return []session.Session{}, nil
+	}
+	ids, err := r.client.ZRevRange(ctx, key, 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return r.getMany(ctx, ids)
+}
+
+// getMany returns the sessions in the order of the ids, skipping the expired ones
+func (r *Repository) getMany(ctx context.Context, ids []string) ([]session.Session, error) {
+	if len(ids) == 0 {
+		return []session.Session{}, nil
+	}
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, itemKey(id))
+	}
+	items, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve sessions: %w", err)
+	}
+	result := make([]session.Session, 0, len(items))
+	for _, item := range items {
+		encoded, ok := item.(string)
+		if !ok {
+			continue
+		}
+		var decoded session.Session
+		if err := json.Unmarshal([]byte(encoded), &decoded); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal session: %w", err)
+		}
+		result = append(result, decoded)
+	}
+	return result, nil
+}
+
+const (
+	onlineKey = "sessions:online"
+	onlineSep = "\x00"
+	// scanBatch is the number of the entries obtained from the index of the active sessions at a time
+	scanBatch = 500
+)
+
+// onlineMember is the entry of the session in the index of the active sessions.
+// The session id follows the name, so that the players sharing the name are told apart.
+func onlineMember(sess session.Session) string {
+	return session.NormalizeName(sess.Name) + onlineSep + sess.ID
+}
+
+func itemKey(id string) string {
+	return fmt.Sprintf("sessions:items:%s", id)
+}
+
+func activeKey(svrAddr addr.Addr) string {
+	return fmt.Sprintf("sessions:active:%s", svrAddr)
+}
+
+func serverKey(svrAddr addr.Addr) string {
+	return fmt.Sprintf("sessions:servers:%s", svrAddr)
+}
+
+func playerKey(name string) string {
+	return fmt.Sprintf("sessions:players:%s", session.NormalizeName(name))
+}
@@ -0,0 +1,251 @@
+package sessions_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/details"
+	"github.com/sergeii/swat4master/internal/core/entities/session"
+	"github.com/sergeii/swat4master/internal/persistence/redis/repositories/sessions"
+	tu "github.com/sergeii/swat4master/internal/testutils"
+	"github.com/sergeii/swat4master/internal/testutils/testredis"
+)
+
+func sessionIDs(items []session.Session) []string {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
+
+func TestSessionsRedisRepo_SaveAndList(t *testing.T) {
+	ctx := context.TODO()
+	rdb := testredis.MakeClient(t)
+	clock := clockwork.NewFakeClockAt(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	repo := sessions.New(rdb, clock)
+
+	svr1 := addr.MustNewFromDotted("1.1.1.1", 10480)
+	svr2 := addr.MustNewFromDotted("2.2.2.2", 10480)
+	now := clock.Now()
+
+	active, err := repo.Active(ctx, svr1)
+	require.NoError(t, err)
+	assert.Empty(t, active)
+
+	serge1 := session.New(svr1, details.Player{Name: "Serge", Score: 5}, now.Add(-time.Hour))
+	serge1.End(now.Add(-time.Minute * 30))
+	mosquito := session.New(svr1, details.Player{Name: "Mosquito"}, now.Add(-time.Minute*20))
+	serge2 := session.New(
+		svr2,
+		details.Player{Name: "[c=FF0000]serge", Team: details.TeamSuspects},
+		now.Add(-time.Minute*10),
+	)
+	tu.MustNoErr(repo.Save(ctx, serge1, mosquito, serge2))
+	tu.MustNoErr(repo.Save(ctx))
+
+	active, err = repo.Active(ctx, svr1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{mosquito.ID}, sessionIDs(active))
+
+	items, err := repo.ListByServer(ctx, svr1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{mosquito.ID, serge1.ID}, sessionIDs(items))
+	assert.Equal(t, 5, items[1].Score)
+	assert.False(t, items[1].IsActive())
+	assert.True(t, now.Add(-time.Minute*30).Equal(items[1].EndedAt))
+	assert.Equal(t, svr1, items[1].Addr)
+
+	items, err = repo.ListByServer(ctx, svr1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{mosquito.ID}, sessionIDs(items))
+
+	items, err = repo.ListByServer(ctx, svr1, 0)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	items, err = repo.ListByPlayer(ctx, "SERGE", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{serge2.ID, serge1.ID}, sessionIDs(items))
+	assert.Equal(t, details.PlayerTeam(details.TeamSuspects), items[0].Team)
+
+	items, err = repo.ListByPlayer(ctx, "[b]Serge[\\b]", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{serge2.ID, serge1.ID}, sessionIDs(items))
+
+	items, err = repo.ListByPlayer(ctx, "Unknown", 10)
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	// ending the session removes it from the active ones but keeps it in the history
+	mosquito.Track(details.Player{Name: "Mosquito", Score: 8, Kills: 2})
+	mosquito.End(now)
+	tu.MustNoErr(repo.Save(ctx, mosquito))
+
+	active, err = repo.Active(ctx, svr1)
+	require.NoError(t, err)
+	assert.Empty(t, active)
+
+	items, err = repo.ListByServer(ctx, svr1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{mosquito.ID, serge1.ID}, sessionIDs(items))
+	assert.Equal(t, 8, items[0].Score)
+	assert.Equal(t, 2, items[0].Kills)
+}
+
+func TestSessionsRedisRepo_EndActive(t *testing.T) {
+	ctx := context.TODO()
+	rdb := testredis.MakeClient(t)
+	clock := clockwork.NewFakeClockAt(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	repo := sessions.New(rdb, clock)
+
+	svr1 := addr.MustNewFromDotted("1.1.1.1", 10480)
+	svr2 := addr.MustNewFromDotted("2.2.2.2", 10480)
+	now := clock.Now()
+
+	serge := session.New(svr1, details.Player{Name: "Serge", Score: 5}, now.Add(-time.Hour))
+	mosquito := session.New(svr1, details.Player{Name: "Mosquito"}, now.Add(-time.Minute*20))
+	ended := session.New(svr1, details.Player{Name: "Bob"}, now.Add(-time.Hour*2))
+	ended.End(now.Add(-time.Hour))
+	other := session.New(svr2, details.Player{Name: "Alice"}, now.Add(-time.Minute*10))
+	tu.MustNoErr(repo.Save(ctx, serge, mosquito, ended, other))
+
+	count, err := repo.EndActive(ctx, svr1, now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	active, err := repo.Active(ctx, svr1)
+	require.NoError(t, err)
+	assert.Empty(t, active)
+
+	items, err := repo.ListByServer(ctx, svr1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{mosquito.ID, serge.ID, ended.ID}, sessionIDs(items))
+	assert.True(t, now.Equal(items[0].EndedAt))
+	assert.True(t, now.Equal(items[1].EndedAt))
+	assert.Equal(t, 5, items[1].Score)
+	assert.True(t, now.Add(-time.Hour).Equal(items[2].EndedAt))
+
+	online, err := repo.FindActive(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{other.ID}, sessionIDs(online))
+
+	// nothing is left to end on the server
+	count, err = repo.EndActive(ctx, svr1, now)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestSessionsRedisRepo_Retention(t *testing.T) {
+	ctx := context.TODO()
+	rdb := testredis.MakeClient(t)
+	clock := clockwork.NewFakeClockAt(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	repo := sessions.New(rdb, clock)
+
+	svrAddr := addr.MustNewFromDotted("1.1.1.1", 10480)
+
+	old := session.New(svrAddr, details.Player{Name: "Serge"}, clock.Now().Add(-time.Hour*24*40))
+	old.End(clock.Now().Add(-time.Hour * 24 * 39))
+	recent := session.New(svrAddr, details.Player{Name: "Serge"}, clock.Now().Add(-time.Hour))
+	tu.MustNoErr(repo.Save(ctx, old, recent))
+
+	items, err := repo.ListByServer(ctx, svrAddr, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{recent.ID}, sessionIDs(items))
+
+	items, err = repo.ListByPlayer(ctx, "Serge", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{recent.ID}, sessionIDs(items))
+}
+
+func TestSessionsRedisRepo_FindActive(t *testing.T) {
+	ctx := context.TODO()
+	rdb := testredis.MakeClient(t)
+	clock := clockwork.NewFakeClockAt(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	repo := sessions.New(rdb, clock)
+
+	svr1 := addr.MustNewFromDotted("1.1.1.1", 10480)
+	svr2 := addr.MustNewFromDotted("2.2.2.2", 10480)
+	now := clock.Now()
+
+	items, err := repo.FindActive(ctx, "serge")
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	serge1 := session.New(svr1, details.Player{Name: "[c=FF0000]Serge"}, now.Add(-time.Hour))
+	serge2 := session.New(svr2, details.Player{Name: "SERGE"}, now.Add(-time.Minute*10))
+	sergeant := session.New(svr2, details.Player{Name: "Sergeant"}, now.Add(-time.Minute*10))
+	mosquito := session.New(svr1, details.Player{Name: "Mosquito"}, now.Add(-time.Minute*20))
+	gone := session.New(svr1, details.Player{Name: "Serge Gone"}, now.Add(-time.Hour))
+	gone.End(now.Add(-time.Minute))
+	tu.MustNoErr(repo.Save(ctx, serge1, serge2, sergeant, mosquito, gone))
+
+	items, err = repo.FindActive(ctx, "[c=00FF00]SeRg")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{serge1.ID, serge2.ID, sergeant.ID}, sessionIDs(items))
+	assert.Equal(t, sergeant.ID, items[2].ID)
+
+	items, err = repo.FindActive(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, items, 4)
+	assert.Equal(t, mosquito.ID, items[0].ID)
+
+	// the ended session is removed from the index
+	serge2.End(now)
+	tu.MustNoErr(repo.Save(ctx, serge2))
+
+	items, err = repo.FindActive(ctx, "serge")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{serge1.ID, sergeant.ID}, sessionIDs(items))
+
+	// the expired session is pruned from the index
+	rdb.Del(ctx, "sessions:items:"+sergeant.ID)
+
+	items, err = repo.FindActive(ctx, "serge")
+	require.NoError(t, err)
+	assert.Equal(t, []string{serge1.ID}, sessionIDs(items))
+
+	members, err := rdb.ZCard(ctx, "sessions:online").Result()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), members)
+}
+
+func TestSessionsRedisRepo_FindActive_ScansWholeIndex(t *testing.T) {
+	ctx := context.TODO()
+	rdb := testredis.MakeClient(t)
+	clock := clockwork.NewFakeClockAt(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	repo := sessions.New(rdb, clock)
+
+	svrAddr := addr.MustNewFromDotted("1.1.1.1", 10480)
+
+	// more players than are obtained from the index at a time
+	online := make([]session.Session, 0, 1234)
+	for i := range 1234 {
+		name := fmt.Sprintf("Player%04d", i)
+		online = append(online, session.New(svrAddr, details.Player{Name: name}, clock.Now()))
+	}
+	tu.MustNoErr(repo.Save(ctx, online...))
+	// some of the sessions have expired in the middle of the index
+	rdb.Del(ctx, "sessions:items:"+online[500].ID, "sessions:items:"+online[1000].ID)
+
+	items, err := repo.FindActive(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, items, 1232)
+	assert.Equal(t, online[0].ID, items[0].ID)
+	assert.Equal(t, online[1233].ID, items[1231].ID)
+
+	items, err = repo.FindActive(ctx, "player1")
+	require.NoError(t, err)
+	assert.Len(t, items, 233)
+
+	members, err := rdb.ZCard(ctx, "sessions:online").Result()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1232), members)
+}
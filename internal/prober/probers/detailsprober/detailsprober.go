@@ -5,19 +5,18 @@ import (
 	"errors"
 	"fmt"
 	"net/netip"
-	"os"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/jonboulle/clockwork"
 	"github.com/rs/zerolog"
 
-	"github.com/sergeii/swat4master/internal/core/entities/addr"
 	"github.com/sergeii/swat4master/internal/core/entities/details"
 	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
 	"github.com/sergeii/swat4master/internal/core/entities/server"
 	"github.com/sergeii/swat4master/internal/metrics"
-	"github.com/sergeii/swat4master/pkg/gamespy/serverquery/gs1"
+	"github.com/sergeii/swat4master/internal/prober/querier"
+	"github.com/sergeii/swat4master/pkg/udp/udpclient"
 )
 
 var (
@@ -27,58 +26,81 @@ var (
 	ErrValidationFailed = errors.New("failed to validate server query response")
 )
 
+type Result struct {
+	Details  details.Details
+	Protocol querier.Protocol
+	Variant  string
+	RTT      time.Duration
+	// Fields are the raw query response fields, as they may carry the ownership claim token
+	Fields map[string]string
+}
+
+var NoResult Result
+
+type Opts struct {
+	RefreshPolicy server.RefreshPolicy
+}
+
 type DetailsProber struct {
-	metrics  *metrics.Collector
-	validate *validator.Validate
-	clock    clockwork.Clock
-	logger   *zerolog.Logger
+	protocols map[querier.Protocol]querier.QueryFunc
+	metrics   *metrics.Collector
+	validate  *validator.Validate
+	clock     clockwork.Clock
+	logger    *zerolog.Logger
+	opts      Opts
 }
 
 func New(
+	opts Opts,
+	dialer udpclient.Dialer,
 	validate *validator.Validate,
 	clock clockwork.Clock,
 	metrics *metrics.Collector,
 	logger *zerolog.Logger,
 ) DetailsProber {
 	return DetailsProber{
-		validate: validate,
-		clock:    clock,
-		metrics:  metrics,
-		logger:   logger,
+		protocols: querier.ProtocolQueries(dialer),
+		validate:  validate,
+		clock:     clock,
+		metrics:   metrics,
+		logger:    logger,
+		opts:      opts,
 	}
 }
 
-// Probe probes specified server's GS1 query port
+// Probe probes specified server's query port with the protocol the server has been answering with.
+// Should the server fail to answer, or should it have never been queried before,
+// every other supported protocol is tried, taking the response of the protocol that has answered first.
 // On success, update the server's extended params
 // In case a server with specified identifier does not exit,
 // create the server beforehand.
 func (p DetailsProber) Probe(
 	ctx context.Context,
-	svrAddr addr.Addr,
+	svr server.Server,
 	queryPort int,
 	timeout time.Duration,
 ) (any, error) {
+	svrAddr := svr.Addr
 	qAddr := netip.AddrPortFrom(netip.AddrFrom4(svrAddr.IP), uint16(queryPort)) //nolint:gosec
 
-	queryStarted := time.Now()
-
-	resp, err := gs1.Query(ctx, qAddr, timeout)
+	preferred := querier.ParseProtocol(svr.QueryProtocol)
+	resp, err := querier.QueryPreferred(ctx, qAddr, timeout, preferred, p.protocols)
 	if err != nil {
 		p.logger.Info().
 			Err(err).
 			Dur("timeout", timeout).Stringer("addr", svrAddr).Int("port", queryPort).
 			Msg("Failed to probe details")
-		if errors.Is(err, os.ErrDeadlineExceeded) {
-			return details.Blank, fmt.Errorf("%w: %w", ErrQueryTimeout, err)
+		if querier.IsTimeout(err) {
+			return NoResult, fmt.Errorf("%w: %w", ErrQueryTimeout, err)
 		}
-		return details.Blank, fmt.Errorf("%w: %w", ErrQueryFailed, err)
+		return NoResult, fmt.Errorf("%w: %w", ErrQueryFailed, err)
 	}
 
-	queryDur := time.Since(queryStarted).Seconds()
-	p.metrics.DiscoveryQueryDurations.Observe(queryDur)
+	p.metrics.DiscoveryQueryDurations.Observe(resp.RTT.Seconds())
+	p.metrics.DiscoveryQueryProtocols.WithLabelValues(string(resp.Protocol)).Inc()
 	p.logger.Debug().
 		Stringer("addr", svrAddr).Int("port", queryPort).
-		Float64("duration", queryDur).Stringer("version", resp.Version).
+		Dur("rtt", resp.RTT).Str("version", resp.Variant()).
 		Msg("Successfully queried server")
 
 	svrDetails, err := details.NewDetailsFromParams(resp.Fields, resp.Players, resp.Objectives)
@@ -86,27 +108,44 @@ func (p DetailsProber) Probe(
 		p.logger.Error().
 			Err(err).Stringer("addr", svrAddr).Int("port", queryPort).
 			Msg("Failed to parse query response")
-		return details.Blank, fmt.Errorf("%w: %w", ErrParseFailed, err)
+		return NoResult, fmt.Errorf("%w: %w", ErrParseFailed, err)
 	}
 	if validateErr := svrDetails.Validate(p.validate); validateErr != nil {
 		p.logger.Error().
 			Err(validateErr).Stringer("addr", svrAddr).Int("port", queryPort).
 			Msg("Failed to validate query response")
-		return details.Blank, fmt.Errorf("%w: %w", ErrValidationFailed, validateErr)
+		return NoResult, fmt.Errorf("%w: %w", ErrValidationFailed, validateErr)
 	}
 
-	return svrDetails, nil
+	result := Result{
+		Details:  svrDetails,
+		Fields:   resp.Fields,
+		Protocol: resp.Protocol,
+		Variant:  resp.Variant(),
+		RTT:      resp.RTT,
+	}
+	return result, nil
 }
 
 func (p DetailsProber) HandleSuccess(result any, svr server.Server) server.Server {
-	det, ok := result.(details.Details)
+	res, ok := result.(Result)
 	if !ok {
 		panic(fmt.Errorf("unexpected result type %T, %v", result, result))
 	}
-	svr.UpdateDetails(det)
-	svr.Refresh(p.clock.Now())
+	now := p.clock.Now()
+	prevInfo := svr.Info
+	svr.UpdateDetails(res.Details)
+	svr.Reschedule(prevInfo, now, p.opts.RefreshPolicy)
+	svr.RecordQuery(res.Variant, res.RTT, now)
+	svr.Refresh(now)
 	svr.UpdateDiscoveryStatus(ds.Info | ds.Details)
 	svr.ClearDiscoveryStatus(ds.NoDetails | ds.DetailsRetry)
+	if svr.ConfirmOwnership(res.Fields, now) {
+		p.logger.Info().Stringer("server", svr).Msg("Confirmed server ownership")
+	}
+	if svr.VerifyListing(res.Details.Info, now) {
+		p.logger.Warn().Stringer("server", svr).Msg("Server keeps reporting info that disagrees with its queries")
+	}
 	return svr
 }
 
@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/jonboulle/clockwork"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -15,12 +17,16 @@ import (
 	"github.com/sergeii/swat4master/internal/core/entities/addr"
 	"github.com/sergeii/swat4master/internal/core/entities/details"
 	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/internal/core/entities/server"
 	"github.com/sergeii/swat4master/internal/metrics"
 	"github.com/sergeii/swat4master/internal/prober/probers/detailsprober"
+	"github.com/sergeii/swat4master/internal/prober/querier"
 	"github.com/sergeii/swat4master/internal/testutils"
 	"github.com/sergeii/swat4master/internal/testutils/factories/serverfactory"
 	"github.com/sergeii/swat4master/internal/validation"
 	"github.com/sergeii/swat4master/pkg/gamespy/serverquery/gs1"
+	"github.com/sergeii/swat4master/pkg/gamespy/serverquery/qr2"
+	"github.com/sergeii/swat4master/pkg/udp/udpclient"
 )
 
 type ResponseFunc func(context.Context, *net.UDPConn, *net.UDPAddr, []byte)
@@ -32,7 +38,7 @@ func TestDetailsProber_Probe_OK(t *testing.T) {
 	validate := validation.MustNew()
 	collector := metrics.New()
 
-	prober := detailsprober.New(validate, clock, collector, &logger)
+	prober := detailsprober.New(detailsprober.Opts{}, udpclient.Direct{}, validate, clock, collector, &logger)
 
 	responses := make(chan []byte)
 	queryHandler, cancel := gs1.PrepareGS1Server(responses)
@@ -55,10 +61,15 @@ func TestDetailsProber_Probe_OK(t *testing.T) {
 		)
 	}(svrAddr.Port)
 
-	ret, err := prober.Probe(ctx, svrAddr, queryAddr.Port, time.Millisecond*100)
+	ret, err := prober.Probe(ctx, server.MustNewFromAddr(svrAddr, queryAddr.Port), queryAddr.Port, time.Millisecond*100)
 	require.NoError(t, err)
 
-	det := ret.(details.Details) //nolint:forcetypeassert
+	result := ret.(detailsprober.Result) //nolint:forcetypeassert
+	assert.Equal(t, querier.ProtocolGS1, result.Protocol)
+	assert.Equal(t, "gs1/gs1", result.Variant)
+	assert.Positive(t, result.RTT)
+
+	det := result.Details
 	assert.Equal(t, "-==MYT Co-op Svr==-", det.Info.Hostname)
 	assert.Equal(t, 0, det.Info.NumPlayers)
 	assert.Equal(t, 5, det.Info.MaxPlayers)
@@ -71,6 +82,106 @@ func TestDetailsProber_Probe_OK(t *testing.T) {
 	assert.Empty(t, det.Players)
 }
 
+func TestDetailsProber_Probe_QR2(t *testing.T) {
+	ctx := context.TODO()
+	logger := zerolog.Nop()
+	clock := clockwork.NewFakeClock()
+	validate := validation.MustNew()
+	collector := metrics.New()
+
+	prober := detailsprober.New(detailsprober.Opts{}, udpclient.Direct{}, validate, clock, collector, &logger)
+
+	packets := make(chan []byte)
+	queryHandler, cancel := qr2.PrepareQR2Server(12345, packets)
+	defer cancel()
+
+	queryAddr := queryHandler.LocalAddr()
+	svrAddr := addr.NewForTesting(queryAddr.IP, queryAddr.Port-1)
+
+	go func(gamePort int) {
+		packets <- qr2.PackPacket(
+			0, true,
+			qr2.PackServerSection(
+				"hostname", "QR2 Server", "numplayers", "2", "maxplayers", "16",
+				"gametype", "VIP Escort", "gamevariant", "SWAT 4", "mapname", "Fairfax Residence",
+				"hostport", strconv.Itoa(gamePort), "password", "0", "gamever", "1.1",
+			),
+			qr2.PackItemSection(
+				qr2.SectionPlayer,
+				qr2.ItemKey{Name: "player_", Offset: 0, Values: []string{"Alice", "Bob"}},
+				qr2.ItemKey{Name: "score_", Offset: 0, Values: []string{"10", "5"}},
+				qr2.ItemKey{Name: "ping_", Offset: 0, Values: []string{"50", "60"}},
+			),
+			qr2.PackItemSection(
+				qr2.SectionTeam,
+				qr2.ItemKey{Name: "team_t", Offset: 0, Values: []string{"SWAT", "Suspects"}},
+				qr2.ItemKey{Name: "score_t", Offset: 0, Values: []string{"41", "36"}},
+			),
+		)
+	}(svrAddr.Port)
+
+	ret, err := prober.Probe(ctx, server.MustNewFromAddr(svrAddr, queryAddr.Port), queryAddr.Port, time.Millisecond*100)
+	require.NoError(t, err)
+
+	result := ret.(detailsprober.Result) //nolint:forcetypeassert
+	assert.Equal(t, querier.ProtocolQR2, result.Protocol)
+	assert.Equal(t, "qr2", result.Variant)
+
+	det := result.Details
+	assert.Equal(t, "QR2 Server", det.Info.Hostname)
+	assert.Equal(t, 2, det.Info.NumPlayers)
+	assert.Equal(t, "Fairfax Residence", det.Info.MapName)
+	// the team scores are reported in the team section
+	assert.Equal(t, 41, det.Info.SwatScore)
+	assert.Equal(t, 36, det.Info.SuspectsScore)
+	require.Len(t, det.Players, 2)
+	assert.Equal(t, "Alice", det.Players[0].Name)
+	assert.Equal(t, 10, det.Players[0].Score)
+	assert.Equal(t, "Bob", det.Players[1].Name)
+	assert.Equal(t, 60, det.Players[1].Ping)
+
+	protocolMetricValue := testutil.ToFloat64(collector.DiscoveryQueryProtocols.WithLabelValues("qr2"))
+	assert.InDelta(t, 1, protocolMetricValue, 1e-9)
+}
+
+func TestDetailsProber_Probe_FallBackFromRecordedProtocol(t *testing.T) {
+	ctx := context.TODO()
+	logger := zerolog.Nop()
+	clock := clockwork.NewFakeClock()
+	validate := validation.MustNew()
+	collector := metrics.New()
+
+	prober := detailsprober.New(detailsprober.Opts{}, udpclient.Direct{}, validate, clock, collector, &logger)
+
+	packets := make(chan []byte)
+	queryHandler, cancel := qr2.PrepareQR2Server(12345, packets)
+	defer cancel()
+
+	queryAddr := queryHandler.LocalAddr()
+	svrAddr := addr.NewForTesting(queryAddr.IP, queryAddr.Port-1)
+	svr := server.MustNewFromAddr(svrAddr, queryAddr.Port)
+	// the server used to answer with gs1, but has since had its mod removed
+	svr.RecordQuery("gs1/gs1", time.Millisecond*10, clock.Now())
+
+	go func(gamePort int) {
+		packets <- qr2.PackPacket(
+			0, true,
+			qr2.PackServerSection(
+				"hostname", "QR2 Server", "numplayers", "0", "maxplayers", "16",
+				"gametype", "VIP Escort", "gamevariant", "SWAT 4", "mapname", "Fairfax Residence",
+				"hostport", strconv.Itoa(gamePort), "password", "0", "gamever", "1.1",
+			),
+		)
+	}(svrAddr.Port)
+
+	ret, err := prober.Probe(ctx, svr, queryAddr.Port, time.Millisecond*100)
+	require.NoError(t, err)
+
+	result := ret.(detailsprober.Result) //nolint:forcetypeassert
+	assert.Equal(t, querier.ProtocolQR2, result.Protocol)
+	assert.Equal(t, "QR2 Server", result.Details.Info.Hostname)
+}
+
 func TestDetailsProber_Probe_Fail(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -130,7 +241,7 @@ func TestDetailsProber_Probe_Fail(t *testing.T) {
 			validate := validation.MustNew()
 			collector := metrics.New()
 
-			prober := detailsprober.New(validate, clock, collector, &logger)
+			prober := detailsprober.New(detailsprober.Opts{}, udpclient.Direct{}, validate, clock, collector, &logger)
 
 			queryHandler, cancel := gs1.ServerFactory(tt.respFactory)
 			defer cancel()
@@ -138,7 +249,8 @@ func TestDetailsProber_Probe_Fail(t *testing.T) {
 			queryAddr := queryHandler.LocalAddr()
 			svrAddr := addr.NewForTesting(queryAddr.IP, queryAddr.Port-1)
 
-			_, probeErr := prober.Probe(ctx, svrAddr, queryAddr.Port, time.Millisecond*100)
+			svr := server.MustNewFromAddr(svrAddr, queryAddr.Port)
+			_, probeErr := prober.Probe(ctx, svr, queryAddr.Port, time.Millisecond*100)
 			require.ErrorIs(t, probeErr, tt.wantErr)
 		})
 	}
@@ -174,16 +286,123 @@ func TestDetailsProber_HandleSuccess_OK(t *testing.T) {
 			validate := validation.MustNew()
 			collector := metrics.New()
 
-			prober := detailsprober.New(validate, clock, collector, &logger)
+			prober := detailsprober.New(detailsprober.Opts{}, udpclient.Direct{}, validate, clock, collector, &logger)
 
 			svr := serverfactory.Build(serverfactory.WithDiscoveryStatus(tt.initStatus))
 			params := testutils.GenExtraServerParams(map[string]string{"mapname": "A-Bomb Nightclub"})
 			det := details.MustNewDetailsFromParams(params, nil, nil)
-
-			updatedSvr := prober.HandleSuccess(det, svr)
+			result := detailsprober.Result{
+				Details:  det,
+				Protocol: querier.ProtocolGS1,
+				Variant:  "gs1/am",
+				RTT:      time.Millisecond * 50,
+			}
+
+			updatedSvr := prober.HandleSuccess(result, svr)
 			assert.Equal(t, tt.wantStatus, updatedSvr.DiscoveryStatus)
+			assert.Equal(t, "A-Bomb Nightclub", updatedSvr.Info.MapName)
+			assert.Equal(t, "gs1/am", updatedSvr.QueryProtocol)
+			assert.Equal(t, time.Millisecond*50, updatedSvr.Latency.Last)
+			assert.Equal(t, time.Millisecond*50, updatedSvr.Latency.Average)
+			assert.Equal(t, clock.Now(), updatedSvr.QueriedAt)
+			assert.Equal(t, clock.Now(), updatedSvr.RefreshedAt)
+			assert.Equal(t, clock.Now(), updatedSvr.Listing.VerifiedAt)
+		})
+	}
+}
+
+func TestDetailsProber_HandleSuccess_ComparesReport(t *testing.T) {
+	logger := zerolog.Nop()
+	clock := clockwork.NewFakeClock()
+	validate := validation.MustNew()
+	collector := metrics.New()
+
+	prober := detailsprober.New(detailsprober.Opts{}, udpclient.Direct{}, validate, clock, collector, &logger)
+
+	params := testutils.GenExtraServerParams(map[string]string{"numplayers": "0", "maxplayers": "16"})
+	result := detailsprober.Result{
+		Details:  details.MustNewDetailsFromParams(params, nil, nil),
+		Protocol: querier.ProtocolGS1,
+		Variant:  "gs1/am",
+		RTT:      time.Millisecond * 50,
+	}
+	reported := details.MustNewInfoFromParams(
+		testutils.GenExtraServerParams(map[string]string{"numplayers": "16", "maxplayers": "16"}),
+	)
+
+	svr := serverfactory.Build(serverfactory.WithDiscoveryStatus(ds.Master | ds.Info | ds.Details))
+	for range server.MismatchThreshold {
+		svr.ReportListing(reported, clock.Now())
+		svr = prober.HandleSuccess(result, svr)
+	}
+	assert.True(t, svr.Listing.IsVerified())
+	assert.True(t, svr.Listing.IsMismatched())
+}
+
+func TestDetailsProber_HandleSuccess_ConfirmsOwnership(t *testing.T) {
+	tests := []struct {
+		name          string
+		fields        map[string]string
+		expires       time.Duration
+		wantConfirmed bool
+	}{
+		{
+			"token in hostname",
+			map[string]string{"hostname": "[c=FF0000]My Server[\\c] S4M-[b]1a2b3c4d"},
+			time.Hour,
+			true,
+		},
+		{
+			"token in other field",
+			map[string]string{"hostname": "My Server", "admin": "s4m-1a2b3c4d"},
+			time.Hour,
+			true,
+		},
+		{
+			"no token",
+			map[string]string{"hostname": "My Server s4m-deadbeef"},
+			time.Hour,
+			false,
+		},
+		{
+			"claim expired",
+			map[string]string{"hostname": "My Server s4m-1a2b3c4d"},
+			-time.Second,
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := zerolog.Nop()
+			clock := clockwork.NewFakeClock()
+			validate := validation.MustNew()
+			collector := metrics.New()
 
-			updatedSvr.RefreshedAt = clock.Now()
+			prober := detailsprober.New(detailsprober.Opts{}, udpclient.Direct{}, validate, clock, collector, &logger)
+
+			svr := serverfactory.Build(serverfactory.WithDiscoveryStatus(ds.Info | ds.Details))
+			svr.ClaimOwnership(server.Claim{
+				Token:     "s4m-1a2b3c4d",
+				KeyHash:   server.HashOwnerKey("secret"),
+				ExpiresAt: clock.Now().Add(tt.expires),
+			}, clock.Now())
+			params := testutils.GenExtraServerParams(map[string]string{"mapname": "A-Bomb Nightclub"})
+			result := detailsprober.Result{
+				Details:  details.MustNewDetailsFromParams(params, nil, nil),
+				Protocol: querier.ProtocolGS1,
+				Variant:  "gs1/am",
+				RTT:      time.Millisecond * 50,
+				Fields:   tt.fields,
+			}
+
+			updatedSvr := prober.HandleSuccess(result, svr)
+			assert.Equal(t, tt.wantConfirmed, updatedSvr.Ownership.IsVerified())
+			assert.Equal(t, tt.wantConfirmed, updatedSvr.Ownership.Authorize("secret"))
+			if tt.wantConfirmed {
+				assert.Equal(t, clock.Now(), updatedSvr.Ownership.VerifiedAt)
+				assert.Empty(t, updatedSvr.Ownership.Claims)
+			}
 		})
 	}
 }
@@ -218,7 +437,7 @@ func TestDetailsProber_HandleRetry_OK(t *testing.T) {
 			validate := validation.MustNew()
 			collector := metrics.New()
 
-			prober := detailsprober.New(validate, clock, collector, &logger)
+			prober := detailsprober.New(detailsprober.Opts{}, udpclient.Direct{}, validate, clock, collector, &logger)
 
 			svr := serverfactory.Build(serverfactory.WithDiscoveryStatus(tt.initStatus))
 
@@ -263,7 +482,7 @@ func TestDetailsProber_HandleFailure_OK(t *testing.T) {
 			validate := validation.MustNew()
 			collector := metrics.New()
 
-			prober := detailsprober.New(validate, clock, collector, &logger)
+			prober := detailsprober.New(detailsprober.Opts{}, udpclient.Direct{}, validate, clock, collector, &logger)
 
 			svr := serverfactory.Build(serverfactory.WithDiscoveryStatus(tt.initStatus))
 
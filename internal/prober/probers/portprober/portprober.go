@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/netip"
+	"slices"
 	"strconv"
 	"sync"
 	"time"
@@ -16,161 +17,379 @@ import (
 	"github.com/sergeii/swat4master/internal/core/entities/addr"
 	"github.com/sergeii/swat4master/internal/core/entities/details"
 	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/internal/core/entities/portoffset"
 	"github.com/sergeii/swat4master/internal/core/entities/server"
+	"github.com/sergeii/swat4master/internal/core/repositories"
 	"github.com/sergeii/swat4master/internal/metrics"
-	"github.com/sergeii/swat4master/pkg/gamespy/serverquery/gs1"
+	"github.com/sergeii/swat4master/internal/prober/querier"
+	"github.com/sergeii/swat4master/pkg/udp/udpclient"
 )
 
 var (
 	ErrPortDiscoveryFailed = errors.New("port discovery probes failed")
 	ErrParseFailed         = errors.New("failed to parse discovered port response")
 	ErrValidationFailed    = errors.New("failed to validate discovered port response")
+	ErrHostPortInvalid     = errors.New("server reports invalid game port")
+	ErrHostPortMismatch    = errors.New("server reports another game port")
 )
 
 type Result struct {
-	Details details.Details
-	Port    int
+	Details  details.Details
+	Port     int
+	Protocol querier.Protocol
+	Variant  string
+	RTT      time.Duration
 }
 
 var NoResult Result
 
+// Outcome is the result of querying one of the candidate query ports of a server
+type Outcome struct {
+	Port   int
+	Offset int
+	// Fallback tells whether the port is one of the fallback ports rather than an offset from the game port
+	Fallback bool
+	// Response is the preferred response the port has answered with, if any
+	Response querier.Response
+	Err      error
+}
+
+// Answered tells whether the server has answered on the port
+func (o Outcome) Answered() bool {
+	return o.Err == nil
+}
+
 type response struct {
-	Response gs1.Response
-	Port     int
+	Response  querier.Response
+	Candidate candidate
+}
+
+// candidate is a port tried as the query port of a server
+type candidate struct {
+	Port int
+	// Fallback tells whether the port is one of the fallback ports rather than an offset from the game port
+	Fallback bool
+}
+
+// fallbackLabel is the metric label of the fallback ports.
+// Unlike the offsets, the fallback ports are not labelled with the offset from the game port,
+// as that would make every game port have its own label.
+const fallbackLabel = "fallback"
+
+func (c candidate) label(gamePort int) string {
+	if c.Fallback {
+		return fallbackLabel
+	}
+	return strconv.Itoa(c.Port - gamePort)
 }
 
 type Opts struct {
 	Offsets []int
+	// FallbackOffsets and FallbackPorts are tried when none of the quick offsets has answered
+	FallbackOffsets []int
+	FallbackPorts   []int
 }
 
+// learnedOffsets is the number of offsets learned from the other servers that are tried along the quick offsets
+const learnedOffsets = 2
+
 type PortProber struct {
-	metrics  *metrics.Collector
-	validate *validator.Validate
-	clock    clockwork.Clock
-	logger   *zerolog.Logger
-	opts     Opts
+	protocols  []querier.QueryFunc
+	offsetRepo repositories.PortOffsetRepository
+	metrics    *metrics.Collector
+	validate   *validator.Validate
+	clock      clockwork.Clock
+	logger     *zerolog.Logger
+	opts       Opts
 }
 
 func New(
 	opts Opts,
+	dialer udpclient.Dialer,
+	offsetRepo repositories.PortOffsetRepository,
 	validate *validator.Validate,
 	clock clockwork.Clock,
 	metrics *metrics.Collector,
 	logger *zerolog.Logger,
 ) PortProber {
 	return PortProber{
-		metrics:  metrics,
-		validate: validate,
-		clock:    clock,
-		logger:   logger,
-		opts:     opts,
+		protocols:  querier.Protocols(dialer),
+		offsetRepo: offsetRepo,
+		metrics:    metrics,
+		validate:   validate,
+		clock:      clock,
+		logger:     logger,
+		opts:       opts,
 	}
 }
 
 // Probe attempts to discover a query port for a given server address.
 // To discover the query port, several ports are tried: public port +1, +2 and so forth.
+// Every port is queried with every supported protocol.
 // In case when multiple query ports are available, the preferred port would be selected
-// according to this order: gs1 mod, admin mod, vanilla response.
+// according to this order: gs1 mod, admin mod, qr2, vanilla response.
+//
+// The ports are tried in stages, until one of the stages succeeds.
+// First, the offset previously discovered for the server's IP address is tried alone.
+// Then the quick offsets are tried along with the offsets most often discovered on the server's network
+// and globally, and finally the fallback ports, if any.
+// The timeout covers the whole probe, so that it is shared by the stages instead of being given to each of them.
 func (p PortProber) Probe(
 	ctx context.Context,
-	svrAddr addr.Addr,
+	svr server.Server,
 	_ int,
 	timeout time.Duration,
 ) (any, error) {
-	results := make(chan response)
-	done := make(chan struct{})
-	wg := &sync.WaitGroup{}
-
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-
-	ip := netip.AddrFrom4(svrAddr.IP)
-	for _, pIdx := range p.opts.Offsets {
-		wg.Go(func() {
-			p.probePort(ctx, results, ip, svrAddr.Port, svrAddr.Port+pIdx, timeout)
-		})
+	svrAddr := svr.Addr
+	learned, err := p.offsetRepo.Get(ctx, svrAddr.IP)
+	if err != nil {
+		p.logger.Warn().Err(err).Stringer("addr", svrAddr).Msg("Unable to obtain learned port offsets")
+		learned = portoffset.Learned{}
 	}
 
-	go func() {
-		wg.Wait()
-		close(done)
-	}()
-
-	best, ok := p.collectResponses(results, done, timeout)
+	var best response
+	ok := false
+	stages := p.plan(svrAddr.Port, learned)
+	// the queries time out in real time, and so does the probe
+	deadline := time.Now().Add(timeout)
+	for i, ports := range stages {
+		// the time left is split evenly between the stages yet to be tried
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			break
+		}
+		stageTimeout := remaining / time.Duration(len(stages)-i)
+		if best, ok = p.probePorts(ctx, svrAddr, ports, stageTimeout); ok {
+			break
+		}
+	}
 	if !ok {
 		return NoResult, ErrPortDiscoveryFailed
 	}
 
+	bestPort := best.Candidate.Port
 	p.logger.Debug().
-		Stringer("addr", svrAddr).Stringer("version", best.Response.Version).Int("port", best.Port).
+		Stringer("addr", svrAddr).Str("version", best.Response.Variant()).Int("port", bestPort).
 		Msg("Selected preferred response")
 
 	det, err := details.NewDetailsFromParams(best.Response.Fields, best.Response.Players, best.Response.Objectives)
 	if err != nil {
 		p.logger.Error().
 			Err(err).
-			Stringer("addr", svrAddr).Stringer("version", best.Response.Version).Int("port", best.Port).
+			Stringer("addr", svrAddr).Str("version", best.Response.Variant()).Int("port", bestPort).
 			Msg("Unable to parse response")
 		return NoResult, fmt.Errorf("%w: %w", ErrParseFailed, err)
 	}
 	if validateErr := det.Validate(p.validate); validateErr != nil {
 		p.logger.Error().
 			Err(validateErr).
-			Stringer("addr", svrAddr).Stringer("version", best.Response.Version).Int("Port", best.Port).
+			Stringer("addr", svrAddr).Str("version", best.Response.Variant()).Int("Port", bestPort).
 			Msg("Failed to validate query response")
 		return details.Blank, fmt.Errorf("%w: %w", ErrValidationFailed, validateErr)
 	}
 
+	p.metrics.DiscoveryQueryProtocols.WithLabelValues(string(best.Response.Protocol)).Inc()
+
+	// the fallback ports are not offsets, so they would only mislead the servers on the same network
+	if !best.Candidate.Fallback {
+		if err := p.offsetRepo.Record(ctx, svrAddr.IP, bestPort-svrAddr.Port); err != nil {
+			p.logger.Warn().Err(err).Stringer("addr", svrAddr).Int("port", bestPort).Msg("Unable to record port offset")
+		}
+	}
+
 	result := Result{
-		Details: det,
-		Port:    best.Port,
+		Details:  det,
+		Port:     bestPort,
+		Protocol: best.Response.Protocol,
+		Variant:  best.Response.Variant(),
+		RTT:      best.Response.RTT,
 	}
 	return result, nil
 }
 
+// Survey queries every candidate query port of the server, the fallback ports included,
+// and reports the outcome of each port in the order the ports would be tried.
+// Unlike Probe, it doesn't stop at the first stage that succeeds,
+// and it neither uses nor records the learned port offsets.
+func (p PortProber) Survey(
+	ctx context.Context,
+	svrAddr addr.Addr,
+	timeout time.Duration,
+) []Outcome {
+	candidates := slices.Concat(p.plan(svrAddr.Port, portoffset.Learned{})...)
+	outcomes := make([]Outcome, len(candidates))
+	ip := netip.AddrFrom4(svrAddr.IP)
+
+	wg := &sync.WaitGroup{}
+	for i, cand := range candidates {
+		wg.Go(func() {
+			outcome := Outcome{Port: cand.Port, Offset: cand.Port - svrAddr.Port, Fallback: cand.Fallback}
+			resps, err := p.queryPort(ctx, ip, svrAddr.Port, cand, timeout)
+			for _, resp := range resps {
+				if resp.Rank() > outcome.Response.Rank() {
+					outcome.Response = resp
+				}
+			}
+			outcome.Err = err
+			outcomes[i] = outcome
+		})
+	}
+	wg.Wait()
+
+	return outcomes
+}
+
+// plan groups the candidate query ports into the stages tried one after another.
+// A port is never tried twice, and the stages left with no ports are skipped.
+func (p PortProber) plan(gamePort int, learned portoffset.Learned) [][]candidate {
+	tried := make(map[int]struct{})
+	stage := func(candidates []candidate) []candidate {
+		untried := make([]candidate, 0, len(candidates))
+		for _, cand := range candidates {
+			if _, ok := tried[cand.Port]; ok || cand.Port < 1 || cand.Port > 65535 {
+				continue
+			}
+			tried[cand.Port] = struct{}{}
+			untried = append(untried, cand)
+		}
+		return untried
+	}
+	withOffsets := func(offsets ...[]int) []candidate {
+		candidates := make([]candidate, 0)
+		for _, group := range offsets {
+			for _, offset := range group {
+				candidates = append(candidates, candidate{Port: gamePort + offset})
+			}
+		}
+		return candidates
+	}
+	fallbackPorts := make([]candidate, 0, len(p.opts.FallbackPorts))
+	for _, port := range p.opts.FallbackPorts {
+		fallbackPorts = append(fallbackPorts, candidate{Port: port, Fallback: true})
+	}
+
+	stages := make([][]candidate, 0, 3)
+	if offset, ok := learned.Likely(); ok {
+		stages = append(stages, stage(withOffsets([]int{offset})))
+	}
+	stages = append(
+		stages,
+		stage(withOffsets(p.opts.Offsets, learned.Ranked(learnedOffsets))),
+		stage(append(withOffsets(p.opts.FallbackOffsets), fallbackPorts...)),
+	)
+
+	return slices.DeleteFunc(stages, func(candidates []candidate) bool {
+		return len(candidates) == 0
+	})
+}
+
+func (p PortProber) probePorts(
+	ctx context.Context,
+	svrAddr addr.Addr,
+	candidates []candidate,
+	timeout time.Duration,
+) (response, bool) {
+	results := make(chan response)
+	done := make(chan struct{})
+	wg := &sync.WaitGroup{}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ip := netip.AddrFrom4(svrAddr.IP)
+	for _, cand := range candidates {
+		wg.Go(func() {
+			p.probePort(ctx, results, ip, svrAddr.Port, cand, timeout)
+		})
+	}
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	return p.collectResponses(results, done, timeout)
+}
+
 func (p PortProber) probePort(
 	ctx context.Context,
 	responses chan response,
 	ip netip.Addr,
 	gamePort int,
-	queryPort int,
+	cand candidate,
 	timeout time.Duration,
 ) {
-	queryStarted := time.Now()
+	resps, err := p.queryPort(ctx, ip, gamePort, cand, timeout)
+	if err != nil {
+		return
+	}
+	for _, resp := range resps {
+		responses <- response{resp, cand}
+	}
+}
 
-	resp, err := gs1.Query(ctx, netip.AddrPortFrom(ip, uint16(queryPort)), timeout) //nolint:gosec
+// queryPort queries the port with every supported protocol
+// and returns the responses of the server that the game port belongs to.
+// In case there are none, the error tells why.
+func (p PortProber) queryPort(
+	ctx context.Context,
+	ip netip.Addr,
+	gamePort int,
+	cand candidate,
+	timeout time.Duration,
+) ([]querier.Response, error) {
+	queryPort := cand.Port
+	offsetLabel := cand.label(gamePort)
+	p.metrics.DiscoveryPortOffsetProbes.WithLabelValues(offsetLabel).Inc()
+
+	qAddr := netip.AddrPortFrom(ip, uint16(queryPort)) //nolint:gosec
+	resps, err := querier.QueryAll(ctx, qAddr, timeout, p.protocols)
 	if err != nil {
 		p.logger.Debug().
 			Err(err).
 			Dur("timeout", timeout).Stringer("ip", ip).Int("Port", queryPort).
 			Msg("Unable to probe port")
-		return
+		return nil, err
 	}
 
-	hostPort, err := strconv.Atoi(resp.Fields["hostport"])
-	switch {
-	case err != nil:
-		p.logger.Error().
-			Err(err).
-			Stringer("ip", ip).Int("port", queryPort).
-			Msg("Unable to parse server hostport")
-		return
-	case hostPort != gamePort:
-		p.logger.Warn().
+	valid := make([]querier.Response, 0, len(resps))
+	var invalidErr error
+	for _, resp := range resps {
+		p.metrics.DiscoveryQueryDurations.Observe(resp.RTT.Seconds())
+
+		hostPort, err := strconv.Atoi(resp.Fields["hostport"])
+		switch {
+		case err != nil:
+			p.logger.Error().
+				Err(err).
+				Stringer("ip", ip).Int("port", queryPort).Str("version", resp.Variant()).
+				Msg("Unable to parse server hostport")
+			invalidErr = fmt.Errorf("%w: %w", ErrHostPortInvalid, err)
+			continue
+		case hostPort != gamePort:
+			p.logger.Warn().
+				Stringer("ip", ip).Int("port", queryPort).Str("version", resp.Variant()).
+				Int("hostport", hostPort).Int("gameport", gamePort).
+				Msg("Server ports dont match")
+			invalidErr = fmt.Errorf("%w: %d", ErrHostPortMismatch, hostPort)
+			continue
+		}
+
+		p.logger.Debug().
 			Stringer("ip", ip).Int("port", queryPort).
-			Int("hostport", hostPort).Int("gameport", gamePort).
-			Msg("Server ports dont match")
-		return
+			Str("version", resp.Variant()).Dur("rtt", resp.RTT).
+			Msg("Successfully probed port")
+
+		valid = append(valid, resp)
 	}
 
-	queryDur := time.Since(queryStarted).Seconds()
-	p.metrics.DiscoveryQueryDurations.Observe(queryDur)
-	p.logger.Debug().
-		Stringer("ip", ip).Int("port", queryPort).
-		Stringer("version", resp.Version).Float64("duration", queryDur).
-		Msg("Successfully probed port")
+	if len(valid) == 0 {
+		return nil, invalidErr
+	}
+
+	p.metrics.DiscoveryPortOffsetHits.WithLabelValues(offsetLabel).Inc()
 
-	responses <- response{resp, queryPort}
+	return valid, nil
 }
 
 func (p PortProber) collectResponses(
@@ -198,7 +417,7 @@ func (p PortProber) collectResponses(
 }
 
 func (p PortProber) compareResponses(this, that response) response {
-	if this.Response.Version > that.Response.Version {
+	if this.Response.Rank() > that.Response.Rank() {
 		return this
 	}
 	return that
@@ -209,11 +428,16 @@ func (p PortProber) HandleSuccess(res any, svr server.Server) server.Server {
 	if !ok {
 		panic(fmt.Errorf("unexpected result type %T, %v", result, result))
 	}
+	now := p.clock.Now()
 	svr.QueryPort = result.Port
 	svr.UpdateDetails(result.Details)
-	svr.Refresh(p.clock.Now())
+	svr.RecordQuery(result.Variant, result.RTT, now)
+	svr.Refresh(now)
 	svr.UpdateDiscoveryStatus(ds.Info | ds.Details | ds.Port)
 	svr.ClearDiscoveryStatus(ds.NoDetails | ds.DetailsRetry | ds.PortRetry | ds.NoPort)
+	if svr.VerifyListing(result.Details.Info, now) {
+		p.logger.Warn().Stringer("server", svr).Msg("Server keeps reporting info that disagrees with its queries")
+	}
 	return svr
 }
 
@@ -1,23 +1,35 @@
 package portprober_test
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"net"
+	"os"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/jonboulle/clockwork"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/sergeii/swat4master/internal/core/entities/addr"
 	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/internal/core/entities/server"
 	"github.com/sergeii/swat4master/internal/metrics"
+	"github.com/sergeii/swat4master/internal/persistence/redis/repositories/portoffsets"
 	"github.com/sergeii/swat4master/internal/prober/probers/portprober"
+	"github.com/sergeii/swat4master/internal/prober/querier"
+	tu "github.com/sergeii/swat4master/internal/testutils"
 	"github.com/sergeii/swat4master/internal/testutils/factories/serverfactory"
+	"github.com/sergeii/swat4master/internal/testutils/testredis"
 	"github.com/sergeii/swat4master/internal/validation"
 	"github.com/sergeii/swat4master/pkg/gamespy/serverquery/gs1"
+	"github.com/sergeii/swat4master/pkg/gamespy/serverquery/qr2"
+	"github.com/sergeii/swat4master/pkg/udp/udpclient"
 )
 
 func TestPortProber_Probe_OK(t *testing.T) {
@@ -44,7 +56,8 @@ func TestPortProber_Probe_OK(t *testing.T) {
 			collector := metrics.New()
 
 			proberOpts := portprober.Opts{Offsets: []int{1, 4}}
-			prober := portprober.New(proberOpts, validate, clock, collector, &logger)
+			offsetRepo := portoffsets.New(testredis.MakeClient(t))
+			prober := portprober.New(proberOpts, udpclient.Direct{}, offsetRepo, validate, clock, collector, &logger)
 
 			responses := make(chan []byte)
 			queryHandler, cancel := gs1.PrepareGS1Server(responses)
@@ -67,7 +80,7 @@ func TestPortProber_Probe_OK(t *testing.T) {
 				)
 			}(svrAddr.Port)
 
-			ret, err := prober.Probe(ctx, svrAddr, queryAddr.Port, time.Millisecond*100)
+			ret, err := prober.Probe(ctx, server.MustNewFromAddr(svrAddr, queryAddr.Port), queryAddr.Port, time.Millisecond*100)
 			require.NoError(t, err)
 
 			result := ret.(portprober.Result) //nolint:forcetypeassert
@@ -94,6 +107,7 @@ func TestPortProber_Probe_BestPort(t *testing.T) {
 		portOffsetsFactory func(gmp int, vnp int, bdp int, amp int, gs1p int) []int
 		wantedPortFactory  func(vnp int, bdp int, amp int, gs1p int) int
 		wantedHostname     string
+		wantedVariant      string
 	}{
 		{
 			"vanilla response",
@@ -104,6 +118,7 @@ func TestPortProber_Probe_BestPort(t *testing.T) {
 				return vnp
 			},
 			"Vanilla Response",
+			"gs1/vanilla",
 		},
 		{
 			"admin mod response",
@@ -114,6 +129,7 @@ func TestPortProber_Probe_BestPort(t *testing.T) {
 				return amp
 			},
 			"AM Response",
+			"gs1/am",
 		},
 		{
 			"gs1 mod response",
@@ -124,6 +140,7 @@ func TestPortProber_Probe_BestPort(t *testing.T) {
 				return gs1p
 			},
 			"GS1 response",
+			"gs1/gs1",
 		},
 	}
 
@@ -200,9 +217,10 @@ func TestPortProber_Probe_BestPort(t *testing.T) {
 
 			portOffsets := tt.portOffsetsFactory(svrAddr.Port, vanillaPort, badPort, amPort, gs1Port)
 			proberOpts := portprober.Opts{Offsets: portOffsets}
-			prober := portprober.New(proberOpts, validate, clock, collector, &logger)
+			offsetRepo := portoffsets.New(testredis.MakeClient(t))
+			prober := portprober.New(proberOpts, udpclient.Direct{}, offsetRepo, validate, clock, collector, &logger)
 
-			ret, err := prober.Probe(ctx, svrAddr, svrAddr.Port, time.Millisecond*100)
+			ret, err := prober.Probe(ctx, server.MustNewFromAddr(svrAddr, svrAddr.Port), svrAddr.Port, time.Millisecond*100)
 			require.NoError(t, err)
 
 			result := ret.(portprober.Result) //nolint:forcetypeassert
@@ -210,10 +228,70 @@ func TestPortProber_Probe_BestPort(t *testing.T) {
 
 			wantedPort := tt.wantedPortFactory(vanillaPort, badPort, amPort, gs1Port)
 			assert.Equal(t, wantedPort, result.Port)
+			assert.Equal(t, tt.wantedVariant, result.Variant)
 		})
 	}
 }
 
+func TestPortProber_Probe_QR2(t *testing.T) {
+	ctx := context.TODO()
+	logger := zerolog.Nop()
+	clock := clockwork.NewFakeClock()
+	validate := validation.MustNew()
+	collector := metrics.New()
+
+	vanillaResponses := make(chan []byte)
+	vanillaHandler, cancelVanilla := gs1.PrepareGS1Server(vanillaResponses)
+	defer cancelVanilla()
+
+	qr2Packets := make(chan []byte)
+	qr2Handler, cancelQR2 := qr2.PrepareQR2Server(-1, qr2Packets)
+	defer cancelQR2()
+
+	vanillaAddr := vanillaHandler.LocalAddr()
+	qr2Port := qr2Handler.LocalAddr().Port
+	svrAddr := addr.NewForTesting(vanillaAddr.IP, vanillaAddr.Port-1)
+
+	go func(gamePort int) {
+		vanillaResponses <- []byte(
+			fmt.Sprintf(
+				"\\hostname\\Vanilla Response\\numplayers\\0\\maxplayers\\16\\gametype\\VIP Escort"+
+					"\\gamevariant\\SWAT 4\\mapname\\Fairfax Residence\\hostport\\%d\\password\\0"+
+					"\\gamever\\1.1\\final\\\\queryid\\1.1",
+				gamePort,
+			),
+		)
+	}(svrAddr.Port)
+
+	go func(gamePort int) {
+		qr2Packets <- qr2.PackPacket(
+			0, true,
+			qr2.PackServerSection(
+				"hostname", "QR2 Response", "numplayers", "0", "maxplayers", "16",
+				"gametype", "VIP Escort", "gamevariant", "SWAT 4", "mapname", "Fairfax Residence",
+				"hostport", strconv.Itoa(gamePort), "password", "0", "gamever", "1.1",
+			),
+		)
+	}(svrAddr.Port)
+
+	proberOpts := portprober.Opts{Offsets: []int{1, qr2Port - svrAddr.Port}}
+	offsetRepo := portoffsets.New(testredis.MakeClient(t))
+	prober := portprober.New(proberOpts, udpclient.Direct{}, offsetRepo, validate, clock, collector, &logger)
+
+	ret, err := prober.Probe(ctx, server.MustNewFromAddr(svrAddr, svrAddr.Port), svrAddr.Port, time.Millisecond*100)
+	require.NoError(t, err)
+
+	result := ret.(portprober.Result) //nolint:forcetypeassert
+	assert.Equal(t, "QR2 Response", result.Details.Info.Hostname)
+	assert.Equal(t, qr2Port, result.Port)
+	assert.Equal(t, querier.ProtocolQR2, result.Protocol)
+	assert.Equal(t, "qr2", result.Variant)
+	assert.Positive(t, result.RTT)
+
+	protocolMetricValue := testutil.ToFloat64(collector.DiscoveryQueryProtocols.WithLabelValues("qr2"))
+	assert.InDelta(t, 1, protocolMetricValue, 1e-9)
+}
+
 func TestPortProber_Probe_Fail(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -294,7 +372,8 @@ func TestPortProber_Probe_Fail(t *testing.T) {
 			collector := metrics.New()
 
 			proberOpts := portprober.Opts{Offsets: []int{1, 4}}
-			prober := portprober.New(proberOpts, validate, clock, collector, &logger)
+			offsetRepo := portoffsets.New(testredis.MakeClient(t))
+			prober := portprober.New(proberOpts, udpclient.Direct{}, offsetRepo, validate, clock, collector, &logger)
 
 			responses := make(chan []byte)
 			queryHandler, cancel := gs1.PrepareGS1Server(responses)
@@ -308,12 +387,53 @@ func TestPortProber_Probe_Fail(t *testing.T) {
 				ch <- packet
 			}(responses, svrAddr.Port, tt.respFactory)
 
-			_, probeErr := prober.Probe(ctx, svrAddr, queryAddr.Port, time.Millisecond*100)
+			svr := server.MustNewFromAddr(svrAddr, queryAddr.Port)
+			_, probeErr := prober.Probe(ctx, svr, queryAddr.Port, time.Millisecond*100)
 			require.ErrorIs(t, probeErr, tt.wantErr)
 		})
 	}
 }
 
+func TestPortProber_Probe_TimeoutIsSharedByStages(t *testing.T) {
+	ctx := context.TODO()
+	logger := zerolog.Nop()
+	clock := clockwork.NewFakeClock()
+	validate := validation.MustNew()
+	collector := metrics.New()
+
+	// the ports are listened on but never answered, so every stage is tried until it times out
+	offsets := make([]int, 0, 3)
+	gamePort := 47810
+	for range 3 {
+		conn := tu.Must(net.ListenPacket("udp", "127.0.0.1:0"))
+		t.Cleanup(func() {
+			conn.Close() //nolint: errcheck
+		})
+		offsets = append(offsets, conn.LocalAddr().(*net.UDPAddr).Port-gamePort) //nolint: forcetypeassert
+	}
+	svrAddr := addr.MustNewFromDotted("127.0.0.1", gamePort)
+	offsetRepo := portoffsets.New(testredis.MakeClient(t))
+	tu.MustNoErr(offsetRepo.Record(ctx, svrAddr.IP, offsets[0]))
+
+	proberOpts := portprober.Opts{Offsets: offsets[1:2], FallbackOffsets: offsets[2:]}
+	prober := portprober.New(proberOpts, udpclient.Direct{}, offsetRepo, validate, clock, collector, &logger)
+
+	timeout := time.Millisecond * 150
+	svr := server.MustNewFromAddr(svrAddr, gamePort+offsets[0])
+	started := time.Now()
+	_, probeErr := prober.Probe(ctx, svr, gamePort+offsets[0], timeout)
+	elapsed := time.Since(started)
+
+	require.ErrorIs(t, probeErr, portprober.ErrPortDiscoveryFailed)
+	// every stage has been tried, yet all of them within the single timeout
+	for _, offset := range offsets {
+		probed := testutil.ToFloat64(collector.DiscoveryPortOffsetProbes.WithLabelValues(strconv.Itoa(offset)))
+		assert.InDelta(t, 1, probed, 1e-9, offset)
+	}
+	assert.GreaterOrEqual(t, elapsed, timeout-time.Millisecond*10)
+	assert.Less(t, elapsed, timeout+time.Millisecond*50)
+}
+
 func TestPortProber_HandleRetry_OK(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -344,7 +464,8 @@ func TestPortProber_HandleRetry_OK(t *testing.T) {
 			validate := validation.MustNew()
 			collector := metrics.New()
 
-			prober := portprober.New(portprober.Opts{}, validate, clock, collector, &logger)
+			offsetRepo := portoffsets.New(testredis.MakeClient(t))
+			prober := portprober.New(portprober.Opts{}, udpclient.Direct{}, offsetRepo, validate, clock, collector, &logger)
 
 			svr := serverfactory.Build(serverfactory.WithDiscoveryStatus(tt.initStatus))
 
@@ -389,7 +510,8 @@ func TestPortProber_HandleFailure_OK(t *testing.T) {
 			validate := validation.MustNew()
 			collector := metrics.New()
 
-			prober := portprober.New(portprober.Opts{}, validate, clock, collector, &logger)
+			offsetRepo := portoffsets.New(testredis.MakeClient(t))
+			prober := portprober.New(portprober.Opts{}, udpclient.Direct{}, offsetRepo, validate, clock, collector, &logger)
 
 			svr := serverfactory.Build(serverfactory.WithDiscoveryStatus(tt.initStatus))
 
@@ -398,3 +520,205 @@ func TestPortProber_HandleFailure_OK(t *testing.T) {
 		})
 	}
 }
+
+func TestPortProber_Probe_LearnedOffsets(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       portprober.Opts
+		portOffset int
+		// add the server's query port to the fallback ports
+		fallbackToPort bool
+		learn          func(repo *portoffsets.Repository, ip [4]byte)
+		wantProbed     map[string]int
+		wantHit        string
+		// whether the offset is remembered for the server's ip
+		wantLearned bool
+	}{
+		{
+			name:       "offset known for the ip is tried alone",
+			opts:       portprober.Opts{Offsets: []int{1, 2}},
+			portOffset: 7,
+			learn: func(repo *portoffsets.Repository, ip [4]byte) {
+				tu.MustNoErr(repo.Record(context.TODO(), ip, 7))
+			},
+			wantProbed:  map[string]int{"7": 1},
+			wantHit:     "7",
+			wantLearned: true,
+		},
+		{
+			name:       "offset known for the ip is followed by the quick offsets",
+			opts:       portprober.Opts{Offsets: []int{1, 2}},
+			portOffset: 2,
+			learn: func(repo *portoffsets.Repository, ip [4]byte) {
+				tu.MustNoErr(repo.Record(context.TODO(), ip, 7))
+			},
+			wantProbed:  map[string]int{"7": 1, "1": 1, "2": 1},
+			wantHit:     "2",
+			wantLearned: true,
+		},
+		{
+			name:       "offsets known for the network are tried with the quick offsets",
+			opts:       portprober.Opts{Offsets: []int{1}},
+			portOffset: 9,
+			learn: func(repo *portoffsets.Repository, ip [4]byte) {
+				neighbour := ip
+				neighbour[3]++
+				tu.MustNoErr(repo.Record(context.TODO(), neighbour, 9))
+			},
+			wantProbed:  map[string]int{"1": 1, "9": 1},
+			wantHit:     "9",
+			wantLearned: true,
+		},
+		{
+			name:        "fallback offsets are tried when the quick offsets fail",
+			opts:        portprober.Opts{Offsets: []int{1, 2}, FallbackOffsets: []int{2, 6}},
+			portOffset:  6,
+			wantProbed:  map[string]int{"1": 1, "2": 1, "6": 1},
+			wantHit:     "6",
+			wantLearned: true,
+		},
+		{
+			name:           "fallback ports are tried when the quick offsets fail",
+			opts:           portprober.Opts{Offsets: []int{1}},
+			portOffset:     100,
+			fallbackToPort: true,
+			wantProbed:     map[string]int{"1": 1, "fallback": 1},
+			wantHit:        "fallback",
+			wantLearned:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TODO()
+			logger := zerolog.Nop()
+			clock := clockwork.NewFakeClock()
+			validate := validation.MustNew()
+			collector := metrics.New()
+
+			responses := make(chan []byte)
+			queryHandler, cancel := gs1.PrepareGS1Server(responses)
+			defer cancel()
+
+			queryAddr := queryHandler.LocalAddr()
+			svrAddr := addr.NewForTesting(queryAddr.IP, queryAddr.Port-tt.portOffset)
+
+			proberOpts := tt.opts
+			if tt.fallbackToPort {
+				proberOpts.FallbackPorts = []int{queryAddr.Port}
+			}
+			offsetRepo := portoffsets.New(testredis.MakeClient(t))
+			if tt.learn != nil {
+				tt.learn(offsetRepo, svrAddr.IP)
+			}
+			prober := portprober.New(proberOpts, udpclient.Direct{}, offsetRepo, validate, clock, collector, &logger)
+
+			go func(gamePort int) {
+				responses <- []byte(
+					fmt.Sprintf(
+						"\\hostname\\Swat4 Server\\numplayers\\0\\maxplayers\\16\\gametype\\VIP Escort"+
+							"\\gamevariant\\SWAT 4\\mapname\\A-Bomb Nightclub\\hostport\\%d"+
+							"\\password\\0\\gamever\\1.1\\final\\\\queryid\\1.1",
+						gamePort,
+					),
+				)
+			}(svrAddr.Port)
+
+			ret, err := prober.Probe(ctx, server.MustNewFromAddr(svrAddr, queryAddr.Port), queryAddr.Port, time.Millisecond*50)
+			require.NoError(t, err)
+
+			result := ret.(portprober.Result) //nolint:forcetypeassert
+			assert.Equal(t, queryAddr.Port, result.Port)
+
+			probed := make(map[string]int)
+			for offset := range tt.wantProbed {
+				value := testutil.ToFloat64(collector.DiscoveryPortOffsetProbes.WithLabelValues(offset))
+				probed[offset] = int(value)
+			}
+			assert.Equal(t, tt.wantProbed, probed)
+			assert.Equal(t, len(tt.wantProbed), testutil.CollectAndCount(collector.DiscoveryPortOffsetProbes))
+
+			hits := testutil.ToFloat64(collector.DiscoveryPortOffsetHits.WithLabelValues(tt.wantHit))
+			assert.InDelta(t, 1, hits, 1e-9)
+			assert.Equal(t, 1, testutil.CollectAndCount(collector.DiscoveryPortOffsetHits))
+
+			learned, err := offsetRepo.Get(ctx, svrAddr.IP)
+			require.NoError(t, err)
+			likely, ok := learned.Likely()
+			if tt.wantLearned {
+				assert.True(t, ok)
+				assert.Equal(t, tt.portOffset, likely)
+			} else {
+				assert.False(t, ok)
+			}
+		})
+	}
+}
+
+func TestPortProber_Survey(t *testing.T) {
+	ctx := context.TODO()
+	logger := zerolog.Nop()
+	clock := clockwork.NewFakeClock()
+	validate := validation.MustNew()
+	collector := metrics.New()
+
+	answerWithHostPort := func(hostPort *int) func(context.Context, *net.UDPConn, *net.UDPAddr, []byte) {
+		return func(_ context.Context, conn *net.UDPConn, addr *net.UDPAddr, req []byte) {
+			if !bytes.HasPrefix(req, []byte("\\status\\")) {
+				return
+			}
+			packet := fmt.Sprintf(
+				"\\hostname\\Swat4 Server\\numplayers\\0\\maxplayers\\16\\gametype\\VIP Escort"+
+					"\\gamevariant\\SWAT 4\\mapname\\Fairfax Residence\\hostport\\%d\\password\\false"+ //nolint:misspell
+					"\\gamever\\1.1\\round\\1\\numrounds\\5\\queryid\\1\\final\\",
+				*hostPort,
+			)
+			conn.WriteToUDP([]byte(packet), addr) //nolint: errcheck
+		}
+	}
+
+	var gamePort int
+	otherPort := 10480
+
+	goodHandler, cancelGood := gs1.ServerFactory(answerWithHostPort(&gamePort))
+	defer cancelGood()
+	otherHandler, cancelOther := gs1.ServerFactory(answerWithHostPort(&otherPort))
+	defer cancelOther()
+	silentHandler, cancelSilent := gs1.ServerFactory(func(context.Context, *net.UDPConn, *net.UDPAddr, []byte) {})
+	defer cancelSilent()
+
+	goodAddr := goodHandler.LocalAddr()
+	gamePort = goodAddr.Port - 1
+	svrAddr := addr.NewForTesting(goodAddr.IP, gamePort)
+
+	otherPortOffset := otherHandler.LocalAddr().Port - gamePort
+	silentPortOffset := silentHandler.LocalAddr().Port - gamePort
+	proberOpts := portprober.Opts{
+		Offsets:         []int{1, otherPortOffset},
+		FallbackOffsets: []int{silentPortOffset, 1},
+	}
+	offsetRepo := portoffsets.New(testredis.MakeClient(t))
+	prober := portprober.New(proberOpts, udpclient.Direct{}, offsetRepo, validate, clock, collector, &logger)
+
+	outcomes := prober.Survey(ctx, svrAddr, time.Millisecond*100)
+	require.Len(t, outcomes, 3)
+
+	assert.Equal(t, goodAddr.Port, outcomes[0].Port)
+	assert.Equal(t, 1, outcomes[0].Offset)
+	assert.True(t, outcomes[0].Answered())
+	assert.Equal(t, "gs1/gs1", outcomes[0].Response.Variant())
+	assert.Equal(t, "Swat4 Server", outcomes[0].Response.Fields["hostname"])
+
+	assert.Equal(t, otherPortOffset, outcomes[1].Offset)
+	assert.False(t, outcomes[1].Answered())
+	assert.ErrorIs(t, outcomes[1].Err, portprober.ErrHostPortMismatch)
+
+	assert.Equal(t, silentPortOffset, outcomes[2].Offset)
+	assert.False(t, outcomes[2].Answered())
+	assert.ErrorIs(t, outcomes[2].Err, os.ErrDeadlineExceeded)
+
+	// surveys don't teach the prober anything
+	learned, err := offsetRepo.Get(ctx, svrAddr.IP)
+	require.NoError(t, err)
+	assert.Empty(t, learned.IP)
+}
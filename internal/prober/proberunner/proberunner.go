@@ -7,6 +7,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/jonboulle/clockwork"
 	"github.com/rs/zerolog"
 
 	"github.com/sergeii/swat4master/internal/core/entities/probe"
@@ -20,19 +21,33 @@ var ErrUnsupportedGoal = errors.New("no associated prober for goal")
 
 type RunnerOpts struct {
 	PollInterval time.Duration
-	Concurrency  int
-	ProbeTimeout time.Duration
+	// Concurrency is the max number of workers
+	Concurrency int
+	// MinConcurrency is the number of workers kept when the queue is quiet.
+	// The number of workers is fixed at Concurrency, unless it is set.
+	MinConcurrency int
+	ScaleInterval  time.Duration
+	ProbeTimeout   time.Duration
 }
 
 type Runner struct {
 	opts      RunnerOpts
+	scaling   ScalingPolicy
 	uc        probeserver.UseCase
 	probeRepo repositories.ProbeRepository
 	probers   probers.ForGoal
 	metrics   *metrics.Collector
+	clock     clockwork.Clock
 	logger    *zerolog.Logger
 	queue     chan probe.Probe
+	retire    chan struct{}
+	workers   int64
 	busy      int64
+
+	// the load observed since the latest scaling decision
+	expired       int64
+	probeCount    int64
+	probeDuration int64
 }
 
 func New(
@@ -41,33 +56,58 @@ func New(
 	uc probeserver.UseCase,
 	probers probers.ForGoal,
 	metrics *metrics.Collector,
+	clock clockwork.Clock,
 	logger *zerolog.Logger,
 ) *Runner {
 	return &Runner{
-		opts:      opts,
+		opts: opts,
+		scaling: ScalingPolicy{
+			MinWorkers: opts.MinConcurrency,
+			MaxWorkers: opts.Concurrency,
+			Interval:   opts.ScaleInterval,
+		},
 		probeRepo: probeRepo,
 		uc:        uc,
 		probers:   probers,
 		metrics:   metrics,
+		clock:     clock,
 		logger:    logger,
 		queue:     make(chan probe.Probe, opts.Concurrency),
+		retire:    make(chan struct{}, opts.Concurrency),
 	}
 }
 
 func (r *Runner) Start(ctx context.Context) {
-	for range r.opts.Concurrency {
-		go r.worker(ctx)
+	if r.scaling.IsEnabled() {
+		r.spawn(ctx, r.scaling.MinWorkers)
+		go r.scaler(ctx)
+	} else {
+		r.spawn(ctx, r.opts.Concurrency)
 	}
-	r.metrics.DiscoveryWorkersAvailable.Add(float64(r.opts.Concurrency))
 	go r.scheduler(ctx)
 }
 
+func (r *Runner) spawn(ctx context.Context, count int) {
+	atomic.AddInt64(&r.workers, int64(count))
+	r.metrics.DiscoveryWorkers.Add(float64(count))
+	r.metrics.DiscoveryWorkersAvailable.Add(float64(count))
+	for range count {
+		go r.worker(ctx)
+	}
+}
+
 func (r *Runner) worker(ctx context.Context) {
 	for {
 		select {
 		case <-ctx.Done():
 			r.logger.Debug().Msg("Stopping worker")
 			return
+		case <-r.retire:
+			atomic.AddInt64(&r.workers, -1)
+			r.metrics.DiscoveryWorkers.Dec()
+			r.metrics.DiscoveryWorkersAvailable.Dec()
+			r.logger.Debug().Msg("Retiring worker")
+			return
 		case prb := <-r.queue:
 			r.probe(ctx, prb)
 		}
@@ -89,6 +129,65 @@ func (r *Runner) scheduler(ctx context.Context) {
 	}
 }
 
+func (r *Runner) scaler(ctx context.Context) {
+	ticker := r.clock.NewTicker(r.scaling.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.logger.Debug().Msg("Stopping scaler")
+			return
+		case <-ticker.Chan():
+			r.scale(ctx)
+		}
+	}
+}
+
+func (r *Runner) scale(ctx context.Context) {
+	depth, err := r.probeRepo.Count(ctx)
+	if err != nil {
+		r.metrics.DiscoveryQueueErrors.Inc()
+		r.logger.Warn().Err(err).Msg("Unable to obtain probe queue depth")
+		return
+	}
+
+	state := ScalingState{
+		// the workers that are about to retire are not counted
+		Workers:    r.Workers() - len(r.retire),
+		Busy:       r.Busy(),
+		QueueDepth: depth,
+		Expired:    int(atomic.SwapInt64(&r.expired, 0)),
+	}
+	probeCount := atomic.SwapInt64(&r.probeCount, 0)
+	probeDuration := atomic.SwapInt64(&r.probeDuration, 0)
+	if probeCount > 0 {
+		state.ProbeDuration = time.Duration(probeDuration / probeCount)
+	}
+
+	target := r.scaling.Decide(state)
+	r.metrics.DiscoveryWorkersTarget.Set(float64(target))
+
+	switch {
+	case target > state.Workers:
+		r.spawn(ctx, target-state.Workers)
+		r.metrics.DiscoveryWorkersScaling.WithLabelValues("up").Inc()
+	case target < state.Workers:
+		for range state.Workers - target {
+			r.retire <- struct{}{}
+		}
+		r.metrics.DiscoveryWorkersScaling.WithLabelValues("down").Inc()
+	default:
+		return
+	}
+
+	r.logger.Info().
+		Int("workers", state.Workers).Int("target", target).
+		Int("busy", state.Busy).Int("queue", state.QueueDepth).Int("expired", state.Expired).
+		Dur("duration", state.ProbeDuration).
+		Msg("Scaling workers")
+}
+
 func (r *Runner) probe(ctx context.Context, prb probe.Probe) {
 	atomic.AddInt64(&r.busy, 1)
 	r.metrics.DiscoveryWorkersBusy.Inc()
@@ -142,8 +241,12 @@ func (r *Runner) probe(ctx context.Context, prb probe.Probe) {
 		Dur("elapsed", time.Since(before)).
 		Msg("Finished probing")
 
+	elapsed := time.Since(before)
+	atomic.AddInt64(&r.probeCount, 1)
+	atomic.AddInt64(&r.probeDuration, int64(elapsed))
+
 	r.metrics.DiscoveryProbes.WithLabelValues(goalLabel).Inc()
-	r.metrics.DiscoveryProbeDurations.WithLabelValues(goalLabel).Observe(time.Since(before).Seconds())
+	r.metrics.DiscoveryProbeDurations.WithLabelValues(goalLabel).Observe(elapsed.Seconds())
 }
 
 func (r *Runner) selectProber(goal probe.Goal) (probers.Prober, error) {
@@ -172,6 +275,7 @@ func (r *Runner) schedule(ctx context.Context) {
 	r.metrics.DiscoveryQueueConsumed.Add(float64(len(probes)))
 	// measure the number of expired probes
 	if expired > 0 {
+		atomic.AddInt64(&r.expired, int64(expired))
 		r.metrics.DiscoveryQueueExpired.Add(float64(expired))
 	}
 
@@ -192,6 +296,10 @@ func (r *Runner) Busy() int {
 	return int(atomic.LoadInt64(&r.busy))
 }
 
+func (r *Runner) Workers() int {
+	return int(atomic.LoadInt64(&r.workers))
+}
+
 func (r *Runner) Available() int {
-	return r.opts.Concurrency - int(atomic.LoadInt64(&r.busy))
+	return r.Workers() - int(atomic.LoadInt64(&r.busy))
 }
@@ -0,0 +1,58 @@
+package proberunner
+
+import (
+	"time"
+)
+
+// ScalingPolicy bounds the number of workers the runner may scale to
+type ScalingPolicy struct {
+	MinWorkers int
+	MaxWorkers int
+	Interval   time.Duration
+}
+
+// ScalingState is the runner load observed since the previous scaling decision
+type ScalingState struct {
+	Workers       int
+	Busy          int
+	QueueDepth    int
+	Expired       int
+	ProbeDuration time.Duration // the average duration of a probe, zero when no probes have finished
+}
+
+// IsEnabled tells whether the number of workers should ever change.
+// Without the distinct bounds the runner keeps the max number of workers all the time.
+func (p ScalingPolicy) IsEnabled() bool {
+	return p.MinWorkers > 0 && p.MinWorkers < p.MaxWorkers && p.Interval > 0
+}
+
+// Decide returns the number of workers the runner should have.
+// There should be enough workers to clear the queued probes within one scaling interval,
+// in addition to the workers that are still busy.
+// Expired probes mean that the runner has fallen behind, so it is scaled up regardless of the queue.
+// The runner is scaled down gradually, so that a short lull does not cause the pool to collapse.
+func (p ScalingPolicy) Decide(s ScalingState) int {
+	if !p.IsEnabled() {
+		return p.MaxWorkers
+	}
+
+	needed := s.Busy
+	if s.QueueDepth > 0 {
+		probeDuration := s.ProbeDuration
+		if probeDuration <= 0 {
+			probeDuration = p.Interval
+		}
+		perWorker := max(1, int(p.Interval/probeDuration))
+		needed += (s.QueueDepth + perWorker - 1) / perWorker
+	}
+
+	step := max(1, s.Workers/4)
+	if s.Expired > 0 {
+		needed = max(needed, s.Workers+step)
+	}
+	if needed < s.Workers {
+		needed = max(needed, s.Workers-step)
+	}
+
+	return min(max(needed, p.MinWorkers), p.MaxWorkers)
+}
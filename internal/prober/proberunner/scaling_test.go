@@ -0,0 +1,97 @@
+package proberunner_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergeii/swat4master/internal/prober/proberunner"
+)
+
+func TestScalingPolicy_Decide(t *testing.T) {
+	policy := proberunner.ScalingPolicy{MinWorkers: 2, MaxWorkers: 20, Interval: time.Second * 5}
+
+	tests := []struct {
+		name  string
+		state proberunner.ScalingState
+		want  int
+	}{
+		{
+			name:  "quiet queue keeps min workers",
+			state: proberunner.ScalingState{Workers: 2},
+			want:  2,
+		},
+		{
+			name:  "queued probes are spread over the interval",
+			state: proberunner.ScalingState{Workers: 2, QueueDepth: 50, ProbeDuration: time.Second},
+			want:  10,
+		},
+		{
+			name:  "busy workers are kept",
+			state: proberunner.ScalingState{Workers: 4, Busy: 4, QueueDepth: 10, ProbeDuration: time.Second},
+			want:  6,
+		},
+		{
+			name:  "unknown probe duration is assumed to take the whole interval",
+			state: proberunner.ScalingState{Workers: 2, QueueDepth: 8},
+			want:  8,
+		},
+		{
+			name:  "slow probes need more workers",
+			state: proberunner.ScalingState{Workers: 2, QueueDepth: 8, ProbeDuration: time.Second * 10},
+			want:  8,
+		},
+		{
+			name:  "max workers are not exceeded",
+			state: proberunner.ScalingState{Workers: 10, QueueDepth: 1000, ProbeDuration: time.Second},
+			want:  20,
+		},
+		{
+			name:  "expired probes scale up",
+			state: proberunner.ScalingState{Workers: 8, Expired: 3},
+			want:  10,
+		},
+		{
+			name:  "expired probes scale up at least by one",
+			state: proberunner.ScalingState{Workers: 2, Expired: 1},
+			want:  3,
+		},
+		{
+			name:  "scale down is gradual",
+			state: proberunner.ScalingState{Workers: 20},
+			want:  15,
+		},
+		{
+			name:  "scale down stops at min workers",
+			state: proberunner.ScalingState{Workers: 3},
+			want:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, policy.Decide(tt.state))
+		})
+	}
+}
+
+func TestScalingPolicy_Disabled(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy proberunner.ScalingPolicy
+	}{
+		{"no min workers", proberunner.ScalingPolicy{MaxWorkers: 10, Interval: time.Second}},
+		{"min workers equal max", proberunner.ScalingPolicy{MinWorkers: 10, MaxWorkers: 10, Interval: time.Second}},
+		{"min workers above max", proberunner.ScalingPolicy{MinWorkers: 20, MaxWorkers: 10, Interval: time.Second}},
+		{"no interval", proberunner.ScalingPolicy{MinWorkers: 2, MaxWorkers: 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.False(t, tt.policy.IsEnabled())
+			assert.Equal(t, 10, tt.policy.Decide(proberunner.ScalingState{Workers: 2}))
+			assert.Equal(t, 10, tt.policy.Decide(proberunner.ScalingState{Workers: 20, QueueDepth: 100}))
+		})
+	}
+}
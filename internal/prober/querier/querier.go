@@ -0,0 +1,294 @@
+package querier
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sergeii/swat4master/pkg/gamespy/serverquery/gs1"
+	"github.com/sergeii/swat4master/pkg/gamespy/serverquery/qr2"
+	"github.com/sergeii/swat4master/pkg/udp/udpclient"
+)
+
+var ErrNoProtocols = errors.New("no query protocols to try")
+
+// Protocol is the query protocol a server has answered with
+type Protocol string
+
+const (
+	ProtocolGS1 Protocol = "gs1"
+	ProtocolQR2 Protocol = "qr2"
+)
+
+// Response is the protocol agnostic response to a server query
+type Response struct {
+	Fields     map[string]string
+	Players    []map[string]string
+	Objectives []map[string]string
+	Protocol   Protocol
+	// Version is the gs1 response variant, it's unknown for the other protocols
+	Version gs1.QueryVersion
+	// RTT is the time it has taken the server to reply,
+	// which is measured up to the first reply packet, regardless of the protocol
+	RTT time.Duration
+}
+
+var Blank Response
+
+// Rank tells how preferable the response is, when a server answers with more than one.
+// The responses are preferred in this order: gs1 mod, admin mod, qr2, vanilla.
+func (r Response) Rank() int {
+	if r.Protocol == ProtocolQR2 {
+		return 2
+	}
+	switch r.Version {
+	case gs1.VerVanilla:
+		return 1
+	case gs1.VerAM:
+		return 3
+	case gs1.VerGS1:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// Variant describes the response protocol along with its variant, if any
+func (r Response) Variant() string {
+	if r.Protocol == ProtocolGS1 {
+		return string(r.Protocol) + "/" + r.Version.String()
+	}
+	return string(r.Protocol)
+}
+
+// ParseProtocol tells the protocol of a response variant, such as gs1 for gs1/am
+func ParseProtocol(variant string) Protocol {
+	protocol, _, _ := strings.Cut(variant, "/")
+	return Protocol(protocol)
+}
+
+type QueryFunc func(context.Context, netip.AddrPort, time.Duration) (Response, error)
+
+// QueryGS1 returns the query function that queries the server with the gs1 protocol
+func QueryGS1(client gs1.Client) QueryFunc {
+	return func(ctx context.Context, addr netip.AddrPort, timeout time.Duration) (Response, error) {
+		resp, err := client.Query(ctx, addr, timeout)
+		if err != nil {
+			return Blank, err
+		}
+		return Response{
+			Fields:     resp.Fields,
+			Players:    resp.Players,
+			Objectives: resp.Objectives,
+			Protocol:   ProtocolGS1,
+			Version:    resp.Version,
+			RTT:        resp.RTT,
+		}, nil
+	}
+}
+
+// QueryQR2 returns the query function that queries the server with the qr2 protocol
+func QueryQR2(client qr2.Client) QueryFunc {
+	return func(ctx context.Context, addr netip.AddrPort, timeout time.Duration) (Response, error) {
+		resp, err := client.Query(ctx, addr, timeout)
+		if err != nil {
+			return Blank, err
+		}
+		return Response{
+			Fields:   withTeamScores(resp.Fields, resp.Teams),
+			Players:  resp.Players,
+			Protocol: ProtocolQR2,
+			RTT:      resp.RTT,
+		}, nil
+	}
+}
+
+// teamScoreFields are the gs1 fields with the team scores, in the order the qr2 response lists the teams
+var teamScoreFields = []string{"swatscore", "suspectsscore"} //nolint: gochecknoglobals
+
+// withTeamScores adds the scores of the qr2 teams to the fields, the way a gs1 response reports them.
+// The scores already reported among the server fields are kept.
+func withTeamScores(fields map[string]string, teams []map[string]string) map[string]string {
+	for i, team := range teams {
+		if i >= len(teamScoreFields) {
+			break
+		}
+		score, ok := team["score"]
+		if !ok {
+			continue
+		}
+		if _, exists := fields[teamScoreFields[i]]; !exists {
+			fields[teamScoreFields[i]] = score
+		}
+	}
+	return fields
+}
+
+// Protocols lists the query functions of the supported protocols,
+// with the queries sent over the sessions opened with the given dialer
+func Protocols(dialer udpclient.Dialer) []QueryFunc {
+	return ordered(ProtocolQueries(dialer), "")
+}
+
+// ProtocolQueries maps the supported protocols to their query functions,
+// with the queries sent over the sessions opened with the given dialer
+func ProtocolQueries(dialer udpclient.Dialer) map[Protocol]QueryFunc {
+	return map[Protocol]QueryFunc{
+		ProtocolGS1: QueryGS1(gs1.NewClient(dialer)),
+		ProtocolQR2: QueryQR2(qr2.NewClient(dialer)),
+	}
+}
+
+// ordered lists the query functions in the order of their protocols, leaving out the excluded protocol
+func ordered(queries map[Protocol]QueryFunc, exclude Protocol) []QueryFunc {
+	protocols := make([]QueryFunc, 0, len(queries))
+	for _, protocol := range slices.Sorted(maps.Keys(queries)) {
+		if protocol != exclude {
+			protocols = append(protocols, queries[protocol])
+		}
+	}
+	return protocols
+}
+
+// QueryAll queries the server with every protocol at once and returns every successful response.
+// In case every protocol fails, the returned error is the combination of their errors.
+func QueryAll(
+	ctx context.Context,
+	addr netip.AddrPort,
+	timeout time.Duration,
+	protocols []QueryFunc,
+) ([]Response, error) {
+	if len(protocols) == 0 {
+		return nil, ErrNoProtocols
+	}
+
+	type result struct {
+		resp Response
+		err  error
+	}
+	results := make(chan result, len(protocols))
+	for _, query := range protocols {
+		go func() {
+			resp, err := query(ctx, addr, timeout)
+			results <- result{resp, err}
+		}()
+	}
+
+	responses := make([]Response, 0, len(protocols))
+	errs := make([]error, 0, len(protocols))
+	for range protocols {
+		res := <-results
+		if res.err != nil {
+			errs = append(errs, res.err)
+			continue
+		}
+		responses = append(responses, res.resp)
+	}
+
+	if len(responses) == 0 {
+		return nil, errors.Join(errs...)
+	}
+	return responses, nil
+}
+
+// QueryFirst queries the server with every protocol at once and returns the first successful response.
+// The queries that are still pending are cancelled.
+// In case every protocol fails, the returned error is the combination of their errors.
+func QueryFirst(
+	ctx context.Context,
+	addr netip.AddrPort,
+	timeout time.Duration,
+	protocols []QueryFunc,
+) (Response, error) {
+	if len(protocols) == 0 {
+		return Blank, ErrNoProtocols
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		resp Response
+		err  error
+	}
+	results := make(chan result, len(protocols))
+	for _, query := range protocols {
+		go func() {
+			resp, err := query(ctx, addr, timeout)
+			results <- result{resp, err}
+		}()
+	}
+
+	errs := make([]error, 0, len(protocols))
+	for range protocols {
+		res := <-results
+		if res.err == nil {
+			return res.resp, nil
+		}
+		errs = append(errs, res.err)
+	}
+
+	return Blank, errors.Join(errs...)
+}
+
+// QueryPreferred queries the server with the preferred protocol alone,
+// which is normally the protocol the server has been answering with.
+// The rest of the protocols are only tried, all at once, should the preferred protocol fail.
+// In case the preferred protocol is unknown, every protocol is tried at once.
+// The timeout covers the whole query, so the preferred protocol is only given half of it,
+// and the fallback protocols are given whatever time is left.
+func QueryPreferred(
+	ctx context.Context,
+	addr netip.AddrPort,
+	timeout time.Duration,
+	preferred Protocol,
+	queries map[Protocol]QueryFunc,
+) (Response, error) {
+	query, ok := queries[preferred]
+	if !ok {
+		return QueryFirst(ctx, addr, timeout, ordered(queries, ""))
+	}
+
+	others := ordered(queries, preferred)
+	if len(others) == 0 {
+		return query(ctx, addr, timeout)
+	}
+
+	deadline := time.Now().Add(timeout)
+	resp, err := query(ctx, addr, timeout/2)
+	if err == nil {
+		return resp, nil
+	}
+
+	remaining := time.Until(deadline)
+	if remaining <= 0 {
+		return Blank, err
+	}
+	resp, fallbackErr := QueryFirst(ctx, addr, remaining, others)
+	if fallbackErr != nil {
+		return Blank, errors.Join(err, fallbackErr)
+	}
+	return resp, nil
+}
+
+// IsTimeout tells whether the query has failed because of the timeout.
+// For the combined errors, every protocol should have timed out,
+// because otherwise the server has answered, even though with an invalid response.
+func IsTimeout(err error) bool {
+	var joined interface{ Unwrap() []error }
+	if errors.As(err, &joined) {
+		errs := joined.Unwrap()
+		for _, e := range errs {
+			if !IsTimeout(e) {
+				return false
+			}
+		}
+		return len(errs) > 0
+	}
+	return errors.Is(err, os.ErrDeadlineExceeded)
+}
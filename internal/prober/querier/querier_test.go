@@ -0,0 +1,261 @@
+package querier_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/prober/querier"
+	"github.com/sergeii/swat4master/pkg/gamespy/serverquery/gs1"
+)
+
+var errQuery = errors.New("query error")
+
+func respondWith(resp querier.Response, delay time.Duration) querier.QueryFunc {
+	return func(ctx context.Context, _ netip.AddrPort, _ time.Duration) (querier.Response, error) {
+		select {
+		case <-time.After(delay):
+			return resp, nil
+		case <-ctx.Done():
+			return querier.Blank, os.ErrDeadlineExceeded
+		}
+	}
+}
+
+func failWith(err error) querier.QueryFunc {
+	return func(context.Context, netip.AddrPort, time.Duration) (querier.Response, error) {
+		return querier.Blank, err
+	}
+}
+
+func TestResponse_Rank(t *testing.T) {
+	vanilla := querier.Response{Protocol: querier.ProtocolGS1, Version: gs1.VerVanilla}
+	qr2 := querier.Response{Protocol: querier.ProtocolQR2}
+	am := querier.Response{Protocol: querier.ProtocolGS1, Version: gs1.VerAM}
+	gs1mod := querier.Response{Protocol: querier.ProtocolGS1, Version: gs1.VerGS1}
+
+	assert.Less(t, querier.Blank.Rank(), vanilla.Rank())
+	assert.Less(t, vanilla.Rank(), qr2.Rank())
+	assert.Less(t, qr2.Rank(), am.Rank())
+	assert.Less(t, am.Rank(), gs1mod.Rank())
+
+	assert.Equal(t, "gs1/vanilla", vanilla.Variant())
+	assert.Equal(t, "gs1/am", am.Variant())
+	assert.Equal(t, "qr2", qr2.Variant())
+}
+
+func TestQueryFirst(t *testing.T) {
+	gs1Resp := querier.Response{Protocol: querier.ProtocolGS1, Version: gs1.VerGS1}
+	qr2Resp := querier.Response{Protocol: querier.ProtocolQR2}
+	addr := netip.MustParseAddrPort("127.0.0.1:10481")
+
+	tests := []struct {
+		name      string
+		protocols []querier.QueryFunc
+		want      querier.Response
+		wantErr   []error
+	}{
+		{
+			name:      "first response wins",
+			protocols: []querier.QueryFunc{respondWith(gs1Resp, time.Millisecond*20), respondWith(qr2Resp, 0)},
+			want:      qr2Resp,
+		},
+		{
+			name:      "failed protocol is skipped",
+			protocols: []querier.QueryFunc{failWith(errQuery), respondWith(gs1Resp, time.Millisecond*10)},
+			want:      gs1Resp,
+		},
+		{
+			name:      "all protocols fail",
+			protocols: []querier.QueryFunc{failWith(errQuery), failWith(os.ErrDeadlineExceeded)},
+			wantErr:   []error{errQuery, os.ErrDeadlineExceeded},
+		},
+		{
+			name:      "no protocols",
+			protocols: nil,
+			wantErr:   []error{querier.ErrNoProtocols},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := querier.QueryFirst(context.TODO(), addr, time.Millisecond*50, tt.protocols)
+			if tt.wantErr != nil {
+				for _, wantErr := range tt.wantErr {
+					assert.ErrorIs(t, err, wantErr)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, resp)
+		})
+	}
+}
+
+func TestQueryPreferred(t *testing.T) {
+	gs1Resp := querier.Response{Protocol: querier.ProtocolGS1, Version: gs1.VerGS1}
+	qr2Resp := querier.Response{Protocol: querier.ProtocolQR2}
+	addr := netip.MustParseAddrPort("127.0.0.1:10481")
+
+	var qr2Queries int
+	countQueries := func(query querier.QueryFunc) querier.QueryFunc {
+		return func(ctx context.Context, addr netip.AddrPort, timeout time.Duration) (querier.Response, error) {
+			qr2Queries++
+			return query(ctx, addr, timeout)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		preferred     querier.Protocol
+		queries       map[querier.Protocol]querier.QueryFunc
+		want          querier.Response
+		wantQR2Called bool
+		wantErr       []error
+	}{
+		{
+			name:      "slower preferred protocol wins",
+			preferred: querier.ProtocolGS1,
+			queries: map[querier.Protocol]querier.QueryFunc{
+				querier.ProtocolGS1: respondWith(gs1Resp, time.Millisecond*20),
+				querier.ProtocolQR2: countQueries(respondWith(qr2Resp, 0)),
+			},
+			want: gs1Resp,
+		},
+		{
+			name:      "failed preferred protocol falls back to others",
+			preferred: querier.ProtocolGS1,
+			queries: map[querier.Protocol]querier.QueryFunc{
+				querier.ProtocolGS1: failWith(os.ErrDeadlineExceeded),
+				querier.ProtocolQR2: countQueries(respondWith(qr2Resp, time.Millisecond*10)),
+			},
+			want:          qr2Resp,
+			wantQR2Called: true,
+		},
+		{
+			name:      "unknown preferred protocol queries all at once",
+			preferred: "",
+			queries: map[querier.Protocol]querier.QueryFunc{
+				querier.ProtocolGS1: respondWith(gs1Resp, time.Millisecond*20),
+				querier.ProtocolQR2: countQueries(respondWith(qr2Resp, 0)),
+			},
+			want:          qr2Resp,
+			wantQR2Called: true,
+		},
+		{
+			name:      "all protocols fail",
+			preferred: querier.ProtocolQR2,
+			queries: map[querier.Protocol]querier.QueryFunc{
+				querier.ProtocolGS1: failWith(errQuery),
+				querier.ProtocolQR2: countQueries(failWith(os.ErrDeadlineExceeded)),
+			},
+			wantQR2Called: true,
+			wantErr:       []error{errQuery, os.ErrDeadlineExceeded},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qr2Queries = 0
+			resp, err := querier.QueryPreferred(context.TODO(), addr, time.Millisecond*50, tt.preferred, tt.queries)
+			assert.Equal(t, tt.wantQR2Called, qr2Queries > 0)
+			if tt.wantErr != nil {
+				for _, wantErr := range tt.wantErr {
+					assert.ErrorIs(t, err, wantErr)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, resp)
+		})
+	}
+}
+
+func TestQueryPreferred_TimeoutIsShared(t *testing.T) {
+	addr := netip.MustParseAddrPort("127.0.0.1:10481")
+	timeout := time.Millisecond * 100
+
+	timeouts := make(map[querier.Protocol]time.Duration)
+	timeOut := func(protocol querier.Protocol) querier.QueryFunc {
+		return func(ctx context.Context, _ netip.AddrPort, timeout time.Duration) (querier.Response, error) {
+			timeouts[protocol] = timeout
+			select {
+			case <-time.After(timeout):
+			case <-ctx.Done():
+			}
+			return querier.Blank, os.ErrDeadlineExceeded
+		}
+	}
+	queries := map[querier.Protocol]querier.QueryFunc{
+		querier.ProtocolGS1: timeOut(querier.ProtocolGS1),
+		querier.ProtocolQR2: timeOut(querier.ProtocolQR2),
+	}
+
+	started := time.Now()
+	_, err := querier.QueryPreferred(context.TODO(), addr, timeout, querier.ProtocolGS1, queries)
+	elapsed := time.Since(started)
+
+	require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	assert.True(t, querier.IsTimeout(err))
+	// the dead server is given up on within the timeout, instead of twice the timeout
+	assert.Equal(t, timeout/2, timeouts[querier.ProtocolGS1])
+	assert.LessOrEqual(t, timeouts[querier.ProtocolQR2], timeout/2)
+	assert.Less(t, elapsed, timeout+time.Millisecond*40)
+}
+
+func TestParseProtocol(t *testing.T) {
+	assert.Equal(t, querier.ProtocolGS1, querier.ParseProtocol("gs1/am"))
+	assert.Equal(t, querier.ProtocolQR2, querier.ParseProtocol("qr2"))
+	assert.Equal(t, querier.Protocol(""), querier.ParseProtocol(""))
+}
+
+func TestQueryAll(t *testing.T) {
+	gs1Resp := querier.Response{Protocol: querier.ProtocolGS1, Version: gs1.VerGS1}
+	qr2Resp := querier.Response{Protocol: querier.ProtocolQR2}
+	addr := netip.MustParseAddrPort("127.0.0.1:10481")
+
+	resps, err := querier.QueryAll(
+		context.TODO(), addr, time.Millisecond*50,
+		[]querier.QueryFunc{respondWith(gs1Resp, time.Millisecond*10), failWith(errQuery), respondWith(qr2Resp, 0)},
+	)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []querier.Response{gs1Resp, qr2Resp}, resps)
+
+	_, err = querier.QueryAll(
+		context.TODO(), addr, time.Millisecond*50,
+		[]querier.QueryFunc{failWith(errQuery), failWith(os.ErrDeadlineExceeded)},
+	)
+	assert.ErrorIs(t, err, errQuery)
+	assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+
+	_, err = querier.QueryAll(context.TODO(), addr, time.Millisecond*50, nil)
+	assert.ErrorIs(t, err, querier.ErrNoProtocols)
+}
+
+func TestIsTimeout(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"timeout", os.ErrDeadlineExceeded, true},
+		{"wrapped timeout", fmt.Errorf("read: %w", os.ErrDeadlineExceeded), true},
+		{"other error", errQuery, false},
+		{"all protocols timed out", errors.Join(os.ErrDeadlineExceeded, os.ErrDeadlineExceeded), true},
+		{"one protocol failed", errors.Join(os.ErrDeadlineExceeded, errQuery), false},
+		{"wrapped combined error", fmt.Errorf("query: %w", errors.Join(os.ErrDeadlineExceeded)), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, querier.IsTimeout(tt.err))
+		})
+	}
+}
@@ -2,8 +2,10 @@ package reporter
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"slices"
 	"sync"
 	"time"
 
@@ -12,10 +14,20 @@ import (
 
 	"github.com/sergeii/swat4master/internal/core/entities/master"
 	"github.com/sergeii/swat4master/internal/metrics"
+	"github.com/sergeii/swat4master/internal/reporter/relay"
 )
 
+var ErrUntrustedRelay = errors.New("relayed request from untrusted source")
+
+type DispatcherOpts struct {
+	// RelaySources are the addresses of the peer masters allowed to relay the requests of the game servers
+	RelaySources []net.IP
+}
+
 type Dispatcher struct {
 	metrics  *metrics.Collector
+	relay    *relay.Relay
+	opts     DispatcherOpts
 	clock    clockwork.Clock
 	logger   *zerolog.Logger
 	handlers map[master.Msg]Handler
@@ -24,11 +36,15 @@ type Dispatcher struct {
 
 func NewDispatcher(
 	metrics *metrics.Collector,
+	relay *relay.Relay,
+	opts DispatcherOpts,
 	clock clockwork.Clock,
 	logger *zerolog.Logger,
 ) *Dispatcher {
 	return &Dispatcher{
 		metrics:  metrics,
+		relay:    relay,
+		opts:     opts,
 		clock:    clock,
 		logger:   logger,
 		handlers: make(map[master.Msg]Handler),
@@ -59,16 +75,33 @@ func (d *Dispatcher) Handle(
 
 	d.metrics.ReporterReceived.Add(float64(len(payload)))
 
-	resp, reqType, err := d.dispatch(ctx, payload, addr)
+	// a request relayed by a peer master is handled as if it came from the game server itself,
+	// but the response is sent back to the peer, which has already answered the game server
+	src, payload, relayed, err := d.unwrap(addr, payload)
+	if err != nil {
+		d.metrics.ReporterErrors.WithLabelValues(master.Msg(payload[0]).String()).Inc()
+		d.logger.Warn().
+			Err(err).Stringer("src", addr).Int("len", len(payload)).
+			Msg("Failed to unwrap relayed request")
+		return
+	}
+
+	resp, reqType, err := d.dispatch(ctx, payload, src)
 	if err != nil {
 		d.metrics.ReporterErrors.WithLabelValues(reqType.String()).Inc()
 		d.logger.Error().
 			Err(err).
-			Stringer("src", addr).Stringer("type", reqType).Int("len", len(payload)).
+			Stringer("src", src).Stringer("type", reqType).Int("len", len(payload)).
 			Msg("Failed to dispatch request")
 		return
 	}
 
+	// only the requests that have been accepted are shared with peer masters,
+	// whereas the relayed requests are not passed any further, so that the peers never relay in a loop
+	if !relayed && (reqType == master.MsgHeartbeat || reqType == master.MsgKeepalive) {
+		d.relay.Forward(addr, payload)
+	}
+
 	// responses are optional for some request types, such as keepalive requests
 	if resp != nil {
 		d.logger.Debug().
@@ -90,6 +123,23 @@ func (d *Dispatcher) Handle(
 		Observe(time.Since(reqStarted).Seconds())
 }
 
+// unwrap extracts the request of the game server from the envelope of a trusted peer master.
+// The requests that are not wrapped are returned as is.
+func (d *Dispatcher) unwrap(addr *net.UDPAddr, payload []byte) (*net.UDPAddr, []byte, bool, error) {
+	if !relay.IsWrapped(payload) {
+		return addr, payload, false, nil
+	}
+	if !slices.ContainsFunc(d.opts.RelaySources, addr.IP.Equal) {
+		return nil, payload, false, ErrUntrustedRelay
+	}
+	src, unwrapped, err := relay.Unwrap(payload)
+	if err != nil {
+		return nil, payload, false, err
+	}
+	d.metrics.ReporterRelayed.Inc()
+	return src, unwrapped, true, nil
+}
+
 func (d *Dispatcher) dispatch(
 	ctx context.Context,
 	payload []byte,
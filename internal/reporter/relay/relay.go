@@ -0,0 +1,231 @@
+package relay
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/rs/zerolog"
+
+	"github.com/sergeii/swat4master/internal/metrics"
+)
+
+// EnvelopeMagic prefixes the payloads forwarded to the peers.
+// The magic is followed by the version byte, the original source IPv4 address and port,
+// and then the original payload, as it was received from the game server.
+var EnvelopeMagic = []byte{0xfe, 0xfe, 'R'}
+
+const (
+	EnvelopeVersion = 0x01
+	EnvelopeLen     = 10
+)
+
+// replyBufferSize is enough for any reply of a master to a reporting request
+const replyBufferSize = 1024
+
+var ErrInvalidEnvelope = errors.New("invalid envelope")
+
+type Opts struct {
+	Peers      []string
+	QueueSize  int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+type packet struct {
+	src     *net.UDPAddr
+	payload []byte
+}
+
+type peer struct {
+	addr    string
+	queue   chan packet
+	conn    net.Conn
+	backoff time.Duration
+	retryAt time.Time
+}
+
+// Relay forwards the accepted reporting requests to the configured peer masters.
+// The requests are always wrapped in the envelope, because the peers would otherwise
+// see every relayed server under the address of this master.
+// Every peer is served by its own goroutine with a bounded queue,
+// so a slow or unreachable peer never blocks the reporter or the other peers.
+// The requests that do not fit in a peer's queue are dropped.
+// The game servers have already been answered by this master,
+// so the replies of the peers are only counted and then discarded.
+type Relay struct {
+	opts    Opts
+	peers   []*peer
+	metrics *metrics.Collector
+	clock   clockwork.Clock
+	logger  *zerolog.Logger
+	stop    chan struct{}
+	wg      sync.WaitGroup
+}
+
+func New(
+	opts Opts,
+	metrics *metrics.Collector,
+	clock clockwork.Clock,
+	logger *zerolog.Logger,
+) *Relay {
+	r := &Relay{
+		opts:    opts,
+		metrics: metrics,
+		clock:   clock,
+		logger:  logger,
+		stop:    make(chan struct{}),
+	}
+	r.peers = make([]*peer, 0, len(opts.Peers))
+	for _, addr := range opts.Peers {
+		r.peers = append(r.peers, &peer{
+			addr:  addr,
+			queue: make(chan packet, opts.QueueSize),
+		})
+	}
+	return r
+}
+
+func (r *Relay) Start() {
+	for _, p := range r.peers {
+		r.wg.Go(func() {
+			r.serve(p)
+		})
+	}
+}
+
+func (r *Relay) Stop() {
+	close(r.stop)
+	r.wg.Wait()
+}
+
+// Forward queues the payload received from src for delivery to every peer.
+// It never blocks.
+func (r *Relay) Forward(src *net.UDPAddr, payload []byte) {
+	pkt := packet{src: src, payload: payload}
+	for _, p := range r.peers {
+		select {
+		case p.queue <- pkt:
+		default:
+			r.metrics.RelayDropped.WithLabelValues(p.addr).Inc()
+		}
+	}
+}
+
+func (r *Relay) serve(p *peer) {
+	defer func() {
+		if p.conn != nil {
+			p.conn.Close() //nolint: errcheck
+		}
+	}()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case pkt := <-p.queue:
+			r.send(p, pkt)
+		}
+	}
+}
+
+func (r *Relay) send(p *peer, pkt packet) {
+	// while backing off, the requests are dropped instead of piling up
+	if r.clock.Now().Before(p.retryAt) {
+		r.metrics.RelayDropped.WithLabelValues(p.addr).Inc()
+		return
+	}
+
+	if p.conn == nil {
+		conn, err := net.Dial("udp", p.addr)
+		if err != nil {
+			r.fail(p, err)
+			return
+		}
+		p.conn = conn
+		// the reader is done once the connection is closed
+		r.wg.Go(func() {
+			r.drain(p.addr, conn)
+		})
+	}
+
+	if _, err := p.conn.Write(Wrap(pkt.src, pkt.payload)); err != nil {
+		p.conn.Close() //nolint: errcheck
+		p.conn = nil
+		r.fail(p, err)
+		return
+	}
+
+	p.backoff = 0
+	r.metrics.RelayForwarded.WithLabelValues(p.addr).Inc()
+}
+
+// drain reads the replies of the peer until the connection is closed
+func (r *Relay) drain(peerAddr string, conn net.Conn) {
+	buf := make([]byte, replyBufferSize)
+	for {
+		if _, err := conn.Read(buf); err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			// such as when the peer is unreachable, so pause instead of spinning on the error
+			r.metrics.RelayErrors.WithLabelValues(peerAddr).Inc()
+			select {
+			case <-r.stop:
+				return
+			case <-r.clock.After(r.opts.MinBackoff):
+			}
+			continue
+		}
+		r.metrics.RelayReplies.WithLabelValues(peerAddr).Inc()
+	}
+}
+
+func (r *Relay) fail(p *peer, err error) {
+	p.backoff = min(max(p.backoff*2, r.opts.MinBackoff), r.opts.MaxBackoff)
+	p.retryAt = r.clock.Now().Add(p.backoff)
+	r.metrics.RelayErrors.WithLabelValues(p.addr).Inc()
+	r.logger.Warn().
+		Err(err).Str("peer", p.addr).Dur("backoff", p.backoff).
+		Msg("Failed to forward request to peer master")
+}
+
+// Wrap puts the payload in the envelope carrying the original source address.
+// Only IPv4 addresses are supported by the game, so the other addresses are zeroed.
+func Wrap(src *net.UDPAddr, payload []byte) []byte {
+	wrapped := make([]byte, 0, EnvelopeLen+len(payload))
+	wrapped = append(wrapped, EnvelopeMagic...)
+	wrapped = append(wrapped, EnvelopeVersion)
+	if ip := src.IP.To4(); ip != nil {
+		wrapped = append(wrapped, ip...)
+	} else {
+		wrapped = append(wrapped, 0x00, 0x00, 0x00, 0x00)
+	}
+	wrapped = binary.BigEndian.AppendUint16(wrapped, uint16(src.Port)) //nolint:gosec
+	return append(wrapped, payload...)
+}
+
+// Unwrap extracts the original source address and the original payload from the envelope
+func Unwrap(payload []byte) (*net.UDPAddr, []byte, error) {
+	if !IsWrapped(payload) || len(payload) <= EnvelopeLen {
+		return nil, nil, fmt.Errorf("%w: too short", ErrInvalidEnvelope)
+	}
+	if payload[3] != EnvelopeVersion {
+		return nil, nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidEnvelope, payload[3])
+	}
+	ip := net.IPv4(payload[4], payload[5], payload[6], payload[7])
+	port := int(binary.BigEndian.Uint16(payload[8:10]))
+	if ip.Equal(net.IPv4zero) || port == 0 {
+		return nil, nil, fmt.Errorf("%w: unknown source address", ErrInvalidEnvelope)
+	}
+	return &net.UDPAddr{IP: ip, Port: port}, payload[EnvelopeLen:], nil
+}
+
+// IsWrapped tells whether the payload starts with the envelope magic
+func IsWrapped(payload []byte) bool {
+	return bytes.HasPrefix(payload, EnvelopeMagic)
+}
@@ -0,0 +1,166 @@
+package relay_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/metrics"
+	"github.com/sergeii/swat4master/internal/reporter/relay"
+)
+
+func TestRelay_Forward_QueueIsBounded(t *testing.T) {
+	logger := zerolog.Nop()
+	collector := metrics.New()
+	clock := clockwork.NewFakeClock()
+
+	rel := relay.New(
+		relay.Opts{Peers: []string{"127.0.0.1:27900"}, QueueSize: 2},
+		collector,
+		clock,
+		&logger,
+	)
+
+	// the relay is not started, so nothing is consumed from the queue
+	src := &net.UDPAddr{IP: net.ParseIP("1.1.1.1"), Port: 10481}
+	for range 5 {
+		rel.Forward(src, []byte{0x08, 0xfe, 0xed, 0xf0, 0x0d})
+	}
+
+	assert.InDelta(t, 3, testutil.ToFloat64(collector.RelayDropped.WithLabelValues("127.0.0.1:27900")), 1e-9)
+}
+
+func TestRelay_Forward_BackoffOnErrors(t *testing.T) {
+	logger := zerolog.Nop()
+	collector := metrics.New()
+	clock := clockwork.NewFakeClock()
+
+	// the address misses the port, so it can never be dialed
+	peerAddr := "127.0.0.1"
+	rel := relay.New(
+		relay.Opts{
+			Peers:      []string{peerAddr},
+			QueueSize:  10,
+			MinBackoff: time.Second,
+			MaxBackoff: time.Second * 3,
+		},
+		collector,
+		clock,
+		&logger,
+	)
+	rel.Start()
+	defer rel.Stop()
+
+	src := &net.UDPAddr{IP: net.ParseIP("1.1.1.1"), Port: 10481}
+	payload := []byte{0x08, 0xfe, 0xed, 0xf0, 0x0d}
+
+	expectCounts := func(errors, dropped float64) {
+		assert.Eventually(t, func() bool {
+			return testutil.ToFloat64(collector.RelayErrors.WithLabelValues(peerAddr)) == errors &&
+				testutil.ToFloat64(collector.RelayDropped.WithLabelValues(peerAddr)) == dropped
+		}, time.Millisecond*100, time.Millisecond)
+	}
+
+	rel.Forward(src, payload)
+	expectCounts(1, 0)
+
+	// the requests are dropped while backing off
+	rel.Forward(src, payload)
+	rel.Forward(src, payload)
+	expectCounts(1, 2)
+
+	clock.Advance(time.Second)
+	rel.Forward(src, payload)
+	expectCounts(2, 2)
+
+	// the backoff is doubled
+	clock.Advance(time.Second)
+	rel.Forward(src, payload)
+	expectCounts(2, 3)
+
+	clock.Advance(time.Second)
+	rel.Forward(src, payload)
+	expectCounts(3, 3)
+
+	// but is never longer than the maximum
+	clock.Advance(time.Second * 3)
+	rel.Forward(src, payload)
+	expectCounts(4, 3)
+
+	assert.InDelta(t, 0, testutil.ToFloat64(collector.RelayForwarded.WithLabelValues(peerAddr)), 1e-9)
+}
+
+func TestRelay_Wrap(t *testing.T) {
+	payload := []byte{0x08, 0xfe, 0xed, 0xf0, 0x0d}
+
+	wrapped := relay.Wrap(&net.UDPAddr{IP: net.ParseIP("1.2.3.4"), Port: 10481}, payload)
+	assert.Equal(t, []byte{0xfe, 0xfe, 'R', 0x01, 1, 2, 3, 4, 0x28, 0xf1, 0x08, 0xfe, 0xed, 0xf0, 0x0d}, wrapped)
+
+	wrapped = relay.Wrap(&net.UDPAddr{IP: net.ParseIP("::1"), Port: 10481}, payload)
+	assert.Equal(t, []byte{0xfe, 0xfe, 'R', 0x01, 0, 0, 0, 0, 0x28, 0xf1, 0x08, 0xfe, 0xed, 0xf0, 0x0d}, wrapped)
+}
+
+func TestRelay_Unwrap(t *testing.T) {
+	payload := []byte{0x08, 0xfe, 0xed, 0xf0, 0x0d}
+
+	src, unwrapped, err := relay.Unwrap(relay.Wrap(&net.UDPAddr{IP: net.ParseIP("1.2.3.4"), Port: 10481}, payload))
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.3.4:10481", src.String())
+	assert.Equal(t, payload, unwrapped)
+
+	tests := []struct {
+		name    string
+		payload []byte
+	}{
+		{"not wrapped", payload},
+		{"no payload", []byte{0xfe, 0xfe, 'R', 0x01, 1, 2, 3, 4, 0x28, 0xf1}},
+		{"unknown version", []byte{0xfe, 0xfe, 'R', 0x02, 1, 2, 3, 4, 0x28, 0xf1, 0x08}},
+		{"unknown address", relay.Wrap(&net.UDPAddr{IP: net.ParseIP("::1"), Port: 10481}, payload)},
+		{"no port", []byte{0xfe, 0xfe, 'R', 0x01, 1, 2, 3, 4, 0x00, 0x00, 0x08}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := relay.Unwrap(tt.payload)
+			require.ErrorIs(t, err, relay.ErrInvalidEnvelope)
+		})
+	}
+}
+
+func TestRelay_Forward_RepliesAreDrained(t *testing.T) {
+	logger := zerolog.Nop()
+	collector := metrics.New()
+	clock := clockwork.NewFakeClock()
+
+	peer, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer peer.Close()
+	peerAddr := peer.LocalAddr().String()
+
+	rel := relay.New(relay.Opts{Peers: []string{peerAddr}, QueueSize: 10}, collector, clock, &logger)
+	rel.Start()
+	defer rel.Stop()
+
+	rel.Forward(&net.UDPAddr{IP: net.ParseIP("1.1.1.1"), Port: 10481}, []byte{0x08, 0xfe, 0xed, 0xf0, 0x0d})
+
+	buf := make([]byte, 1024)
+	require.NoError(t, peer.SetReadDeadline(time.Now().Add(time.Millisecond*100)))
+	_, relayAddr, err := peer.ReadFrom(buf)
+	require.NoError(t, err)
+
+	// the peer replies twice
+	for range 2 {
+		_, err = peer.WriteTo([]byte{0xfe, 0xfd, 0x0a, 0xfe, 0xed, 0xf0, 0x0d}, relayAddr)
+		require.NoError(t, err)
+	}
+
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(collector.RelayReplies.WithLabelValues(peerAddr)) == 2
+	}, time.Millisecond*100, time.Millisecond)
+	assert.InDelta(t, 1, testutil.ToFloat64(collector.RelayForwarded.WithLabelValues(peerAddr)), 1e-9)
+}
@@ -1,16 +1,24 @@
 package api
 
 import (
+	"github.com/jonboulle/clockwork"
 	"github.com/rs/zerolog"
 
 	"github.com/sergeii/swat4master/cmd/swat4master/container"
 	"github.com/sergeii/swat4master/internal/settings"
 )
 
+type Opts struct {
+	// AdminToken guards the admin endpoints. The endpoints are disabled unless the token is set.
+	AdminToken string
+}
+
 type API struct {
 	settings  settings.Settings
 	container container.Container
+	clock     clockwork.Clock
 	logger    *zerolog.Logger
+	opts      Opts
 }
 
 type Error struct {
@@ -21,10 +29,14 @@ func New(
 	settings settings.Settings,
 	logger *zerolog.Logger,
 	container container.Container,
+	clock clockwork.Clock,
+	opts Opts,
 ) *API {
 	return &API{
 		container: container,
 		settings:  settings,
+		clock:     clock,
 		logger:    logger,
+		opts:      opts,
 	}
 }
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sergeii/swat4master/internal/core/entities/featured"
+	"github.com/sergeii/swat4master/internal/core/usecases/featureserver"
+	"github.com/sergeii/swat4master/internal/core/usecases/listfeatured"
+	"github.com/sergeii/swat4master/internal/core/usecases/unfeatureserver"
+	"github.com/sergeii/swat4master/internal/rest/model"
+)
+
+// ListFeatured godoc
+// @Summary      List featured servers
+// @Description  List all featured servers, including the ones outside of their featuring window
+// @Tags         admin
+// @Produce      json
+// @Security     AdminToken
+// @Success      200 {array} model.FeaturedServer
+// @Router       /admin/featured [get]
+func (a *API) ListFeatured(c *gin.Context) {
+	items, err := a.container.ListFeatured.Execute(c, listfeatured.NewRequest(false))
+	if err != nil {
+		a.logger.Err(err).Msg("Failed to obtain featured servers")
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	result := make([]model.FeaturedServer, 0, len(items))
+	for _, item := range items {
+		result = append(result, model.NewFeaturedServerFromDomain(item))
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// FeatureServer godoc
+// @Summary      Feature server
+// @Description  Add a server to the featured list or update its featuring parameters
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     AdminToken
+// @Param        server  body      model.FeatureServer  true  "Featuring parameters"
+// @Success      200     {object}  model.FeaturedServer
+// @Router       /admin/featured/:address [put]
+func (a *API) FeatureServer(c *gin.Context) {
+	address, parseErr := parseViewServerAddress(c)
+	if parseErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid server address"})
+		return
+	}
+
+	var req model.FeatureServer
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid featuring parameters"})
+		return
+	}
+
+	ucRequest := featureserver.NewRequest(address, req.Priority, timeOrNC(req.Since), timeOrNC(req.Until))
+	item, err := a.container.FeatureServer.Execute(c, ucRequest)
+	if err != nil {
+		switch {
+		case errors.Is(err, featureserver.ErrInvalidWindow):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid featuring window"})
+		default:
+			a.logger.Err(err).Stringer("addr", address.ToAddr()).Msg("Failed to feature server")
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewFeaturedServerFromDomain(item))
+}
+
+// UnfeatureServer godoc
+// @Summary      Unfeature server
+// @Description  Remove a server from the featured list
+// @Tags         admin
+// @Security     AdminToken
+// @Success      204  "Server is no longer featured"
+// @Failure      404  "Server is not featured"
+// @Router       /admin/featured/:address [delete]
+func (a *API) UnfeatureServer(c *gin.Context) {
+	address, parseErr := parseViewServerAddress(c)
+	if parseErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid server address"})
+		return
+	}
+
+	if err := a.container.UnfeatureServer.Execute(c, address); err != nil {
+		switch {
+		case errors.Is(err, unfeatureserver.ErrServerNotFeatured):
+			c.Status(http.StatusNotFound)
+		default:
+			a.logger.Err(err).Stringer("addr", address.ToAddr()).Msg("Failed to unfeature server")
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func timeOrNC(t *time.Time) time.Time {
+	if t == nil {
+		return featured.NC
+	}
+	return *t
+}
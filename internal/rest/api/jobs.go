@@ -0,0 +1,81 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sergeii/swat4master/internal/core/usecases/listjobruns"
+	"github.com/sergeii/swat4master/internal/rest/model"
+)
+
+type JobRunsForm struct {
+	Limit int `binding:"omitempty,gte=1,lte=100" form:"limit"`
+}
+
+const defaultJobRunsLimit = 20
+
+// ListJobs godoc
+// @Summary      List scheduled jobs
+// @Description  List the last run of every scheduled job that has ever run
+// @Tags         admin
+// @Produce      json
+// @Security     AdminToken
+// @Success      200 {array} model.JobRun
+// @Router       /admin/jobs [get]
+func (a *API) ListJobs(c *gin.Context) {
+	runs, err := a.container.ListJobs.Execute(c)
+	if err != nil {
+		a.logger.Err(err).Msg("Failed to obtain jobs")
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	result := make([]model.JobRun, 0, len(runs))
+	for _, run := range runs {
+		result = append(result, model.NewJobRunFromDomain(run))
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// ListJobRuns godoc
+// @Summary      List job runs
+// @Description  List the latest runs of a scheduled job, the latest run first
+// @Tags         admin
+// @Produce      json
+// @Security     AdminToken
+// @Param        limit  query  int  false  "Number of runs to list (1-100, 20 by default)"
+// @Success      200 {array} model.JobRun
+// @Failure      404  "Job has never run"
+// @Router       /admin/jobs/:name [get]
+func (a *API) ListJobRuns(c *gin.Context) {
+	var form JobRunsForm
+	if err := c.ShouldBindQuery(&form); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+	limit := form.Limit
+	if limit == 0 {
+		limit = defaultJobRunsLimit
+	}
+
+	job := c.Param("name")
+	runs, err := a.container.ListJobRuns.Execute(c, listjobruns.NewRequest(job, limit))
+	if err != nil {
+		switch {
+		case errors.Is(err, listjobruns.ErrJobNotFound):
+			c.Status(http.StatusNotFound)
+		default:
+			a.logger.Err(err).Str("job", job).Msg("Failed to obtain job runs")
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	result := make([]model.JobRun, 0, len(runs))
+	for _, run := range runs {
+		result = append(result, model.NewJobRunFromDomain(run))
+	}
+	c.JSON(http.StatusOK, result)
+}
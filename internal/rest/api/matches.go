@@ -0,0 +1,83 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sergeii/swat4master/internal/core/usecases/getmatch"
+	"github.com/sergeii/swat4master/internal/core/usecases/listmatches"
+	"github.com/sergeii/swat4master/internal/rest/model"
+)
+
+type MatchesForm struct {
+	Limit int `binding:"omitempty,gte=1,lte=100" form:"limit"`
+}
+
+const defaultMatchesLimit = 20
+
+// ListServerMatches godoc
+// @Summary      List server matches
+// @Description  List the results of the latest matches played on a server, the latest match first
+// @Tags         matches
+// @Produce      json
+// @Param        limit  query  int  false  "Number of matches to list (1-100, 20 by default)"
+// @Success      200 {array} model.Match
+// @Router       /servers/:address/matches [get]
+func (a *API) ListServerMatches(c *gin.Context) {
+	address, parseErr := parseViewServerAddress(c)
+	if parseErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid server address"})
+		return
+	}
+
+	var form MatchesForm
+	if err := c.ShouldBindQuery(&form); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+	limit := form.Limit
+	if limit == 0 {
+		limit = defaultMatchesLimit
+	}
+
+	matches, err := a.container.ListMatches.Execute(c, listmatches.NewRequest(address.ToAddr(), limit))
+	if err != nil {
+		a.logger.Err(err).Stringer("addr", address.ToAddr()).Msg("Failed to obtain server matches")
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	result := make([]model.Match, 0, len(matches))
+	for _, m := range matches {
+		result = append(result, model.NewMatchFromDomain(m))
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// ViewMatch godoc
+// @Summary      View match detail
+// @Description  Return the result of a match along with the players and the objectives
+// @Tags         matches
+// @Produce      json
+// @Success      200 {object} model.MatchDetail
+// @Failure      404  "Match not found"
+// @Router       /matches/:id [get]
+func (a *API) ViewMatch(c *gin.Context) {
+	id := c.Param("id")
+	m, err := a.container.GetMatch.Execute(c, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, getmatch.ErrMatchNotFound):
+			a.logger.Debug().Str("id", id).Msg("Requested match not found")
+			c.Status(http.StatusNotFound)
+		default:
+			a.logger.Err(err).Str("id", id).Msg("Failed to obtain match")
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewMatchDetailFromDomain(m))
+}
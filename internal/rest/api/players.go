@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sergeii/swat4master/internal/core/usecases/searchplayers"
+	"github.com/sergeii/swat4master/internal/rest/model"
+)
+
+type PlayersForm struct {
+	Name  string `binding:"required"                 form:"name"`
+	Limit int    `binding:"omitempty,gte=1,lte=100" form:"limit"`
+}
+
+const defaultPlayersLimit = 20
+
+// SearchPlayers godoc
+// @Summary      Search online players
+// @Description  Search the players currently playing on the live servers by name.
+// @Description  The name is matched case-insensitively and regardless of text styles.
+// @Description  Besides the names starting with or containing the query, the names with minor typos match as well.
+// @Description  The closest matches go first.
+// @Tags         players
+// @Produce      json
+// @Param        name   query  string  true   "Player name to search for"
+// @Param        limit  query  int     false  "Number of players to list (1-100, 20 by default)"
+// @Success      200 {array} model.OnlinePlayer
+// @Router       /players [get]
+func (a *API) SearchPlayers(c *gin.Context) {
+	var form PlayersForm
+	if err := c.ShouldBindQuery(&form); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid search query"})
+		return
+	}
+	limit := form.Limit
+	if limit == 0 {
+		limit = defaultPlayersLimit
+	}
+
+	req := searchplayers.NewRequest(form.Name, limit, a.settings.ServerLiveness)
+	players, err := a.container.SearchPlayers.Execute(c, req)
+	if err != nil {
+		a.logger.Err(err).Str("name", form.Name).Msg("Failed to search players")
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	now := a.clock.Now()
+	result := make([]model.OnlinePlayer, 0, len(players))
+	for _, player := range players {
+		result = append(result, model.NewOnlinePlayerFromDomain(player.Session, player.Server, player.Match, now))
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sergeii/swat4master/internal/core/usecases/getserverhistory"
+	"github.com/sergeii/swat4master/internal/rest/model"
+)
+
+type ServerHistoryForm struct {
+	From time.Time     `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To   time.Time     `form:"to"   time_format:"2006-01-02T15:04:05Z07:00"`
+	Step time.Duration `binding:"omitempty,gte=0" form:"step"`
+}
+
+// ViewServerHistory godoc
+// @Summary      View server history
+// @Description  Return the history of players, maps and availability of a server aggregated by the step.
+// @Description  The history is kept raw for a day, in 5 minute rollups for 30 days and in hourly rollups for a year.
+// @Tags         servers
+// @Produce      json
+// @Param        from  query  string  false  "Start of the range in RFC 3339 (24 hours before the end by default)"
+// @Param        to    query  string  false  "End of the range in RFC 3339 (now by default)"
+// @Param        step  query  string  false  "Aggregation step, e.g. 5m or 1h (picked to fit the range by default)"
+// @Success      200 {object} model.ServerHistory
+// @Router       /servers/:address/history [get]
+func (a *API) ViewServerHistory(c *gin.Context) {
+	address, parseErr := parseViewServerAddress(c)
+	if parseErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid server address"})
+		return
+	}
+
+	var form ServerHistoryForm
+	if err := c.ShouldBindQuery(&form); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid history range"})
+		return
+	}
+
+	req := getserverhistory.NewRequest(address.ToAddr(), form.From, form.To, form.Step)
+	resp, err := a.container.GetHistory.Execute(c, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, getserverhistory.ErrInvalidRange),
+			errors.Is(err, getserverhistory.ErrRangeOutOfRetention),
+			errors.Is(err, getserverhistory.ErrTooManyPoints):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			a.logger.Err(err).Stringer("addr", address.ToAddr()).Msg("Failed to obtain server history")
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewServerHistory(resp.From, resp.To, resp.Resolution, resp.Step, resp.Points))
+}
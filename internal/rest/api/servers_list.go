@@ -1,17 +1,26 @@
 package api
 
 import (
+	"cmp"
 	"net/http"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/internal/core/entities/featured"
+	"github.com/sergeii/swat4master/internal/core/entities/server"
+	"github.com/sergeii/swat4master/internal/core/usecases/listfeatured"
 	"github.com/sergeii/swat4master/internal/core/usecases/listservers"
 	"github.com/sergeii/swat4master/internal/rest/model"
 	"github.com/sergeii/swat4master/pkg/gamespy/browsing/query"
 	"github.com/sergeii/swat4master/pkg/gamespy/browsing/query/filter"
 )
 
+const uptimeSortWindow = time.Hour * 24 * 7
+
 type ServerFilterForm struct {
 	GameVariant    string `form:"gamevariant"`
 	GameVer        string `form:"gamever"`
@@ -19,11 +28,17 @@ type ServerFilterForm struct {
 	HidePassworded bool   `form:"nopassworded"`
 	HideFull       bool   `form:"nofull"`
 	HideEmpty      bool   `form:"noempty"`
+	Country        string `form:"country"`
+	Continent      string `form:"continent"`
+	HideFederated  bool   `form:"nofederated"`
+	Sort           string `binding:"omitempty,oneof=latency uptime" form:"sort"`
 }
 
 // ListServers godoc
 // @Summary      List servers
-// @Description  List servers that report to the master server as well as the servers discovered by other means
+// @Description  List servers that report to the master server as well as the servers discovered by other means.
+// @Description  Featured servers are listed first.
+// @Description  Reported servers never reached by a probe are flagged as unverified and may be hidden by configuration.
 // @Tags         servers
 // @Produce      json
 // @Param        gamevariant     query    string  false  "Game variant (SWAT 4, SWAT 4X, etc)"
@@ -32,6 +47,10 @@ type ServerFilterForm struct {
 // @Param        nopassworded    query    bool    false  "Hide password protected servers"
 // @Param        nofull          query    bool    false  "Hide full servers"
 // @Param        noempty         query    bool    false  "Hide empty servers"
+// @Param        country         query    string  false  "Country code (DE, US, etc)"
+// @Param        continent       query    string  false  "Continent code (EU, NA, etc)"
+// @Param        nofederated     query    bool    false  "Hide servers imported from upstream master servers"
+// @Param        sort            query    string  false  "Sort servers: latency - lowest latency first, uptime - highest weekly uptime first"
 // @Success      200 {array} model.Server
 // @Router       /servers [get]
 func (a *API) ListServers(c *gin.Context) {
@@ -43,6 +62,12 @@ func (a *API) ListServers(c *gin.Context) {
 
 	q := prepareQuery(form)
 	ucRequest := listservers.NewRequest(q, a.settings.ServerLiveness, ds.Info)
+	if a.settings.HideUnverified {
+		ucRequest = ucRequest.OnlyTrusted()
+	}
+	if form.HideFederated {
+		ucRequest = ucRequest.WithoutStatus(ds.Federated)
+	}
 
 	servers, err := a.container.ListServers.Execute(c, ucRequest)
 	if err != nil {
@@ -51,13 +76,70 @@ func (a *API) ListServers(c *gin.Context) {
 		return
 	}
 
+	switch form.Sort {
+	case "latency":
+		sortByLatency(servers)
+	case "uptime":
+		sortByUptime(servers, a.clock.Now())
+	}
+
+	// featured servers go first, provided they match the filters
+	var ranking featured.Ranking
+	items, err := a.container.ListFeatured.Execute(c, listfeatured.NewRequest(true))
+	if err != nil {
+		a.logger.Warn().Err(err).Msg("Failed to obtain featured servers")
+	} else {
+		ranking = featured.NewRanking(items)
+		slices.SortStableFunc(servers, func(svrA, svrB server.Server) int {
+			return ranking.Compare(svrA.Addr, svrB.Addr)
+		})
+	}
+
 	result := make([]model.Server, 0, len(servers))
 	for _, svr := range servers {
-		result = append(result, model.NewServerFromDomain(svr))
+		item := model.NewServerFromDomain(svr)
+		item.Featured = ranking.IsFeatured(svr.Addr)
+		result = append(result, item)
 	}
 	c.JSON(http.StatusOK, result)
 }
 
+// sortByLatency puts the servers with the lowest average latency first.
+// The servers with unknown latency go last.
+func sortByLatency(servers []server.Server) {
+	slices.SortStableFunc(servers, func(svrA, svrB server.Server) int {
+		knownA, knownB := svrA.Latency.IsKnown(), svrB.Latency.IsKnown()
+		switch {
+		case knownA && knownB:
+			return cmp.Compare(svrA.Latency.Average, svrB.Latency.Average)
+		case knownA:
+			return -1
+		case knownB:
+			return 1
+		default:
+			return 0
+		}
+	})
+}
+
+// sortByUptime puts the servers with the highest uptime over the last week first.
+// The servers with unknown availability go last.
+func sortByUptime(servers []server.Server, now time.Time) {
+	slices.SortStableFunc(servers, func(svrA, svrB server.Server) int {
+		knownA, knownB := svrA.Availability.IsKnown(), svrB.Availability.IsKnown()
+		switch {
+		case knownA && knownB:
+			return cmp.Compare(svrB.Availability.Uptime(uptimeSortWindow, now), svrA.Availability.Uptime(uptimeSortWindow, now))
+		case knownA:
+			return -1
+		case knownB:
+			return 1
+		default:
+			return 0
+		}
+	})
+}
+
 func maybeAddFilter(filters []filter.Filter, f filter.Filter, err error) []filter.Filter {
 	if err == nil {
 		filters = append(filters, f)
@@ -91,6 +173,15 @@ func prepareQuery(form ServerFilterForm) query.Query {
 		f, err := filter.New("numplayers", ">", 0)
 		filters = maybeAddFilter(filters, f, err)
 	}
+	// the location codes are resolved in upper case
+	if form.Country != "" {
+		f, err := filter.New("country", "=", strings.ToUpper(form.Country))
+		filters = maybeAddFilter(filters, f, err)
+	}
+	if form.Continent != "" {
+		f, err := filter.New("continent", "=", strings.ToUpper(form.Continent))
+		filters = maybeAddFilter(filters, f, err)
+	}
 	if len(filters) > 0 {
 		if q, err := query.New(filters); err == nil {
 			return q
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sergeii/swat4master/internal/core/usecases/claimserver"
+	"github.com/sergeii/swat4master/internal/core/usecases/updateprofile"
+	"github.com/sergeii/swat4master/internal/rest/model"
+)
+
+// ClaimServer godoc
+// @Summary      Claim server ownership
+// @Description  Issue a token and a key to prove the ownership of the server.
+// @Description  The owner puts the token in the server's hostname or any other query field until it is confirmed.
+// @Description  The key is given only once and lets the owner edit the server's profile once the claim is confirmed.
+// @Tags         servers
+// @Produce      json
+// @Success      201 {object} model.ServerClaim
+// @Failure      404 "Server is not found"
+// @Failure      409 "Server is not queried for its details, so the claim cannot be confirmed"
+// @Failure      429 "Server has too many pending claims"
+// @Router       /servers/:address/claim [post]
+func (a *API) ClaimServer(c *gin.Context) {
+	address, parseErr := parseViewServerAddress(c)
+	if parseErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid server address"})
+		return
+	}
+
+	resp, err := a.container.ClaimServer.Execute(c, address)
+	if err != nil {
+		switch {
+		case errors.Is(err, claimserver.ErrServerNotFound):
+			c.Status(http.StatusNotFound)
+		case errors.Is(err, claimserver.ErrServerHasNoDetails):
+			c.JSON(http.StatusConflict, gin.H{"error": "Server is not queried for its details"})
+		case errors.Is(err, claimserver.ErrTooManyClaims):
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Server has too many pending claims"})
+		default:
+			a.logger.Err(err).Stringer("addr", address.ToAddr()).Msg("Failed to claim server")
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	a.logger.Info().Stringer("addr", address.ToAddr()).Time("expires", resp.ExpiresAt).Msg("Issued server claim")
+
+	c.JSON(http.StatusCreated, model.NewServerClaim(resp.Token, resp.Key, resp.ExpiresAt))
+}
+
+// UpdateServerProfile godoc
+// @Summary      Update server profile
+// @Description  Replace the profile of the server. Only the verified owner of the server is allowed to do so.
+// @Tags         servers
+// @Accept       json
+// @Produce      json
+// @Param        Authorization  header    string                   true  "Bearer <owner key>"
+// @Param        profile        body      model.EditServerProfile  true  "Server profile"
+// @Success      200            {object}  model.ServerProfile
+// @Failure      401            "The key does not belong to the server owner"
+// @Failure      404            "Server is not found"
+// @Router       /servers/:address/profile [put]
+func (a *API) UpdateServerProfile(c *gin.Context) {
+	address, parseErr := parseViewServerAddress(c)
+	if parseErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid server address"})
+		return
+	}
+
+	key, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok || key == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Owner key is required"})
+		return
+	}
+
+	var req model.EditServerProfile
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid profile"})
+		return
+	}
+
+	ucRequest := updateprofile.NewRequest(address, key, req.Description, req.Website, req.Discord, req.Region)
+	item, err := a.container.UpdateProfile.Execute(c, ucRequest)
+	if err != nil {
+		switch {
+		case errors.Is(err, updateprofile.ErrServerNotFound):
+			c.Status(http.StatusNotFound)
+		case errors.Is(err, updateprofile.ErrNotOwner):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid owner key"})
+		default:
+			a.logger.Err(err).Stringer("addr", address.ToAddr()).Msg("Failed to update server profile")
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, model.NewServerProfileFromDomain(item))
+}
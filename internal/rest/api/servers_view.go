@@ -7,6 +7,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/usecases/getprofile"
 	"github.com/sergeii/swat4master/internal/core/usecases/getserver"
 	"github.com/sergeii/swat4master/internal/rest/model"
 )
@@ -47,7 +48,17 @@ func (a *API) ViewServer(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, model.NewServerDetailFromDomain(svr))
+	detail := model.NewServerDetailFromDomain(svr, a.clock.Now())
+	item, err := a.container.GetProfile.Execute(c, address)
+	switch {
+	case err == nil:
+		profile := model.NewServerProfileFromDomain(item)
+		detail.Profile = &profile
+	case !errors.Is(err, getprofile.ErrProfileNotFound):
+		a.logger.Warn().Err(err).Stringer("addr", address.ToAddr()).Msg("Failed to obtain server profile")
+	}
+
+	c.JSON(http.StatusOK, detail)
 }
 
 func parseViewServerAddress(c *gin.Context) (addr.PublicAddr, error) {
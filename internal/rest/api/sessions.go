@@ -0,0 +1,93 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sergeii/swat4master/internal/core/entities/session"
+	"github.com/sergeii/swat4master/internal/core/usecases/listsessions"
+	"github.com/sergeii/swat4master/internal/rest/model"
+)
+
+type SessionsForm struct {
+	Limit int `binding:"omitempty,gte=1,lte=100" form:"limit"`
+}
+
+const defaultSessionsLimit = 20
+
+// ListServerSessions godoc
+// @Summary      List server sessions
+// @Description  List the latest player sessions on a server, the latest session first
+// @Tags         sessions
+// @Produce      json
+// @Param        limit  query  int  false  "Number of sessions to list (1-100, 20 by default)"
+// @Success      200 {array} model.Session
+// @Router       /servers/:address/sessions [get]
+func (a *API) ListServerSessions(c *gin.Context) {
+	address, parseErr := parseViewServerAddress(c)
+	if parseErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid server address"})
+		return
+	}
+
+	limit, ok := bindSessionsLimit(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := a.container.ListSessions.Execute(c, listsessions.NewServerRequest(address.ToAddr(), limit))
+	if err != nil {
+		a.logger.Err(err).Stringer("addr", address.ToAddr()).Msg("Failed to obtain server sessions")
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, presentSessions(sessions))
+}
+
+// ListPlayerSessions godoc
+// @Summary      List player sessions
+// @Description  List the latest sessions of a player on any server, the latest session first.
+// @Description  The player name is matched case-insensitively.
+// @Tags         sessions
+// @Produce      json
+// @Param        limit  query  int  false  "Number of sessions to list (1-100, 20 by default)"
+// @Success      200 {array} model.Session
+// @Router       /players/:name/sessions [get]
+func (a *API) ListPlayerSessions(c *gin.Context) {
+	limit, ok := bindSessionsLimit(c)
+	if !ok {
+		return
+	}
+
+	name := c.Param("name")
+	sessions, err := a.container.ListSessions.Execute(c, listsessions.NewPlayerRequest(name, limit))
+	if err != nil {
+		a.logger.Err(err).Str("player", name).Msg("Failed to obtain player sessions")
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, presentSessions(sessions))
+}
+
+func bindSessionsLimit(c *gin.Context) (int, bool) {
+	var form SessionsForm
+	if err := c.ShouldBindQuery(&form); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return 0, false
+	}
+	if form.Limit == 0 {
+		return defaultSessionsLimit, true
+	}
+	return form.Limit, true
+}
+
+func presentSessions(sessions []session.Session) []model.Session {
+	result := make([]model.Session, 0, len(sessions))
+	for _, sess := range sessions {
+		result = append(result, model.NewSessionFromDomain(sess))
+	}
+	return result
+}
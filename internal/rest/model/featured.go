@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"github.com/sergeii/swat4master/internal/core/entities/featured"
+)
+
+type FeatureServer struct {
+	Priority int        `json:"priority"`
+	Since    *time.Time `json:"since"`
+	Until    *time.Time `json:"until"`
+}
+
+type FeaturedServer struct {
+	Address  string     `json:"address"`
+	IP       string     `json:"ip"`
+	Port     int        `json:"port"`
+	Priority int        `json:"priority"`
+	Since    *time.Time `json:"since"`
+	Until    *time.Time `json:"until"`
+}
+
+func NewFeaturedServerFromDomain(item featured.Featured) FeaturedServer {
+	return FeaturedServer{
+		Address:  item.Addr.String(),
+		IP:       item.Addr.GetDottedIP(),
+		Port:     item.Addr.Port,
+		Priority: item.Priority,
+		Since:    timeOrNil(item.Since),
+		Until:    timeOrNil(item.Until),
+	}
+}
+
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
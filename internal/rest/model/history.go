@@ -0,0 +1,56 @@
+package model
+
+import (
+	"time"
+
+	"github.com/sergeii/swat4master/internal/core/entities/history"
+)
+
+type HistoryPoint struct {
+	At         time.Time `json:"at"`
+	Uptime     float64   `json:"uptime"` // share of the samples the server was online, 0..1
+	PlayersAvg float64   `json:"players_avg"`
+	PlayersMax int       `json:"players_max"`
+	PlayerMax  int       `json:"player_max"` // the server capacity
+	MapName    string    `json:"mapname"`
+	GameType   string    `json:"gametype"`
+}
+
+func NewHistoryPointFromDomain(pt history.Point) HistoryPoint {
+	return HistoryPoint{
+		At:         pt.At,
+		Uptime:     pt.Uptime(),
+		PlayersAvg: pt.PlayersAvg(),
+		PlayersMax: pt.PlayersMax,
+		PlayerMax:  pt.MaxPlayers,
+		MapName:    pt.MapName,
+		GameType:   pt.GameType,
+	}
+}
+
+type ServerHistory struct {
+	From       time.Time      `json:"from"`
+	To         time.Time      `json:"to"`
+	Resolution string         `json:"resolution"` // raw, 5m or 1h
+	Step       int64          `json:"step"`       // in seconds
+	Points     []HistoryPoint `json:"points"`
+}
+
+func NewServerHistory(
+	from, to time.Time,
+	res history.Resolution,
+	step time.Duration,
+	points []history.Point,
+) ServerHistory {
+	items := make([]HistoryPoint, 0, len(points))
+	for _, pt := range points {
+		items = append(items, NewHistoryPointFromDomain(pt))
+	}
+	return ServerHistory{
+		From:       from,
+		To:         to,
+		Resolution: res.Name,
+		Step:       int64(step.Seconds()),
+		Points:     items,
+	}
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"github.com/sergeii/swat4master/internal/core/entities/jobrun"
+)
+
+type JobRun struct {
+	Job         string    `json:"job"`
+	ScheduledAt time.Time `json:"scheduled_at"`
+	StartedAt   time.Time `json:"started_at"`
+	FinishedAt  time.Time `json:"finished_at"`
+	Duration    int64     `json:"duration"` // in ms
+	Outcome     string    `json:"outcome"`  // success or failure
+	Error       *string   `json:"error"`
+}
+
+func NewJobRunFromDomain(run jobrun.Run) JobRun {
+	var runErr *string
+	if run.Error != "" {
+		runErr = &run.Error
+	}
+	return JobRun{
+		Job:         run.Job,
+		ScheduledAt: run.ScheduledAt,
+		StartedAt:   run.StartedAt,
+		FinishedAt:  run.FinishedAt,
+		Duration:    run.Duration().Milliseconds(),
+		Outcome:     string(run.Outcome),
+		Error:       runErr,
+	}
+}
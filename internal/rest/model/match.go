@@ -0,0 +1,88 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gosimple/slug"
+
+	"github.com/sergeii/swat4master/internal/core/entities/match"
+	"github.com/sergeii/swat4master/pkg/swat/styles"
+)
+
+type Match struct {
+	ID             string    `json:"id"`
+	Address        string    `json:"address"`
+	Hostname       string    `json:"hostname"`
+	HostnamePlain  string    `json:"hostname_plain"`
+	GameType       string    `json:"gametype"`
+	GameTypeSlug   string    `json:"gametype_slug"`
+	MapName        string    `json:"mapname"`
+	MapNameSlug    string    `json:"mapname_slug"`
+	PlayerNum      int       `json:"player_num"`
+	RoundNum       int       `json:"round_num"`
+	RoundMax       int       `json:"round_max"`
+	SwatScore      int       `json:"score_swat"`
+	SuspectsScore  int       `json:"score_sus"`
+	BombsDefused   int       `json:"bombs_defused"`
+	BombsTotal     int       `json:"bombs_total"`
+	TocReports     string    `json:"coop_reports"`
+	WeaponsSecured string    `json:"coop_weapons"`
+	Winner         *string   `json:"winner"` // swat, suspects or tie, unknown for CO-OP
+	EndedAt        time.Time `json:"ended_at"`
+}
+
+func NewMatchFromDomain(m match.Match) Match {
+	var winner *string
+	if m.Winner != match.WinnerUnknown {
+		value := string(m.Winner)
+		winner = &value
+	}
+	return Match{
+		ID:             m.ID,
+		Address:        m.Addr.String(),
+		Hostname:       m.Info.Hostname,
+		HostnamePlain:  styles.Clean(m.Info.Hostname),
+		GameType:       m.Info.GameType,
+		GameTypeSlug:   slug.Make(m.Info.GameType),
+		MapName:        m.Info.MapName,
+		MapNameSlug:    slug.Make(m.Info.MapName),
+		PlayerNum:      len(m.Players),
+		RoundNum:       m.Info.Round,
+		RoundMax:       m.Info.NumRounds,
+		SwatScore:      m.Info.SwatScore,
+		SuspectsScore:  m.Info.SuspectsScore,
+		BombsDefused:   m.Info.BombsDefused,
+		BombsTotal:     m.Info.BombsTotal,
+		TocReports:     m.Info.TocReports,
+		WeaponsSecured: m.Info.WeaponsSecured,
+		Winner:         winner,
+		EndedAt:        m.EndedAt,
+	}
+}
+
+type MatchDetail struct {
+	Info       Match             `json:"info"`
+	Players    []ServerPlayer    `json:"players"`
+	Objectives []ServerObjective `json:"objectives"`
+}
+
+func NewMatchDetailFromDomain(m match.Match) MatchDetail {
+	players := make([]ServerPlayer, 0, len(m.Players))
+	for _, player := range m.Players {
+		players = append(players, NewServerPlayerFromDomain(player))
+	}
+
+	var objectives []ServerObjective
+	if len(m.Objectives) > 0 {
+		objectives = make([]ServerObjective, 0, len(m.Objectives))
+		for _, obj := range m.Objectives {
+			objectives = append(objectives, NewServerObjectiveFromDomain(obj))
+		}
+	}
+
+	return MatchDetail{
+		Info:       NewMatchFromDomain(m),
+		Players:    players,
+		Objectives: objectives,
+	}
+}
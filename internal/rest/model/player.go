@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"github.com/sergeii/swat4master/internal/core/entities/server"
+	"github.com/sergeii/swat4master/internal/core/entities/session"
+)
+
+type OnlinePlayer struct {
+	Name          string    `json:"name"`
+	Team          string    `json:"team"` // swat or suspects
+	Score         int       `json:"score"`
+	Kills         int       `json:"kills"`
+	Arrests       int       `json:"arrests"`
+	StartedAt     time.Time `json:"started_at"`
+	TimeInSession int64     `json:"time_in_session"` // in seconds
+	Match         string    `json:"match"`           // exact, prefix, substring or fuzzy
+	Server        Server    `json:"server"`
+}
+
+func NewOnlinePlayerFromDomain(
+	sess session.Session,
+	svr server.Server,
+	match session.Match,
+	now time.Time,
+) OnlinePlayer {
+	return OnlinePlayer{
+		Name:          sess.Name,
+		Team:          sess.Team.String(),
+		Score:         sess.Score,
+		Kills:         sess.Kills,
+		Arrests:       sess.Arrests,
+		StartedAt:     sess.StartedAt,
+		TimeInSession: int64(sess.Duration(now).Seconds()),
+		Match:         match.String(),
+		Server:        NewServerFromDomain(svr),
+	}
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+
+	"github.com/sergeii/swat4master/internal/core/entities/profile"
+)
+
+type ServerClaim struct {
+	Token     string    `json:"token"` // to be put in the server's hostname or any other query field
+	Key       string    `json:"key"`   // to edit the server's profile once the claim is confirmed
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func NewServerClaim(token, key string, expiresAt time.Time) ServerClaim {
+	return ServerClaim{
+		Token:     token,
+		Key:       key,
+		ExpiresAt: expiresAt,
+	}
+}
+
+type EditServerProfile struct {
+	Description string `binding:"max=500"               json:"description"`
+	Website     string `binding:"omitempty,http_url,max=200" json:"website"`
+	Discord     string `binding:"omitempty,http_url,max=200" json:"discord"`
+	Region      string `binding:"max=32"                json:"region"`
+}
+
+type ServerProfile struct {
+	Description string    `json:"description"`
+	Website     string    `json:"website"`
+	Discord     string    `json:"discord"`
+	Region      string    `json:"region"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func NewServerProfileFromDomain(item profile.Profile) ServerProfile {
+	return ServerProfile{
+		Description: item.Description,
+		Website:     item.Website,
+		Discord:     item.Discord,
+		Region:      item.Region,
+		UpdatedAt:   item.UpdatedAt,
+	}
+}
@@ -1,9 +1,13 @@
 package model
 
 import (
+	"math"
+	"time"
+
 	"github.com/gosimple/slug"
 
 	"github.com/sergeii/swat4master/internal/core/entities/details"
+	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
 	"github.com/sergeii/swat4master/internal/core/entities/server"
 	"github.com/sergeii/swat4master/pkg/swat/styles"
 )
@@ -41,6 +45,14 @@ type Server struct {
 	BombsTotal     int    `json:"bombs_total"`
 	TocReports     string `json:"coop_reports"` // 24/28
 	WeaponsSecured string `json:"coop_weapons"` // 17/19
+	Featured       bool   `json:"featured"`
+	Latency        *int64 `json:"latency"`    // average query rtt in ms, unknown for the servers never queried
+	Country        string `json:"country"`    // ISO country code, empty if unknown
+	Continent      string `json:"continent"`  // continent code, empty if unknown
+	ASN            int    `json:"asn"`        // autonomous system number, zero if unknown
+	Unverified     bool   `json:"unverified"` // never reached by a probe, only known from heartbeats
+	Mismatched     bool   `json:"mismatched"` // heartbeats consistently disagree with query responses
+	Federated      bool   `json:"federated"`  // imported from an upstream master server
 }
 
 func NewServerFromDomain(s server.Server) Server {
@@ -73,11 +85,20 @@ func NewServerFromDomain(s server.Server) Server {
 		BombsTotal:     s.Info.BombsTotal,
 		TocReports:     s.Info.TocReports,
 		WeaponsSecured: s.Info.WeaponsSecured,
+		Latency:        latencyMillis(s.Latency),
+		Country:        s.Location.Country,
+		Continent:      s.Location.Continent,
+		ASN:            s.Location.ASN,
+		Unverified:     !s.Listing.IsVerified(),
+		Mismatched:     s.Listing.IsMismatched(),
+		Federated:      s.HasDiscoveryStatus(ds.Federated),
 	}
 }
 
 type ServerPlayer struct {
 	Name            string `json:"name"`
+	NamePlain       string `json:"name_plain"`
+	NameHTML        string `json:"name_html"`
 	Ping            int    `json:"ping"`
 	Score           int    `json:"score"`
 	Team            string `json:"team"` // swat or suspects
@@ -105,6 +126,8 @@ func NewServerPlayerFromDomain(player details.Player) ServerPlayer {
 	coopStatus := player.CoopStatus.String()
 	return ServerPlayer{
 		Name:            player.Name,
+		NamePlain:       styles.Clean(player.Name),
+		NameHTML:        styles.ToHTML(player.Name),
 		Ping:            player.Ping,
 		Team:            player.Team.String(),
 		Score:           player.Score,
@@ -144,13 +167,99 @@ func NewServerObjectiveFromDomain(obj details.Objective) ServerObjective {
 	}
 }
 
+type ServerQuery struct {
+	Protocol      string    `json:"protocol"`       // gs1/vanilla, gs1/am, gs1/gs1, qr2
+	LatencyLast   int64     `json:"latency_last"`   // ms
+	LatencyAvg    int64     `json:"latency_avg"`    // ms
+	LatencyJitter int64     `json:"latency_jitter"` // ms
+	QueriedAt     time.Time `json:"queried_at"`
+}
+
+func NewServerQueryFromDomain(svr server.Server) *ServerQuery {
+	// the servers only reporting to the master are never queried
+	if !svr.Latency.IsKnown() {
+		return nil
+	}
+	return &ServerQuery{
+		Protocol:      svr.QueryProtocol,
+		LatencyLast:   svr.Latency.Last.Milliseconds(),
+		LatencyAvg:    svr.Latency.Average.Milliseconds(),
+		LatencyJitter: svr.Latency.Jitter.Milliseconds(),
+		QueriedAt:     svr.QueriedAt,
+	}
+}
+
+type ServerOutage struct {
+	Start    time.Time  `json:"start"`
+	End      *time.Time `json:"end"`      // unknown for the ongoing outage
+	Duration int64      `json:"duration"` // seconds
+}
+
+type ServerAvailability struct {
+	Since     time.Time      `json:"since"`
+	Online    bool           `json:"online"`
+	Uptime24h float64        `json:"uptime_24h"` // percent
+	Uptime7d  float64        `json:"uptime_7d"`  // percent
+	Uptime30d float64        `json:"uptime_30d"` // percent
+	MTBO      *int64         `json:"mtbo"`       // mean time between outages in seconds, unknown with no outages
+	Outages   []ServerOutage `json:"outages"`    // the latest go first
+}
+
+func NewServerAvailabilityFromDomain(svr server.Server, now time.Time) *ServerAvailability {
+	avail := svr.Availability
+	// the availability of a server is unknown until it's been tracked for the first time
+	if !avail.IsKnown() {
+		return nil
+	}
+
+	recent := avail.RecentOutages(server.AvailabilityRetention, now)
+	outages := make([]ServerOutage, 0, len(recent))
+	for i := len(recent) - 1; i >= 0; i-- {
+		outages = append(outages, newServerOutage(recent[i], now))
+	}
+
+	var mtbo *int64
+	if between := avail.MeanTimeBetweenOutages(now); between > 0 {
+		seconds := int64(between.Seconds())
+		mtbo = &seconds
+	}
+
+	return &ServerAvailability{
+		Since:     avail.Since,
+		Online:    !avail.IsDown(),
+		Uptime24h: uptimePercent(avail, time.Hour*24, now),
+		Uptime7d:  uptimePercent(avail, time.Hour*24*7, now),
+		Uptime30d: uptimePercent(avail, server.AvailabilityRetention, now),
+		MTBO:      mtbo,
+		Outages:   outages,
+	}
+}
+
+func newServerOutage(o server.Outage, now time.Time) ServerOutage {
+	outage := ServerOutage{
+		Start:    o.Start,
+		Duration: int64(o.Duration(now).Seconds()),
+	}
+	if !o.IsOngoing() {
+		end := o.End
+		outage.End = &end
+	}
+	return outage
+}
+
 type ServerDetail struct {
-	Info       Server            `json:"info"`
-	Players    []ServerPlayer    `json:"players"`
-	Objectives []ServerObjective `json:"objectives"`
+	Info         Server              `json:"info"`
+	Players      []ServerPlayer      `json:"players"`
+	Objectives   []ServerObjective   `json:"objectives"`
+	Query        *ServerQuery        `json:"query"`
+	Availability *ServerAvailability `json:"availability"`
+	Profile      *ServerProfile      `json:"profile"`      // provided by the verified owner
+	Verified     bool                `json:"verified"`     // whether the server has a verified owner
+	DuplicateOf  *string             `json:"duplicate_of"` // address of the primary server, if the server duplicates it
+	Duplicates   []string            `json:"duplicates"`   // addresses of the servers duplicating this one
 }
 
-func NewServerDetailFromDomain(svr server.Server) ServerDetail {
+func NewServerDetailFromDomain(svr server.Server, now time.Time) ServerDetail {
 	var objectives []ServerObjective
 	var players []ServerPlayer
 
@@ -169,10 +278,43 @@ func NewServerDetailFromDomain(svr server.Server) ServerDetail {
 	}
 
 	return ServerDetail{
-		Info:       NewServerFromDomain(svr),
-		Players:    players,
-		Objectives: objectives,
+		Info:         NewServerFromDomain(svr),
+		Players:      players,
+		Objectives:   objectives,
+		Query:        NewServerQueryFromDomain(svr),
+		Availability: NewServerAvailabilityFromDomain(svr, now),
+		Verified:     svr.Ownership.IsVerified(),
+		DuplicateOf:  duplicateOf(svr),
+		Duplicates:   duplicates(svr),
+	}
+}
+
+func duplicateOf(svr server.Server) *string {
+	if !svr.IsDuplicate() {
+		return nil
 	}
+	primary := svr.DuplicateOf.String()
+	return &primary
+}
+
+func duplicates(svr server.Server) []string {
+	dups := make([]string, 0, len(svr.Duplicates))
+	for _, dup := range svr.Duplicates {
+		dups = append(dups, dup.String())
+	}
+	return dups
+}
+
+func latencyMillis(latency server.Latency) *int64 {
+	if !latency.IsKnown() {
+		return nil
+	}
+	millis := latency.Average.Milliseconds()
+	return &millis
+}
+
+func uptimePercent(avail server.Availability, window time.Duration, now time.Time) float64 {
+	return math.Round(avail.Uptime(window, now)*10000) / 100
 }
 
 func boolToInt(v bool) uint8 {
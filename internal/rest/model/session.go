@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+
+	"github.com/sergeii/swat4master/internal/core/entities/session"
+)
+
+type Session struct {
+	Address   string     `json:"address"`
+	Name      string     `json:"name"`
+	Team      string     `json:"team"` // swat or suspects
+	Score     int        `json:"score"`
+	Kills     int        `json:"kills"`
+	Arrests   int        `json:"arrests"`
+	Active    bool       `json:"active"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at"`
+	Duration  *int64     `json:"duration"` // in seconds, only for ended sessions
+}
+
+func NewSessionFromDomain(sess session.Session) Session {
+	var endedAt *time.Time
+	var duration *int64
+	if !sess.IsActive() {
+		endedAt = &sess.EndedAt
+		seconds := int64(sess.Duration(sess.EndedAt).Seconds())
+		duration = &seconds
+	}
+	return Session{
+		Address:   sess.Addr.String(),
+		Name:      sess.Name,
+		Team:      sess.Team.String(),
+		Score:     sess.Score,
+		Kills:     sess.Kills,
+		Arrests:   sess.Arrests,
+		Active:    sess.IsActive(),
+		StartedAt: sess.StartedAt,
+		EndedAt:   endedAt,
+		Duration:  duration,
+	}
+}
@@ -14,7 +14,21 @@ func NewRouter(a *api.API) *gin.Engine {
 	router.GET("/status", a.Status)
 	router.GET("/api/servers", a.ListServers)
 	router.GET("/api/servers/:address", a.ViewServer)
+	router.GET("/api/servers/:address/sessions", a.ListServerSessions)
+	router.GET("/api/servers/:address/matches", a.ListServerMatches)
+	router.GET("/api/servers/:address/history", a.ViewServerHistory)
+	router.GET("/api/matches/:id", a.ViewMatch)
+	router.GET("/api/players", a.SearchPlayers)
+	router.GET("/api/players/:name/sessions", a.ListPlayerSessions)
 	router.POST("/api/servers", a.AddServer)
+	router.POST("/api/servers/:address/claim", a.ClaimServer)
+	router.PUT("/api/servers/:address/profile", a.UpdateServerProfile)
+	admin := router.Group("/api/admin", a.RequireAdmin)
+	admin.GET("/featured", a.ListFeatured)
+	admin.PUT("/featured/:address", a.FeatureServer)
+	admin.DELETE("/featured/:address", a.UnfeatureServer)
+	admin.GET("/jobs", a.ListJobs)
+	admin.GET("/jobs/:name", a.ListJobRuns)
 	router.GET("/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	return router
 }
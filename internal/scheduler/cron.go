@@ -0,0 +1,153 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron is the schedule of a job that runs at the times matching a cron expression.
+// The expression is evaluated in UTC, so that every replica agrees on the times.
+type Cron struct {
+	minutes  []bool
+	hours    []bool
+	days     []bool
+	months   []bool
+	weekdays []bool
+	// anyDay and anyWeekday tell whether the day fields are unrestricted.
+	// In case both are restricted, a day matching either of them is due, as in the standard cron.
+	anyDay     bool
+	anyWeekday bool
+}
+
+var shorthands = map[string]string{ //nolint: gochecknoglobals
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+// cronHorizon limits how far in the future a cron expression is looked up,
+// so that the expressions that never match (e.g., "0 0 30 2 *") don't loop forever
+const cronHorizon = 5 * 366 * 24 * time.Hour
+
+// ParseCron parses a standard 5-field cron expression:
+// minute, hour, day of month, month and day of week (0-7, both 0 and 7 are Sunday).
+// Every field is either *, a number, a range (1-5) or a list of those (1,3,5),
+// and any of them may be followed by a step (*/15, 0-30/10).
+func ParseCron(spec string) (Cron, error) {
+	if expanded, ok := shorthands[spec]; ok {
+		spec = expanded
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return Cron{}, fmt.Errorf("%w: expected 5 fields, got %d", ErrInvalidSchedule, len(fields))
+	}
+
+	var c Cron
+	var err error
+	if c.minutes, err = parseCronField(fields[0], 0, 59); err != nil {
+		return Cron{}, err
+	}
+	if c.hours, err = parseCronField(fields[1], 0, 23); err != nil {
+		return Cron{}, err
+	}
+	if c.days, err = parseCronField(fields[2], 1, 31); err != nil {
+		return Cron{}, err
+	}
+	if c.months, err = parseCronField(fields[3], 1, 12); err != nil {
+		return Cron{}, err
+	}
+	if c.weekdays, err = parseCronField(fields[4], 0, 7); err != nil {
+		return Cron{}, err
+	}
+	// Sunday is both 0 and 7
+	if c.weekdays[7] {
+		c.weekdays[0] = true
+	}
+	c.anyDay = fields[2] == "*"
+	c.anyWeekday = fields[4] == "*"
+
+	return c, nil
+}
+
+func (c Cron) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	horizon := t.Add(cronHorizon)
+	for t.Before(horizon) {
+		switch {
+		case !c.months[t.Month()]:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !c.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case !c.hours[t.Hour()]:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case !c.minutes[t.Minute()]:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (c Cron) matchesDay(t time.Time) bool {
+	day := c.days[t.Day()]
+	weekday := c.weekdays[t.Weekday()]
+	switch {
+	case c.anyDay && c.anyWeekday:
+		return true
+	case c.anyDay:
+		return weekday
+	case c.anyWeekday:
+		return day
+	default:
+		return day || weekday
+	}
+}
+
+func parseCronField(field string, lo, hi int) ([]bool, error) {
+	matches := make([]bool, hi+1)
+	for part := range strings.SplitSeq(field, ",") {
+		span, stepSpec, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepSpec); err != nil || step <= 0 {
+				return nil, fmt.Errorf("%w: invalid step in %q", ErrInvalidSchedule, part)
+			}
+		}
+
+		from, to := lo, hi
+		switch {
+		case span == "*":
+		case strings.Contains(span, "-"):
+			fromSpec, toSpec, _ := strings.Cut(span, "-")
+			var fromErr, toErr error
+			from, fromErr = strconv.Atoi(fromSpec)
+			to, toErr = strconv.Atoi(toSpec)
+			if fromErr != nil || toErr != nil {
+				return nil, fmt.Errorf("%w: invalid range in %q", ErrInvalidSchedule, part)
+			}
+		default:
+			value, err := strconv.Atoi(span)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid value in %q", ErrInvalidSchedule, part)
+			}
+			from = value
+			// a single value with a step (5/15) runs from the value up to the field's maximum
+			if !hasStep {
+				to = value
+			}
+		}
+		if from < lo || to > hi || from > to {
+			return nil, fmt.Errorf("%w: %q is out of range %d-%d", ErrInvalidSchedule, part, lo, hi)
+		}
+
+		for i := from; i <= to; i += step {
+			matches[i] = true
+		}
+	}
+	return matches, nil
+}
@@ -0,0 +1,94 @@
+package scheduler_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/scheduler"
+)
+
+func TestCron_Next(t *testing.T) {
+	// Wednesday
+	now := time.Date(2025, 1, 15, 12, 34, 56, 0, time.UTC)
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2025, 1, 15, 12, 35, 0, 0, time.UTC)},
+		{"*/10 * * * *", time.Date(2025, 1, 15, 12, 40, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2025, 1, 15, 13, 0, 0, 0, time.UTC)},
+		{"30 4 * * *", time.Date(2025, 1, 16, 4, 30, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 0", time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 9-17/4 * * 1-5", time.Date(2025, 1, 15, 13, 0, 0, 0, time.UTC)},
+		{"15,45 * * * *", time.Date(2025, 1, 15, 12, 45, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// either the day of month or the weekday
+		{"0 0 20 * 5", time.Date(2025, 1, 17, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2025, 1, 15, 13, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			cron, err := scheduler.ParseCron(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cron.Next(now))
+		})
+	}
+}
+
+func TestCron_NextIsUTC(t *testing.T) {
+	cron, err := scheduler.ParseCron("0 12 * * *")
+	require.NoError(t, err)
+
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	now := time.Date(2025, 1, 15, 14, 0, 0, 0, loc)
+	assert.Equal(t, time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC), cron.Next(now))
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1-b * * * *",
+		"@yearly",
+	} {
+		t.Run(spec, func(t *testing.T) {
+			_, err := scheduler.ParseCron(spec)
+			assert.ErrorIs(t, err, scheduler.ErrInvalidSchedule)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	now := time.Date(2025, 1, 15, 12, 34, 56, 0, time.UTC)
+
+	schedule, err := scheduler.Parse("@every 90s")
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Second*90), schedule.Next(now))
+
+	schedule, err = scheduler.Parse(" */5 * * * * ")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 1, 15, 12, 35, 0, 0, time.UTC), schedule.Next(now))
+
+	for _, spec := range []string{"@every", "@every foo", "@every -1s", "@every 0s", "foo"} {
+		_, err = scheduler.Parse(spec)
+		assert.ErrorIs(t, err, scheduler.ErrInvalidSchedule, spec)
+	}
+}
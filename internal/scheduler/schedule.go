@@ -0,0 +1,45 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// Schedule tells when a job is due next
+type Schedule interface {
+	// Next returns the earliest time the job is due after the given time
+	Next(time.Time) time.Time
+}
+
+// Interval is the schedule of a job that runs every so often
+type Interval time.Duration
+
+func Every(d time.Duration) Interval {
+	return Interval(d)
+}
+
+func (i Interval) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(i))
+}
+
+// Parse parses the schedule specification.
+// The specification is either a standard 5-field cron expression (e.g., "*/10 * * * *"),
+// one of the @hourly, @daily, @weekly or @monthly shorthands, or "@every <duration>" (e.g., "@every 5m").
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if every, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(every))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidSchedule, err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("%w: interval must be positive", ErrInvalidSchedule)
+		}
+		return Every(d), nil
+	}
+	return ParseCron(spec)
+}
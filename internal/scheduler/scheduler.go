@@ -0,0 +1,234 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/rs/zerolog"
+
+	"github.com/sergeii/swat4master/internal/core/entities/jobrun"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+	"github.com/sergeii/swat4master/internal/metrics"
+	"github.com/sergeii/swat4master/internal/persistence/redis/redislock"
+)
+
+var ErrDuplicateJob = errors.New("job with the same name is already scheduled")
+
+// defaultTimeout limits the runs of the jobs that would never run again
+const defaultTimeout = time.Minute
+
+type Job struct {
+	Name     string
+	Schedule Schedule
+	// Timeout limits how long a run may take, the job's lock is held for as long.
+	// Unless set, a run may take until the job is due again.
+	Timeout time.Duration
+	Run     func(context.Context) error
+}
+
+// Scheduler runs the periodic jobs.
+// Any number of replicas may schedule the same job, only one of them would run the job when it's due.
+// The replica that has taken the job's lock is the job's leader for the run.
+// Because the runs are recorded in the shared repository,
+// the replicas align their schedules with the runs of each other.
+type Scheduler struct {
+	mutex   sync.Mutex
+	jobs    []Job
+	cancel  context.CancelFunc
+	ctx     context.Context //nolint: containedctx
+	wg      sync.WaitGroup
+	runRepo repositories.JobRunRepository
+	locker  *redislock.Manager
+	metrics *metrics.Collector
+	clock   clockwork.Clock
+	logger  *zerolog.Logger
+}
+
+func New(
+	runRepo repositories.JobRunRepository,
+	locker *redislock.Manager,
+	metrics *metrics.Collector,
+	clock clockwork.Clock,
+	logger *zerolog.Logger,
+) *Scheduler {
+	return &Scheduler{
+		runRepo: runRepo,
+		locker:  locker,
+		metrics: metrics,
+		clock:   clock,
+		logger:  logger,
+	}
+}
+
+// Add schedules the job. The job is started right away in case the scheduler is already running.
+func (s *Scheduler) Add(job Job) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, other := range s.jobs {
+		if other.Name == job.Name {
+			return fmt.Errorf("%w: %s", ErrDuplicateJob, job.Name)
+		}
+	}
+	s.jobs = append(s.jobs, job)
+	if s.ctx != nil {
+		s.spawn(job)
+	}
+	return nil
+}
+
+func (s *Scheduler) Start() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.ctx != nil {
+		return
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	for _, job := range s.jobs {
+		s.spawn(job)
+	}
+}
+
+// Stop stops the scheduler and waits for the running jobs to complete
+func (s *Scheduler) Stop() {
+	s.mutex.Lock()
+	if s.ctx == nil {
+		s.mutex.Unlock()
+		return
+	}
+	s.cancel()
+	s.ctx, s.cancel = nil, nil
+	s.mutex.Unlock()
+	s.wg.Wait()
+}
+
+func (s *Scheduler) spawn(job Job) {
+	ctx := s.ctx
+	s.wg.Go(func() {
+		s.loop(ctx, job)
+	})
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	due := s.upcoming(ctx, job, s.clock.Now())
+	s.logger.Info().Str("job", job.Name).Time("due", due).Msg("Scheduled job")
+	for {
+		if due.IsZero() {
+			s.logger.Warn().Str("job", job.Name).Msg("Job is never due")
+			return
+		}
+		timer := s.clock.NewTimer(due.Sub(s.clock.Now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.Chan():
+		}
+		s.attempt(ctx, job, due)
+		due = s.upcoming(ctx, job, due)
+	}
+}
+
+// upcoming returns the time the job is due next after the given time.
+// In case another replica has run the job since, the job is due after that run instead.
+// A job that is already overdue is due immediately.
+func (s *Scheduler) upcoming(ctx context.Context, job Job, after time.Time) time.Time {
+	now := s.clock.Now()
+	next := job.Schedule.Next(after)
+
+	last, err := s.runRepo.Last(ctx, job.Name)
+	switch {
+	case err == nil:
+		if shared := job.Schedule.Next(last.ScheduledAt); shared.After(now) {
+			next = shared
+		}
+	case !errors.Is(err, repositories.ErrJobRunNotFound) && ctx.Err() == nil:
+		s.logger.Warn().Err(err).Str("job", job.Name).Msg("Unable to obtain last job run")
+	}
+
+	if !next.IsZero() && next.Before(now) {
+		return now
+	}
+	return next
+}
+
+// attempt runs the job, unless another replica has taken the job's lock
+// or has already run the job by the time the lock was taken.
+// The lock is leased for as long as the run may take, so it expires even if the replica fails to release it.
+func (s *Scheduler) attempt(ctx context.Context, job Job, due time.Time) {
+	timeout := job.Timeout
+	if timeout <= 0 {
+		timeout = job.Schedule.Next(due).Sub(due)
+	}
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	lease, err := s.locker.Acquire(ctx, lockKey(job.Name), timeout)
+	switch {
+	case err == nil:
+	case errors.Is(err, redislock.ErrNotAcquired):
+		s.skip(job, due)
+		return
+	case ctx.Err() != nil:
+		return
+	default:
+		s.logger.Warn().Err(err).Str("job", job.Name).Msg("Unable to run job")
+		return
+	}
+	// release the lock even if the scheduler is stopping, so that another replica can take over right away
+	defer lease.Release(context.WithoutCancel(ctx)) //nolint: errcheck
+
+	if s.hasRun(ctx, job) {
+		s.skip(job, due)
+		return
+	}
+	s.run(ctx, job, due, timeout)
+}
+
+func (s *Scheduler) skip(job Job, due time.Time) {
+	s.metrics.SchedulerSkipped.WithLabelValues(job.Name).Inc()
+	s.logger.Debug().Str("job", job.Name).Time("due", due).Msg("Job is run by another replica")
+}
+
+// hasRun tells whether the job has been run by another replica since it was due last
+func (s *Scheduler) hasRun(ctx context.Context, job Job) bool {
+	last, err := s.runRepo.Last(ctx, job.Name)
+	if err != nil {
+		if !errors.Is(err, repositories.ErrJobRunNotFound) {
+			s.logger.Warn().Err(err).Str("job", job.Name).Msg("Unable to obtain last job run")
+		}
+		return false
+	}
+	return job.Schedule.Next(last.ScheduledAt).After(s.clock.Now())
+}
+
+func (s *Scheduler) run(ctx context.Context, job Job, due time.Time, timeout time.Duration) {
+	startedAt := s.clock.Now()
+
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	err := job.Run(runCtx)
+	cancel()
+
+	run := jobrun.New(job.Name, due, startedAt, s.clock.Now(), err)
+
+	s.metrics.SchedulerRuns.WithLabelValues(job.Name, string(run.Outcome)).Inc()
+	s.metrics.SchedulerDurations.WithLabelValues(job.Name).Observe(run.Duration().Seconds())
+
+	if err != nil {
+		s.logger.Warn().Err(err).Str("job", job.Name).Dur("duration", run.Duration()).Msg("Job failed")
+	} else {
+		s.logger.Debug().Str("job", job.Name).Dur("duration", run.Duration()).Msg("Job completed")
+	}
+
+	if err := s.runRepo.Record(ctx, run); err != nil {
+		s.logger.Warn().Err(err).Str("job", job.Name).Msg("Unable to record job run")
+	}
+}
+
+func lockKey(job string) string {
+	return fmt.Sprintf("scheduler:jobs:%s:lock", job)
+}
@@ -0,0 +1,234 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/jobrun"
+	"github.com/sergeii/swat4master/internal/metrics"
+	"github.com/sergeii/swat4master/internal/persistence/redis/redislock"
+	"github.com/sergeii/swat4master/internal/persistence/redis/repositories/jobruns"
+	"github.com/sergeii/swat4master/internal/scheduler"
+	tu "github.com/sergeii/swat4master/internal/testutils"
+	"github.com/sergeii/swat4master/internal/testutils/testredis"
+)
+
+func makeScheduler(rdb *redis.Client, clock clockwork.Clock) (*scheduler.Scheduler, *metrics.Collector) {
+	logger := zerolog.Nop()
+	collector := metrics.New()
+	s := scheduler.New(jobruns.New(rdb), redislock.NewManager(rdb, &logger), collector, clock, &logger)
+	return s, collector
+}
+
+// advance moves the clock once all of the schedulers' jobs are waiting for their time,
+// and waits for the jobs to be done with the current run
+func advance(t *testing.T, clock *clockwork.FakeClock, waiters int, d time.Duration) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+	require.NoError(t, clock.BlockUntilContext(ctx, waiters))
+	clock.Advance(d)
+	require.NoError(t, clock.BlockUntilContext(ctx, waiters))
+}
+
+func TestScheduler_RunsJobOnSchedule(t *testing.T) {
+	ctx := context.TODO()
+	rdb := testredis.MakeClient(t)
+	clock := clockwork.NewFakeClock()
+	started := clock.Now()
+
+	s, collector := makeScheduler(rdb, clock)
+
+	runs := atomic.Int32{}
+	tu.MustNoErr(s.Add(scheduler.Job{
+		Name:     "refresher",
+		Schedule: scheduler.Every(time.Second * 5),
+		Run: func(context.Context) error {
+			if runs.Add(1) == 2 {
+				return errors.New("boom")
+			}
+			return nil
+		},
+	}))
+	s.Start()
+	defer s.Stop()
+
+	// not due yet
+	advance(t, clock, 1, time.Second*4)
+	assert.Equal(t, int32(0), runs.Load())
+
+	advance(t, clock, 1, time.Second)
+	assert.Equal(t, int32(1), runs.Load())
+
+	advance(t, clock, 1, time.Second*5)
+	assert.Equal(t, int32(2), runs.Load())
+
+	history, err := jobruns.New(rdb).List(ctx, "refresher", 10)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+
+	assert.Equal(t, jobrun.OutcomeFailure, history[0].Outcome)
+	assert.Equal(t, "boom", history[0].Error)
+	assert.True(t, started.Add(time.Second*10).Equal(history[0].ScheduledAt))
+
+	assert.Equal(t, jobrun.OutcomeSuccess, history[1].Outcome)
+	assert.True(t, started.Add(time.Second*5).Equal(history[1].ScheduledAt))
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(collector.SchedulerRuns.WithLabelValues("refresher", "success")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(collector.SchedulerRuns.WithLabelValues("refresher", "failure")))
+}
+
+func TestScheduler_OnlyOneReplicaRunsJob(t *testing.T) {
+	ctx := context.TODO()
+	rdb := testredis.MakeClient(t)
+	clock := clockwork.NewFakeClock()
+
+	runs := atomic.Int32{}
+	job := scheduler.Job{
+		Name:     "reviver",
+		Schedule: scheduler.Every(time.Minute),
+		Run: func(context.Context) error {
+			runs.Add(1)
+			return nil
+		},
+	}
+
+	replicas := make([]*scheduler.Scheduler, 0, 3)
+	collectors := make([]*metrics.Collector, 0, 3)
+	for range 3 {
+		s, collector := makeScheduler(rdb, clock)
+		tu.MustNoErr(s.Add(job))
+		s.Start()
+		defer s.Stop()
+		replicas = append(replicas, s)
+		collectors = append(collectors, collector)
+	}
+
+	for i := range 5 {
+		advance(t, clock, len(replicas), time.Minute)
+		assert.Equal(t, int32(i+1), runs.Load())
+	}
+
+	history, err := jobruns.New(rdb).List(ctx, "reviver", 10)
+	require.NoError(t, err)
+	assert.Len(t, history, 5)
+
+	skipped := 0.0
+	for _, collector := range collectors {
+		skipped += testutil.ToFloat64(collector.SchedulerSkipped.WithLabelValues("reviver"))
+	}
+	assert.Equal(t, 10.0, skipped)
+}
+
+func TestScheduler_FollowsOtherReplicas(t *testing.T) {
+	ctx := context.TODO()
+	rdb := testredis.MakeClient(t)
+	clock := clockwork.NewFakeClock()
+	now := clock.Now()
+	repo := jobruns.New(rdb)
+
+	// another replica has run the job 40 seconds ago
+	ranAt := now.Add(-time.Second * 40)
+	tu.MustNoErr(repo.Record(ctx, jobrun.New("cleaner", ranAt, ranAt, ranAt, nil)))
+
+	s, _ := makeScheduler(rdb, clock)
+	runs := atomic.Int32{}
+	tu.MustNoErr(s.Add(scheduler.Job{
+		Name:     "cleaner",
+		Schedule: scheduler.Every(time.Minute),
+		Run: func(context.Context) error {
+			runs.Add(1)
+			return nil
+		},
+	}))
+	s.Start()
+	defer s.Stop()
+
+	// the job is due a minute after the other replica's run rather than a minute after the start
+	advance(t, clock, 1, time.Second*20)
+	assert.Equal(t, int32(1), runs.Load())
+
+	last, err := repo.Last(ctx, "cleaner")
+	require.NoError(t, err)
+	assert.True(t, now.Add(time.Second*20).Equal(last.ScheduledAt))
+}
+
+func TestScheduler_JobTimeout(t *testing.T) {
+	ctx := context.TODO()
+	rdb := testredis.MakeClient(t)
+	clock := clockwork.NewFakeClock()
+
+	s, _ := makeScheduler(rdb, clock)
+	tu.MustNoErr(s.Add(scheduler.Job{
+		Name:     "observer",
+		Schedule: scheduler.Every(time.Second),
+		Timeout:  time.Millisecond * 10,
+		Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}))
+	s.Start()
+	defer s.Stop()
+
+	advance(t, clock, 1, time.Second)
+
+	last, err := jobruns.New(rdb).Last(ctx, "observer")
+	require.NoError(t, err)
+	assert.Equal(t, jobrun.OutcomeFailure, last.Outcome)
+	assert.Equal(t, context.DeadlineExceeded.Error(), last.Error)
+}
+
+func TestScheduler_LeasesLockForRun(t *testing.T) {
+	ctx := context.TODO()
+	rdb := testredis.MakeClient(t)
+	clock := clockwork.NewFakeClock()
+
+	s, _ := makeScheduler(rdb, clock)
+	lockTTL := atomic.Int64{}
+	tu.MustNoErr(s.Add(scheduler.Job{
+		Name:     "sampler",
+		Schedule: scheduler.Every(time.Second),
+		Timeout:  time.Millisecond * 500,
+		Run: func(ctx context.Context) error {
+			// the lock is leased for as long as the run may take
+			lockTTL.Store(int64(rdb.PTTL(ctx, "scheduler:jobs:sampler:lock").Val()))
+			return nil
+		},
+	}))
+	s.Start()
+	defer s.Stop()
+
+	advance(t, clock, 1, time.Second)
+
+	assert.Equal(t, time.Millisecond*500, time.Duration(lockTTL.Load()))
+	// the lock is released once the run is over
+	assert.Equal(t, int64(0), rdb.Exists(ctx, "scheduler:jobs:sampler:lock").Val())
+
+	last, err := jobruns.New(rdb).Last(ctx, "sampler")
+	require.NoError(t, err)
+	assert.Equal(t, jobrun.OutcomeSuccess, last.Outcome)
+}
+
+func TestScheduler_AddDuplicate(t *testing.T) {
+	rdb := testredis.MakeClient(t)
+	s, _ := makeScheduler(rdb, clockwork.NewFakeClock())
+
+	job := scheduler.Job{
+		Name:     "observer",
+		Schedule: scheduler.Every(time.Second),
+		Run:      func(context.Context) error { return nil },
+	}
+	require.NoError(t, s.Add(job))
+	assert.ErrorIs(t, s.Add(job), scheduler.ErrDuplicateJob)
+}
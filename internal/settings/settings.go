@@ -6,7 +6,11 @@ import (
 
 type Settings struct {
 	ServerLiveness time.Duration
+	HideUnverified bool
 
 	DiscoveryRevivalRetries int
 	DiscoveryRefreshRetries int
+
+	GeoIPCountryDB string
+	GeoIPASNDB     string
 }
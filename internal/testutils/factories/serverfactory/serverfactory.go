@@ -7,6 +7,7 @@ import (
 
 	"github.com/sergeii/swat4master/internal/core/entities/details"
 	"github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/internal/core/entities/location"
 	"github.com/sergeii/swat4master/internal/core/entities/server"
 	"github.com/sergeii/swat4master/internal/core/repositories"
 	"github.com/sergeii/swat4master/internal/testutils"
@@ -22,6 +23,15 @@ type BuildParams struct {
 	Players         []map[string]string
 	Objectives      []map[string]string
 	RefreshedAt     time.Time
+	QueryProtocol   string
+	QueryRTTs       []time.Duration
+	QueriedAt       time.Time
+	Schedule        server.Schedule
+	Availability    server.Availability
+	Listing         server.Listing
+	Country         string
+	Continent       string
+	ASN             int
 }
 
 type BuildOption func(*BuildParams)
@@ -85,6 +95,42 @@ func WithRefreshedAt(refreshedAt time.Time) BuildOption {
 	}
 }
 
+// WithQuery records the successful queries of the server with the given round trip times
+func WithQuery(protocol string, queriedAt time.Time, rtts ...time.Duration) BuildOption {
+	return func(p *BuildParams) {
+		p.QueryProtocol = protocol
+		p.QueriedAt = queriedAt
+		p.QueryRTTs = rtts
+	}
+}
+
+func WithSchedule(sched server.Schedule) BuildOption {
+	return func(p *BuildParams) {
+		p.Schedule = sched
+	}
+}
+
+func WithAvailability(avail server.Availability) BuildOption {
+	return func(p *BuildParams) {
+		p.Availability = avail
+	}
+}
+
+func WithListing(listing server.Listing) BuildOption {
+	return func(p *BuildParams) {
+		p.Listing = listing
+	}
+}
+
+// WithLocation sets the location as if it was resolved for the server's address
+func WithLocation(country, continent string, asn int) BuildOption {
+	return func(p *BuildParams) {
+		p.Country = country
+		p.Continent = continent
+		p.ASN = asn
+	}
+}
+
 func Build(opts ...BuildOption) server.Server {
 	params := BuildParams{
 		IP:              "1.1.1.1",
@@ -118,6 +164,15 @@ func Build(opts ...BuildOption) server.Server {
 	)
 	svr.UpdateDiscoveryStatus(params.DiscoveryStatus)
 	svr.Refresh(params.RefreshedAt)
+	for _, rtt := range params.QueryRTTs {
+		svr.RecordQuery(params.QueryProtocol, rtt, params.QueriedAt)
+	}
+	svr.Schedule = params.Schedule
+	svr.Availability = params.Availability
+	svr.Listing = params.Listing
+	if params.Country != "" || params.Continent != "" || params.ASN != 0 {
+		svr.UpdateLocation(location.New(svr.Addr.GetIP(), params.Country, params.Continent, params.ASN))
+	}
 
 	return svr
 }
@@ -42,6 +42,14 @@ func MustHaveNoBody() TestRequestOpt {
 	}
 }
 
+func WithBearerToken(token string) TestRequestOpt {
+	return func(req *http.Request, _ *http.Response) {
+		if req != nil {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+}
+
 func DoTestRequest(
 	ts *httptest.Server, method, path string, body io.Reader, opts ...TestRequestOpt,
 ) Response {
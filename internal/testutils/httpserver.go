@@ -14,10 +14,18 @@ import (
 	"github.com/sergeii/swat4master/tests/testapp"
 )
 
+const AdminToken = "admin-token"
+
 type TestServerRepositories struct {
 	Servers   repositories.ServerRepository
 	Instances repositories.InstanceRepository
 	Probes    repositories.ProbeRepository
+	Featured  repositories.FeaturedRepository
+	JobRuns   repositories.JobRunRepository
+	Sessions  repositories.SessionRepository
+	Matches   repositories.MatchRepository
+	History   repositories.HistoryRepository
+	Profiles  repositories.ProfileRepository
 }
 
 func PrepareTestServer(tb fxtest.TB, extra ...fx.Option) (*httptest.Server, func()) {
@@ -30,6 +38,7 @@ func PrepareTestServer(tb fxtest.TB, extra ...fx.Option) (*httptest.Server, func
 		fx.Provide(testapp.ProvideSettings),
 		fx.Provide(testapp.ProvidePersistence),
 		application.Module,
+		fx.Supply(api.Opts{AdminToken: AdminToken}),
 		fx.Provide(api.New),
 		fx.Provide(rest.NewRouter),
 		fx.NopLogger,
@@ -55,7 +64,10 @@ func PrepareTestServerWithRepos(
 	var repos TestServerRepositories
 	extra = append(
 		extra,
-		fx.Populate(&repos.Servers, &repos.Instances, &repos.Probes),
+		fx.Populate(
+			&repos.Servers, &repos.Instances, &repos.Probes, &repos.Featured, &repos.JobRuns,
+			&repos.Sessions, &repos.Matches, &repos.History, &repos.Profiles,
+		),
 	)
 	ts, cleanup := PrepareTestServer(tb, extra...)
 	return ts, repos, cleanup
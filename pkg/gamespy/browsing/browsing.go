@@ -14,10 +14,12 @@ const (
 )
 
 type Request struct {
-	Filters   string
-	Fields    []string
-	Challenge [8]byte
-	unparsed  []byte
+	GameName    string
+	GameVersion uint32
+	Filters     string
+	Fields      []string
+	Challenge   [8]byte
+	unparsed    []byte
 }
 
 var (
@@ -47,9 +49,10 @@ func NewRequest(data []byte) (Request, error) {
 		return Blank, ErrInvalidRequestFormat
 	}
 	req := Request{
-		// skip the following 7 bytes (excluding the first two that encode the payload length)
-		// that contain metadata we don't need to look into
-		unparsed: data[9:dataLen],
+		// of the following 7 bytes (excluding the first two that encode the payload length)
+		// only the last 4 bytes are of interest, as they contain the client's game version
+		GameVersion: binary.BigEndian.Uint32(data[5:9]),
+		unparsed:    data[9:dataLen],
 	}
 	if err := req.parse(); err != nil {
 		return Blank, err
@@ -59,12 +62,13 @@ func NewRequest(data []byte) (Request, error) {
 
 func (req *Request) parse() error {
 	// consume 2 equal strings with game identifier (such as swat4 or swat4xp1)
-	// because they don't serve any purpose for us, just bin them
+	// the first one is the client's game, and the second one is the queried game
 	for range 2 {
-		_, rem := binutils.ConsumeCString(req.unparsed)
+		gameName, rem := binutils.ConsumeCString(req.unparsed)
 		if rem == nil {
 			return ErrInvalidRequestFormat
 		}
+		req.GameName = string(gameName)
 		req.unparsed = rem
 	}
 	if err := req.parseChallenge(); err != nil {
@@ -0,0 +1,271 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sergeii/swat4master/pkg/binutils"
+	"github.com/sergeii/swat4master/pkg/gamespy/crypt"
+	"github.com/sergeii/swat4master/pkg/random"
+)
+
+var (
+	ErrResponseIncomplete = errors.New("response payload is not complete")
+	ErrResponseMalformed  = errors.New("response payload contains invalid data")
+)
+
+// server entry flags, as defined in the GameSpy SDK
+const (
+	flagPrivateIP              = 0x02
+	flagICMPIP                 = 0x08
+	flagNonStandardPort        = 0x10
+	flagNonStandardPrivatePort = 0x20
+	flagHasKeys                = 0x40
+)
+
+const (
+	keyTypeString   = 0x00
+	valueTypeInline = 0xff
+)
+
+var listTerminator = []byte{0xff, 0xff, 0xff, 0xff}
+
+const bufferSize = 4096
+
+type Request struct {
+	GameName string
+	GameKey  [crypt.GMSL]byte
+	Filters  string
+	Fields   []string
+}
+
+type Server struct {
+	// Addr is the server's public IP address paired with the port the master announces it with.
+	// For SWAT4 that is the query port, not the game port.
+	Addr   netip.AddrPort
+	Fields map[string]string
+}
+
+// Query requests the server list from a GameSpy master server listening at the given address.
+// The list is requested with the same payload the game sends from the in-game server browser.
+func Query(ctx context.Context, address string, req Request, timeout time.Duration) ([]Server, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	dialer := net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err = conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+
+	var challenge [crypt.CCHL]byte
+	for i := range challenge {
+		challenge[i] = uint8(random.RandInt(1, 255)) //nolint:gosec
+	}
+
+	if _, err = conn.Write(PackRequest(req, challenge)); err != nil {
+		return nil, err
+	}
+
+	payload, err := readResponse(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	return UnpackResponse(crypt.Decrypt(req.GameKey, challenge, payload))
+}
+
+func readResponse(conn net.Conn) ([]byte, error) {
+	payload := make([]byte, 0, bufferSize)
+	buffer := make([]byte, bufferSize)
+	for {
+		n, err := conn.Read(buffer)
+		payload = append(payload, buffer[:n]...)
+		if err != nil {
+			// masters close the connection once the list is sent,
+			// but some of them may leave it open until the client hangs up
+			if errors.Is(err, io.EOF) || (errors.Is(err, os.ErrDeadlineExceeded) && len(payload) > 0) {
+				break
+			}
+			return nil, err
+		}
+	}
+	// a valid encrypted payload is never shorter than its header
+	if len(payload) <= crypt.HDRL {
+		return nil, ErrResponseIncomplete
+	}
+	return payload, nil
+}
+
+// PackRequest builds a server list request payload.
+// The resulting payload is the same as the one sent by the game.
+func PackRequest(req Request, challenge [crypt.CCHL]byte) []byte {
+	payload := make([]byte, 0, 64)
+	payload = append(payload, 0x00, 0x00) // reserved for the payload length
+	payload = append(payload, 0x00)       // request type - server list
+	payload = append(payload, 0x01)       // protocol version
+	payload = append(payload, 0x03)       // encoding version
+	payload = append(payload, 0x00, 0x00, 0x00, 0x00)
+	// the game name is declared twice, once for the game and once for the queried game
+	for range 2 {
+		payload = append(payload, []byte(req.GameName)...)
+		payload = append(payload, 0x00)
+	}
+	payload = append(payload, challenge[:]...)
+	payload = append(payload, []byte(req.Filters)...)
+	payload = append(payload, 0x00)
+	payload = append(payload, '\\')
+	payload = append(payload, []byte(strings.Join(req.Fields, "\\"))...)
+	payload = append(payload, 0x00)
+	// options - plain server list
+	payload = append(payload, 0x00, 0x00, 0x00, 0x00)
+	binary.BigEndian.PutUint16(payload[:2], uint16(len(payload))) //nolint:gosec
+	return payload
+}
+
+// UnpackResponse parses a decrypted server list response.
+func UnpackResponse(payload []byte) ([]Server, error) {
+	// the first 6 bytes are the requester's IP and the default port
+	if len(payload) < 8 {
+		return nil, ErrResponseIncomplete
+	}
+	defaultPort := binary.BigEndian.Uint16(payload[4:6])
+
+	fields, unparsed, err := unpackFields(payload[6:])
+	if err != nil {
+		return nil, err
+	}
+
+	servers := make([]Server, 0)
+	for {
+		if len(unparsed) == 0 {
+			return nil, ErrResponseIncomplete
+		}
+		// the list ends with a zero flags byte followed by the 0xffffffff marker
+		if unparsed[0] == 0x00 {
+			if !bytes.HasPrefix(unparsed[1:], listTerminator) {
+				return nil, ErrResponseIncomplete
+			}
+			return servers, nil
+		}
+		var svr Server
+		svr, unparsed, err = unpackServer(unparsed, fields, defaultPort)
+		if err != nil {
+			return nil, err
+		}
+		servers = append(servers, svr)
+	}
+}
+
+func unpackFields(data []byte) ([]string, []byte, error) {
+	var name []byte
+	fieldCount := int(data[0])
+	unparsed := data[1:]
+	fields := make([]string, 0, fieldCount)
+	for range fieldCount {
+		// each field is preceded by its type, and only string fields are expected
+		if len(unparsed) == 0 {
+			return nil, nil, ErrResponseIncomplete
+		}
+		if unparsed[0] != keyTypeString {
+			return nil, nil, fmt.Errorf("%w: unsupported field type 0x%02x", ErrResponseMalformed, unparsed[0])
+		}
+		name, unparsed = binutils.ConsumeCString(unparsed[1:])
+		if unparsed == nil {
+			return nil, nil, ErrResponseIncomplete
+		}
+		fields = append(fields, string(name))
+	}
+	// popular values are not used by SWAT4 masters, but they still have to be skipped
+	if len(unparsed) == 0 {
+		return nil, nil, ErrResponseIncomplete
+	}
+	popularCount := int(unparsed[0])
+	unparsed = unparsed[1:]
+	for range popularCount {
+		_, unparsed = binutils.ConsumeCString(unparsed)
+		if unparsed == nil {
+			return nil, nil, ErrResponseIncomplete
+		}
+	}
+	return fields, unparsed, nil
+}
+
+func unpackServer(data []byte, fields []string, defaultPort uint16) (Server, []byte, error) {
+	var value []byte
+
+	flags := data[0]
+	unparsed := data[1:]
+
+	if len(unparsed) < 4 {
+		return Server{}, nil, ErrResponseIncomplete
+	}
+	ip := netip.AddrFrom4([4]byte(unparsed[:4]))
+	unparsed = unparsed[4:]
+
+	port := defaultPort
+	if flags&flagNonStandardPort != 0 {
+		if len(unparsed) < 2 {
+			return Server{}, nil, ErrResponseIncomplete
+		}
+		port = binary.BigEndian.Uint16(unparsed[:2])
+		unparsed = unparsed[2:]
+	}
+
+	// skip the addresses we are not interested in
+	skip := 0
+	if flags&flagPrivateIP != 0 {
+		skip += 4
+	}
+	if flags&flagNonStandardPrivatePort != 0 {
+		skip += 2
+	}
+	if flags&flagICMPIP != 0 {
+		skip += 4
+	}
+	if len(unparsed) < skip {
+		return Server{}, nil, ErrResponseIncomplete
+	}
+	unparsed = unparsed[skip:]
+
+	svr := Server{
+		Addr:   netip.AddrPortFrom(ip, port),
+		Fields: make(map[string]string, len(fields)),
+	}
+
+	if flags&flagHasKeys == 0 {
+		return svr, unparsed, nil
+	}
+
+	for _, field := range fields {
+		if len(unparsed) == 0 {
+			return Server{}, nil, ErrResponseIncomplete
+		}
+		if unparsed[0] != valueTypeInline {
+			return Server{}, nil, fmt.Errorf("%w: unsupported value type 0x%02x", ErrResponseMalformed, unparsed[0])
+		}
+		value, unparsed = binutils.ConsumeCString(unparsed[1:])
+		if unparsed == nil {
+			return Server{}, nil, ErrResponseIncomplete
+		}
+		svr.Fields[field] = string(value)
+	}
+
+	return svr, unparsed, nil
+}
@@ -0,0 +1,155 @@
+package client_test
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/pkg/gamespy/browsing"
+	"github.com/sergeii/swat4master/pkg/gamespy/browsing/client"
+)
+
+var gameKey = [6]byte{'t', 'G', '3', 'j', '8', 'c'}
+
+func TestPackRequest_IsAcceptedByBrowser(t *testing.T) {
+	challenge := [8]byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}
+	req := client.Request{
+		GameName: "swat4",
+		GameKey:  gameKey,
+		Filters:  "gametype='CO-OP'",
+		Fields:   []string{"hostname", "hostport"},
+	}
+
+	parsed, err := browsing.NewRequest(client.PackRequest(req, challenge))
+	require.NoError(t, err)
+	assert.Equal(t, challenge, parsed.Challenge)
+	assert.Equal(t, "swat4", parsed.GameName)
+	assert.Equal(t, uint32(0), parsed.GameVersion)
+	assert.Equal(t, "gametype='CO-OP'", parsed.Filters)
+	assert.Equal(t, []string{"hostname", "hostport"}, parsed.Fields)
+}
+
+func TestUnpackResponse_OK(t *testing.T) {
+	servers := []client.Server{
+		{
+			Addr:   netip.MustParseAddrPort("1.1.1.1:10481"),
+			Fields: map[string]string{"hostname": "Swat4 Server", "hostport": "10480"},
+		},
+		{
+			Addr:   netip.MustParseAddrPort("2.2.2.2:10581"),
+			Fields: map[string]string{"hostname": "Another Swat4 Server", "hostport": "10580"},
+		},
+	}
+	payload := client.PackResponse([]string{"hostname", "hostport"}, servers)
+
+	unpacked, err := client.UnpackResponse(payload)
+	require.NoError(t, err)
+	assert.Equal(t, servers, unpacked)
+}
+
+func TestUnpackResponse_Empty(t *testing.T) {
+	payload := client.PackResponse([]string{"hostname", "hostport"}, nil)
+
+	unpacked, err := client.UnpackResponse(payload)
+	require.NoError(t, err)
+	assert.Empty(t, unpacked)
+}
+
+func TestUnpackResponse_Errors(t *testing.T) {
+	servers := []client.Server{
+		{
+			Addr:   netip.MustParseAddrPort("1.1.1.1:10481"),
+			Fields: map[string]string{"hostname": "Swat4 Server", "hostport": "10480"},
+		},
+	}
+	payload := client.PackResponse([]string{"hostname", "hostport"}, servers)
+
+	tests := []struct {
+		name    string
+		payload []byte
+		wantErr error
+	}{
+		{
+			"too short",
+			payload[:7],
+			client.ErrResponseIncomplete,
+		},
+		{
+			"no terminator",
+			payload[:len(payload)-5],
+			client.ErrResponseIncomplete,
+		},
+		{
+			"truncated terminator",
+			payload[:len(payload)-2],
+			client.ErrResponseIncomplete,
+		},
+		{
+			"truncated server entry",
+			payload[:len(payload)-12],
+			client.ErrResponseIncomplete,
+		},
+		{
+			"unsupported value type",
+			func() []byte {
+				malformed := make([]byte, len(payload))
+				copy(malformed, payload)
+				// the first value type byte right after the server address
+				malformed[6+2+len("hostname\x00\x00hostport\x00\x00")+7] = 0x01
+				return malformed
+			}(),
+			client.ErrResponseMalformed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.UnpackResponse(tt.payload)
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestQuery_OK(t *testing.T) {
+	servers := []client.Server{
+		{
+			Addr:   netip.MustParseAddrPort("1.1.1.1:10481"),
+			Fields: map[string]string{"hostname": "Swat4 Server", "hostport": "10480"},
+		},
+		{
+			Addr:   netip.MustParseAddrPort("2.2.2.2:10581"),
+			Fields: map[string]string{"hostname": "Another Swat4 Server", "hostport": "10580"},
+		},
+	}
+	master, cancel := client.PrepareMasterServer(gameKey, servers)
+	defer cancel()
+
+	req := client.Request{
+		GameName: "swat4",
+		GameKey:  gameKey,
+		Fields:   []string{"hostname", "hostport"},
+	}
+	got, err := client.Query(context.TODO(), master.LocalAddr().String(), req, time.Millisecond*100)
+	require.NoError(t, err)
+	assert.Equal(t, servers, got)
+}
+
+func TestQuery_Timeout(t *testing.T) {
+	master, cancel := client.ServerFactory(func(ctx context.Context, conn *net.TCPConn) {
+		defer conn.Close()
+		<-ctx.Done()
+	})
+	defer cancel()
+
+	req := client.Request{
+		GameName: "swat4",
+		GameKey:  gameKey,
+		Fields:   []string{"hostname", "hostport"},
+	}
+	_, err := client.Query(context.TODO(), master.LocalAddr().String(), req, time.Millisecond*10)
+	require.Error(t, err)
+}
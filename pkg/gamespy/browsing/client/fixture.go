@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+
+	"github.com/sergeii/swat4master/pkg/gamespy/browsing"
+	"github.com/sergeii/swat4master/pkg/gamespy/crypt"
+	"github.com/sergeii/swat4master/pkg/tcp/tcpserver"
+)
+
+func ServerFactory(
+	handler func(ctx context.Context, conn *net.TCPConn),
+) (*tcpserver.Server, func()) {
+	ready := make(chan struct{})
+	server, _ := tcpserver.New(
+		"localhost:0", // 0 - listen an any available port
+		tcpserver.HandleFunc(handler),
+		tcpserver.WithReadySignal(func(net.Addr) {
+			ready <- struct{}{}
+		}),
+	)
+	go func() {
+		server.Listen() //nolint: errcheck
+	}()
+	<-ready
+	return server, func() {
+		server.Stop() //nolint: errcheck
+	}
+}
+
+func PrepareMasterServer(gameKey [crypt.GMSL]byte, servers []Server) (*tcpserver.Server, func()) {
+	return ServerFactory(
+		func(_ context.Context, conn *net.TCPConn) {
+			defer conn.Close()
+			buf := make([]byte, 2048)
+			n, err := conn.Read(buf)
+			if err != nil {
+				return
+			}
+			req, err := browsing.NewRequest(buf[:n])
+			if err != nil {
+				return
+			}
+			resp := PackResponse(req.Fields, servers)
+			conn.Write(crypt.Encrypt(gameKey, req.Challenge, resp)) //nolint: errcheck
+		},
+	)
+}
+
+// PackResponse builds a server list response the same way the master server's browser does.
+func PackResponse(fields []string, servers []Server) []byte {
+	payload := make([]byte, 6, 64)
+	payload = append(payload, uint8(len(fields)), 0x00) //nolint:gosec
+	for _, field := range fields {
+		payload = append(payload, []byte(field)...)
+		payload = append(payload, 0x00, 0x00)
+	}
+	for _, svr := range servers {
+		ip := svr.Addr.Addr().As4()
+		payload = append(payload, 0x51)
+		payload = append(payload, ip[:]...)
+		payload = binary.BigEndian.AppendUint16(payload, svr.Addr.Port())
+		for _, field := range fields {
+			payload = append(payload, 0xff)
+			payload = append(payload, []byte(svr.Fields[field])...)
+			payload = append(payload, 0x00)
+		}
+	}
+	return append(payload, 0x00, 0xff, 0xff, 0xff, 0xff)
+}
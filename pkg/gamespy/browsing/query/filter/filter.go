@@ -249,7 +249,11 @@ func IsQueryField(field string) bool {
 		"hostport",
 		"password",
 		"statsenabled",
-		"gamever":
+		"gamever",
+		// pseudo fields that are not reported by servers
+		"country",
+		"continent",
+		"asn":
 		return true
 	}
 	return false
@@ -270,6 +274,14 @@ func getStructField(v any, fieldName string) (any, error) {
 		if !field.IsExported() {
 			continue
 		}
+		// look into embedded structs, so that the field set can be extended with extra fields
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			value, err := getStructField(sv.FieldByIndex(field.Index).Addr().Interface(), fieldName)
+			if err == nil {
+				return value, nil
+			}
+			continue
+		}
 		// compare field names or their "param" aliases
 		param, ok := params.GetParamName(field)
 		if !ok || param != fieldName {
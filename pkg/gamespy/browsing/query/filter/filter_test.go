@@ -26,6 +26,14 @@ func TestIsGoodField(t *testing.T) {
 			"statsenabled",
 			true,
 		},
+		{
+			"country",
+			true,
+		},
+		{
+			"asn",
+			true,
+		},
 		{
 			"statechanged",
 			false,
@@ -373,3 +381,45 @@ func TestFilter_Match(t *testing.T) {
 		})
 	}
 }
+
+func TestFilter_Match_EmbeddedFields(t *testing.T) {
+	type Info struct {
+		Hostname   string
+		NumPlayers int
+	}
+	type Schema struct {
+		Info
+		Country string `param:"country"`
+		ASN     int    `param:"asn"`
+	}
+
+	fields := Schema{
+		Info:    Info{Hostname: "Swat4 Server", NumPlayers: 10},
+		Country: "DE",
+		ASN:     24940,
+	}
+
+	tests := []struct {
+		filter string
+		want   bool
+	}{
+		{"hostname='Swat4 Server'", true},
+		{"numplayers>5", true},
+		{"numplayers>10", false},
+		{"country='DE'", true},
+		{"country!='DE'", false},
+		{"country='FR'", false},
+		{"asn=24940", true},
+		{"continent='EU'", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			f, err := filter.Parse(tt.filter)
+			require.NoError(t, err)
+
+			match, err := f.Match(&fields)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, match)
+		})
+	}
+}
@@ -29,9 +29,15 @@ func ServerFactory(
 }
 
 func PrepareGS1Server(responses chan []byte) (*udpserver.Server, func()) {
+	return PrepareQueryServer(TypeStatus, responses)
+}
+
+// PrepareQueryServer prepares a server answering the queries of the given type only
+func PrepareQueryServer(qType Type, responses chan []byte) (*udpserver.Server, func()) {
+	prefix := []byte("\\" + string(qType) + "\\")
 	return ServerFactory(
 		func(ctx context.Context, conn *net.UDPConn, addr *net.UDPAddr, req []byte) {
-			if !bytes.Equal([]byte("\\status\\"), req[:8]) {
+			if !bytes.HasPrefix(req, prefix) {
 				return
 			}
 			for {
@@ -5,18 +5,40 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"net"
 	"net/netip"
 	"sort"
 	"strconv"
 	"time"
 
 	"golang.org/x/text/encoding/charmap"
+
+	"github.com/sergeii/swat4master/pkg/udp/udpclient"
 )
 
 var (
 	ErrResponseIncomplete = errors.New("response payload is not complete")
 	ErrResponseMalformed  = errors.New("response payload contains invalid data")
+	ErrEchoMismatch       = errors.New("echo response does not match the request")
+)
+
+// Type is the type of the query sent to a server.
+// The status query is the heaviest one, as it combines the others,
+// so the cheaper queries should be preferred when only a part of the response is of interest.
+type Type string
+
+const (
+	// TypeStatus asks for everything the server has to report
+	TypeStatus Type = "status"
+	// TypeBasic asks for the game name, version and location
+	TypeBasic Type = "basic"
+	// TypeInfo asks for the hostname, the map, the game type, the player count and the like
+	TypeInfo Type = "info"
+	// TypeRules asks for the server settings, such as the round limits and the team scores
+	TypeRules Type = "rules"
+	// TypePlayers asks for the player list with the player stats
+	TypePlayers Type = "players"
+	// TypeEcho asks the server to repeat the value sent in the request
+	TypeEcho Type = "echo"
 )
 
 type QueryVersion int
@@ -48,11 +70,16 @@ func (ver QueryVersion) String() string {
 	}
 }
 
+// Response is the reassembled response to a query.
+// Which of its fields are filled depends on the query type, see the functions sending the queries.
+// The version and the round trip time are filled for every query type.
 type Response struct {
 	Fields     map[string]string
 	Players    []map[string]string
 	Objectives []map[string]string
 	Version    QueryVersion
+	// RTT is the time it has taken the first response fragment to arrive
+	RTT time.Duration
 }
 
 type Param struct {
@@ -69,49 +96,118 @@ type fragment struct {
 
 var Blank Response
 
-const bufferSize = 2048
+// Client queries the servers over the sessions opened with its dialer
+type Client struct {
+	dialer udpclient.Dialer
+}
+
+func NewClient(dialer udpclient.Dialer) Client {
+	return Client{dialer}
+}
+
+// defaultClient dials a new socket for every query
+var defaultClient = NewClient(udpclient.Direct{}) //nolint: gochecknoglobals
 
+// Query sends the status query to the server
 func Query(ctx context.Context, addr netip.AddrPort, timeout time.Duration) (Response, error) {
-	ctx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
+	return defaultClient.Query(ctx, addr, timeout)
+}
+
+// QueryBasic sends the basic query to the server.
+// Only the Fields of the response are filled, with the game name, the game version and the server location.
+func QueryBasic(ctx context.Context, addr netip.AddrPort, timeout time.Duration) (Response, error) {
+	return defaultClient.QueryType(ctx, addr, TypeBasic, timeout)
+}
+
+// QueryInfo sends the info query to the server.
+// Only the Fields of the response are filled, with the hostname, the map, the game type,
+// the player count and limit, the password flag and the like.
+func QueryInfo(ctx context.Context, addr netip.AddrPort, timeout time.Duration) (Response, error) {
+	return defaultClient.QueryType(ctx, addr, TypeInfo, timeout)
+}
+
+// QueryRules sends the rules query to the server.
+// Only the Fields of the response are filled, with the server settings, such as the round limits,
+// and the team scores, should the server report any.
+func QueryRules(ctx context.Context, addr netip.AddrPort, timeout time.Duration) (Response, error) {
+	return defaultClient.QueryType(ctx, addr, TypeRules, timeout)
+}
+
+// QueryPlayers sends the players query to the server.
+// The Players of the response are filled, one map of the player stats per player, ordered by the player index.
+// The Fields are filled only with the values that are not indexed by player, should the server send any.
+func QueryPlayers(ctx context.Context, addr netip.AddrPort, timeout time.Duration) (Response, error) {
+	return defaultClient.QueryType(ctx, addr, TypePlayers, timeout)
+}
 
-	conn, err := net.DialUDP("udp", nil, net.UDPAddrFromAddrPort(addr))
+// Echo sends the echo query with the given value to the server
+// and makes sure the server has repeated the value in the response
+func Echo(ctx context.Context, addr netip.AddrPort, value string, timeout time.Duration) (Response, error) {
+	return defaultClient.Echo(ctx, addr, value, timeout)
+}
+
+// QueryType sends the query of the given type to the server.
+// The response fragments are reassembled the same way regardless of the query type.
+// The servers only report the objectives in response to the status query.
+func QueryType(ctx context.Context, addr netip.AddrPort, qType Type, timeout time.Duration) (Response, error) {
+	return defaultClient.QueryType(ctx, addr, qType, timeout)
+}
+
+// Query sends the status query to the server
+func (c Client) Query(ctx context.Context, addr netip.AddrPort, timeout time.Duration) (Response, error) {
+	return c.QueryType(ctx, addr, TypeStatus, timeout)
+}
+
+// QueryType sends the query of the given type to the server
+func (c Client) QueryType(
+	ctx context.Context,
+	addr netip.AddrPort,
+	qType Type,
+	timeout time.Duration,
+) (Response, error) {
+	return c.send(ctx, addr, []byte(fmt.Sprintf("\\%s\\", qType)), timeout)
+}
+
+// Echo sends the echo query with the given value to the server
+// and makes sure the server has repeated the value in the response
+func (c Client) Echo(ctx context.Context, addr netip.AddrPort, value string, timeout time.Duration) (Response, error) {
+	resp, err := c.send(ctx, addr, []byte(fmt.Sprintf("\\%s\\%s", TypeEcho, value)), timeout)
 	if err != nil {
 		return Blank, err
 	}
+	if echoed, ok := resp.Fields[string(TypeEcho)]; !ok || echoed != value {
+		return Blank, ErrEchoMismatch
+	}
+	return resp, nil
+}
 
-	closing := make(chan struct{})
-	defer func() {
-		close(closing)
-		conn.Close()
-	}()
-
-	go func() {
-		select {
-		case <-ctx.Done():
-			conn.SetReadDeadline(time.Now()) //nolint: errcheck
-		case <-closing:
-		}
-	}()
-
-	_, err = conn.Write([]byte("\\status\\"))
+func (c Client) send(ctx context.Context, addr netip.AddrPort, payload []byte, timeout time.Duration) (Response, error) {
+	sess, err := c.dialer.Open(ctx, addr, timeout)
 	if err != nil {
 		return Blank, err
 	}
+	defer sess.Close()
 
-	return getResponse(conn)
+	sentAt := time.Now()
+	if err = sess.Send(payload); err != nil {
+		return Blank, err
+	}
+
+	return getResponse(sess, sentAt)
 }
 
-func getResponse(conn *net.UDPConn) (Response, error) {
+func getResponse(sess udpclient.Session, sentAt time.Time) (Response, error) {
 	fragments := make([][]byte, 0, 4)
+	var rtt time.Duration
 	for {
-		buffer := make([]byte, bufferSize)
-		n, _, err := conn.ReadFromUDP(buffer)
+		rawFragment, err := sess.Receive()
 		if err != nil {
 			return Blank, err
 		}
+		if len(fragments) == 0 {
+			rtt = time.Since(sentAt)
+		}
 
-		rawFragment := buffer[:n]
 		if len(rawFragment) == 0 {
 			return Blank, ErrResponseIncomplete
 		}
@@ -129,6 +225,7 @@ func getResponse(conn *net.UDPConn) (Response, error) {
 		if err != nil {
 			return Blank, err
 		}
+		response.RTT = rtt
 
 		return response, nil
 	}
@@ -297,7 +394,7 @@ func expandPayload(payload []byte, version QueryVersion) (Response, error) {
 
 	for _, param := range parseParams(payload) {
 		// \obj_Neutralize_All_Enemies\0\
-		if bytes.Equal(param.Name[:4], []byte("obj_")) {
+		if bytes.HasPrefix(param.Name, []byte("obj_")) {
 			if len(param.Name) > 4 {
 				objectives = append(objectives, map[string]string{
 					"name":   string(param.Name[4:]),
@@ -2,6 +2,8 @@ package gs1_test
 
 import (
 	"context"
+	"net"
+	"net/netip"
 	"os"
 	"testing"
 	"time"
@@ -10,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/sergeii/swat4master/pkg/gamespy/serverquery/gs1"
+	"github.com/sergeii/swat4master/pkg/udp/udpclient"
 )
 
 type b []byte
@@ -154,6 +157,25 @@ func TestQuery_AdminModFragmentedAndSplitResponse(t *testing.T) {
 	assert.Equal(t, gs1.VerAM, resp.Version)
 }
 
+func TestQuery_RTTIsMeasuredToFirstFragment(t *testing.T) {
+	responses := make(chan []byte)
+	go func() {
+		responses <- b("\\statusresponse\\0\\hostname\\Swat4 Server\\numplayers\\0\\eof\\")
+		// the rest of the fragments are late
+		time.Sleep(time.Millisecond * 50)
+		responses <- b("\\statusresponse\\1\\maxplayers\\16\\queryid\\AMv1\\final\\\\eof\\")
+	}()
+	server, cancel := gs1.PrepareGS1Server(responses)
+	defer cancel()
+
+	resp, err := gs1.Query(context.TODO(), server.LocalAddrPort(), time.Millisecond*200)
+	require.NoError(t, err)
+
+	assert.Equal(t, "16", resp.Fields["maxplayers"])
+	assert.Positive(t, resp.RTT)
+	assert.Less(t, resp.RTT, time.Millisecond*50)
+}
+
 func TestQuery_ExtendedAdminModUnfragmentedResponse(t *testing.T) {
 	responses := make(chan []byte)
 	go func() {
@@ -786,3 +808,262 @@ func TestQuery_ParentContextIsCancelled(t *testing.T) {
 	_, err := gs1.Query(ctx, server.LocalAddrPort(), time.Millisecond*100)
 	assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
 }
+
+func TestQueryType_SubQueries(t *testing.T) {
+	tests := []struct {
+		name      string
+		qType     gs1.Type
+		queryFunc func(context.Context, netip.AddrPort, time.Duration) (gs1.Response, error)
+		responses [][]byte
+		want      gs1.Response
+	}{
+		{
+			name:      "vanilla basic",
+			qType:     gs1.TypeBasic,
+			queryFunc: gs1.QueryBasic,
+			responses: [][]byte{
+				b("\\gamename\\swat4\\gamever\\1.1\\location\\0\\final\\\\queryid\\1.1"),
+			},
+			want: gs1.Response{
+				// vanilla responses keep the final token in the middle of the payload
+				Fields: map[string]string{
+					"gamename": "swat4", "gamever": "1.1", "location": "0", "final": "", "queryid": "1.1",
+				},
+				Objectives: []map[string]string{},
+				Version:    gs1.VerVanilla,
+			},
+		},
+		{
+			name:      "gs1 info",
+			qType:     gs1.TypeInfo,
+			queryFunc: gs1.QueryInfo,
+			responses: [][]byte{
+				b("\\hostname\\Swat4 Server\\numplayers\\2\\queryid\\1\\final\\"),
+			},
+			want: gs1.Response{
+				Fields:     map[string]string{"hostname": "Swat4 Server", "numplayers": "2"},
+				Objectives: []map[string]string{},
+				Version:    gs1.VerGS1,
+			},
+		},
+		{
+			name:      "am rules",
+			qType:     gs1.TypeRules,
+			queryFunc: gs1.QueryRules,
+			responses: [][]byte{
+				b("\\statusresponse\\0\\round\\1\\numrounds\\5\\eof\\"),
+				b("\\statusresponse\\1\\swatscore\\10\\final\\\\queryid\\AMv1\\eof\\"),
+			},
+			want: gs1.Response{
+				Fields:     map[string]string{"round": "1", "numrounds": "5", "swatscore": "10"},
+				Objectives: []map[string]string{},
+				Version:    gs1.VerAM,
+			},
+		},
+		{
+			name:      "gs1 fragmented players",
+			qType:     gs1.TypePlayers,
+			queryFunc: gs1.QueryPlayers,
+			responses: [][]byte{
+				b("\\player_1\\Bob\\score_1\\5\\queryid\\2\\final\\"),
+				b("\\player_0\\Alice\\score_0\\10\\queryid\\1"),
+			},
+			want: gs1.Response{
+				Fields: map[string]string{},
+				Players: []map[string]string{
+					{"player": "Alice", "score": "10"},
+					{"player": "Bob", "score": "5"},
+				},
+				Objectives: []map[string]string{},
+				Version:    gs1.VerGS1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			responses := make(chan []byte)
+			go func() {
+				for _, resp := range tt.responses {
+					responses <- resp
+				}
+			}()
+			server, cancel := gs1.PrepareQueryServer(tt.qType, responses)
+			defer cancel()
+
+			resp, err := tt.queryFunc(context.TODO(), server.LocalAddrPort(), time.Millisecond*50)
+			require.NoError(t, err)
+			assert.Positive(t, resp.RTT)
+			resp.RTT = 0
+			assert.Equal(t, tt.want, resp)
+		})
+	}
+}
+
+func querySubType(
+	t *testing.T,
+	qType gs1.Type,
+	queryFunc func(context.Context, netip.AddrPort, time.Duration) (gs1.Response, error),
+	payloads ...string,
+) gs1.Response {
+	responses := make(chan []byte)
+	go func() {
+		for _, payload := range payloads {
+			responses <- b(payload)
+		}
+	}()
+	server, cancel := gs1.PrepareQueryServer(qType, responses)
+	defer cancel()
+
+	resp, err := queryFunc(context.TODO(), server.LocalAddrPort(), time.Millisecond*50)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestQueryBasic(t *testing.T) {
+	resp := querySubType(
+		t, gs1.TypeBasic, gs1.QueryBasic,
+		"\\gamename\\swat4\\gamever\\1.1\\location\\0\\queryid\\1\\final\\",
+	)
+
+	assert.Equal(t, map[string]string{"gamename": "swat4", "gamever": "1.1", "location": "0"}, resp.Fields)
+	assert.Nil(t, resp.Players)
+	assert.Empty(t, resp.Objectives)
+	assert.Equal(t, gs1.VerGS1, resp.Version)
+	assert.Positive(t, resp.RTT)
+}
+
+func TestQueryInfo(t *testing.T) {
+	resp := querySubType(
+		t, gs1.TypeInfo, gs1.QueryInfo,
+		"\\hostname\\Swat4 Server\\hostport\\10480\\gamevariant\\SWAT 4\\mapname\\A-Bomb Nightclub"+
+			"\\gametype\\Barricaded Suspects\\numplayers\\2\\maxplayers\\16\\password\\0\\queryid\\1\\final\\",
+	)
+
+	assert.Equal(t, "Swat4 Server", resp.Fields["hostname"])
+	assert.Equal(t, "10480", resp.Fields["hostport"])
+	assert.Equal(t, "A-Bomb Nightclub", resp.Fields["mapname"])
+	assert.Equal(t, "Barricaded Suspects", resp.Fields["gametype"])
+	assert.Equal(t, "2", resp.Fields["numplayers"])
+	assert.Equal(t, "16", resp.Fields["maxplayers"])
+	assert.Equal(t, "0", resp.Fields["password"])
+	assert.Nil(t, resp.Players)
+	assert.Empty(t, resp.Objectives)
+	assert.Equal(t, gs1.VerGS1, resp.Version)
+	assert.Positive(t, resp.RTT)
+}
+
+func TestQueryRules(t *testing.T) {
+	resp := querySubType(
+		t, gs1.TypeRules, gs1.QueryRules,
+		"\\round\\2\\numrounds\\5\\timeleft\\109\\swatscore\\41\\suspectsscore\\36"+
+			"\\swatwon\\1\\suspectswon\\0\\queryid\\1\\final\\",
+	)
+
+	assert.Equal(t, map[string]string{
+		"round": "2", "numrounds": "5", "timeleft": "109",
+		"swatscore": "41", "suspectsscore": "36", "swatwon": "1", "suspectswon": "0",
+	}, resp.Fields)
+	assert.Nil(t, resp.Players)
+	assert.Empty(t, resp.Objectives)
+	assert.Equal(t, gs1.VerGS1, resp.Version)
+	assert.Positive(t, resp.RTT)
+}
+
+func TestQueryPlayers(t *testing.T) {
+	resp := querySubType(
+		t, gs1.TypePlayers, gs1.QueryPlayers,
+		"\\player_1\\Bob\\score_1\\5\\ping_1\\80\\team_1\\1\\queryid\\2\\final\\",
+		"\\player_0\\Alice\\score_0\\10\\ping_0\\45\\team_0\\0\\queryid\\1",
+	)
+
+	assert.Equal(t, []map[string]string{
+		{"player": "Alice", "score": "10", "ping": "45", "team": "0"},
+		{"player": "Bob", "score": "5", "ping": "80", "team": "1"},
+	}, resp.Players)
+	assert.Empty(t, resp.Fields)
+	assert.Empty(t, resp.Objectives)
+	assert.Equal(t, gs1.VerGS1, resp.Version)
+	assert.Positive(t, resp.RTT)
+}
+
+func TestQueryType_OtherQueriesAreNotAnswered(t *testing.T) {
+	responses := make(chan []byte)
+	go func() {
+		responses <- b("\\hostname\\Swat4 Server\\final\\\\queryid\\1.1")
+	}()
+	server, cancel := gs1.PrepareQueryServer(gs1.TypeInfo, responses)
+	defer cancel()
+
+	_, err := gs1.QueryPlayers(context.TODO(), server.LocalAddrPort(), time.Millisecond*50)
+	assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+}
+
+func TestEcho(t *testing.T) {
+	tests := []struct {
+		name     string
+		response []byte
+		wantErr  error
+	}{
+		{
+			name:     "echo matches",
+			response: b("\\echo\\ping\\final\\\\queryid\\1.1"),
+		},
+		{
+			name:     "echo does not match",
+			response: b("\\echo\\pong\\final\\\\queryid\\1.1"),
+			wantErr:  gs1.ErrEchoMismatch,
+		},
+		{
+			name:     "echo is missing",
+			response: b("\\hostname\\Swat4 Server\\final\\\\queryid\\1.1"),
+			wantErr:  gs1.ErrEchoMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			responses := make(chan []byte)
+			go func() {
+				responses <- tt.response
+			}()
+			server, cancel := gs1.PrepareQueryServer(gs1.TypeEcho, responses)
+			defer cancel()
+
+			resp, err := gs1.Echo(context.TODO(), server.LocalAddrPort(), "ping", time.Millisecond*50)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "ping", resp.Fields["echo"])
+			assert.Equal(t, gs1.VerVanilla, resp.Version)
+		})
+	}
+}
+
+func TestClient_QueryOverMux(t *testing.T) {
+	mux, err := udpclient.NewMux(1)
+	require.NoError(t, err)
+	defer mux.Close()
+
+	responses := make(chan []byte)
+	go func() {
+		responses <- b("\\hostport\\10480\\queryid\\2\\final\\")
+		responses <- b("\\hostname\\test\\queryid\\1")
+	}()
+	server, cancel := gs1.PrepareGS1Server(responses)
+	defer cancel()
+
+	client := gs1.NewClient(mux)
+	resp, err := client.Query(context.TODO(), server.LocalAddrPort(), time.Millisecond*50)
+	require.NoError(t, err)
+	assert.Equal(t, "10480", resp.Fields["hostport"])
+	assert.Equal(t, "test", resp.Fields["hostname"])
+	assert.Equal(t, gs1.VerGS1, resp.Version)
+
+	silent, cancelSilent := gs1.ServerFactory(func(context.Context, *net.UDPConn, *net.UDPAddr, []byte) {})
+	defer cancelSilent()
+	_, err = client.Query(context.TODO(), silent.LocalAddrPort(), time.Millisecond*50)
+	assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+}
@@ -0,0 +1,116 @@
+package qr2
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+	"strconv"
+
+	"github.com/sergeii/swat4master/pkg/udp/udpserver"
+)
+
+func ServerFactory(
+	handler func(ctx context.Context, conn *net.UDPConn, addr *net.UDPAddr, req []byte),
+) (*udpserver.Server, func()) {
+	ready := make(chan struct{})
+	server, _ := udpserver.New(
+		"localhost:0", // 0 - listen an any available port
+		udpserver.HandleFunc(handler),
+		udpserver.WithReadySignal(func() {
+			ready <- struct{}{}
+		}),
+	)
+	go func() {
+		server.Listen() //nolint: errcheck
+	}()
+	<-ready
+	return server, func() {
+		server.Stop() //nolint: errcheck
+	}
+}
+
+// PrepareQR2Server prepares a server that issues the given challenge
+// and answers the queries carrying the challenge with the packets sent to the channel.
+// The packets are sent as is, prefixed with the query packet header.
+func PrepareQR2Server(challenge int32, packets chan []byte) (*udpserver.Server, func()) {
+	return ServerFactory(
+		func(ctx context.Context, conn *net.UDPConn, addr *net.UDPAddr, req []byte) {
+			if len(req) < 7 || !bytes.HasPrefix(req, Magic) {
+				return
+			}
+			sessionID := req[3:7]
+			switch req[2] {
+			case PacketChallenge:
+				resp := make([]byte, 0, 16)
+				resp = append(resp, PacketChallenge)
+				resp = append(resp, sessionID...)
+				resp = append(resp, []byte(strconv.Itoa(int(challenge)))...)
+				resp = append(resp, 0x00)
+				conn.WriteToUDP(resp, addr) //nolint: errcheck
+			case PacketQuery:
+				if len(req) < 11 || binary.BigEndian.Uint32(req[7:11]) != uint32(challenge) { //nolint:gosec
+					return
+				}
+				for {
+					select {
+					case packet := <-packets:
+						resp := make([]byte, 0, headerLen+len(packet))
+						resp = append(resp, PacketQuery)
+						resp = append(resp, sessionID...)
+						resp = append(resp, packet...)
+						conn.WriteToUDP(resp, addr) //nolint: errcheck
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		},
+	)
+}
+
+// PackPacket builds the body of a split response packet out of the sections
+func PackPacket(order int, isLast bool, sections ...[]byte) []byte {
+	num := byte(order) //nolint:gosec
+	if isLast {
+		num |= 0x80
+	}
+	packet := make([]byte, 0, 64)
+	packet = append(packet, []byte(SplitNum)...)
+	packet = append(packet, num)
+	for _, section := range sections {
+		packet = append(packet, section...)
+	}
+	return packet
+}
+
+// PackServerSection builds the server section out of the key-value pairs
+func PackServerSection(fields ...string) []byte {
+	section := []byte{SectionServer}
+	for _, field := range fields {
+		section = append(section, []byte(field)...)
+		section = append(section, 0x00)
+	}
+	return append(section, 0x00)
+}
+
+type ItemKey struct {
+	Name   string
+	Offset int
+	Values []string
+}
+
+// PackItemSection builds the player or the team section out of the item keys
+func PackItemSection(sectionType byte, keys ...ItemKey) []byte {
+	section := []byte{sectionType}
+	for _, key := range keys {
+		section = append(section, []byte(key.Name)...)
+		section = append(section, 0x00, byte(key.Offset)) //nolint:gosec
+		for _, value := range key.Values {
+			section = append(section, []byte(value)...)
+			section = append(section, 0x00)
+		}
+		section = append(section, 0x00)
+	}
+	return append(section, 0x00)
+}
@@ -0,0 +1,337 @@
+package qr2
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding/charmap"
+
+	"github.com/sergeii/swat4master/pkg/binutils"
+	"github.com/sergeii/swat4master/pkg/random"
+	"github.com/sergeii/swat4master/pkg/udp/udpclient"
+)
+
+var (
+	ErrResponseIncomplete = errors.New("response payload is not complete")
+	ErrResponseMalformed  = errors.New("response payload contains invalid data")
+	ErrChallengeMalformed = errors.New("challenge response contains invalid data")
+)
+
+// Magic prefixes every query sent to a server
+var Magic = []byte{0xfe, 0xfd}
+
+const (
+	PacketQuery     = 0x00
+	PacketChallenge = 0x09
+)
+
+const (
+	SectionServer = 0x00
+	SectionPlayer = 0x01
+	SectionTeam   = 0x02
+)
+
+// SplitNum follows the header of every packet of a split response
+const SplitNum = "splitnum\x00"
+
+const headerLen = 5 // packet type and session id
+
+// Response is the server's reply to a full stats query.
+// The players and teams are keyed the same way as in the gs1 response, e.g. player and score,
+// with the qr2 suffixes stripped from the key names.
+type Response struct {
+	Fields  map[string]string
+	Players []map[string]string
+	Teams   []map[string]string
+	// RTT is the time it has taken the challenge to arrive,
+	// as the challenge exchange is the network round trip with the least server processing involved
+	RTT time.Duration
+}
+
+var Blank Response
+
+// Client queries the servers over the sessions opened with its dialer
+type Client struct {
+	dialer udpclient.Dialer
+}
+
+func NewClient(dialer udpclient.Dialer) Client {
+	return Client{dialer}
+}
+
+// defaultClient dials a new socket for every query
+var defaultClient = NewClient(udpclient.Direct{}) //nolint: gochecknoglobals
+
+// Query performs the qr2 query handshake with the server:
+// first it obtains the challenge, then it requests all server, player and team keys
+// in a split packet response, which is reassembled before being returned.
+func Query(ctx context.Context, addr netip.AddrPort, timeout time.Duration) (Response, error) {
+	return defaultClient.Query(ctx, addr, timeout)
+}
+
+// Query performs the qr2 query handshake with the server
+func (c Client) Query(ctx context.Context, addr netip.AddrPort, timeout time.Duration) (Response, error) {
+	sess, err := c.dialer.Open(ctx, addr, timeout)
+	if err != nil {
+		return Blank, err
+	}
+	defer sess.Close()
+
+	sessionID := NewSessionID()
+
+	sentAt := time.Now()
+	if err = sess.Send(PackChallengeRequest(sessionID)); err != nil {
+		return Blank, err
+	}
+	challenge, err := getChallenge(sess, sessionID)
+	if err != nil {
+		return Blank, err
+	}
+	rtt := time.Since(sentAt)
+
+	if err = sess.Send(PackQueryRequest(sessionID, challenge)); err != nil {
+		return Blank, err
+	}
+
+	resp, err := getResponse(sess, sessionID)
+	if err != nil {
+		return Blank, err
+	}
+	resp.RTT = rtt
+
+	return resp, nil
+}
+
+// NewSessionID generates a random session id.
+// The high bits of every byte are cleared, as some servers would refuse the id otherwise.
+func NewSessionID() [4]byte {
+	var sessionID [4]byte
+	copy(sessionID[:], random.RandBytes(4))
+	for i := range sessionID {
+		sessionID[i] &= 0x0f
+	}
+	return sessionID
+}
+
+func PackChallengeRequest(sessionID [4]byte) []byte {
+	payload := make([]byte, 0, 7)
+	payload = append(payload, Magic...)
+	payload = append(payload, PacketChallenge)
+	return append(payload, sessionID[:]...)
+}
+
+func PackQueryRequest(sessionID [4]byte, challenge uint32) []byte {
+	payload := make([]byte, 0, 15)
+	payload = append(payload, Magic...)
+	payload = append(payload, PacketQuery)
+	payload = append(payload, sessionID[:]...)
+	payload = binary.BigEndian.AppendUint32(payload, challenge)
+	// request all server, player and team keys, and allow the response to be split
+	return append(payload, 0xff, 0xff, 0xff, 0x01)
+}
+
+func getChallenge(sess udpclient.Session, sessionID [4]byte) (uint32, error) {
+	for {
+		body, err := readPacket(sess, PacketChallenge, sessionID)
+		if err != nil {
+			return 0, err
+		}
+		if body == nil {
+			continue
+		}
+		value, _ := binutils.ConsumeCString(body)
+		// the challenge is a signed 32-bit integer in its decimal form
+		challenge, err := strconv.ParseInt(string(value), 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %w", ErrChallengeMalformed, err)
+		}
+		return uint32(challenge), nil //nolint:gosec
+	}
+}
+
+func getResponse(sess udpclient.Session, sessionID [4]byte) (Response, error) {
+	packets := make(map[int][]byte)
+	count := -1
+	for {
+		body, err := readPacket(sess, PacketQuery, sessionID)
+		if err != nil {
+			return Blank, err
+		}
+		if body == nil {
+			continue
+		}
+
+		order, isLast, data, err := inspectPacket(body)
+		if err != nil {
+			return Blank, err
+		}
+		if isLast {
+			count = order + 1
+		}
+		packets[order] = data
+
+		if count == -1 || len(packets) < count {
+			continue
+		}
+		if len(packets) > count {
+			return Blank, fmt.Errorf("%w: packet order exceeds packet count", ErrResponseMalformed)
+		}
+
+		return expandPackets(packets, count)
+	}
+}
+
+// readPacket reads the next packet and strips its header.
+// The packets of unexpected type or belonging to another session are skipped with nil body.
+func readPacket(sess udpclient.Session, packetType byte, sessionID [4]byte) ([]byte, error) {
+	packet, err := sess.Receive()
+	if err != nil {
+		return nil, err
+	}
+	if len(packet) < headerLen {
+		return nil, ErrResponseIncomplete
+	}
+	if packet[0] != packetType || !bytes.Equal(packet[1:headerLen], sessionID[:]) {
+		return nil, nil
+	}
+	return packet[headerLen:], nil
+}
+
+func inspectPacket(body []byte) (int, bool, []byte, error) {
+	if !bytes.HasPrefix(body, []byte(SplitNum)) || len(body) < len(SplitNum)+1 {
+		return -1, false, nil, fmt.Errorf("%w: splitnum is missing", ErrResponseMalformed)
+	}
+	num := body[len(SplitNum)]
+	// the high bit marks the last packet, the rest is the packet's zero based order
+	return int(num & 0x7f), num&0x80 != 0, body[len(SplitNum)+1:], nil
+}
+
+func expandPackets(packets map[int][]byte, count int) (Response, error) {
+	fields := make(map[string]string)
+	players := make(map[int]map[string]string)
+	teams := make(map[int]map[string]string)
+
+	for i := range count {
+		packet, ok := packets[i]
+		if !ok {
+			return Blank, fmt.Errorf("%w: packet %d is missing", ErrResponseIncomplete, i)
+		}
+		if err := expandPacket(packet, fields, players, teams); err != nil {
+			return Blank, err
+		}
+	}
+
+	return Response{
+		Fields:  fields,
+		Players: collectItems(players),
+		Teams:   collectItems(teams),
+	}, nil
+}
+
+func expandPacket(
+	data []byte,
+	fields map[string]string,
+	players map[int]map[string]string,
+	teams map[int]map[string]string,
+) error {
+	var err error
+	for unparsed := data; len(unparsed) > 0; {
+		section := unparsed[0]
+		unparsed = unparsed[1:]
+		switch section {
+		case SectionServer:
+			unparsed, err = expandServerSection(unparsed, fields)
+		case SectionPlayer:
+			unparsed, err = expandItemSection(unparsed, players, "_")
+		case SectionTeam:
+			unparsed, err = expandItemSection(unparsed, teams, "_t")
+		default:
+			return fmt.Errorf("%w: unknown section %d", ErrResponseMalformed, section)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expandServerSection consumes the key-value pairs that follow each other until an empty key
+func expandServerSection(data []byte, fields map[string]string) ([]byte, error) {
+	var key, value []byte
+	unparsed := data
+	for {
+		key, unparsed = binutils.ConsumeCString(unparsed)
+		if unparsed == nil {
+			return nil, fmt.Errorf("%w: server section is not terminated", ErrResponseMalformed)
+		}
+		if len(key) == 0 {
+			return unparsed, nil
+		}
+		value, unparsed = binutils.ConsumeCString(unparsed)
+		if unparsed == nil {
+			return nil, fmt.Errorf("%w: server key %s has no value", ErrResponseMalformed, key)
+		}
+		fields[string(key)] = latin1(value)
+	}
+}
+
+// expandItemSection consumes the player or team keys until an empty key.
+// Every key is followed by the offset of the first item it describes,
+// and then by the values for the subsequent items until an empty value.
+func expandItemSection(data []byte, items map[int]map[string]string, suffix string) ([]byte, error) {
+	var key, value []byte
+	unparsed := data
+	for {
+		key, unparsed = binutils.ConsumeCString(unparsed)
+		if unparsed == nil {
+			return nil, fmt.Errorf("%w: item section is not terminated", ErrResponseMalformed)
+		}
+		if len(key) == 0 {
+			return unparsed, nil
+		}
+		if len(unparsed) == 0 {
+			return nil, fmt.Errorf("%w: item key %s has no offset", ErrResponseMalformed, key)
+		}
+		name := strings.TrimSuffix(string(key), suffix)
+		offset := int(unparsed[0])
+		unparsed = unparsed[1:]
+		for id := offset; ; id++ {
+			value, unparsed = binutils.ConsumeCString(unparsed)
+			if unparsed == nil {
+				return nil, fmt.Errorf("%w: item key %s values are not terminated", ErrResponseMalformed, key)
+			}
+			if len(value) == 0 {
+				break
+			}
+			if _, ok := items[id]; !ok {
+				items[id] = make(map[string]string)
+			}
+			items[id][name] = latin1(value)
+		}
+	}
+}
+
+func collectItems(itemsByID map[int]map[string]string) []map[string]string {
+	if len(itemsByID) == 0 {
+		return nil
+	}
+	items := make([]map[string]string, 0, len(itemsByID))
+	for id := 0; len(items) < len(itemsByID); id++ {
+		if item, ok := itemsByID[id]; ok {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func latin1(bytes []byte) string {
+	encoded, _ := charmap.ISO8859_1.NewDecoder().Bytes(bytes)
+	return string(encoded)
+}
@@ -0,0 +1,315 @@
+package qr2_test
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/pkg/gamespy/serverquery/qr2"
+	"github.com/sergeii/swat4master/pkg/udp/udpclient"
+)
+
+func sendPackets(packets ...[]byte) chan []byte {
+	ch := make(chan []byte)
+	go func() {
+		for _, packet := range packets {
+			ch <- packet
+		}
+	}()
+	return ch
+}
+
+func TestQuery_SinglePacket(t *testing.T) {
+	packets := sendPackets(
+		qr2.PackPacket(
+			0, true,
+			qr2.PackServerSection(
+				"hostname", "Swat4 Server",
+				"hostport", "10480",
+				"numplayers", "2",
+				"gamever", "1.1",
+			),
+			qr2.PackItemSection(
+				qr2.SectionPlayer,
+				qr2.ItemKey{Name: "player_", Offset: 0, Values: []string{"Alice", "Bob"}},
+				qr2.ItemKey{Name: "score_", Offset: 0, Values: []string{"10", "-5"}},
+			),
+			qr2.PackItemSection(
+				qr2.SectionTeam,
+				qr2.ItemKey{Name: "team_t", Offset: 0, Values: []string{"SWAT", "Suspects"}},
+				qr2.ItemKey{Name: "score_t", Offset: 0, Values: []string{"100", "50"}},
+			),
+		),
+	)
+	server, cancel := qr2.PrepareQR2Server(-123456, packets)
+	defer cancel()
+
+	resp, err := qr2.Query(context.TODO(), server.LocalAddrPort(), time.Millisecond*50)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"hostname":   "Swat4 Server",
+		"hostport":   "10480",
+		"numplayers": "2",
+		"gamever":    "1.1",
+	}, resp.Fields)
+	assert.Equal(t, []map[string]string{
+		{"player": "Alice", "score": "10"},
+		{"player": "Bob", "score": "-5"},
+	}, resp.Players)
+	assert.Equal(t, []map[string]string{
+		{"team": "SWAT", "score": "100"},
+		{"team": "Suspects", "score": "50"},
+	}, resp.Teams)
+}
+
+func TestQuery_SplitPacketsAreReassembled(t *testing.T) {
+	// the packets arrive out of order, and the player list spans two of them
+	packets := sendPackets(
+		qr2.PackPacket(
+			2, true,
+			qr2.PackItemSection(
+				qr2.SectionTeam,
+				qr2.ItemKey{Name: "team_t", Offset: 0, Values: []string{"SWAT", "Suspects"}},
+			),
+		),
+		qr2.PackPacket(
+			0, false,
+			qr2.PackServerSection("hostname", "Swat4 \xabServer\xbb", "hostport", "10480"),
+			qr2.PackItemSection(
+				qr2.SectionPlayer,
+				qr2.ItemKey{Name: "player_", Offset: 0, Values: []string{"Alice", "Bob"}},
+			),
+		),
+		qr2.PackPacket(
+			1, false,
+			qr2.PackServerSection("numplayers", "3"),
+			qr2.PackItemSection(
+				qr2.SectionPlayer,
+				qr2.ItemKey{Name: "player_", Offset: 2, Values: []string{"Carol"}},
+				qr2.ItemKey{Name: "ping_", Offset: 0, Values: []string{"50", "60", "70"}},
+			),
+		),
+	)
+	server, cancel := qr2.PrepareQR2Server(42, packets)
+	defer cancel()
+
+	resp, err := qr2.Query(context.TODO(), server.LocalAddrPort(), time.Millisecond*50)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"hostname":   "Swat4 «Server»",
+		"hostport":   "10480",
+		"numplayers": "3",
+	}, resp.Fields)
+	assert.Equal(t, []map[string]string{
+		{"player": "Alice", "ping": "50"},
+		{"player": "Bob", "ping": "60"},
+		{"player": "Carol", "ping": "70"},
+	}, resp.Players)
+	assert.Equal(t, []map[string]string{
+		{"team": "SWAT"},
+		{"team": "Suspects"},
+	}, resp.Teams)
+}
+
+func TestQuery_NoPlayers(t *testing.T) {
+	packets := sendPackets(
+		qr2.PackPacket(0, true, qr2.PackServerSection("hostname", "Swat4 Server")),
+	)
+	server, cancel := qr2.PrepareQR2Server(0, packets)
+	defer cancel()
+
+	resp, err := qr2.Query(context.TODO(), server.LocalAddrPort(), time.Millisecond*50)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"hostname": "Swat4 Server"}, resp.Fields)
+	assert.Nil(t, resp.Players)
+	assert.Nil(t, resp.Teams)
+}
+
+func TestQuery_RTTIsMeasuredToChallenge(t *testing.T) {
+	packets := make(chan []byte)
+	go func() {
+		// the server takes its time to gather the stats
+		time.Sleep(time.Millisecond * 50)
+		packets <- qr2.PackPacket(0, true, qr2.PackServerSection("hostname", "Swat4 Server"))
+	}()
+	server, cancel := qr2.PrepareQR2Server(0, packets)
+	defer cancel()
+
+	resp, err := qr2.Query(context.TODO(), server.LocalAddrPort(), time.Millisecond*200)
+	require.NoError(t, err)
+
+	assert.Positive(t, resp.RTT)
+	assert.Less(t, resp.RTT, time.Millisecond*50)
+}
+
+func TestQuery_MalformedResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		packets [][]byte
+		wantErr error
+	}{
+		{
+			name:    "no splitnum",
+			packets: [][]byte{[]byte("hostname\x00Swat4 Server\x00\x00")},
+			wantErr: qr2.ErrResponseMalformed,
+		},
+		{
+			name:    "unknown section",
+			packets: [][]byte{qr2.PackPacket(0, true, []byte{0x05, 0x00})},
+			wantErr: qr2.ErrResponseMalformed,
+		},
+		{
+			name:    "server section is not terminated",
+			packets: [][]byte{qr2.PackPacket(0, true, []byte("\x00hostname\x00Swat4 Server\x00"))},
+			wantErr: qr2.ErrResponseMalformed,
+		},
+		{
+			name:    "server key has no value",
+			packets: [][]byte{qr2.PackPacket(0, true, []byte("\x00hostname"))},
+			wantErr: qr2.ErrResponseMalformed,
+		},
+		{
+			name:    "player key has no offset",
+			packets: [][]byte{qr2.PackPacket(0, true, []byte("\x01player_\x00"))},
+			wantErr: qr2.ErrResponseMalformed,
+		},
+		{
+			name:    "player values are not terminated",
+			packets: [][]byte{qr2.PackPacket(0, true, []byte("\x01player_\x00\x00Alice"))},
+			wantErr: qr2.ErrResponseMalformed,
+		},
+		{
+			name: "packet order exceeds packet count",
+			packets: [][]byte{
+				qr2.PackPacket(2, false, qr2.PackServerSection("hostname", "Swat4 Server")),
+				qr2.PackPacket(0, true, qr2.PackServerSection("hostport", "10480")),
+			},
+			wantErr: qr2.ErrResponseMalformed,
+		},
+		{
+			name: "last packet is missing",
+			packets: [][]byte{
+				qr2.PackPacket(0, false, qr2.PackServerSection("hostname", "Swat4 Server")),
+			},
+			wantErr: os.ErrDeadlineExceeded,
+		},
+		{
+			name: "middle packet is missing",
+			packets: [][]byte{
+				qr2.PackPacket(0, false, qr2.PackServerSection("hostname", "Swat4 Server")),
+				qr2.PackPacket(2, true, qr2.PackServerSection("hostport", "10480")),
+			},
+			wantErr: os.ErrDeadlineExceeded,
+		},
+		{
+			name: "packet is missing in place of packet out of order",
+			packets: [][]byte{
+				qr2.PackPacket(0, false, qr2.PackServerSection("hostname", "Swat4 Server")),
+				qr2.PackPacket(5, false, qr2.PackServerSection("mapname", "A-Bomb Nightclub")),
+				qr2.PackPacket(2, true, qr2.PackServerSection("hostport", "10480")),
+			},
+			wantErr: qr2.ErrResponseIncomplete,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, cancel := qr2.PrepareQR2Server(1, sendPackets(tt.packets...))
+			defer cancel()
+
+			_, err := qr2.Query(context.TODO(), server.LocalAddrPort(), time.Millisecond*50)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestQuery_MalformedChallenge(t *testing.T) {
+	server, cancel := qr2.ServerFactory(
+		func(_ context.Context, conn *net.UDPConn, addr *net.UDPAddr, req []byte) {
+			resp := append([]byte{qr2.PacketChallenge}, req[3:7]...)
+			resp = append(resp, []byte("foo\x00")...)
+			conn.WriteToUDP(resp, addr) //nolint: errcheck
+		},
+	)
+	defer cancel()
+
+	_, err := qr2.Query(context.TODO(), server.LocalAddrPort(), time.Millisecond*50)
+	assert.ErrorIs(t, err, qr2.ErrChallengeMalformed)
+}
+
+func TestQuery_OtherSessionsAreIgnored(t *testing.T) {
+	server, cancel := qr2.ServerFactory(
+		func(_ context.Context, conn *net.UDPConn, addr *net.UDPAddr, _ []byte) {
+			resp := []byte{qr2.PacketChallenge, 0x0f, 0x0f, 0x0f, 0x0f}
+			resp = append(resp, []byte("1\x00")...)
+			conn.WriteToUDP(resp, addr) //nolint: errcheck
+		},
+	)
+	defer cancel()
+
+	_, err := qr2.Query(context.TODO(), server.LocalAddrPort(), time.Millisecond*50)
+	assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+}
+
+func TestQuery_NoResponse(t *testing.T) {
+	server, cancel := qr2.ServerFactory(
+		func(_ context.Context, _ *net.UDPConn, _ *net.UDPAddr, _ []byte) {},
+	)
+	defer cancel()
+
+	_, err := qr2.Query(context.TODO(), server.LocalAddrPort(), time.Millisecond*50)
+	assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+}
+
+func TestQuery_ChallengeIsRequired(t *testing.T) {
+	// the server expects another challenge, so the query is never answered
+	server, cancel := qr2.PrepareQR2Server(1, sendPackets(
+		qr2.PackPacket(0, true, qr2.PackServerSection("hostname", "Swat4 Server")),
+	))
+	defer cancel()
+
+	sessionID := qr2.NewSessionID()
+	conn, err := net.DialUDP("udp", nil, net.UDPAddrFromAddrPort(server.LocalAddrPort()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write(qr2.PackQueryRequest(sessionID, 2))
+	require.NoError(t, err)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Millisecond*50)))
+	_, err = conn.Read(make([]byte, 64))
+	assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+}
+
+func TestNewSessionID(t *testing.T) {
+	for range 100 {
+		sessionID := qr2.NewSessionID()
+		for _, b := range sessionID {
+			assert.Equal(t, byte(0), b&0xf0)
+		}
+	}
+}
+
+func TestClient_QueryOverMux(t *testing.T) {
+	mux, err := udpclient.NewMux(1)
+	require.NoError(t, err)
+	defer mux.Close()
+
+	packets := sendPackets(
+		qr2.PackPacket(1, true, qr2.PackServerSection("hostport", "10480")),
+		qr2.PackPacket(0, false, qr2.PackServerSection("hostname", "Swat4 Server")),
+	)
+	server, cancel := qr2.PrepareQR2Server(-42, packets)
+	defer cancel()
+
+	resp, err := qr2.NewClient(mux).Query(context.TODO(), server.LocalAddrPort(), time.Millisecond*50)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"hostname": "Swat4 Server", "hostport": "10480"}, resp.Fields)
+}
@@ -0,0 +1,139 @@
+package styles
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Plain renders the text with no styles
+func (t Text) Plain() string {
+	var b strings.Builder
+	for _, run := range t {
+		b.WriteString(run.Text)
+	}
+	return b.String()
+}
+
+// HTML renders the text as escaped HTML.
+// A color is rendered as a styled span, bold and underlined text are wrapped in <b> and <u> respectively.
+func (t Text) HTML() string {
+	var b strings.Builder
+	for _, run := range t {
+		text := html.EscapeString(run.Text)
+		if run.Style.Underline {
+			text = "<u>" + text + "</u>"
+		}
+		if run.Style.Bold {
+			text = "<b>" + text + "</b>"
+		}
+		if run.Style.Color != "" {
+			text = fmt.Sprintf(`<span style="color:#%s;">%s</span>`, run.Style.Color, text)
+		}
+		b.WriteString(text)
+	}
+	return b.String()
+}
+
+// ANSI renders the text with the ANSI escape sequences understood by the terminals.
+// The colors are rendered in 24-bit, every styled run is followed by a reset.
+// The control characters are stripped, so that the text cannot sneak its own escape sequences in.
+func (t Text) ANSI() string {
+	var b strings.Builder
+	for _, run := range t {
+		text := stripControls(run.Text)
+		if run.Style.IsPlain() {
+			b.WriteString(text)
+			continue
+		}
+		b.WriteString("\x1b[" + ansiParams(run.Style) + "m")
+		b.WriteString(text)
+		b.WriteString("\x1b[0m")
+	}
+	return b.String()
+}
+
+// stripControls removes the C0 and C1 control characters, including ESC and CSI
+func stripControls(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, text)
+}
+
+func ansiParams(style Style) string {
+	params := make([]string, 0, 3)
+	if style.Bold {
+		params = append(params, "1")
+	}
+	if style.Underline {
+		params = append(params, "4")
+	}
+	if style.Color != "" {
+		rgb, _ := strconv.ParseUint(style.Color, 16, 32)
+		params = append(params, fmt.Sprintf("38;2;%d;%d;%d", rgb>>16&0xFF, rgb>>8&0xFF, rgb&0xFF))
+	}
+	return strings.Join(params, ";")
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`*`, `\*`,
+	`_`, `\_`,
+	`~`, `\~`,
+	"`", "\\`",
+	`|`, `\|`,
+	`>`, `\>`,
+	`#`, `\#`,
+	`[`, `\[`,
+	`]`, `\]`,
+	// a zero-width space keeps @everyone, @here and <@id> from pinging anyone
+	`@`, "@\u200b",
+)
+
+// Markdown renders the text as escaped Markdown, the way Discord understands it.
+// The mentions are defused, so that the text does not notify anyone when posted.
+// The colors are dropped, as Markdown has no means to render them.
+// Bold text is wrapped in ** and underlined text is wrapped in __, leaving the surrounding whitespace out,
+// as the markers enclosing whitespace are not recognized.
+func (t Text) Markdown() string {
+	var b strings.Builder
+	for _, run := range t.mergeColors() {
+		text := markdownEscaper.Replace(run.Text)
+		styled := strings.TrimFunc(text, unicode.IsSpace)
+		if styled == "" || !run.Style.Bold && !run.Style.Underline {
+			b.WriteString(text)
+			continue
+		}
+		if run.Style.Underline {
+			styled = "__" + styled + "__"
+		}
+		if run.Style.Bold {
+			styled = "**" + styled + "**"
+		}
+		lead := len(text) - len(strings.TrimLeftFunc(text, unicode.IsSpace))
+		trail := len(strings.TrimRightFunc(text, unicode.IsSpace))
+		b.WriteString(text[:lead])
+		b.WriteString(styled)
+		b.WriteString(text[trail:])
+	}
+	return b.String()
+}
+
+// mergeColors merges the runs that only differ in the color
+func (t Text) mergeColors() Text {
+	merged := make(Text, 0, len(t))
+	for _, run := range t {
+		run.Style.Color = ""
+		if last := len(merged) - 1; last >= 0 && merged[last].Style == run.Style {
+			merged[last].Text += run.Text
+			continue
+		}
+		merged = append(merged, run)
+	}
+	return merged
+}
@@ -0,0 +1,61 @@
+package styles_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sergeii/swat4master/pkg/swat/styles"
+)
+
+func TestToANSI(t *testing.T) {
+	tests := []struct {
+		styled string
+		want   string
+	}{
+		{``, ``},
+		{`Swat4 Server`, `Swat4 Server`},
+		{`[c=FF8000]Swat4[\c] Server`, "\x1b[38;2;255;128;0mSwat4\x1b[0m Server"},
+		{`[b]Swat4 [u]Server`, "\x1b[1mSwat4 \x1b[0m\x1b[1;4mServer\x1b[0m"},
+		{`[c=0000FF][b][u]Swat4`, "\x1b[1;4;38;2;0;0;255mSwat4\x1b[0m"},
+		{"Swat4\x1b[2J Server", "Swat4[2J Server"},
+		{"[b]Swat4\x1b]0;pwned\x07\u009b31m\r\n", "\x1b[1mSwat4]0;pwned31m\x1b[0m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.styled, func(t *testing.T) {
+			got := styles.ToANSI(tt.styled)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestToMarkdown(t *testing.T) {
+	tests := []struct {
+		styled string
+		want   string
+	}{
+		{``, ``},
+		{`Swat4 Server`, `Swat4 Server`},
+		{`[c=FF0000]Swat4[c=00FF00] Server`, `Swat4 Server`},
+		{`[b]Swat4[\b] Server`, `**Swat4** Server`},
+		{`[b]Swat4 [\b]Server`, `**Swat4** Server`},
+		{`[b][c=FF0000]Swat4 [c=00FF00]Server[\b]`, `**Swat4 Server**`},
+		{`[u] Swat4 [b]Server`, ` __Swat4__ **__Server__**`},
+		{`[b] [\b]Swat4`, ` Swat4`},
+		{`[b]*Swat4_Server*`, `**\*Swat4\_Server\***`},
+		{"|MYT|`Serge` > ~all~", "\\|MYT\\|\\`Serge\\` \\> \\~all\\~"},
+		{"[b]@everyone[\\b] join", "**@\u200beveryone** join"},
+		{"<@1234> <@&5678> @here", "<@\u200b1234\\> <@\u200b&5678\\> @\u200bhere"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.styled, func(t *testing.T) {
+			got := styles.ToMarkdown(tt.styled)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestText_Plain(t *testing.T) {
+	text := styles.Parse(`[c=FF0000] [b]Swat4[\b] <Server> [c=FF`)
+	assert.Equal(t, ` Swat4 <Server> [c=FF`, text.Plain())
+}
@@ -1,30 +1,88 @@
+// Package styles deals with the markup the game uses to style the text, such as the server and player names.
+// The styled text is parsed into a sequence of runs, each of the runs being a piece of text
+// sharing the same style, which is then rendered as plain text, HTML, ANSI terminal colors or Markdown.
 package styles
 
-import (
-	"regexp"
-	"strings"
-)
+import "strings"
 
+// Style is the style a piece of text is displayed with
+type Style struct {
+	Color     string // RRGGBB in upper case, empty for the default color
+	Bold      bool
+	Underline bool
+}
+
+// IsPlain tells whether the text is displayed with no style
+func (s Style) IsPlain() bool {
+	return s == Style{}
+}
+
+// Run is a piece of text sharing the same style
+type Run struct {
+	Text  string
+	Style Style
+}
+
+// Text is the styled text as a sequence of runs
+type Text []Run
+
+// Parse parses the styled text.
+// A style stays in effect until it is closed with the matching tag or, for a color, until another color is set.
+// The tags are not required to be either closed or closed in order, as the game does not require that either.
+// A color tag with no valid color resets the color, just like the closing color tag.
+func Parse(text string) Text {
+	var style Style
+	runs := make(Text, 0, 1)
+	for _, tok := range tokenize(text) {
+		switch tok.kind {
+		case tokenText:
+			if last := len(runs) - 1; last >= 0 && runs[last].Style == style {
+				runs[last].Text += tok.value
+			} else {
+				runs = append(runs, Run{Text: tok.value, Style: style})
+			}
+		case tokenColor:
+			style.Color = tok.value
+		case tokenColorEnd:
+			style.Color = ""
+		case tokenBold:
+			style.Bold = true
+		case tokenBoldEnd:
+			style.Bold = false
+		case tokenUnderline:
+			style.Underline = true
+		case tokenUnderlineEnd:
+			style.Underline = false
+		}
+	}
+	return runs
+}
+
+// Clean strips the styled text of the markup, as well as of the surrounding whitespace.
+// The text is stripped repeatedly until no markup is left,
+// so that a tag hidden within another tag, e.g. [c=FF[b]0000], does not make it through.
 func Clean(text string) string {
-	r := regexp.MustCompile(`(?i)(\[[\\/]?[cub]\]|\[c[^\w][^\[\]]*?\])`)
-	for r.MatchString(text) {
-		text = r.ReplaceAllString(text, "")
+	for {
+		plain := Parse(text).Plain()
+		if plain == text {
+			break
+		}
+		text = plain
 	}
 	return strings.TrimSpace(text)
 }
 
+// ToHTML renders the styled text as HTML
 func ToHTML(text string) string {
-	// remove [b], [\b], [u], [\u] tags
-	text = regexp.
-		MustCompile(`(?i)\[(?:\\)?[bu]\]`).
-		ReplaceAllString(text, "")
-	// replace [c=XXXXXX] with spans
-	text = regexp.
-		MustCompile(`(?i)\[c[^\w]([a-f0-9]{6})\]([^\[]+)`).
-		ReplaceAllString(text, `<span style="color:#$1;">$2</span>`)
-	// remove unparsed and remaining [c=XXXXXX] and [\c] tags
-	text = regexp.
-		MustCompile(`(?i)\[(?:\\)?c(?:[^\w][^\[\]]*)?\]`).
-		ReplaceAllString(text, "")
-	return text
+	return Parse(text).HTML()
+}
+
+// ToANSI renders the styled text for a terminal
+func ToANSI(text string) string {
+	return Parse(text).ANSI()
+}
+
+// ToMarkdown renders the styled text as Markdown, as understood by Discord
+func ToMarkdown(text string) string {
+	return Parse(text).Markdown()
 }
@@ -71,11 +71,31 @@ func TestToHTML(t *testing.T) {
 		},
 		{
 			`[c=FF00FF][b][u]Swat4[\u][C=0000FF]Server[\c]`,
-			`<span style="color:#FF00FF;">Swat4</span><span style="color:#0000FF;">Server</span>`,
+			`<span style="color:#FF00FF;"><b><u>Swat4</u></b></span><span style="color:#0000FF;"><b>Server</b></span>`,
 		},
 		{
 			`[b][u][c=FF00FF]Swat4[C=0000FF]Server[\c][\u]`,
-			`<span style="color:#FF00FF;">Swat4</span><span style="color:#0000FF;">Server</span>`,
+			`<span style="color:#FF00FF;"><b><u>Swat4</u></b></span><span style="color:#0000FF;"><b><u>Server</u></b></span>`,
+		},
+		{
+			`[c=ff00ff]Swat4 [c=FF00]Server`,
+			`<span style="color:#FF00FF;">Swat4 </span>Server`,
+		},
+		{
+			`[c=FF00FF]<Swat4> & "Server"`,
+			`<span style="color:#FF00FF;">&lt;Swat4&gt; &amp; &#34;Server&#34;</span>`,
+		},
+		{
+			`[c=FF00FF]Swat4 [c=FF00FF`,
+			`<span style="color:#FF00FF;">Swat4 [c=FF00FF</span>`,
+		},
+		{
+			`[c=FF00FF]Swat4 [c=FF[b]00FF]`,
+			`<span style="color:#FF00FF;">Swat4 [c=FF</span><span style="color:#FF00FF;"><b>00FF]</b></span>`,
+		},
+		{
+			`[i]Swat4[\i] [/b]Server[/c]`,
+			`[i]Swat4[\i] Server`,
 		},
 	}
 	for _, tt := range tests {
@@ -85,3 +105,50 @@ func TestToHTML(t *testing.T) {
 		})
 	}
 }
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		styled string
+		want   styles.Text
+	}{
+		{``, styles.Text{}},
+		{`[c=FF0000][b][\b]`, styles.Text{}},
+		{`Serge`, styles.Text{{Text: "Serge"}}},
+		{
+			`[c=ff0000]Ser[b]ge[\c] [u]Mosquito`,
+			styles.Text{
+				{Text: "Ser", Style: styles.Style{Color: "FF0000"}},
+				{Text: "ge", Style: styles.Style{Color: "FF0000", Bold: true}},
+				{Text: " ", Style: styles.Style{Bold: true}},
+				{Text: "Mosquito", Style: styles.Style{Bold: true, Underline: true}},
+			},
+		},
+		{
+			`[c=FF0000]Ser[c=FF0000]ge[b][\b]`,
+			styles.Text{{Text: "Serge", Style: styles.Style{Color: "FF0000"}}},
+		},
+		{
+			`[c=FF0000]Ser[c]ge[c=00FF00]Mos[c:0000FF]qui[c=XYZ]to`,
+			styles.Text{
+				{Text: "Ser", Style: styles.Style{Color: "FF0000"}},
+				{Text: "ge"},
+				{Text: "Mos", Style: styles.Style{Color: "00FF00"}},
+				{Text: "qui", Style: styles.Style{Color: "0000FF"}},
+				{Text: "to"},
+			},
+		},
+		{
+			`[b]Serge[cat] [[u]]`,
+			styles.Text{
+				{Text: "Serge[cat] [", Style: styles.Style{Bold: true}},
+				{Text: "]", Style: styles.Style{Bold: true, Underline: true}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.styled, func(t *testing.T) {
+			got := styles.Parse(tt.styled)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package styles
+
+import "strings"
+
+type tokenKind int
+
+const (
+	tokenText tokenKind = iota
+	tokenColor
+	tokenColorEnd
+	tokenBold
+	tokenBoldEnd
+	tokenUnderline
+	tokenUnderlineEnd
+)
+
+type token struct {
+	kind  tokenKind
+	value string // the text of a text token or the RRGGBB color of a color token
+}
+
+// tokenize splits the styled text into the text and the markup tags.
+// A tag is enclosed in square brackets and is recognized regardless of the case:
+// [b], [u] and [c=RRGGBB] open a style, [\b], [\u] and [\c] close it (a forward slash works too).
+// A color tag may have any non-word character in place of the equal sign.
+// Anything in square brackets that is not a tag, including an unterminated tag, is left as text.
+func tokenize(text string) []token {
+	tokens := make([]token, 0, 4)
+	start := 0
+	for i := 0; i < len(text); i++ {
+		if text[i] != '[' {
+			continue
+		}
+		tok, size, ok := scanTag(text[i:])
+		if !ok {
+			continue
+		}
+		if i > start {
+			tokens = append(tokens, token{kind: tokenText, value: text[start:i]})
+		}
+		tokens = append(tokens, tok)
+		i += size - 1
+		start = i + 1
+	}
+	if start < len(text) {
+		tokens = append(tokens, token{kind: tokenText, value: text[start:]})
+	}
+	return tokens
+}
+
+// scanTag recognizes the tag at the beginning of the text, returning the tag and its length
+func scanTag(text string) (token, int, bool) {
+	end := strings.IndexAny(text[1:], "[]")
+	if end == -1 || text[1+end] != ']' {
+		return token{}, 0, false
+	}
+	size := end + 2
+	inner := strings.ToLower(text[1 : size-1])
+
+	switch inner {
+	case "b":
+		return token{kind: tokenBold}, size, true
+	case `\b`, "/b":
+		return token{kind: tokenBoldEnd}, size, true
+	case "u":
+		return token{kind: tokenUnderline}, size, true
+	case `\u`, "/u":
+		return token{kind: tokenUnderlineEnd}, size, true
+	case "c":
+		return token{kind: tokenColor}, size, true
+	case `\c`, "/c":
+		return token{kind: tokenColorEnd}, size, true
+	}
+
+	if len(inner) < 2 || inner[0] != 'c' || isWordChar(inner[1]) {
+		return token{}, 0, false
+	}
+	color := strings.ToUpper(inner[2:])
+	if !isHexColor(color) {
+		color = ""
+	}
+	return token{kind: tokenColor, value: color}, size, true
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isHexColor(color string) bool {
+	if len(color) != 6 {
+		return false
+	}
+	for i := range len(color) {
+		c := color[i]
+		if (c < '0' || c > '9') && (c < 'A' || c > 'F') {
+			return false
+		}
+	}
+	return true
+}
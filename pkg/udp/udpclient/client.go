@@ -0,0 +1,84 @@
+package udpclient
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"os"
+	"time"
+)
+
+// Session is a single request-response exchange with a remote address.
+// Receive blocks until a packet from the remote address arrives,
+// or fails with os.ErrDeadlineExceeded once the session's context is done or its timeout has passed.
+type Session interface {
+	Send(payload []byte) error
+	Receive() ([]byte, error)
+	Close()
+}
+
+// Dialer opens sessions with remote addresses
+type Dialer interface {
+	Open(ctx context.Context, addr netip.AddrPort, timeout time.Duration) (Session, error)
+}
+
+const bufferSize = 2048
+
+// Direct dials a new socket for every session
+type Direct struct{}
+
+func (Direct) Open(ctx context.Context, addr netip.AddrPort, timeout time.Duration) (Session, error) {
+	conn, err := net.DialUDP("udp", nil, net.UDPAddrFromAddrPort(addr))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	sess := &directSession{
+		conn:    conn,
+		cancel:  cancel,
+		closing: make(chan struct{}),
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.SetReadDeadline(time.Now()) //nolint: errcheck
+		case <-sess.closing:
+		}
+	}()
+
+	return sess, nil
+}
+
+type directSession struct {
+	conn    *net.UDPConn
+	cancel  context.CancelFunc
+	closing chan struct{}
+}
+
+func (s *directSession) Send(payload []byte) error {
+	_, err := s.conn.Write(payload)
+	return err
+}
+
+func (s *directSession) Receive() ([]byte, error) {
+	buffer := make([]byte, bufferSize)
+	n, err := s.conn.Read(buffer)
+	if err != nil {
+		return nil, err
+	}
+	return buffer[:n], nil
+}
+
+func (s *directSession) Close() {
+	close(s.closing)
+	s.cancel()
+	s.conn.Close()
+}
+
+// expired is the error a session fails with once it's out of time,
+// so that the callers can handle the timeouts of both kinds of sessions the same way
+func expired() error {
+	return &net.OpError{Op: "read", Net: "udp", Err: os.ErrDeadlineExceeded}
+}
@@ -0,0 +1,220 @@
+package udpclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var ErrMuxClosed = errors.New("mux is closed")
+
+// pendingPackets is the number of packets a session may have unread,
+// the excess packets are dropped.
+const pendingPackets = 16
+
+// readBackoff is the pause after a failed read, so that a socket failing persistently does not spin
+const readBackoff = time.Millisecond * 10
+
+// Mux sends the sessions over a few long-lived sockets.
+// The replies are routed to the pending sessions by their source address,
+// so a socket may only have one session per remote address at a time.
+// Once a session is closed, its remote address lingers on the socket for as long as the session's timeout,
+// with the replies from the address dropped meanwhile, so that a late reply to an earlier session
+// is never mistaken for a reply to the next session with the same address.
+// The next session is sent over another socket instead, the socket's port effectively tagging the session.
+// When every socket already has a session with the remote address, the session is dialed directly instead.
+//
+// Unlike with the directly dialed sockets, the ICMP errors are not reported to the sessions,
+// so a query to a closed port would time out rather than fail early.
+type Mux struct {
+	sockets  []*muxSocket
+	next     atomic.Uint64
+	closed   atomic.Bool
+	fallback Direct
+}
+
+type muxSocket struct {
+	conn       *net.UDPConn
+	readErrors atomic.Uint64
+	mu         sync.Mutex
+	pending    map[netip.AddrPort]*muxSession
+	lingering  map[netip.AddrPort]time.Time // the time until which the closed session's address is held
+	nextSweep  time.Time
+}
+
+// NewMux listens on the given number of sockets and starts routing the replies
+func NewMux(sockets int) (*Mux, error) {
+	mux := &Mux{
+		sockets: make([]*muxSocket, 0, sockets),
+	}
+	for range max(sockets, 1) {
+		conn, err := net.ListenUDP("udp4", nil)
+		if err != nil {
+			mux.Close()
+			return nil, err
+		}
+		sock := &muxSocket{
+			conn:      conn,
+			pending:   make(map[netip.AddrPort]*muxSession),
+			lingering: make(map[netip.AddrPort]time.Time),
+		}
+		mux.sockets = append(mux.sockets, sock)
+		go sock.route()
+	}
+	return mux, nil
+}
+
+// Open registers a session with the remote address on one of the sockets
+func (m *Mux) Open(ctx context.Context, addr netip.AddrPort, timeout time.Duration) (Session, error) {
+	if m.closed.Load() {
+		return nil, ErrMuxClosed
+	}
+
+	addr = unmap(addr)
+
+	// start with a different socket every time, so that the sessions are spread evenly
+	start := m.next.Add(1)
+	for i := range len(m.sockets) {
+		sock := m.sockets[(start+uint64(i))%uint64(len(m.sockets))]
+		if sess, ok := sock.register(ctx, addr, timeout); ok {
+			return sess, nil
+		}
+	}
+
+	return m.fallback.Open(ctx, addr, timeout)
+}
+
+// ReadErrors returns the number of the failed reads on the sockets
+func (m *Mux) ReadErrors() uint64 {
+	var total uint64
+	for _, sock := range m.sockets {
+		total += sock.readErrors.Load()
+	}
+	return total
+}
+
+// Close closes the sockets, the pending sessions time out
+func (m *Mux) Close() {
+	m.closed.Store(true)
+	for _, sock := range m.sockets {
+		sock.conn.Close()
+	}
+}
+
+func (s *muxSocket) register(
+	ctx context.Context,
+	addr netip.AddrPort,
+	timeout time.Duration,
+) (*muxSession, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, taken := s.pending[addr]; taken {
+		return nil, false
+	}
+	if until, ok := s.lingering[addr]; ok {
+		if time.Now().Before(until) {
+			return nil, false
+		}
+		delete(s.lingering, addr)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	sess := &muxSession{
+		socket:  s,
+		addr:    addr,
+		timeout: timeout,
+		ctx:     ctx,
+		cancel:  cancel,
+		packets: make(chan []byte, pendingPackets),
+	}
+	s.pending[addr] = sess
+	return sess, true
+}
+
+func (s *muxSocket) unregister(sess *muxSession) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pending[sess.addr] != sess {
+		return
+	}
+	delete(s.pending, sess.addr)
+
+	now := time.Now()
+	s.lingering[sess.addr] = now.Add(sess.timeout)
+	// the addresses that are never dialed again are swept every once in a while
+	if now.After(s.nextSweep) {
+		for addr, until := range s.lingering {
+			if now.After(until) {
+				delete(s.lingering, addr)
+			}
+		}
+		s.nextSweep = now.Add(sess.timeout)
+	}
+}
+
+func (s *muxSocket) route() {
+	buffer := make([]byte, bufferSize)
+	for {
+		n, from, err := s.conn.ReadFromUDPAddrPort(buffer)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			s.readErrors.Add(1)
+			time.Sleep(readBackoff)
+			continue
+		}
+
+		s.mu.Lock()
+		sess, ok := s.pending[unmap(from)]
+		s.mu.Unlock()
+		if !ok {
+			continue
+		}
+
+		packet := make([]byte, n)
+		copy(packet, buffer[:n])
+		select {
+		case sess.packets <- packet:
+		default:
+		}
+	}
+}
+
+type muxSession struct {
+	socket  *muxSocket
+	addr    netip.AddrPort
+	timeout time.Duration
+	ctx     context.Context //nolint: containedctx
+	cancel  context.CancelFunc
+	packets chan []byte
+}
+
+func (s *muxSession) Send(payload []byte) error {
+	if s.ctx.Err() != nil {
+		return expired()
+	}
+	_, err := s.socket.conn.WriteToUDPAddrPort(payload, s.addr)
+	return err
+}
+
+func (s *muxSession) Receive() ([]byte, error) {
+	select {
+	case packet := <-s.packets:
+		return packet, nil
+	case <-s.ctx.Done():
+		return nil, expired()
+	}
+}
+
+func (s *muxSession) Close() {
+	s.socket.unregister(s)
+	s.cancel()
+}
+
+func unmap(addr netip.AddrPort) netip.AddrPort {
+	return netip.AddrPortFrom(addr.Addr().Unmap(), addr.Port())
+}
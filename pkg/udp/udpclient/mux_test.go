@@ -0,0 +1,204 @@
+package udpclient_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/pkg/udp/udpclient"
+	"github.com/sergeii/swat4master/pkg/udp/udpserver"
+)
+
+func runServer(
+	t *testing.T,
+	handler func(ctx context.Context, conn *net.UDPConn, addr *net.UDPAddr, req []byte),
+) *udpserver.Server {
+	ready := make(chan struct{})
+	server, err := udpserver.New(
+		"localhost:0",
+		udpserver.HandleFunc(handler),
+		udpserver.WithReadySignal(func() {
+			close(ready)
+		}),
+	)
+	require.NoError(t, err)
+	go func() {
+		server.Listen() //nolint: errcheck
+	}()
+	<-ready
+	t.Cleanup(func() {
+		server.Stop() //nolint: errcheck
+	})
+	return server
+}
+
+func runEchoServer(t *testing.T, prefix string) *udpserver.Server {
+	return runServer(t, func(_ context.Context, conn *net.UDPConn, addr *net.UDPAddr, req []byte) {
+		conn.WriteToUDP(append([]byte(prefix), req...), addr) //nolint: errcheck
+	})
+}
+
+func exchange(
+	dialer udpclient.Dialer,
+	server *udpserver.Server,
+	payload string,
+	timeout time.Duration,
+) (string, error) {
+	sess, err := dialer.Open(context.TODO(), server.LocalAddrPort(), timeout)
+	if err != nil {
+		return "", err
+	}
+	defer sess.Close()
+	if err := sess.Send([]byte(payload)); err != nil {
+		return "", err
+	}
+	resp, err := sess.Receive()
+	return string(resp), err
+}
+
+func TestMux_RoutesRepliesBySource(t *testing.T) {
+	mux, err := udpclient.NewMux(1)
+	require.NoError(t, err)
+	defer mux.Close()
+
+	servers := make([]*udpserver.Server, 0, 10)
+	for i := range 10 {
+		servers = append(servers, runEchoServer(t, fmt.Sprintf("%d:", i)))
+	}
+
+	wg := &sync.WaitGroup{}
+	for i, server := range servers {
+		wg.Go(func() {
+			resp, err := exchange(mux, server, "hello", time.Millisecond*100)
+			assert.NoError(t, err)
+			assert.Equal(t, fmt.Sprintf("%d:hello", i), resp)
+		})
+	}
+	wg.Wait()
+	assert.Zero(t, mux.ReadErrors())
+}
+
+func TestMux_ConcurrentSessionsWithSameAddress(t *testing.T) {
+	// the server replies after a while, so that the sessions overlap
+	server := runServer(t, func(_ context.Context, conn *net.UDPConn, addr *net.UDPAddr, req []byte) {
+		<-time.After(time.Millisecond * 20)
+		conn.WriteToUDP(req, addr) //nolint: errcheck
+	})
+
+	for _, sockets := range []int{1, 2} {
+		t.Run(fmt.Sprintf("%d sockets", sockets), func(t *testing.T) {
+			mux, err := udpclient.NewMux(sockets)
+			require.NoError(t, err)
+			defer mux.Close()
+
+			wg := &sync.WaitGroup{}
+			for i := range 3 {
+				wg.Go(func() {
+					payload := fmt.Sprintf("hello %d", i)
+					resp, err := exchange(mux, server, payload, time.Millisecond*100)
+					assert.NoError(t, err)
+					assert.Equal(t, payload, resp)
+				})
+			}
+			wg.Wait()
+		})
+	}
+}
+
+func TestMux_StaleRepliesAreDropped(t *testing.T) {
+	// the server is slow to reply to the first query, so the reply arrives after the query has timed out
+	server := runServer(t, func(_ context.Context, conn *net.UDPConn, addr *net.UDPAddr, req []byte) {
+		delay := time.Millisecond * 80
+		if string(req) == "first" {
+			delay = time.Millisecond * 40
+		}
+		<-time.After(delay)
+		conn.WriteToUDP(req, addr) //nolint: errcheck
+	})
+
+	for _, sockets := range []int{1, 2} {
+		t.Run(fmt.Sprintf("%d sockets", sockets), func(t *testing.T) {
+			mux, err := udpclient.NewMux(sockets)
+			require.NoError(t, err)
+			defer mux.Close()
+
+			_, err = exchange(mux, server, "first", time.Millisecond*20)
+			require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+
+			// the late reply to the first query arrives while the second query is pending
+			resp, err := exchange(mux, server, "second", time.Millisecond*200)
+			require.NoError(t, err)
+			assert.Equal(t, "second", resp)
+		})
+	}
+}
+
+func TestMux_Timeout(t *testing.T) {
+	server := runServer(t, func(context.Context, *net.UDPConn, *net.UDPAddr, []byte) {})
+
+	mux, err := udpclient.NewMux(1)
+	require.NoError(t, err)
+	defer mux.Close()
+
+	started := time.Now()
+	_, err = exchange(mux, server, "hello", time.Millisecond*50)
+	assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	assert.GreaterOrEqual(t, time.Since(started), time.Millisecond*50)
+
+	// the address is free for the next session once the previous one has timed out
+	echo := runEchoServer(t, "")
+	resp, err := exchange(mux, echo, "hello", time.Millisecond*50)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", resp)
+}
+
+func TestMux_ContextCancelled(t *testing.T) {
+	server := runServer(t, func(context.Context, *net.UDPConn, *net.UDPAddr, []byte) {})
+
+	mux, err := udpclient.NewMux(1)
+	require.NoError(t, err)
+	defer mux.Close()
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	sess, err := mux.Open(ctx, server.LocalAddrPort(), time.Second)
+	require.NoError(t, err)
+	defer sess.Close()
+
+	go func() {
+		<-time.After(time.Millisecond * 20)
+		cancel()
+	}()
+
+	_, err = sess.Receive()
+	assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+}
+
+func TestMux_Closed(t *testing.T) {
+	server := runEchoServer(t, "")
+
+	mux, err := udpclient.NewMux(2)
+	require.NoError(t, err)
+	mux.Close()
+
+	_, err = mux.Open(context.TODO(), server.LocalAddrPort(), time.Millisecond*50)
+	assert.ErrorIs(t, err, udpclient.ErrMuxClosed)
+}
+
+func TestDirect_Exchange(t *testing.T) {
+	server := runEchoServer(t, "echo:")
+
+	resp, err := exchange(udpclient.Direct{}, server, "hello", time.Millisecond*50)
+	require.NoError(t, err)
+	assert.Equal(t, "echo:hello", resp)
+
+	silent := runServer(t, func(context.Context, *net.UDPConn, *net.UDPAddr, []byte) {})
+	_, err = exchange(udpclient.Direct{}, silent, "hello", time.Millisecond*50)
+	assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+}
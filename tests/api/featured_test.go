@@ -0,0 +1,266 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/internal/core/entities/featured"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+	"github.com/sergeii/swat4master/internal/rest/api"
+	"github.com/sergeii/swat4master/internal/testutils"
+	"github.com/sergeii/swat4master/internal/testutils/factories/serverfactory"
+)
+
+type featuredSchema struct {
+	Address  string     `json:"address"`
+	IP       string     `json:"ip"`
+	Port     int        `json:"port"`
+	Priority int        `json:"priority"`
+	Since    *time.Time `json:"since"`
+	Until    *time.Time `json:"until"`
+}
+
+type featuredListedSchema struct {
+	Address  string `json:"address"`
+	Hostname string `json:"hostname"`
+	Featured bool   `json:"featured"`
+}
+
+func TestAPI_Featured_Auth(t *testing.T) {
+	tests := []struct {
+		name       string
+		adminToken string
+		opts       []testutils.TestRequestOpt
+		wantStatus int
+	}{
+		{
+			name:       "valid token",
+			adminToken: testutils.AdminToken,
+			opts:       []testutils.TestRequestOpt{testutils.WithBearerToken(testutils.AdminToken)},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "no token",
+			adminToken: testutils.AdminToken,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "invalid token",
+			adminToken: testutils.AdminToken,
+			opts:       []testutils.TestRequestOpt{testutils.WithBearerToken("foo")},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "admin api is disabled",
+			adminToken: "",
+			opts:       []testutils.TestRequestOpt{testutils.WithBearerToken("")},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts, cancel := testutils.PrepareTestServer(
+				t,
+				fx.Decorate(func(opts api.Opts) api.Opts {
+					opts.AdminToken = tt.adminToken
+					return opts
+				}),
+			)
+			defer cancel()
+
+			for _, req := range []struct {
+				method string
+				path   string
+			}{
+				{http.MethodGet, "/api/admin/featured"},
+				{http.MethodPut, "/api/admin/featured/1.1.1.1:10480"},
+				{http.MethodDelete, "/api/admin/featured/1.1.1.1:10480"},
+			} {
+				resp := testutils.DoTestRequest(ts, req.method, req.path, bytes.NewReader([]byte(`{}`)), tt.opts...)
+				if tt.wantStatus == http.StatusOK {
+					assert.NotContains(t, []int{http.StatusUnauthorized, http.StatusForbidden}, resp.StatusCode)
+				} else {
+					assert.Equal(t, tt.wantStatus, resp.StatusCode)
+				}
+			}
+		})
+	}
+}
+
+func TestAPI_Featured_FeatureAndUnfeature(t *testing.T) {
+	ctx := context.TODO()
+	ts, repos, cancel := testutils.PrepareTestServerWithRepos(t)
+	defer cancel()
+
+	auth := testutils.WithBearerToken(testutils.AdminToken)
+	until := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var item featuredSchema
+	payload := []byte(`{"priority": 5, "until": "2030-01-01T00:00:00Z"}`)
+	resp := testutils.DoTestRequest(
+		ts, http.MethodPut, "/api/admin/featured/1.1.1.1:10480", bytes.NewReader(payload),
+		auth, testutils.MustBindJSON(&item),
+	)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "1.1.1.1:10480", item.Address)
+	assert.Equal(t, "1.1.1.1", item.IP)
+	assert.Equal(t, 10480, item.Port)
+	assert.Equal(t, 5, item.Priority)
+	assert.Nil(t, item.Since)
+	require.NotNil(t, item.Until)
+	assert.True(t, until.Equal(*item.Until))
+
+	stored, err := repos.Featured.Get(ctx, addr.MustNewFromDotted("1.1.1.1", 10480))
+	require.NoError(t, err)
+	assert.Equal(t, 5, stored.Priority)
+
+	// featuring the server again updates it
+	resp = testutils.DoTestRequest(
+		ts, http.MethodPut, "/api/admin/featured/1.1.1.1:10480", bytes.NewReader([]byte(`{"priority": 1}`)),
+		auth, testutils.MustBindJSON(&item),
+	)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 1, item.Priority)
+	assert.Nil(t, item.Until)
+
+	resp = testutils.DoTestRequest(
+		ts, http.MethodPut, "/api/admin/featured/2.2.2.2:10580", bytes.NewReader([]byte(`{"priority": 0}`)),
+		auth, testutils.MustBindJSON(&item),
+	)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var items []featuredSchema
+	resp = testutils.DoTestRequest(
+		ts, http.MethodGet, "/api/admin/featured", nil,
+		auth, testutils.MustBindJSON(&items),
+	)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, items, 2)
+	assert.Equal(t, "2.2.2.2:10580", items[0].Address)
+	assert.Equal(t, "1.1.1.1:10480", items[1].Address)
+
+	resp = testutils.DoTestRequest(
+		ts, http.MethodDelete, "/api/admin/featured/1.1.1.1:10480", nil,
+		auth, testutils.MustHaveNoBody(),
+	)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	_, err = repos.Featured.Get(ctx, addr.MustNewFromDotted("1.1.1.1", 10480))
+	require.ErrorIs(t, err, repositories.ErrFeaturedNotFound)
+
+	resp = testutils.DoTestRequest(ts, http.MethodDelete, "/api/admin/featured/1.1.1.1:10480", nil, auth)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestAPI_Featured_InvalidRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		payload string
+		wantErr string
+	}{
+		{"invalid address", "1.1.1.1", `{"priority": 1}`, "Invalid server address"},
+		{"private address", "127.0.0.1:10480", `{"priority": 1}`, "Invalid server address"},
+		{"invalid payload", "1.1.1.1:10480", `{"priority": "foo"}`, "Invalid featuring parameters"},
+		{"invalid time", "1.1.1.1:10480", `{"since": "tomorrow"}`, "Invalid featuring parameters"},
+		{
+			"window ends before it starts",
+			"1.1.1.1:10480",
+			`{"since": "2030-01-02T00:00:00Z", "until": "2030-01-01T00:00:00Z"}`,
+			"Invalid featuring window",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TODO()
+			ts, repos, cancel := testutils.PrepareTestServerWithRepos(t)
+			defer cancel()
+
+			var respErr serverAddErrorSchema
+			resp := testutils.DoTestRequest(
+				ts, http.MethodPut, "/api/admin/featured/"+tt.address, bytes.NewReader([]byte(tt.payload)),
+				testutils.WithBearerToken(testutils.AdminToken), testutils.MustBindJSON(&respErr),
+			)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			assert.Equal(t, tt.wantErr, respErr.Error)
+
+			items, err := repos.Featured.List(ctx)
+			require.NoError(t, err)
+			assert.Empty(t, items)
+		})
+	}
+}
+
+func TestAPI_ListServers_FeaturedFirst(t *testing.T) {
+	ctx := context.TODO()
+	ts, repos, cancel := testutils.PrepareTestServerWithRepos(t)
+	defer cancel()
+
+	for _, params := range []struct {
+		ip       string
+		hostname string
+		gametype string
+	}{
+		{"1.1.1.1", "Swat4 Server", "VIP Escort"},
+		{"2.2.2.2", "Featured Server", "VIP Escort"},
+		{"3.3.3.3", "Another Server", "VIP Escort"},
+		{"4.4.4.4", "Top Featured Server", "VIP Escort"},
+		{"5.5.5.5", "Featured CO-OP Server", "CO-OP"},
+		{"6.6.6.6", "Expired Featured Server", "VIP Escort"},
+	} {
+		serverfactory.Create(
+			ctx,
+			repos.Servers,
+			serverfactory.WithAddress(params.ip, 10480),
+			serverfactory.WithDiscoveryStatus(ds.Master|ds.Info),
+			serverfactory.WithRefreshedAt(time.Now()),
+			serverfactory.WithInfo(map[string]string{
+				"hostname":    params.hostname,
+				"hostport":    "10480",
+				"gamever":     "1.1",
+				"gamevariant": "SWAT 4",
+				"gametype":    params.gametype,
+			}),
+		)
+	}
+
+	for _, item := range []featured.Featured{
+		featured.New(addr.MustNewFromDotted("2.2.2.2", 10480), 10, featured.NC, featured.NC),
+		featured.New(addr.MustNewFromDotted("4.4.4.4", 10480), 1, time.Now().Add(-time.Hour), featured.NC),
+		featured.New(addr.MustNewFromDotted("5.5.5.5", 10480), 0, featured.NC, featured.NC),
+		featured.New(addr.MustNewFromDotted("6.6.6.6", 10480), 0, featured.NC, time.Now().Add(-time.Hour)),
+		// featured server that is not listed
+		featured.New(addr.MustNewFromDotted("7.7.7.7", 10480), 0, featured.NC, featured.NC),
+	} {
+		testutils.MustNoErr(repos.Featured.Add(ctx, item))
+	}
+
+	var servers []featuredListedSchema
+	resp := testutils.DoTestRequest(
+		ts, http.MethodGet, "/api/servers?gametype=VIP+Escort", nil,
+		testutils.MustBindJSON(&servers),
+	)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, servers, 5)
+
+	assert.Equal(t, "Top Featured Server", servers[0].Hostname)
+	assert.True(t, servers[0].Featured)
+	assert.Equal(t, "Featured Server", servers[1].Hostname)
+	assert.True(t, servers[1].Featured)
+
+	notFeatured := make([]string, 0, 3)
+	for _, svr := range servers[2:] {
+		assert.False(t, svr.Featured)
+		notFeatured = append(notFeatured, svr.Hostname)
+	}
+	assert.ElementsMatch(t, []string{"Swat4 Server", "Another Server", "Expired Featured Server"}, notFeatured)
+}
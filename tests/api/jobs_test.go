@@ -0,0 +1,118 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/jobrun"
+	"github.com/sergeii/swat4master/internal/testutils"
+)
+
+type jobRunSchema struct {
+	Job         string    `json:"job"`
+	ScheduledAt time.Time `json:"scheduled_at"`
+	StartedAt   time.Time `json:"started_at"`
+	FinishedAt  time.Time `json:"finished_at"`
+	Duration    int64     `json:"duration"`
+	Outcome     string    `json:"outcome"`
+	Error       *string   `json:"error"`
+}
+
+func TestAPI_Jobs_Auth(t *testing.T) {
+	ts, cancel := testutils.PrepareTestServer(t)
+	defer cancel()
+
+	for _, path := range []string{"/api/admin/jobs", "/api/admin/jobs/refresher"} {
+		resp := testutils.DoTestRequest(ts, http.MethodGet, path, nil)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	}
+}
+
+func TestAPI_Jobs_List(t *testing.T) {
+	ctx := context.TODO()
+	ts, repos, cancel := testutils.PrepareTestServerWithRepos(t)
+	defer cancel()
+
+	auth := testutils.WithBearerToken(testutils.AdminToken)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	var items []jobRunSchema
+	resp := testutils.DoTestRequest(ts, http.MethodGet, "/api/admin/jobs", nil, auth, testutils.MustBindJSON(&items))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, items)
+
+	for i := range 3 {
+		at := now.Add(time.Minute * time.Duration(i))
+		testutils.MustNoErr(repos.JobRuns.Record(ctx, jobrun.New("reviver", at, at, at.Add(time.Second), nil)))
+	}
+	failedAt := now.Add(time.Second * 5)
+	testutils.MustNoErr(repos.JobRuns.Record(
+		ctx,
+		jobrun.New("cleaner", failedAt, failedAt, failedAt.Add(time.Millisecond*250), errors.New("boom")),
+	))
+
+	resp = testutils.DoTestRequest(ts, http.MethodGet, "/api/admin/jobs", nil, auth, testutils.MustBindJSON(&items))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, items, 2)
+
+	assert.Equal(t, "cleaner", items[0].Job)
+	assert.Equal(t, "failure", items[0].Outcome)
+	require.NotNil(t, items[0].Error)
+	assert.Equal(t, "boom", *items[0].Error)
+	assert.Equal(t, int64(250), items[0].Duration)
+	assert.True(t, failedAt.Equal(items[0].ScheduledAt))
+
+	assert.Equal(t, "reviver", items[1].Job)
+	assert.Equal(t, "success", items[1].Outcome)
+	assert.Nil(t, items[1].Error)
+	assert.Equal(t, int64(1000), items[1].Duration)
+	assert.True(t, now.Add(time.Minute*2).Equal(items[1].ScheduledAt))
+	assert.True(t, now.Add(time.Minute*2).Equal(items[1].StartedAt))
+	assert.True(t, now.Add(time.Minute*2+time.Second).Equal(items[1].FinishedAt))
+}
+
+func TestAPI_Jobs_Runs(t *testing.T) {
+	ctx := context.TODO()
+	ts, repos, cancel := testutils.PrepareTestServerWithRepos(t)
+	defer cancel()
+
+	auth := testutils.WithBearerToken(testutils.AdminToken)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := range 30 {
+		at := now.Add(time.Minute * time.Duration(i))
+		testutils.MustNoErr(repos.JobRuns.Record(ctx, jobrun.New("refresher", at, at, at, nil)))
+	}
+
+	var items []jobRunSchema
+	resp := testutils.DoTestRequest(
+		ts, http.MethodGet, "/api/admin/jobs/refresher", nil,
+		auth, testutils.MustBindJSON(&items),
+	)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, items, 20)
+	assert.True(t, now.Add(time.Minute*29).Equal(items[0].ScheduledAt))
+	assert.True(t, now.Add(time.Minute*10).Equal(items[19].ScheduledAt))
+
+	resp = testutils.DoTestRequest(
+		ts, http.MethodGet, "/api/admin/jobs/refresher?limit=5", nil,
+		auth, testutils.MustBindJSON(&items),
+	)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, items, 5)
+	assert.Equal(t, "refresher", items[4].Job)
+
+	for _, limit := range []string{"-1", "101", "foo"} {
+		resp = testutils.DoTestRequest(ts, http.MethodGet, "/api/admin/jobs/refresher?limit="+limit, nil, auth)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, limit)
+	}
+
+	resp = testutils.DoTestRequest(ts, http.MethodGet, "/api/admin/jobs/unknown", nil, auth)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
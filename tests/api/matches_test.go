@@ -0,0 +1,167 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/details"
+	"github.com/sergeii/swat4master/internal/core/entities/match"
+	"github.com/sergeii/swat4master/internal/testutils"
+)
+
+type matchSchema struct {
+	ID             string    `json:"id"`
+	Address        string    `json:"address"`
+	Hostname       string    `json:"hostname"`
+	HostnamePlain  string    `json:"hostname_plain"`
+	GameType       string    `json:"gametype"`
+	GameTypeSlug   string    `json:"gametype_slug"`
+	MapName        string    `json:"mapname"`
+	MapNameSlug    string    `json:"mapname_slug"`
+	PlayerNum      int       `json:"player_num"`
+	RoundNum       int       `json:"round_num"`
+	RoundMax       int       `json:"round_max"`
+	SwatScore      int       `json:"score_swat"`
+	SuspectsScore  int       `json:"score_sus"`
+	TocReports     string    `json:"coop_reports"`
+	WeaponsSecured string    `json:"coop_weapons"`
+	Winner         *string   `json:"winner"`
+	EndedAt        time.Time `json:"ended_at"`
+}
+
+type matchDetailSchema struct {
+	Info    matchSchema `json:"info"`
+	Players []struct {
+		Name  string `json:"name"`
+		Score int    `json:"score"`
+		Kills int    `json:"kills"`
+	} `json:"players"`
+	Objectives []struct {
+		Name   string `json:"name"`
+		Status string `json:"status"`
+	} `json:"objectives"`
+}
+
+func TestAPI_Matches_ListAndView(t *testing.T) {
+	ctx := context.TODO()
+	ts, repos, cancel := testutils.PrepareTestServerWithRepos(t)
+	defer cancel()
+
+	svrAddr := addr.MustNewFromDotted("1.1.1.1", 10480)
+	now := time.Now().Truncate(time.Second)
+
+	vip := match.Match{
+		ID:   "vip",
+		Addr: svrAddr,
+		Info: details.Info{
+			Hostname:      "[c=FF0000]Swat4[\\c] Server",
+			MapName:       "A-Bomb Nightclub",
+			GameType:      "VIP Escort",
+			Round:         2,
+			NumRounds:     5,
+			SwatScore:     50,
+			SuspectsScore: 20,
+		},
+		Players: []details.Player{{Name: "Serge", Score: 15, Kills: 3}, {Name: "Mosquito", Score: 5}},
+		Winner:  match.WinnerSwat,
+		EndedAt: now.Add(-time.Hour),
+	}
+	coop := match.Match{
+		ID:   "coop",
+		Addr: svrAddr,
+		Info: details.Info{
+			Hostname:       "Swat4 Server",
+			MapName:        "Fairfax Residence",
+			GameType:       "CO-OP",
+			TocReports:     "12/13",
+			WeaponsSecured: "3/4",
+		},
+		Players:    []details.Player{{Name: "Serge"}},
+		Objectives: []details.Objective{{Name: "obj_Rescue_All_Hostages", Status: details.ObjCompleted}},
+		EndedAt:    now.Add(-time.Minute),
+	}
+	testutils.MustNoErr(repos.Matches.Add(ctx, vip))
+	testutils.MustNoErr(repos.Matches.Add(ctx, coop))
+
+	var items []matchSchema
+	resp := testutils.DoTestRequest(
+		ts, http.MethodGet, "/api/servers/1.1.1.1:10480/matches", nil,
+		testutils.MustBindJSON(&items),
+	)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, items, 2)
+
+	assert.Equal(t, "coop", items[0].ID)
+	assert.Equal(t, "co-op", items[0].GameTypeSlug)
+	assert.Equal(t, "12/13", items[0].TocReports)
+	assert.Equal(t, "3/4", items[0].WeaponsSecured)
+	assert.Nil(t, items[0].Winner)
+
+	assert.Equal(t, "vip", items[1].ID)
+	assert.Equal(t, "1.1.1.1:10480", items[1].Address)
+	assert.Equal(t, "Swat4 Server", items[1].HostnamePlain)
+	assert.Equal(t, "a-bomb-nightclub", items[1].MapNameSlug)
+	assert.Equal(t, 2, items[1].PlayerNum)
+	assert.Equal(t, 2, items[1].RoundNum)
+	assert.Equal(t, 5, items[1].RoundMax)
+	assert.Equal(t, 50, items[1].SwatScore)
+	assert.Equal(t, 20, items[1].SuspectsScore)
+	require.NotNil(t, items[1].Winner)
+	assert.Equal(t, "swat", *items[1].Winner)
+	assert.True(t, now.Add(-time.Hour).Equal(items[1].EndedAt))
+
+	resp = testutils.DoTestRequest(
+		ts, http.MethodGet, "/api/servers/1.1.1.1:10480/matches?limit=1", nil,
+		testutils.MustBindJSON(&items),
+	)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, items, 1)
+	assert.Equal(t, "coop", items[0].ID)
+
+	var detail matchDetailSchema
+	resp = testutils.DoTestRequest(ts, http.MethodGet, "/api/matches/vip", nil, testutils.MustBindJSON(&detail))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "vip", detail.Info.ID)
+	require.Len(t, detail.Players, 2)
+	assert.Equal(t, "Serge", detail.Players[0].Name)
+	assert.Equal(t, 15, detail.Players[0].Score)
+	assert.Equal(t, 3, detail.Players[0].Kills)
+	assert.Empty(t, detail.Objectives)
+
+	resp = testutils.DoTestRequest(ts, http.MethodGet, "/api/matches/coop", nil, testutils.MustBindJSON(&detail))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, detail.Objectives, 1)
+	assert.Equal(t, "obj_Rescue_All_Hostages", detail.Objectives[0].Name)
+	assert.Equal(t, "Completed", detail.Objectives[0].Status)
+}
+
+func TestAPI_Matches_NotFoundAndInvalid(t *testing.T) {
+	ts, cancel := testutils.PrepareTestServer(t)
+	defer cancel()
+
+	resp := testutils.DoTestRequest(ts, http.MethodGet, "/api/matches/unknown", nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	var items []matchSchema
+	resp = testutils.DoTestRequest(
+		ts, http.MethodGet, "/api/servers/1.1.1.1:10480/matches", nil,
+		testutils.MustBindJSON(&items),
+	)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, items)
+
+	for _, path := range []string{
+		"/api/servers/1.1.1.1/matches",
+		"/api/servers/1.1.1.1:10480/matches?limit=-1",
+		"/api/servers/1.1.1.1:10480/matches?limit=101",
+	} {
+		resp = testutils.DoTestRequest(ts, http.MethodGet, path, nil)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, path)
+	}
+}
@@ -0,0 +1,123 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/details"
+	"github.com/sergeii/swat4master/internal/core/entities/session"
+	"github.com/sergeii/swat4master/internal/testutils"
+	"github.com/sergeii/swat4master/internal/testutils/factories/serverfactory"
+)
+
+type onlinePlayerSchema struct {
+	Name          string           `json:"name"`
+	Team          string           `json:"team"`
+	Score         int              `json:"score"`
+	Kills         int              `json:"kills"`
+	Arrests       int              `json:"arrests"`
+	StartedAt     time.Time        `json:"started_at"`
+	TimeInSession int64            `json:"time_in_session"`
+	Match         string           `json:"match"`
+	Server        serverListSchema `json:"server"`
+}
+
+func TestAPI_SearchPlayers_OK(t *testing.T) {
+	ctx := context.TODO()
+	ts, repos, cancel := testutils.PrepareTestServerWithRepos(t)
+	defer cancel()
+
+	now := time.Now().Truncate(time.Second)
+	live := serverfactory.Create(
+		ctx,
+		repos.Servers,
+		serverfactory.WithAddress("1.1.1.1", 10480),
+		serverfactory.WithInfo(map[string]string{"hostname": "[c=FF0000]Swat4 Server", "mapname": "Food Wall Restaurant"}),
+		serverfactory.WithRefreshedAt(now),
+	)
+	stale := serverfactory.Create(
+		ctx,
+		repos.Servers,
+		serverfactory.WithAddress("2.2.2.2", 10480),
+		serverfactory.WithRefreshedAt(now.Add(-time.Hour)),
+	)
+
+	exact := session.New(
+		live.Addr,
+		details.Player{Name: "[c=00FF00]Serge", Score: 15, Kills: 3, Arrests: 1, Team: details.TeamSuspects},
+		now.Add(-time.Minute*10),
+	)
+	prefix := session.New(live.Addr, details.Player{Name: "Sergeant"}, now.Add(-time.Minute*5))
+	fuzzy := session.New(live.Addr, details.Player{Name: "Sarge"}, now.Add(-time.Minute*5))
+	other := session.New(live.Addr, details.Player{Name: "Mosquito"}, now.Add(-time.Minute*5))
+	onStale := session.New(stale.Addr, details.Player{Name: "Serge"}, now.Add(-time.Minute*5))
+	ended := session.New(live.Addr, details.Player{Name: "Serge"}, now.Add(-time.Hour))
+	ended.End(now.Add(-time.Minute * 30))
+	testutils.MustNoErr(repos.Sessions.Save(ctx, exact, prefix, fuzzy, other, onStale, ended))
+
+	var items []onlinePlayerSchema
+	resp := testutils.DoTestRequest(
+		ts, http.MethodGet, "/api/players?name=SERGE", nil,
+		testutils.MustBindJSON(&items),
+	)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, items, 3)
+
+	assert.Equal(t, "[c=00FF00]Serge", items[0].Name)
+	assert.Equal(t, "exact", items[0].Match)
+	assert.Equal(t, "suspects", items[0].Team)
+	assert.Equal(t, 15, items[0].Score)
+	assert.Equal(t, 3, items[0].Kills)
+	assert.Equal(t, 1, items[0].Arrests)
+	assert.True(t, now.Add(-time.Minute*10).Equal(items[0].StartedAt))
+	assert.GreaterOrEqual(t, items[0].TimeInSession, int64(600))
+	assert.Equal(t, "1.1.1.1:10480", items[0].Server.Address)
+	assert.Equal(t, "Swat4 Server", items[0].Server.HostnamePlain)
+	assert.Equal(t, "Food Wall Restaurant", items[0].Server.MapName)
+
+	assert.Equal(t, "Sergeant", items[1].Name)
+	assert.Equal(t, "prefix", items[1].Match)
+	assert.Equal(t, "Sarge", items[2].Name)
+	assert.Equal(t, "fuzzy", items[2].Match)
+
+	resp = testutils.DoTestRequest(
+		ts, http.MethodGet, "/api/players?name=serge&limit=1", nil,
+		testutils.MustBindJSON(&items),
+	)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, items, 1)
+	assert.Equal(t, "[c=00FF00]Serge", items[0].Name)
+}
+
+func TestAPI_SearchPlayers_NotFound(t *testing.T) {
+	ts, cancel := testutils.PrepareTestServer(t)
+	defer cancel()
+
+	items := make([]onlinePlayerSchema, 0)
+	resp := testutils.DoTestRequest(
+		ts, http.MethodGet, "/api/players?name=serge", nil,
+		testutils.MustBindJSON(&items),
+	)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, items)
+}
+
+func TestAPI_SearchPlayers_Invalid(t *testing.T) {
+	ts, cancel := testutils.PrepareTestServer(t)
+	defer cancel()
+
+	for _, path := range []string{
+		"/api/players",
+		"/api/players?name=",
+		"/api/players?name=serge&limit=101",
+		"/api/players?name=serge&limit=foo",
+	} {
+		resp := testutils.DoTestRequest(ts, http.MethodGet, path, nil)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, path)
+	}
+}
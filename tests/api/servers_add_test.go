@@ -247,7 +247,7 @@ func TestAPI_AddServer_AlreadyDiscovered(t *testing.T) {
 
 	assert.Equal(t, `[C=FFFFFF]Swat4[\c] [b]Server`, obj.Hostname)
 	assert.Equal(t, "Swat4 Server", obj.HostnamePlain)
-	assert.Equal(t, `<span style="color:#FFFFFF;">Swat4</span> Server`, obj.HostnameHTML)
+	assert.Equal(t, `<span style="color:#FFFFFF;">Swat4</span> <b>Server</b>`, obj.HostnameHTML)
 	assert.Equal(t, "1.1.1.1:10480", obj.Address)
 	assert.Equal(t, "1.1.1.1", obj.IP)
 	assert.Equal(t, 10480, obj.Port)
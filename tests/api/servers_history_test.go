@@ -0,0 +1,134 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/history"
+	"github.com/sergeii/swat4master/internal/testutils"
+)
+
+type serverHistorySchema struct {
+	From       time.Time `json:"from"`
+	To         time.Time `json:"to"`
+	Resolution string    `json:"resolution"`
+	Step       int64     `json:"step"`
+	Points     []struct {
+		At         time.Time `json:"at"`
+		Uptime     float64   `json:"uptime"`
+		PlayersAvg float64   `json:"players_avg"`
+		PlayersMax int       `json:"players_max"`
+		PlayerMax  int       `json:"player_max"`
+		MapName    string    `json:"mapname"`
+		GameType   string    `json:"gametype"`
+	} `json:"points"`
+}
+
+func TestAPI_ServerHistory_OK(t *testing.T) {
+	ctx := context.TODO()
+	ts, repos, cancel := testutils.PrepareTestServerWithRepos(t)
+	defer cancel()
+
+	svrAddr := addr.MustNewFromDotted("1.1.1.1", 10480)
+	start := time.Now().Add(-time.Hour).Truncate(time.Hour)
+
+	for i := range 20 {
+		testutils.MustNoErr(repos.History.Record(ctx, history.Sample{
+			Addr:       svrAddr,
+			At:         start.Add(time.Minute * time.Duration(i)),
+			Online:     i%10 != 9,
+			Players:    i,
+			MaxPlayers: 16,
+			MapName:    "Northside Vending",
+			GameType:   "VIP Escort",
+		}))
+	}
+
+	query := url.Values{}
+	query.Set("from", start.Format(time.RFC3339))
+	query.Set("to", start.Add(time.Minute*20).Format(time.RFC3339))
+	query.Set("step", "10m")
+
+	var obj serverHistorySchema
+	resp := testutils.DoTestRequest(
+		ts, http.MethodGet, "/api/servers/1.1.1.1:10480/history?"+query.Encode(), nil,
+		testutils.MustBindJSON(&obj),
+	)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Equal(t, "raw", obj.Resolution)
+	assert.Equal(t, int64(600), obj.Step)
+	assert.True(t, start.Equal(obj.From))
+	require.Len(t, obj.Points, 2)
+
+	assert.True(t, start.Equal(obj.Points[0].At))
+	assert.InDelta(t, 0.9, obj.Points[0].Uptime, 1e-9)
+	assert.InDelta(t, 4.0, obj.Points[0].PlayersAvg, 1e-9) // (0+...+8) / 9
+	assert.Equal(t, 8, obj.Points[0].PlayersMax)
+	assert.Equal(t, 16, obj.Points[0].PlayerMax)
+	assert.Equal(t, "Northside Vending", obj.Points[0].MapName)
+	assert.Equal(t, "VIP Escort", obj.Points[0].GameType)
+
+	assert.True(t, start.Add(time.Minute*10).Equal(obj.Points[1].At))
+	assert.Equal(t, 18, obj.Points[1].PlayersMax)
+
+	// the history of the last day by default
+	resp = testutils.DoTestRequest(
+		ts, http.MethodGet, "/api/servers/1.1.1.1:10480/history", nil,
+		testutils.MustBindJSON(&obj),
+	)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "raw", obj.Resolution)
+	assert.Equal(t, int64(120), obj.Step)
+	assert.Equal(t, time.Hour*24, obj.To.Sub(obj.From))
+	assert.NotEmpty(t, obj.Points)
+
+	// no history for unknown servers
+	resp = testutils.DoTestRequest(
+		ts, http.MethodGet, "/api/servers/2.2.2.2:10480/history", nil,
+		testutils.MustBindJSON(&obj),
+	)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, obj.Points)
+}
+
+func TestAPI_ServerHistory_InvalidRequests(t *testing.T) {
+	ts, cancel := testutils.PrepareTestServer(t)
+	defer cancel()
+
+	now := time.Now()
+
+	tests := []struct {
+		name  string
+		query url.Values
+	}{
+		{"invalid from", url.Values{"from": {"yesterday"}}},
+		{"invalid step", url.Values{"step": {"often"}}},
+		{"negative step", url.Values{"step": {"-5m"}}},
+		{"reversed range", url.Values{
+			"from": {now.Format(time.RFC3339)},
+			"to":   {now.Add(-time.Hour).Format(time.RFC3339)},
+		}},
+		{"beyond retention", url.Values{"from": {now.Add(-time.Hour * 24 * 400).Format(time.RFC3339)}}},
+		{"too many points", url.Values{"step": {"1m"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := testutils.DoTestRequest(
+				ts, http.MethodGet, "/api/servers/1.1.1.1:10480/history?"+tt.query.Encode(), nil,
+			)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+	}
+
+	resp := testutils.DoTestRequest(ts, http.MethodGet, "/api/servers/1.1.1.1/history", nil)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -9,9 +9,12 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/fx"
 
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
 	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/internal/core/entities/server"
 	"github.com/sergeii/swat4master/internal/settings"
 	"github.com/sergeii/swat4master/internal/testutils"
 	"github.com/sergeii/swat4master/internal/testutils/factories/serverfactory"
@@ -45,6 +48,13 @@ type serverListSchema struct {
 	BombsTotal     int    `json:"bombs_total"`
 	TocReports     string `json:"coop_reports"`
 	WeaponsSecured string `json:"coop_weapons"`
+	Latency        *int64 `json:"latency"`
+	Country        string `json:"country"`
+	Continent      string `json:"continent"`
+	ASN            int    `json:"asn"`
+	Unverified     bool   `json:"unverified"`
+	Mismatched     bool   `json:"mismatched"`
+	Federated      bool   `json:"federated"`
 }
 
 func TestAPI_ListServers_OK(t *testing.T) {
@@ -167,7 +177,7 @@ func TestAPI_ListServers_OK(t *testing.T) {
 	svr1 := svrByAddr["1.1.1.1:10580"]
 	assert.Equal(t, `[C=FFFFFF]Swat4[\c] [b]Server`, svr1.Hostname)
 	assert.Equal(t, "Swat4 Server", svr1.HostnamePlain)
-	assert.Equal(t, `<span style="color:#FFFFFF;">Swat4</span> Server`, svr1.HostnameHTML)
+	assert.Equal(t, `<span style="color:#FFFFFF;">Swat4</span> <b>Server</b>`, svr1.HostnameHTML)
 	assert.Equal(t, "1.1.1.1:10580", svr1.Address)
 	assert.Equal(t, "1.1.1.1", svr1.IP)
 	assert.Equal(t, 10580, svr1.Port)
@@ -551,6 +561,274 @@ func TestAPI_ListServers_Filters(t *testing.T) {
 	}
 }
 
+func TestAPI_ListServers_FilterByLocation(t *testing.T) {
+	ctx := context.TODO()
+	ts, repos, cancel := testutils.PrepareTestServerWithRepos(t)
+	defer cancel()
+
+	for _, params := range []struct {
+		ip        string
+		country   string
+		continent string
+		asn       int
+	}{
+		{"1.1.1.1", "DE", "EU", 24940},
+		{"2.2.2.2", "FR", "EU", 16276},
+		{"3.3.3.3", "US", "NA", 0},
+		{"4.4.4.4", "", "", 0},
+	} {
+		serverfactory.Create(
+			ctx,
+			repos.Servers,
+			serverfactory.WithAddress(params.ip, 10480),
+			serverfactory.WithDiscoveryStatus(ds.Master|ds.Info),
+			serverfactory.WithRefreshedAt(time.Now()),
+			serverfactory.WithLocation(params.country, params.continent, params.asn),
+		)
+	}
+
+	tests := []struct {
+		name string
+		qs   url.Values
+		want []string
+	}{
+		{
+			"no filters applied",
+			url.Values{},
+			[]string{"1.1.1.1", "2.2.2.2", "3.3.3.3", "4.4.4.4"},
+		},
+		{
+			"country",
+			url.Values{"country": []string{"DE"}},
+			[]string{"1.1.1.1"},
+		},
+		{
+			"country in lower case",
+			url.Values{"country": []string{"fr"}},
+			[]string{"2.2.2.2"},
+		},
+		{
+			"continent",
+			url.Values{"continent": []string{"EU"}},
+			[]string{"1.1.1.1", "2.2.2.2"},
+		},
+		{
+			"country and continent",
+			url.Values{"country": []string{"US"}, "continent": []string{"EU"}},
+			[]string{},
+		},
+		{
+			"unknown country",
+			url.Values{"country": []string{"AU"}},
+			[]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			respJSON := make([]serverListSchema, 0)
+			uri := "/api/servers"
+			if len(tt.qs) > 0 {
+				uri = fmt.Sprintf("%s?%s", uri, tt.qs.Encode())
+			}
+			resp := testutils.DoTestRequest(
+				ts, http.MethodGet, uri, nil,
+				testutils.MustBindJSON(&respJSON),
+			)
+			assert.Equal(t, 200, resp.StatusCode)
+
+			actualIPs := make([]string, 0, len(respJSON))
+			for _, svr := range respJSON {
+				actualIPs = append(actualIPs, svr.IP)
+				if svr.IP == "1.1.1.1" {
+					assert.Equal(t, "DE", svr.Country)
+					assert.Equal(t, "EU", svr.Continent)
+					assert.Equal(t, 24940, svr.ASN)
+				}
+			}
+			assert.ElementsMatch(t, tt.want, actualIPs)
+		})
+	}
+}
+
+func TestAPI_ListServers_HidesDuplicates(t *testing.T) {
+	ctx := context.TODO()
+	ts, repos, cancel := testutils.PrepareTestServerWithRepos(t)
+	defer cancel()
+
+	primary := serverfactory.Build(
+		serverfactory.WithAddress("1.1.1.1", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Master|ds.Info),
+		serverfactory.WithRefreshedAt(time.Now()),
+	)
+	dup := serverfactory.Build(
+		serverfactory.WithAddress("2.2.2.2", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Info|ds.Details),
+		serverfactory.WithRefreshedAt(time.Now()),
+	)
+	other := serverfactory.Build(
+		serverfactory.WithAddress("3.3.3.3", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Master|ds.Info),
+		serverfactory.WithRefreshedAt(time.Now()),
+	)
+	primary.LinkDuplicates(addr.Blank, []addr.Addr{dup.Addr})
+	dup.LinkDuplicates(primary.Addr, nil)
+	for _, svr := range []server.Server{primary, dup, other} {
+		serverfactory.Save(ctx, repos.Servers, svr)
+	}
+
+	respJSON := make([]serverListSchema, 0)
+	resp := testutils.DoTestRequest(
+		ts, http.MethodGet, "/api/servers", nil,
+		testutils.MustBindJSON(&respJSON),
+	)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	actualIPs := make([]string, 0, len(respJSON))
+	for _, svr := range respJSON {
+		actualIPs = append(actualIPs, svr.IP)
+	}
+	assert.ElementsMatch(t, []string{"1.1.1.1", "3.3.3.3"}, actualIPs)
+}
+
+func TestAPI_ListServers_Unverified(t *testing.T) {
+	tests := []struct {
+		name           string
+		hideUnverified bool
+		want           []string
+	}{
+		{
+			"unverified servers are listed by default",
+			false,
+			[]string{"1.1.1.1", "2.2.2.2", "3.3.3.3"},
+		},
+		{
+			"unverified servers are hidden",
+			true,
+			[]string{"1.1.1.1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TODO()
+			ts, repos, cancel := testutils.PrepareTestServerWithRepos(
+				t,
+				fx.Decorate(func(settings settings.Settings) settings.Settings {
+					settings.ServerLiveness = time.Minute
+					settings.HideUnverified = tt.hideUnverified
+					return settings
+				}),
+			)
+			defer cancel()
+
+			verifiedAt := time.Now().Add(-time.Hour)
+			for _, params := range []struct {
+				ip      string
+				listing server.Listing
+			}{
+				{"1.1.1.1", server.Listing{VerifiedAt: verifiedAt}},
+				{"2.2.2.2", server.Listing{}},
+				{"3.3.3.3", server.Listing{VerifiedAt: verifiedAt, Mismatches: server.MismatchThreshold}},
+			} {
+				serverfactory.Create(
+					ctx,
+					repos.Servers,
+					serverfactory.WithAddress(params.ip, 10480),
+					serverfactory.WithDiscoveryStatus(ds.Master|ds.Info),
+					serverfactory.WithRefreshedAt(time.Now()),
+					serverfactory.WithListing(params.listing),
+				)
+			}
+
+			respJSON := make([]serverListSchema, 0)
+			resp := testutils.DoTestRequest(
+				ts, http.MethodGet, "/api/servers", nil,
+				testutils.MustBindJSON(&respJSON),
+			)
+			assert.Equal(t, 200, resp.StatusCode)
+
+			actualIPs := make([]string, 0, len(respJSON))
+			for _, svr := range respJSON {
+				actualIPs = append(actualIPs, svr.IP)
+				switch svr.IP {
+				case "1.1.1.1":
+					assert.False(t, svr.Unverified)
+					assert.False(t, svr.Mismatched)
+				case "2.2.2.2":
+					assert.True(t, svr.Unverified)
+					assert.False(t, svr.Mismatched)
+				case "3.3.3.3":
+					assert.False(t, svr.Unverified)
+					assert.True(t, svr.Mismatched)
+				}
+			}
+			assert.ElementsMatch(t, tt.want, actualIPs)
+		})
+	}
+}
+
+func TestAPI_ListServers_Federated(t *testing.T) {
+	tests := []struct {
+		name  string
+		query url.Values
+		want  []string
+	}{
+		{
+			"federated servers are listed by default",
+			url.Values{},
+			[]string{"1.1.1.1", "2.2.2.2"},
+		},
+		{
+			"federated servers are hidden",
+			url.Values{"nofederated": []string{"true"}},
+			[]string{"1.1.1.1"},
+		},
+		{
+			"federated servers are listed explicitly",
+			url.Values{"nofederated": []string{"false"}},
+			[]string{"1.1.1.1", "2.2.2.2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TODO()
+			ts, repos, cancel := testutils.PrepareTestServerWithRepos(t)
+			defer cancel()
+
+			serverfactory.Create(
+				ctx,
+				repos.Servers,
+				serverfactory.WithAddress("1.1.1.1", 10480),
+				serverfactory.WithDiscoveryStatus(ds.Master|ds.Info),
+				serverfactory.WithRefreshedAt(time.Now()),
+			)
+			serverfactory.Create(
+				ctx,
+				repos.Servers,
+				serverfactory.WithAddress("2.2.2.2", 10480),
+				serverfactory.WithDiscoveryStatus(ds.Federated|ds.Info|ds.Details|ds.Port),
+				serverfactory.WithRefreshedAt(time.Now()),
+			)
+
+			respJSON := make([]serverListSchema, 0)
+			resp := testutils.DoTestRequest(
+				ts, http.MethodGet, "/api/servers?"+tt.query.Encode(), nil,
+				testutils.MustBindJSON(&respJSON),
+			)
+			assert.Equal(t, 200, resp.StatusCode)
+
+			actualIPs := make([]string, 0, len(respJSON))
+			for _, svr := range respJSON {
+				actualIPs = append(actualIPs, svr.IP)
+				assert.Equal(t, svr.IP == "2.2.2.2", svr.Federated)
+			}
+			assert.ElementsMatch(t, tt.want, actualIPs)
+		})
+	}
+}
+
 func TestAPI_ListServers_Empty(t *testing.T) {
 	ts, cancel := testutils.PrepareTestServer(t)
 	defer cancel()
@@ -563,3 +841,115 @@ func TestAPI_ListServers_Empty(t *testing.T) {
 	assert.Equal(t, 200, resp.StatusCode)
 	assert.Empty(t, respJSON)
 }
+
+func TestAPI_ListServers_SortByLatency(t *testing.T) {
+	ctx := context.TODO()
+	ts, repos, cancel := testutils.PrepareTestServerWithRepos(t)
+	defer cancel()
+
+	for _, params := range []struct {
+		ip   string
+		rtts []time.Duration
+	}{
+		{"1.1.1.1", nil},
+		{"2.2.2.2", []time.Duration{time.Millisecond * 200}},
+		{"3.3.3.3", []time.Duration{time.Millisecond * 40}},
+		{"4.4.4.4", nil},
+		{"5.5.5.5", []time.Duration{time.Millisecond * 120, time.Millisecond * 40}},
+	} {
+		serverfactory.Create(
+			ctx,
+			repos.Servers,
+			serverfactory.WithAddress(params.ip, 10480),
+			serverfactory.WithDiscoveryStatus(ds.Master|ds.Info),
+			serverfactory.WithRefreshedAt(time.Now()),
+			serverfactory.WithQuery("gs1/vanilla", time.Now(), params.rtts...),
+		)
+	}
+
+	respJSON := make([]serverListSchema, 0)
+	resp := testutils.DoTestRequest(
+		ts, http.MethodGet, "/api/servers?sort=latency", nil,
+		testutils.MustBindJSON(&respJSON),
+	)
+	assert.Equal(t, 200, resp.StatusCode)
+	require.Len(t, respJSON, 5)
+
+	assert.Equal(t, "3.3.3.3", respJSON[0].IP)
+	assert.Equal(t, int64(40), *respJSON[0].Latency)
+	assert.Equal(t, "5.5.5.5", respJSON[1].IP)
+	assert.Equal(t, int64(110), *respJSON[1].Latency)
+	assert.Equal(t, "2.2.2.2", respJSON[2].IP)
+	assert.Equal(t, int64(200), *respJSON[2].Latency)
+	for _, item := range respJSON[3:] {
+		assert.Nil(t, item.Latency)
+	}
+	assert.ElementsMatch(t, []string{"1.1.1.1", "4.4.4.4"}, []string{respJSON[3].IP, respJSON[4].IP})
+}
+
+func TestAPI_ListServers_SortByUptime(t *testing.T) {
+	ctx := context.TODO()
+	ts, repos, cancel := testutils.PrepareTestServerWithRepos(t)
+	defer cancel()
+
+	now := time.Now()
+	for _, params := range []struct {
+		ip    string
+		avail server.Availability
+	}{
+		{"1.1.1.1", server.Availability{}},
+		{
+			"2.2.2.2",
+			server.Availability{
+				Since:   now.Add(-time.Hour * 24 * 10),
+				Outages: []server.Outage{{Start: now.Add(-time.Hour * 48), End: now.Add(-time.Hour * 24)}},
+			},
+		},
+		{"3.3.3.3", server.Availability{Since: now.Add(-time.Hour * 24 * 10)}},
+		{
+			"4.4.4.4",
+			server.Availability{
+				Since:   now.Add(-time.Hour * 24 * 10),
+				Outages: []server.Outage{{Start: now.Add(-time.Hour * 24 * 9), End: now.Add(-time.Hour * 24 * 8)}},
+			},
+		},
+		{
+			"5.5.5.5",
+			server.Availability{
+				Since:   now.Add(-time.Hour * 24 * 10),
+				Outages: []server.Outage{{Start: now.Add(-time.Hour * 6), End: now.Add(-time.Hour)}},
+			},
+		},
+	} {
+		serverfactory.Create(
+			ctx,
+			repos.Servers,
+			serverfactory.WithAddress(params.ip, 10480),
+			serverfactory.WithDiscoveryStatus(ds.Master|ds.Info),
+			serverfactory.WithRefreshedAt(now),
+			serverfactory.WithAvailability(params.avail),
+		)
+	}
+
+	respJSON := make([]serverListSchema, 0)
+	resp := testutils.DoTestRequest(
+		ts, http.MethodGet, "/api/servers?sort=uptime", nil,
+		testutils.MustBindJSON(&respJSON),
+	)
+	assert.Equal(t, 200, resp.StatusCode)
+	require.Len(t, respJSON, 5)
+
+	// the outage that has happened before the last week is not taken into account
+	assert.ElementsMatch(t, []string{"3.3.3.3", "4.4.4.4"}, []string{respJSON[0].IP, respJSON[1].IP})
+	assert.Equal(t, "5.5.5.5", respJSON[2].IP)
+	assert.Equal(t, "2.2.2.2", respJSON[3].IP)
+	assert.Equal(t, "1.1.1.1", respJSON[4].IP)
+}
+
+func TestAPI_ListServers_InvalidSort(t *testing.T) {
+	ts, cancel := testutils.PrepareTestServer(t)
+	defer cancel()
+
+	resp := testutils.DoTestRequest(ts, http.MethodGet, "/api/servers?sort=hostname", nil)
+	assert.Equal(t, 400, resp.StatusCode)
+}
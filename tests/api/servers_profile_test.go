@@ -0,0 +1,291 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/internal/core/entities/server"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+	"github.com/sergeii/swat4master/internal/testutils"
+	"github.com/sergeii/swat4master/internal/testutils/factories/serverfactory"
+)
+
+type serverClaimSchema struct {
+	Token     string    `json:"token"`
+	Key       string    `json:"key"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type serverProfileSchema struct {
+	Description string    `json:"description"`
+	Website     string    `json:"website"`
+	Discord     string    `json:"discord"`
+	Region      string    `json:"region"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type serverDetailWithProfileSchema struct {
+	Profile  *serverProfileSchema `json:"profile"`
+	Verified bool                 `json:"verified"`
+}
+
+func TestAPI_ServerProfile_ClaimAndEdit(t *testing.T) {
+	ctx := context.TODO()
+	ts, repos, cancel := testutils.PrepareTestServerWithRepos(t)
+	defer cancel()
+
+	svrAddr := addr.MustNewFromDotted("1.1.1.1", 10480)
+	serverfactory.Create(
+		ctx,
+		repos.Servers,
+		serverfactory.WithAddress("1.1.1.1", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Master|ds.Info|ds.Details),
+	)
+
+	claim := serverClaimSchema{}
+	resp := testutils.DoTestRequest(
+		ts, http.MethodPost, "/api/servers/1.1.1.1:10480/claim", nil,
+		testutils.MustBindJSON(&claim),
+	)
+	require.Equal(t, 201, resp.StatusCode)
+	assert.True(t, strings.HasPrefix(claim.Token, "s4m-"))
+	assert.NotEmpty(t, claim.Key)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), claim.ExpiresAt, time.Minute)
+
+	payload := []byte(`{"description": "Best server", "website": "https://example.com", "region": "EU"}`)
+
+	// the claim is not confirmed yet
+	resp = testutils.DoTestRequest(
+		ts, http.MethodPut, "/api/servers/1.1.1.1:10480/profile", bytes.NewReader(payload),
+		testutils.WithBearerToken(claim.Key),
+	)
+	assert.Equal(t, 401, resp.StatusCode)
+
+	// the owner has put the token in the hostname and the server has been probed
+	svr := testutils.Must(repos.Servers.Get(ctx, svrAddr))
+	require.True(t, svr.ConfirmOwnership(map[string]string{"hostname": "My Server " + claim.Token}, time.Now()))
+	testutils.Must(repos.Servers.Update(ctx, svr, repositories.ServerOnConflictIgnore))
+
+	detail := serverDetailWithProfileSchema{}
+	resp = testutils.DoTestRequest(
+		ts, http.MethodGet, "/api/servers/1.1.1.1:10480", nil,
+		testutils.MustBindJSON(&detail),
+	)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.True(t, detail.Verified)
+	assert.Nil(t, detail.Profile)
+
+	updated := serverProfileSchema{}
+	resp = testutils.DoTestRequest(
+		ts, http.MethodPut, "/api/servers/1.1.1.1:10480/profile", bytes.NewReader(payload),
+		testutils.WithBearerToken(claim.Key),
+		testutils.MustBindJSON(&updated),
+	)
+	require.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "Best server", updated.Description)
+	assert.Equal(t, "https://example.com", updated.Website)
+	assert.Empty(t, updated.Discord)
+	assert.Equal(t, "EU", updated.Region)
+
+	// someone else claims the server, the owner is not affected until the claim is confirmed
+	another := serverClaimSchema{}
+	resp = testutils.DoTestRequest(
+		ts, http.MethodPost, "/api/servers/1.1.1.1:10480/claim", nil,
+		testutils.MustBindJSON(&another),
+	)
+	require.Equal(t, 201, resp.StatusCode)
+	resp = testutils.DoTestRequest(
+		ts, http.MethodPut, "/api/servers/1.1.1.1:10480/profile", bytes.NewReader([]byte(`{"description": "Hacked"}`)),
+		testutils.WithBearerToken(another.Key),
+	)
+	assert.Equal(t, 401, resp.StatusCode)
+
+	detail = serverDetailWithProfileSchema{}
+	resp = testutils.DoTestRequest(
+		ts, http.MethodGet, "/api/servers/1.1.1.1:10480", nil,
+		testutils.MustBindJSON(&detail),
+	)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.True(t, detail.Verified)
+	require.NotNil(t, detail.Profile)
+	assert.Equal(t, "Best server", detail.Profile.Description)
+	assert.Equal(t, "https://example.com", detail.Profile.Website)
+	assert.Equal(t, "EU", detail.Profile.Region)
+}
+
+func TestAPI_ServerProfile_Claim_Errors(t *testing.T) {
+	ctx := context.TODO()
+	ts, repos, cancel := testutils.PrepareTestServerWithRepos(t)
+	defer cancel()
+
+	serverfactory.Create(
+		ctx,
+		repos.Servers,
+		serverfactory.WithAddress("2.2.2.2", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Master|ds.Info),
+	)
+
+	tests := []struct {
+		name     string
+		address  string
+		wantCode int
+	}{
+		{"unknown server", "1.1.1.1:10480", 404},
+		{"server is not queried", "2.2.2.2:10480", 409},
+		{"invalid address", "1.1.1.1:0", 400},
+		{"private address", "127.0.0.1:10480", 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := testutils.DoTestRequest(ts, http.MethodPost, "/api/servers/"+tt.address+"/claim", nil)
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+		})
+	}
+}
+
+func TestAPI_ServerProfile_Claim_TooMany(t *testing.T) {
+	ctx := context.TODO()
+	ts, repos, cancel := testutils.PrepareTestServerWithRepos(t)
+	defer cancel()
+
+	serverfactory.Create(
+		ctx,
+		repos.Servers,
+		serverfactory.WithAddress("1.1.1.1", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Master|ds.Info|ds.Details),
+	)
+
+	claims := make([]serverClaimSchema, server.MaxPendingClaims)
+	for i := range claims {
+		resp := testutils.DoTestRequest(
+			ts, http.MethodPost, "/api/servers/1.1.1.1:10480/claim", nil,
+			testutils.MustBindJSON(&claims[i]),
+		)
+		require.Equal(t, 201, resp.StatusCode)
+	}
+
+	resp := testutils.DoTestRequest(ts, http.MethodPost, "/api/servers/1.1.1.1:10480/claim", nil)
+	assert.Equal(t, 429, resp.StatusCode)
+
+	// the first claim is still pending and can be confirmed
+	svr := testutils.Must(repos.Servers.Get(ctx, addr.MustNewFromDotted("1.1.1.1", 10480)))
+	require.True(t, svr.ConfirmOwnership(map[string]string{"hostname": claims[0].Token}, time.Now()))
+	testutils.Must(repos.Servers.Update(ctx, svr, repositories.ServerOnConflictIgnore))
+
+	resp = testutils.DoTestRequest(
+		ts, http.MethodPut, "/api/servers/1.1.1.1:10480/profile", bytes.NewReader([]byte(`{"description": "Mine"}`)),
+		testutils.WithBearerToken(claims[0].Key),
+	)
+	assert.Equal(t, 200, resp.StatusCode)
+}
+
+func TestAPI_ServerProfile_Edit_Errors(t *testing.T) {
+	ctx := context.TODO()
+	ts, repos, cancel := testutils.PrepareTestServerWithRepos(t)
+	defer cancel()
+
+	svr := serverfactory.Create(
+		ctx,
+		repos.Servers,
+		serverfactory.WithAddress("1.1.1.1", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Master|ds.Info|ds.Details),
+	)
+	claim := serverClaimSchema{}
+	testutils.DoTestRequest(
+		ts, http.MethodPost, "/api/servers/1.1.1.1:10480/claim", nil,
+		testutils.MustBindJSON(&claim),
+	)
+	svr = testutils.Must(repos.Servers.Get(ctx, svr.Addr))
+	require.True(t, svr.ConfirmOwnership(map[string]string{"hostname": claim.Token}, time.Now()))
+	testutils.Must(repos.Servers.Update(ctx, svr, repositories.ServerOnConflictIgnore))
+
+	tests := []struct {
+		name     string
+		address  string
+		payload  string
+		opts     []testutils.TestRequestOpt
+		wantCode int
+	}{
+		{
+			"no key",
+			"1.1.1.1:10480",
+			`{"description": "Best server"}`,
+			nil,
+			401,
+		},
+		{
+			"wrong key",
+			"1.1.1.1:10480",
+			`{"description": "Best server"}`,
+			[]testutils.TestRequestOpt{testutils.WithBearerToken("other")},
+			401,
+		},
+		{
+			"unknown server",
+			"2.2.2.2:10480",
+			`{"description": "Best server"}`,
+			[]testutils.TestRequestOpt{testutils.WithBearerToken(claim.Key)},
+			404,
+		},
+		{
+			"invalid website",
+			"1.1.1.1:10480",
+			`{"website": "not a url"}`,
+			[]testutils.TestRequestOpt{testutils.WithBearerToken(claim.Key)},
+			400,
+		},
+		{
+			"script website",
+			"1.1.1.1:10480",
+			`{"website": "javascript:alert(1)"}`,
+			[]testutils.TestRequestOpt{testutils.WithBearerToken(claim.Key)},
+			400,
+		},
+		{
+			"website is not http",
+			"1.1.1.1:10480",
+			`{"website": "ftp://example.com"}`,
+			[]testutils.TestRequestOpt{testutils.WithBearerToken(claim.Key)},
+			400,
+		},
+		{
+			"script discord",
+			"1.1.1.1:10480",
+			`{"discord": "javascript://discord.gg/%0Aalert(1)"}`,
+			[]testutils.TestRequestOpt{testutils.WithBearerToken(claim.Key)},
+			400,
+		},
+		{
+			"description is too long",
+			"1.1.1.1:10480",
+			`{"description": "` + strings.Repeat("a", 501) + `"}`,
+			[]testutils.TestRequestOpt{testutils.WithBearerToken(claim.Key)},
+			400,
+		},
+		{
+			"invalid payload",
+			"1.1.1.1:10480",
+			`[]`,
+			[]testutils.TestRequestOpt{testutils.WithBearerToken(claim.Key)},
+			400,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := testutils.DoTestRequest(
+				ts, http.MethodPut, "/api/servers/"+tt.address+"/profile", bytes.NewReader([]byte(tt.payload)),
+				tt.opts...,
+			)
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+		})
+	}
+}
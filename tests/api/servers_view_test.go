@@ -4,10 +4,14 @@ import (
 	"context"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
 	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/internal/core/entities/server"
 	"github.com/sergeii/swat4master/internal/testutils"
 	"github.com/sergeii/swat4master/internal/testutils/factories/serverfactory"
 )
@@ -40,10 +44,13 @@ type serverDetailInfoSchema struct {
 	BombsTotal     int    `json:"bombs_total"`
 	TocReports     string `json:"coop_reports"`
 	WeaponsSecured string `json:"coop_weapons"`
+	Latency        *int64 `json:"latency"`
 }
 
 type serverDetailPlayerSchema struct {
 	Name            string `json:"name"`
+	NamePlain       string `json:"name_plain"`
+	NameHTML        string `json:"name_html"`
 	Ping            int    `json:"ping"`
 	Score           int    `json:"score"`
 	Team            string `json:"team"`
@@ -73,10 +80,38 @@ type serverDetailObjectiveSchema struct {
 	StatusSlug string `json:"status_slug"`
 }
 
+type serverDetailQuerySchema struct {
+	Protocol      string    `json:"protocol"`
+	LatencyLast   int64     `json:"latency_last"`
+	LatencyAvg    int64     `json:"latency_avg"`
+	LatencyJitter int64     `json:"latency_jitter"`
+	QueriedAt     time.Time `json:"queried_at"`
+}
+
+type serverDetailOutageSchema struct {
+	Start    time.Time  `json:"start"`
+	End      *time.Time `json:"end"`
+	Duration int64      `json:"duration"`
+}
+
+type serverDetailAvailabilitySchema struct {
+	Since     time.Time                  `json:"since"`
+	Online    bool                       `json:"online"`
+	Uptime24h float64                    `json:"uptime_24h"`
+	Uptime7d  float64                    `json:"uptime_7d"`
+	Uptime30d float64                    `json:"uptime_30d"`
+	MTBO      *int64                     `json:"mtbo"`
+	Outages   []serverDetailOutageSchema `json:"outages"`
+}
+
 type serverDetailSchema struct {
-	Info       serverDetailInfoSchema        `json:"info"`
-	Players    []serverDetailPlayerSchema    `json:"players"`
-	Objectives []serverDetailObjectiveSchema `json:"objectives"`
+	Info         serverDetailInfoSchema          `json:"info"`
+	Players      []serverDetailPlayerSchema      `json:"players"`
+	Objectives   []serverDetailObjectiveSchema   `json:"objectives"`
+	Query        *serverDetailQuerySchema        `json:"query"`
+	Availability *serverDetailAvailabilitySchema `json:"availability"`
+	DuplicateOf  *string                         `json:"duplicate_of"`
+	Duplicates   []string                        `json:"duplicates"`
 }
 
 func TestAPI_ViewServer_OK(t *testing.T) {
@@ -116,7 +151,7 @@ func TestAPI_ViewServer_OK(t *testing.T) {
 			"deaths": "1", "ping": "111", "player": "Reynolds", "score": "0", "team": "0",
 		},
 		{
-			"deaths": "1", "ping": "117", "player": "4Taws", "score": "0", "team": "0",
+			"deaths": "1", "ping": "117", "player": "[c=00FF00]4[b]Taws", "score": "0", "team": "0",
 		},
 		{
 			"ping": "142", "player": "Daro", "score": "0", "team": "1",
@@ -146,7 +181,7 @@ func TestAPI_ViewServer_OK(t *testing.T) {
 
 	assert.Equal(t, `[C=FFFFFF]Swat4[\c] [b]Server`, obj.Info.Hostname)
 	assert.Equal(t, "Swat4 Server", obj.Info.HostnamePlain)
-	assert.Equal(t, `<span style="color:#FFFFFF;">Swat4</span> Server`, obj.Info.HostnameHTML)
+	assert.Equal(t, `<span style="color:#FFFFFF;">Swat4</span> <b>Server</b>`, obj.Info.HostnameHTML)
 	assert.Equal(t, "1.1.1.1:10580", obj.Info.Address)
 	assert.Equal(t, "1.1.1.1", obj.Info.IP)
 	assert.Equal(t, 10580, obj.Info.Port)
@@ -172,18 +207,150 @@ func TestAPI_ViewServer_OK(t *testing.T) {
 
 	player1 := obj.Players[0]
 	assert.Equal(t, "{FAB}Nikki_Sixx<CPL>", player1.Name)
+	assert.Equal(t, "{FAB}Nikki_Sixx<CPL>", player1.NamePlain)
+	assert.Equal(t, "{FAB}Nikki_Sixx&lt;CPL&gt;", player1.NameHTML)
 	assert.Equal(t, 155, player1.Ping)
 	assert.Equal(t, "suspects", player1.Team)
 	assert.Equal(t, 0, player1.Score)
 	assert.Equal(t, 0, player1.Kills)
 
 	player6 := obj.Players[5]
-	assert.Equal(t, "4Taws", player6.Name)
+	assert.Equal(t, "[c=00FF00]4[b]Taws", player6.Name)
+	assert.Equal(t, "4Taws", player6.NamePlain)
+	assert.Equal(
+		t,
+		`<span style="color:#00FF00;">4</span><span style="color:#00FF00;"><b>Taws</b></span>`,
+		player6.NameHTML,
+	)
 	assert.Equal(t, 117, player6.Ping)
 	assert.Equal(t, "swat", player6.Team)
 	assert.Equal(t, 0, player6.Score)
 	assert.Equal(t, 0, player6.Kills)
 	assert.Equal(t, 1, player6.Deaths)
+
+	// reporting servers are not queried
+	assert.Nil(t, obj.Info.Latency)
+	assert.Nil(t, obj.Query)
+	// the availability of the server has never been tracked
+	assert.Nil(t, obj.Availability)
+}
+
+func TestAPI_ViewServer_Query_OK(t *testing.T) {
+	ctx := context.TODO()
+	ts, repos, cancel := testutils.PrepareTestServerWithRepos(t)
+	defer cancel()
+
+	queriedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	serverfactory.Create(
+		ctx,
+		repos.Servers,
+		serverfactory.WithAddress("1.1.1.1", 10580),
+		serverfactory.WithQueryPort(10581),
+		serverfactory.WithDiscoveryStatus(ds.Details|ds.Info|ds.Port),
+		serverfactory.WithQuery("gs1/am", queriedAt, time.Millisecond*100, time.Millisecond*20),
+	)
+
+	obj := serverDetailSchema{}
+	resp := testutils.DoTestRequest(
+		ts, http.MethodGet, "/api/servers/1.1.1.1:10580", nil,
+		testutils.MustBindJSON(&obj),
+	)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	require.NotNil(t, obj.Info.Latency)
+	assert.Equal(t, int64(90), *obj.Info.Latency)
+	require.NotNil(t, obj.Query)
+	assert.Equal(t, "gs1/am", obj.Query.Protocol)
+	assert.Equal(t, int64(20), obj.Query.LatencyLast)
+	assert.Equal(t, int64(90), obj.Query.LatencyAvg)
+	assert.Equal(t, int64(5), obj.Query.LatencyJitter)
+	assert.True(t, queriedAt.Equal(obj.Query.QueriedAt))
+}
+
+func TestAPI_ViewServer_Availability_OK(t *testing.T) {
+	ctx := context.TODO()
+	ts, repos, cancel := testutils.PrepareTestServerWithRepos(t)
+	defer cancel()
+
+	now := time.Now().Truncate(time.Second)
+	serverfactory.Create(
+		ctx,
+		repos.Servers,
+		serverfactory.WithAddress("1.1.1.1", 10580),
+		serverfactory.WithQueryPort(10581),
+		serverfactory.WithDiscoveryStatus(ds.Details|ds.Info|ds.Port),
+		serverfactory.WithAvailability(server.Availability{
+			Since: now.Add(-time.Hour * 48),
+			Outages: []server.Outage{
+				{Start: now.Add(-time.Hour * 12), End: now.Add(-time.Hour * 6)},
+				{Start: now.Add(-time.Hour)},
+			},
+		}),
+	)
+
+	obj := serverDetailSchema{}
+	resp := testutils.DoTestRequest(
+		ts, http.MethodGet, "/api/servers/1.1.1.1:10580", nil,
+		testutils.MustBindJSON(&obj),
+	)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	avail := obj.Availability
+	require.NotNil(t, avail)
+	assert.True(t, now.Add(-time.Hour*48).Equal(avail.Since))
+	assert.False(t, avail.Online)
+	assert.InDelta(t, 70.83, avail.Uptime24h, 0.01)
+	assert.InDelta(t, 85.42, avail.Uptime7d, 0.01)
+	assert.InDelta(t, 85.42, avail.Uptime30d, 0.01)
+	require.NotNil(t, avail.MTBO)
+	assert.InDelta(t, 73800, *avail.MTBO, 5)
+
+	require.Len(t, avail.Outages, 2)
+	assert.True(t, now.Add(-time.Hour).Equal(avail.Outages[0].Start))
+	assert.Nil(t, avail.Outages[0].End)
+	assert.InDelta(t, 3600, avail.Outages[0].Duration, 5)
+	assert.True(t, now.Add(-time.Hour*12).Equal(avail.Outages[1].Start))
+	require.NotNil(t, avail.Outages[1].End)
+	assert.True(t, now.Add(-time.Hour*6).Equal(*avail.Outages[1].End))
+	assert.Equal(t, int64(21600), avail.Outages[1].Duration)
+}
+
+func TestAPI_ViewServer_Duplicates_OK(t *testing.T) {
+	ctx := context.TODO()
+	ts, repos, cancel := testutils.PrepareTestServerWithRepos(t)
+	defer cancel()
+
+	primary := serverfactory.Build(
+		serverfactory.WithAddress("1.1.1.1", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Master|ds.Details|ds.Info|ds.Port),
+	)
+	dup := serverfactory.Build(
+		serverfactory.WithAddress("2.2.2.2", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Details|ds.Info|ds.Port),
+	)
+	primary.LinkDuplicates(addr.Blank, []addr.Addr{dup.Addr})
+	dup.LinkDuplicates(primary.Addr, nil)
+	serverfactory.Save(ctx, repos.Servers, primary)
+	serverfactory.Save(ctx, repos.Servers, dup)
+
+	obj := serverDetailSchema{}
+	resp := testutils.DoTestRequest(
+		ts, http.MethodGet, "/api/servers/1.1.1.1:10480", nil,
+		testutils.MustBindJSON(&obj),
+	)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Nil(t, obj.DuplicateOf)
+	assert.Equal(t, []string{"2.2.2.2:10480"}, obj.Duplicates)
+
+	obj = serverDetailSchema{}
+	resp = testutils.DoTestRequest(
+		ts, http.MethodGet, "/api/servers/2.2.2.2:10480", nil,
+		testutils.MustBindJSON(&obj),
+	)
+	assert.Equal(t, 200, resp.StatusCode)
+	require.NotNil(t, obj.DuplicateOf)
+	assert.Equal(t, "1.1.1.1:10480", *obj.DuplicateOf)
+	assert.Empty(t, obj.Duplicates)
 }
 
 func TestAPI_ViewServer_Coop_OK(t *testing.T) {
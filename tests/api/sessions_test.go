@@ -0,0 +1,125 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/details"
+	"github.com/sergeii/swat4master/internal/core/entities/session"
+	"github.com/sergeii/swat4master/internal/testutils"
+)
+
+type sessionSchema struct {
+	Address   string     `json:"address"`
+	Name      string     `json:"name"`
+	Team      string     `json:"team"`
+	Score     int        `json:"score"`
+	Kills     int        `json:"kills"`
+	Arrests   int        `json:"arrests"`
+	Active    bool       `json:"active"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at"`
+	Duration  *int64     `json:"duration"`
+}
+
+func TestAPI_Sessions_ListByServerAndPlayer(t *testing.T) {
+	ctx := context.TODO()
+	ts, repos, cancel := testutils.PrepareTestServerWithRepos(t)
+	defer cancel()
+
+	svr1 := addr.MustNewFromDotted("1.1.1.1", 10480)
+	svr2 := addr.MustNewFromDotted("2.2.2.2", 10480)
+	now := time.Now().Truncate(time.Second)
+
+	ended := session.New(
+		svr1,
+		details.Player{Name: "Serge", Score: 15, Kills: 3, Arrests: 1, Team: details.TeamSuspects},
+		now.Add(-time.Hour),
+	)
+	ended.End(now.Add(-time.Minute * 30))
+	active := session.New(svr1, details.Player{Name: "Mosquito", Score: 2}, now.Add(-time.Minute*10))
+	other := session.New(svr2, details.Player{Name: "serge"}, now.Add(-time.Minute*5))
+	testutils.MustNoErr(repos.Sessions.Save(ctx, ended, active, other))
+
+	var items []sessionSchema
+	resp := testutils.DoTestRequest(
+		ts, http.MethodGet, "/api/servers/1.1.1.1:10480/sessions", nil,
+		testutils.MustBindJSON(&items),
+	)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, items, 2)
+
+	assert.Equal(t, "Mosquito", items[0].Name)
+	assert.Equal(t, "1.1.1.1:10480", items[0].Address)
+	assert.True(t, items[0].Active)
+	assert.Nil(t, items[0].EndedAt)
+	assert.Nil(t, items[0].Duration)
+	assert.Equal(t, 2, items[0].Score)
+
+	assert.Equal(t, "Serge", items[1].Name)
+	assert.False(t, items[1].Active)
+	assert.Equal(t, "suspects", items[1].Team)
+	assert.Equal(t, 15, items[1].Score)
+	assert.Equal(t, 3, items[1].Kills)
+	assert.Equal(t, 1, items[1].Arrests)
+	assert.True(t, now.Add(-time.Hour).Equal(items[1].StartedAt))
+	require.NotNil(t, items[1].EndedAt)
+	assert.True(t, now.Add(-time.Minute*30).Equal(*items[1].EndedAt))
+	require.NotNil(t, items[1].Duration)
+	assert.Equal(t, int64(1800), *items[1].Duration)
+
+	resp = testutils.DoTestRequest(
+		ts, http.MethodGet, "/api/servers/1.1.1.1:10480/sessions?limit=1", nil,
+		testutils.MustBindJSON(&items),
+	)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, items, 1)
+	assert.Equal(t, "Mosquito", items[0].Name)
+
+	resp = testutils.DoTestRequest(
+		ts, http.MethodGet, "/api/players/SERGE/sessions", nil,
+		testutils.MustBindJSON(&items),
+	)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, items, 2)
+	assert.Equal(t, "serge", items[0].Name)
+	assert.Equal(t, "2.2.2.2:10480", items[0].Address)
+	assert.Equal(t, "Serge", items[1].Name)
+	assert.Equal(t, "1.1.1.1:10480", items[1].Address)
+
+	resp = testutils.DoTestRequest(
+		ts, http.MethodGet, "/api/servers/3.3.3.3:10480/sessions", nil,
+		testutils.MustBindJSON(&items),
+	)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, items)
+}
+
+func TestAPI_Sessions_InvalidRequests(t *testing.T) {
+	ts, cancel := testutils.PrepareTestServer(t)
+	defer cancel()
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"invalid address", "/api/servers/1.1.1.1/sessions"},
+		{"private address", "/api/servers/127.0.0.1:10480/sessions"},
+		{"negative limit", "/api/servers/1.1.1.1:10480/sessions?limit=-1"},
+		{"limit too large", "/api/players/Serge/sessions?limit=101"},
+		{"non numeric limit", "/api/players/Serge/sessions?limit=foo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := testutils.DoTestRequest(ts, http.MethodGet, tt.path, nil)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -16,8 +18,14 @@ import (
 
 	"github.com/sergeii/swat4master/cmd/swat4master/application"
 	"github.com/sergeii/swat4master/cmd/swat4master/components/browser"
+	internalbrowser "github.com/sergeii/swat4master/internal/browser"
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
 	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/internal/core/entities/featured"
+	"github.com/sergeii/swat4master/internal/core/entities/location"
+	"github.com/sergeii/swat4master/internal/core/entities/server"
 	"github.com/sergeii/swat4master/internal/core/repositories"
+	"github.com/sergeii/swat4master/internal/geoip"
 	"github.com/sergeii/swat4master/internal/metrics"
 	"github.com/sergeii/swat4master/internal/settings"
 	tu "github.com/sergeii/swat4master/internal/testutils"
@@ -29,6 +37,13 @@ import (
 )
 
 func makeAppWithBrowser(extra ...fx.Option) (*fx.App, func()) {
+	return makeAppWithBrowserSettings(func(*settings.Settings) {}, extra...)
+}
+
+func makeAppWithBrowserSettings(
+	configure func(*settings.Settings),
+	extra ...fx.Option,
+) (*fx.App, func()) {
 	fxopts := make([]fx.Option, 0, 9+len(extra))
 	fxopts = append(fxopts,
 		fx.Provide(testapp.NoLogging),
@@ -37,6 +52,7 @@ func makeAppWithBrowser(extra ...fx.Option) (*fx.App, func()) {
 		application.Module,
 		fx.Decorate(func(settings settings.Settings) settings.Settings {
 			settings.ServerLiveness = time.Hour
+			configure(&settings)
 			return settings
 		}),
 		fx.Supply(browser.Config{
@@ -140,6 +156,26 @@ func TestBrowser_Filters(t *testing.T) {
 			filters: "hostport=localport",
 			servers: []string{"Swat4 Server", "New Swat4 Server", "Another Swat4 Server"},
 		},
+		{
+			name:    "filter by country",
+			filters: "country='DE'",
+			servers: []string{"Swat4 Server"},
+		},
+		{
+			name:    "filter by continent",
+			filters: "continent='EU'",
+			servers: []string{"Swat4 Server", "Another Swat4 Server"},
+		},
+		{
+			name:    "filter by country and continent",
+			filters: "continent='EU' and country!='DE' and numplayers=0",
+			servers: []string{"Another Swat4 Server"},
+		},
+		{
+			name:    "filter by asn",
+			filters: "asn=24940",
+			servers: []string{"Swat4 Server"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -169,6 +205,7 @@ func TestBrowser_Filters(t *testing.T) {
 					"maxplayers": "16",
 				}),
 				serverfactory.WithRefreshedAt(time.Now()),
+				serverfactory.WithLocation("DE", "EU", 24940),
 			)
 
 			serverfactory.Create(
@@ -187,6 +224,7 @@ func TestBrowser_Filters(t *testing.T) {
 					"maxplayers": "16",
 				}),
 				serverfactory.WithRefreshedAt(time.Now()),
+				serverfactory.WithLocation("FR", "EU", 16276),
 			)
 
 			serverfactory.Create(
@@ -256,6 +294,519 @@ func TestBrowser_Filters(t *testing.T) {
 	}
 }
 
+func TestBrowser_ListingPolicy(t *testing.T) {
+	tests := []struct {
+		name           string
+		policy         internalbrowser.ListingPolicy
+		servers        []string
+		wantReported   float64
+		wantDiscovered float64
+	}{
+		{
+			name:         "default policy lists reported servers",
+			policy:       "",
+			servers:      []string{"Reported Server", "Reported And Discovered Server"},
+			wantReported: 2,
+		},
+		{
+			name:         "reported servers",
+			policy:       internalbrowser.ListReported,
+			servers:      []string{"Reported Server", "Reported And Discovered Server"},
+			wantReported: 2,
+		},
+		{
+			name:           "discovered servers",
+			policy:         internalbrowser.ListDiscovered,
+			servers:        []string{"Discovered Server", "Reported And Discovered Server"},
+			wantDiscovered: 2,
+		},
+		{
+			name:           "both reported and discovered servers",
+			policy:         internalbrowser.ListBoth,
+			servers:        []string{"Reported Server", "Discovered Server", "Reported And Discovered Server"},
+			wantReported:   2,
+			wantDiscovered: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var serverRepo repositories.ServerRepository
+			var collector *metrics.Collector
+
+			ctx := context.TODO()
+			app, cancel := makeAppWithBrowser(
+				fx.Decorate(func(cfg browser.Config) browser.Config {
+					cfg.ListingPolicy = tt.policy
+					return cfg
+				}),
+				fx.Populate(&serverRepo, &collector),
+			)
+			defer cancel()
+			tu.MustNoErr(app.Start(ctx))
+
+			for _, params := range []struct {
+				hostname string
+				status   ds.DiscoveryStatus
+				since    time.Duration
+			}{
+				{"Reported Server", ds.Master | ds.Info, 0},
+				{"Reported And Discovered Server", ds.Master | ds.Info | ds.Details | ds.Port, 0},
+				{"Discovered Server", ds.Info | ds.Details | ds.Port, 0},
+				// details probes have started failing
+				{"Failing Discovered Server", ds.Info | ds.Details | ds.DetailsRetry | ds.Port, 0},
+				// details probes are no longer succeeding
+				{"Outdated Discovered Server", ds.Info | ds.Details | ds.Port, time.Minute * 61},
+				{"Server With No Details", ds.NoDetails | ds.Port, 0},
+			} {
+				serverfactory.Create(
+					ctx,
+					serverRepo,
+					serverfactory.WithRandomAddress(),
+					serverfactory.WithDiscoveryStatus(params.status),
+					serverfactory.WithInfo(map[string]string{
+						"hostname":   params.hostname,
+						"gamever":    "1.1",
+						"gametype":   "VIP Escort",
+						"hostport":   "10480",
+						"numplayers": "0",
+						"maxplayers": "16",
+					}),
+					serverfactory.WithRefreshedAt(time.Now().Add(-params.since)),
+				)
+			}
+
+			resp := tu.SendBrowserRequest("localhost:13382", "")
+			listedServers := tu.UnpackServerList(resp)
+
+			serverNames := make([]string, 0, len(listedServers))
+			for _, svr := range listedServers {
+				serverNames = append(serverNames, svr["hostname"])
+			}
+			assert.ElementsMatch(t, tt.servers, serverNames)
+
+			reportedMetricValue := testutil.ToFloat64(collector.BrowserListed.WithLabelValues("reported"))
+			assert.InDelta(t, tt.wantReported, reportedMetricValue, 1e-9)
+			discoveredMetricValue := testutil.ToFloat64(collector.BrowserListed.WithLabelValues("discovered"))
+			assert.InDelta(t, tt.wantDiscovered, discoveredMetricValue, 1e-9)
+		})
+	}
+}
+
+func TestBrowser_HideUnverified(t *testing.T) {
+	tests := []struct {
+		name           string
+		hideUnverified bool
+		servers        []string
+	}{
+		{
+			name:           "unverified servers are listed by default",
+			hideUnverified: false,
+			servers:        []string{"Verified Server", "Unverified Server", "Mismatched Server"},
+		},
+		{
+			name:           "unverified servers are hidden",
+			hideUnverified: true,
+			servers:        []string{"Verified Server"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var serverRepo repositories.ServerRepository
+
+			ctx := context.TODO()
+			app, cancel := makeAppWithBrowserSettings(
+				func(settings *settings.Settings) {
+					settings.HideUnverified = tt.hideUnverified
+				},
+				fx.Populate(&serverRepo),
+			)
+			defer cancel()
+			tu.MustNoErr(app.Start(ctx))
+
+			verifiedAt := time.Now().Add(-time.Hour)
+			for _, params := range []struct {
+				hostname string
+				listing  server.Listing
+			}{
+				{"Verified Server", server.Listing{VerifiedAt: verifiedAt}},
+				{"Unverified Server", server.Listing{}},
+				{"Mismatched Server", server.Listing{VerifiedAt: verifiedAt, Mismatches: server.MismatchThreshold}},
+			} {
+				serverfactory.Create(
+					ctx,
+					serverRepo,
+					serverfactory.WithRandomAddress(),
+					serverfactory.WithDiscoveryStatus(ds.Master|ds.Info),
+					serverfactory.WithInfo(map[string]string{
+						"hostname":   params.hostname,
+						"gamever":    "1.1",
+						"gametype":   "VIP Escort",
+						"hostport":   "10480",
+						"numplayers": "0",
+						"maxplayers": "16",
+					}),
+					serverfactory.WithRefreshedAt(time.Now()),
+					serverfactory.WithListing(params.listing),
+				)
+			}
+
+			resp := tu.SendBrowserRequest("localhost:13382", "")
+			listedServers := tu.UnpackServerList(resp)
+
+			serverNames := make([]string, 0, len(listedServers))
+			for _, svr := range listedServers {
+				serverNames = append(serverNames, svr["hostname"])
+			}
+			assert.ElementsMatch(t, tt.servers, serverNames)
+		})
+	}
+}
+
+func TestBrowser_HideFederated(t *testing.T) {
+	tests := []struct {
+		name          string
+		hideFederated bool
+		servers       []string
+	}{
+		{
+			name:          "federated servers are listed by default",
+			hideFederated: false,
+			servers:       []string{"Reported Server", "Discovered Server", "Federated Server"},
+		},
+		{
+			name:          "federated servers are hidden",
+			hideFederated: true,
+			servers:       []string{"Reported Server", "Discovered Server"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var serverRepo repositories.ServerRepository
+
+			ctx := context.TODO()
+			app, cancel := makeAppWithBrowser(
+				fx.Decorate(func(cfg browser.Config) browser.Config {
+					cfg.ListingPolicy = internalbrowser.ListBoth
+					cfg.HideFederated = tt.hideFederated
+					return cfg
+				}),
+				fx.Populate(&serverRepo),
+			)
+			defer cancel()
+			tu.MustNoErr(app.Start(ctx))
+
+			for _, params := range []struct {
+				hostname string
+				status   ds.DiscoveryStatus
+			}{
+				{"Reported Server", ds.Master | ds.Info},
+				{"Discovered Server", ds.Info | ds.Details | ds.Port},
+				{"Federated Server", ds.Federated | ds.Info | ds.Details | ds.Port},
+			} {
+				serverfactory.Create(
+					ctx,
+					serverRepo,
+					serverfactory.WithRandomAddress(),
+					serverfactory.WithDiscoveryStatus(params.status),
+					serverfactory.WithInfo(map[string]string{
+						"hostname":   params.hostname,
+						"gamever":    "1.1",
+						"gametype":   "VIP Escort",
+						"hostport":   "10480",
+						"numplayers": "0",
+						"maxplayers": "16",
+					}),
+					serverfactory.WithRefreshedAt(time.Now()),
+				)
+			}
+
+			resp := tu.SendBrowserRequest("localhost:13382", "")
+			listedServers := tu.UnpackServerList(resp)
+
+			serverNames := make([]string, 0, len(listedServers))
+			for _, svr := range listedServers {
+				serverNames = append(serverNames, svr["hostname"])
+			}
+			assert.ElementsMatch(t, tt.servers, serverNames)
+		})
+	}
+}
+
+func TestBrowser_FeaturedFirst(t *testing.T) {
+	var serverRepo repositories.ServerRepository
+	var featuredRepo repositories.FeaturedRepository
+
+	ctx := context.TODO()
+	app, cancel := makeAppWithBrowser(
+		fx.Populate(&serverRepo, &featuredRepo),
+	)
+	defer cancel()
+	tu.MustNoErr(app.Start(ctx))
+
+	for _, params := range []struct {
+		ip       string
+		hostname string
+		gamever  string
+	}{
+		{"1.1.1.1", "Swat4 Server", "1.1"},
+		{"2.2.2.2", "Featured Server", "1.1"},
+		{"3.3.3.3", "Another Server", "1.1"},
+		{"4.4.4.4", "Top Featured Server", "1.1"},
+		{"5.5.5.5", "Featured Server 1.0", "1.0"},
+		{"6.6.6.6", "Upcoming Featured Server", "1.1"},
+	} {
+		serverfactory.Create(
+			ctx,
+			serverRepo,
+			serverfactory.WithAddress(params.ip, 10480),
+			serverfactory.WithQueryPort(10481),
+			serverfactory.WithDiscoveryStatus(ds.Master|ds.Info),
+			serverfactory.WithInfo(map[string]string{
+				"hostname":   params.hostname,
+				"gamever":    params.gamever,
+				"gametype":   "VIP Escort",
+				"hostport":   "10480",
+				"numplayers": "0",
+				"maxplayers": "16",
+			}),
+			serverfactory.WithRefreshedAt(time.Now()),
+		)
+	}
+
+	for _, item := range []featured.Featured{
+		featured.New(addr.MustNewFromDotted("2.2.2.2", 10480), 10, featured.NC, featured.NC),
+		featured.New(addr.MustNewFromDotted("4.4.4.4", 10480), 1, featured.NC, time.Now().Add(time.Hour)),
+		featured.New(addr.MustNewFromDotted("5.5.5.5", 10480), 0, featured.NC, featured.NC),
+		featured.New(addr.MustNewFromDotted("6.6.6.6", 10480), 0, time.Now().Add(time.Hour), featured.NC),
+	} {
+		tu.MustNoErr(featuredRepo.Add(ctx, item))
+	}
+
+	resp := tu.SendBrowserRequest("localhost:13382", "gamever='1.1'")
+	listedServers := tu.UnpackServerList(resp)
+	require.Len(t, listedServers, 5)
+
+	assert.Equal(t, "Top Featured Server", listedServers[0]["hostname"])
+	assert.Equal(t, "Featured Server", listedServers[1]["hostname"])
+
+	otherNames := make([]string, 0, 3)
+	for _, svr := range listedServers[2:] {
+		otherNames = append(otherNames, svr["hostname"])
+	}
+	assert.ElementsMatch(t, []string{"Swat4 Server", "Another Server", "Upcoming Featured Server"}, otherNames)
+}
+
+type stubLocator map[string]location.Location
+
+func (l stubLocator) Locate(ip net.IP) (location.Location, error) {
+	return l[ip.String()], nil
+}
+
+func TestBrowser_NearestFirst(t *testing.T) {
+	tests := []struct {
+		name     string
+		order    internalbrowser.ListingOrder
+		limit    int
+		featured string
+		want     []string
+		wantAny  bool
+	}{
+		{
+			name:    "servers are not ordered by default",
+			order:   "",
+			want:    []string{"US Server", "German Server", "Busy German Server", "French Server", "Unknown Server"},
+			wantAny: true,
+		},
+		{
+			name:  "nearest servers first",
+			order: internalbrowser.OrderNearest,
+			want:  []string{"Busy German Server", "German Server", "French Server", "US Server", "Unknown Server"},
+		},
+		{
+			name:  "nearest servers are capped",
+			order: internalbrowser.OrderNearest,
+			limit: 3,
+			want:  []string{"Busy German Server", "German Server", "French Server"},
+		},
+		{
+			name:     "featured servers still go first",
+			order:    internalbrowser.OrderNearest,
+			limit:    2,
+			featured: "1.1.1.1",
+			want:     []string{"US Server", "Busy German Server"},
+		},
+		{
+			name:    "servers are capped without ordering",
+			order:   internalbrowser.OrderNone,
+			limit:   10,
+			want:    []string{"US Server", "German Server", "Busy German Server", "French Server", "Unknown Server"},
+			wantAny: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var serverRepo repositories.ServerRepository
+			var featuredRepo repositories.FeaturedRepository
+
+			ctx := context.TODO()
+			app, cancel := makeAppWithBrowser(
+				fx.Decorate(func(cfg browser.Config) browser.Config {
+					cfg.ListingOrder = tt.order
+					cfg.ListingLimit = tt.limit
+					return cfg
+				}),
+				fx.Decorate(func() geoip.Locator {
+					return stubLocator{"127.0.0.1": location.New(net.ParseIP("127.0.0.1"), "DE", "EU", 0)}
+				}),
+				fx.Populate(&serverRepo, &featuredRepo),
+			)
+			defer cancel()
+			tu.MustNoErr(app.Start(ctx))
+
+			for _, params := range []struct {
+				ip         string
+				hostname   string
+				numplayers string
+				country    string
+				continent  string
+			}{
+				{"1.1.1.1", "US Server", "10", "US", "NA"},
+				{"2.2.2.2", "German Server", "2", "DE", "EU"},
+				{"3.3.3.3", "Busy German Server", "12", "DE", "EU"},
+				{"4.4.4.4", "French Server", "0", "FR", "EU"},
+				{"5.5.5.5", "Unknown Server", "16", "", ""},
+			} {
+				serverfactory.Create(
+					ctx,
+					serverRepo,
+					serverfactory.WithAddress(params.ip, 10480),
+					serverfactory.WithDiscoveryStatus(ds.Master|ds.Info),
+					serverfactory.WithInfo(map[string]string{
+						"hostname":   params.hostname,
+						"gamever":    "1.1",
+						"gametype":   "VIP Escort",
+						"hostport":   "10480",
+						"numplayers": params.numplayers,
+						"maxplayers": "16",
+					}),
+					serverfactory.WithRefreshedAt(time.Now()),
+					serverfactory.WithLocation(params.country, params.continent, 0),
+				)
+			}
+			if tt.featured != "" {
+				item := featured.New(addr.MustNewFromDotted(tt.featured, 10480), 1, featured.NC, featured.NC)
+				tu.MustNoErr(featuredRepo.Add(ctx, item))
+			}
+
+			resp := tu.SendBrowserRequest("localhost:13382", "")
+			listedServers := tu.UnpackServerList(resp)
+
+			serverNames := make([]string, 0, len(listedServers))
+			for _, svr := range listedServers {
+				serverNames = append(serverNames, svr["hostname"])
+			}
+			if tt.wantAny {
+				assert.ElementsMatch(t, tt.want, serverNames)
+			} else {
+				assert.Equal(t, tt.want, serverNames)
+			}
+		})
+	}
+}
+
+func TestBrowser_Announcements(t *testing.T) {
+	var serverRepo repositories.ServerRepository
+	var collector *metrics.Collector
+
+	announcementsPath := filepath.Join(t.TempDir(), "announcements.json")
+	announcements := fmt.Sprintf(`[
+		{"hostname": "[c=FFFF00]Welcome!", "address": "127.0.0.1:1", "params": {"gametype": "VIP Escort"}},
+		{"hostname": "CO-OP night", "address": "127.0.0.2:2", "params": {"gametype": "CO-OP"}},
+		{"hostname": "Expansion news", "address": "127.0.0.3:3", "games": ["swat4xp1"]},
+		{"hostname": "Old news", "address": "127.0.0.4:4", "until": %q},
+		{"hostname": "Turned off", "address": "127.0.0.5:5", "exclude_versions": [0]}
+	]`, time.Now().Add(-time.Hour).Format(time.RFC3339))
+	tu.MustNoErr(os.WriteFile(announcementsPath, []byte(announcements), 0o600))
+
+	ctx := context.TODO()
+	app, cancel := makeAppWithBrowser(
+		fx.Decorate(func(cfg browser.Config) browser.Config {
+			cfg.Announcements = announcementsPath
+			return cfg
+		}),
+		fx.Populate(&serverRepo, &collector),
+	)
+	defer cancel()
+	tu.MustNoErr(app.Start(ctx))
+
+	serverfactory.Create(
+		ctx,
+		serverRepo,
+		serverfactory.WithAddress("1.1.1.1", 10480),
+		serverfactory.WithQueryPort(10481),
+		serverfactory.WithDiscoveryStatus(ds.Master|ds.Info),
+		serverfactory.WithInfo(map[string]string{
+			"hostname":   "Swat4 Server",
+			"gamever":    "1.1",
+			"gametype":   "VIP Escort",
+			"hostport":   "10480",
+			"numplayers": "0",
+			"maxplayers": "16",
+		}),
+		serverfactory.WithRefreshedAt(time.Now()),
+	)
+
+	resp := tu.SendBrowserRequest("localhost:13382", "")
+	listedServers := tu.UnpackServerList(resp)
+	require.Len(t, listedServers, 3)
+	assert.Equal(t, "[c=FFFF00]Welcome!", listedServers[0]["hostname"])
+	assert.Equal(t, "127.0.0.1", listedServers[0]["host"])
+	assert.Equal(t, "1", listedServers[0]["port"])
+	assert.Equal(t, "1", listedServers[0]["hostport"])
+	assert.Equal(t, "VIP Escort", listedServers[0]["gametype"])
+	assert.Empty(t, listedServers[0]["mapname"])
+	assert.Equal(t, "CO-OP night", listedServers[1]["hostname"])
+	assert.Equal(t, "127.0.0.2", listedServers[1]["host"])
+	assert.Equal(t, "Swat4 Server", listedServers[2]["hostname"])
+	assert.Equal(t, "1.1.1.1", listedServers[2]["host"])
+
+	// announcements are subject to the client's filters
+	resp = tu.SendBrowserRequest("localhost:13382", "gametype='VIP Escort'")
+	listedServers = tu.UnpackServerList(resp)
+	require.Len(t, listedServers, 2)
+	assert.Equal(t, "[c=FFFF00]Welcome!", listedServers[0]["hostname"])
+	assert.Equal(t, "Swat4 Server", listedServers[1]["hostname"])
+
+	resp = tu.SendBrowserRequest("localhost:13382", "gametype='CO-OP'")
+	listedServers = tu.UnpackServerList(resp)
+	require.Len(t, listedServers, 1)
+	assert.Equal(t, "CO-OP night", listedServers[0]["hostname"])
+
+	announcedMetricValue := testutil.ToFloat64(collector.BrowserListed.WithLabelValues("announcement"))
+	assert.InDelta(t, 4, announcedMetricValue, 1e-9)
+	reportedMetricValue := testutil.ToFloat64(collector.BrowserListed.WithLabelValues("reported"))
+	assert.InDelta(t, 2, reportedMetricValue, 1e-9)
+}
+
+func TestBrowser_InvalidAnnouncements(t *testing.T) {
+	announcementsPath := filepath.Join(t.TempDir(), "announcements.json")
+	tu.MustNoErr(os.WriteFile(announcementsPath, []byte(`[{"hostname": "News"}]`), 0o600))
+
+	app, cancel := makeAppWithBrowser(
+		fx.Decorate(func(cfg browser.Config) browser.Config {
+			cfg.Announcements = announcementsPath
+			return cfg
+		}),
+	)
+	defer cancel()
+	err := app.Start(context.TODO())
+	assert.ErrorIs(t, err, internalbrowser.ErrAnnouncementInvalidAddress)
+}
+
 func TestBrowser_ParseResponse(t *testing.T) {
 	var repo repositories.ServerRepository
 
@@ -12,9 +12,14 @@ import (
 
 	"github.com/sergeii/swat4master/cmd/swat4master/application"
 	"github.com/sergeii/swat4master/cmd/swat4master/components/cleaner"
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	"github.com/sergeii/swat4master/internal/core/entities/details"
 	"github.com/sergeii/swat4master/internal/core/entities/instance"
+	"github.com/sergeii/swat4master/internal/core/entities/profile"
+	"github.com/sergeii/swat4master/internal/core/entities/session"
 	"github.com/sergeii/swat4master/internal/core/repositories"
 	"github.com/sergeii/swat4master/internal/metrics"
+	"github.com/sergeii/swat4master/internal/scheduler"
 	"github.com/sergeii/swat4master/internal/testutils/factories/instancefactory"
 	"github.com/sergeii/swat4master/internal/testutils/factories/serverfactory"
 	"github.com/sergeii/swat4master/tests/testapp"
@@ -54,11 +59,13 @@ func makeAppWithCleaner(extra ...fx.Option) (*fx.App, func()) {
 func TestCleaner_OK(t *testing.T) {
 	var serverRepo repositories.ServerRepository
 	var instanceRepo repositories.InstanceRepository
+	var profileRepo repositories.ProfileRepository
+	var sessionRepo repositories.SessionRepository
 	var collector *metrics.Collector
 
 	ctx := context.TODO()
 	app, cancel := makeAppWithCleaner(
-		fx.Populate(&serverRepo, &instanceRepo, &collector),
+		fx.Populate(&serverRepo, &instanceRepo, &profileRepo, &sessionRepo, &collector),
 	)
 	defer cancel()
 	app.Start(ctx) //nolint: errcheck
@@ -88,7 +95,7 @@ func TestCleaner_OK(t *testing.T) {
 		serverfactory.WithAddress("1.1.1.1", 10480),
 		serverfactory.WithQueryPort(10481),
 	)
-	serverfactory.Create(
+	gs2 := serverfactory.Create(
 		ctx,
 		serverRepo,
 		serverfactory.WithAddress("2.2.2.2", 10480),
@@ -101,6 +108,17 @@ func TestCleaner_OK(t *testing.T) {
 		serverfactory.WithQueryPort(10481),
 	)
 
+	// the profiles are removed along with their servers
+	for _, svrAddr := range []addr.Addr{gs1.Addr, gs2.Addr} {
+		item := profile.New(svrAddr, "Best server", "", "", "EU", time.Now())
+		require.NoError(t, profileRepo.Save(ctx, item))
+	}
+
+	// so are the sessions of the players on the servers
+	sess1 := session.New(gs1.Addr, details.Player{Name: "Serge"}, time.Now())
+	sess2 := session.New(gs2.Addr, details.Player{Name: "Mosquito"}, time.Now())
+	require.NoError(t, sessionRepo.Save(ctx, sess1, sess2))
+
 	// wait for cleaner to run some cycles
 	<-time.After(time.Millisecond * 100)
 
@@ -138,6 +156,24 @@ func TestCleaner_OK(t *testing.T) {
 	_, err = serverRepo.Get(ctx, gs5.Addr)
 	require.NoError(t, err)
 
+	_, err = profileRepo.Get(ctx, gs1.Addr)
+	require.NoError(t, err)
+	_, err = profileRepo.Get(ctx, gs2.Addr)
+	require.ErrorIs(t, err, repositories.ErrProfileNotFound)
+
+	active, err := sessionRepo.Active(ctx, gs1.Addr)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	assert.Equal(t, sess1.ID, active[0].ID)
+	active, err = sessionRepo.Active(ctx, gs2.Addr)
+	require.NoError(t, err)
+	assert.Empty(t, active)
+	ended, err := sessionRepo.ListByServer(ctx, gs2.Addr, 10)
+	require.NoError(t, err)
+	require.Len(t, ended, 1)
+	assert.Equal(t, sess2.ID, ended[0].ID)
+	assert.False(t, ended[0].IsActive())
+
 	// 2 instances should be cleaned up
 	insCount, err := instanceRepo.Count(ctx)
 	require.NoError(t, err)
@@ -179,3 +215,14 @@ func TestCleaner_NoErrorWhenNothingToClean(t *testing.T) {
 		assert.InDelta(t, 0.0, errorValue, 1e-9)
 	}
 }
+
+func TestCleaner_InvalidSchedule(t *testing.T) {
+	app, cancel := makeAppWithCleaner(
+		fx.Decorate(func(cfg cleaner.Config) cleaner.Config {
+			cfg.CleanSchedule = "every minute"
+			return cfg
+		}),
+	)
+	defer cancel()
+	assert.ErrorIs(t, app.Err(), scheduler.ErrInvalidSchedule)
+}
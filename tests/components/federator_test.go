@@ -0,0 +1,153 @@
+package components_test
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+
+	"github.com/sergeii/swat4master/cmd/swat4master/application"
+	"github.com/sergeii/swat4master/cmd/swat4master/components/federator"
+	"github.com/sergeii/swat4master/internal/browser"
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+	"github.com/sergeii/swat4master/internal/metrics"
+	"github.com/sergeii/swat4master/internal/testutils/factories/serverfactory"
+	"github.com/sergeii/swat4master/pkg/gamespy/browsing/client"
+	"github.com/sergeii/swat4master/tests/testapp"
+)
+
+func makeAppWithFederator(upstreams []string, extra ...fx.Option) (*fx.App, func()) {
+	fxopts := make([]fx.Option, 0, 8+len(extra))
+	fxopts = append(fxopts,
+		fx.Provide(testapp.NoLogging),
+		fx.Provide(testapp.ProvideSettings),
+		fx.Provide(testapp.ProvidePersistence),
+		application.Module,
+		fx.Supply(federator.Config{
+			Upstreams: upstreams,
+			Interval:  time.Hour,
+			Timeout:   time.Millisecond * 50,
+		}),
+		federator.Module,
+		fx.NopLogger,
+		fx.Invoke(func(*federator.Component) {}),
+	)
+	fxopts = append(fxopts, extra...)
+	app := fx.New(fxopts...)
+	return app, func() {
+		app.Stop(context.TODO()) //nolint: errcheck
+	}
+}
+
+func prepareUpstream(servers []client.Server) (string, func()) {
+	var gameKey [6]byte
+	copy(gameKey[:], browser.GameEncKey)
+	upstream, cancel := client.PrepareMasterServer(gameKey, servers)
+	return upstream.LocalAddr().String(), cancel
+}
+
+func TestFederator_OK(t *testing.T) {
+	var serverRepo repositories.ServerRepository
+	var probeRepo repositories.ProbeRepository
+	var collector *metrics.Collector
+
+	ctx := context.TODO()
+
+	upstream, cancelUpstream := prepareUpstream([]client.Server{
+		{
+			Addr:   netip.MustParseAddrPort("1.1.1.1:10481"),
+			Fields: map[string]string{"hostname": "Swat4 Server", "hostport": "10480"},
+		},
+		{
+			Addr:   netip.MustParseAddrPort("2.2.2.2:10581"),
+			Fields: map[string]string{"hostname": "Another Swat4 Server", "hostport": "10580"},
+		},
+		{
+			Addr:   netip.MustParseAddrPort("3.3.3.3:10481"),
+			Fields: map[string]string{"hostname": "Known Swat4 Server", "hostport": "10480"},
+		},
+		{
+			Addr:   netip.MustParseAddrPort("4.4.4.4:10481"),
+			Fields: map[string]string{"hostname": "Broken Swat4 Server", "hostport": "foo"},
+		},
+	})
+	defer cancelUpstream()
+
+	app, cancel := makeAppWithFederator(
+		[]string{upstream},
+		fx.Populate(&serverRepo, &probeRepo, &collector),
+	)
+	defer cancel()
+
+	known := serverfactory.Create(
+		ctx,
+		serverRepo,
+		serverfactory.WithAddress("3.3.3.3", 10480),
+		serverfactory.WithQueryPort(10481),
+		serverfactory.WithDiscoveryStatus(ds.Master|ds.Info|ds.Details),
+	)
+
+	app.Start(ctx) //nolint: errcheck
+
+	// the federator runs once at start, without waiting for the first tick
+	<-time.After(time.Millisecond * 100)
+
+	svr1, err := serverRepo.Get(ctx, addr.MustNewFromDotted("1.1.1.1", 10480))
+	require.NoError(t, err)
+	assert.Equal(t, ds.Federated|ds.PortRetry, svr1.DiscoveryStatus)
+
+	svr2, err := serverRepo.Get(ctx, addr.MustNewFromDotted("2.2.2.2", 10580))
+	require.NoError(t, err)
+	assert.Equal(t, ds.Federated|ds.PortRetry, svr2.DiscoveryStatus)
+
+	// known servers are left intact
+	svr3, err := serverRepo.Get(ctx, known.Addr)
+	require.NoError(t, err)
+	assert.Equal(t, ds.Master|ds.Info|ds.Details, svr3.DiscoveryStatus)
+
+	_, err = serverRepo.Get(ctx, addr.MustNewFromDotted("4.4.4.4", 10480))
+	require.ErrorIs(t, err, repositories.ErrServerNotFound)
+
+	probeCount, err := probeRepo.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, probeCount)
+
+	assert.InDelta(t, 1, testutil.ToFloat64(collector.FederationRequests.WithLabelValues(upstream)), 1e-9)
+	assert.InDelta(t, 0, testutil.ToFloat64(collector.FederationErrors.WithLabelValues(upstream)), 1e-9)
+	assert.InDelta(t, 4, testutil.ToFloat64(collector.FederationServers.WithLabelValues(upstream)), 1e-9)
+	assert.InDelta(t, 3, testutil.ToFloat64(collector.FederationImported.WithLabelValues(upstream)), 1e-9)
+}
+
+func TestFederator_UpstreamIsUnavailable(t *testing.T) {
+	var serverRepo repositories.ServerRepository
+	var collector *metrics.Collector
+
+	ctx := context.TODO()
+
+	// take the address of an upstream that is no longer listening
+	upstream, cancelUpstream := prepareUpstream(nil)
+	cancelUpstream()
+
+	app, cancel := makeAppWithFederator(
+		[]string{upstream},
+		fx.Populate(&serverRepo, &collector),
+	)
+	defer cancel()
+	app.Start(ctx) //nolint: errcheck
+
+	<-time.After(time.Millisecond * 100)
+
+	count, err := serverRepo.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	assert.InDelta(t, 1, testutil.ToFloat64(collector.FederationRequests.WithLabelValues(upstream)), 1e-9)
+	assert.InDelta(t, 1, testutil.ToFloat64(collector.FederationErrors.WithLabelValues(upstream)), 1e-9)
+}
@@ -1,6 +1,7 @@
 package components_test
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"net"
@@ -8,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/fx"
@@ -20,6 +22,7 @@ import (
 	"github.com/sergeii/swat4master/internal/core/entities/probe"
 	"github.com/sergeii/swat4master/internal/core/entities/server"
 	"github.com/sergeii/swat4master/internal/core/repositories"
+	"github.com/sergeii/swat4master/internal/metrics"
 	"github.com/sergeii/swat4master/internal/testutils/factories/serverfactory"
 	"github.com/sergeii/swat4master/pkg/gamespy/serverquery/gs1"
 	"github.com/sergeii/swat4master/tests/testapp"
@@ -28,6 +31,7 @@ import (
 func TestProber_Run(t *testing.T) {
 	var serverRepo repositories.ServerRepository
 	var probeRepo repositories.ProbeRepository
+	var sessionRepo repositories.SessionRepository
 
 	ctx, cancel := context.WithCancel(t.Context())
 	defer cancel()
@@ -42,17 +46,25 @@ func TestProber_Run(t *testing.T) {
 			Concurrency:  5,
 			ProbeTimeout: time.Millisecond * 50,
 			PortOffsets:  []int{1},
+			QuerySockets: 2,
+
+			RefreshMinInterval: time.Second * 5,
+			RefreshMaxInterval: time.Minute,
 		}),
 		prober.Module,
 		fx.NopLogger,
 		fx.Invoke(func(*prober.Component) {}),
-		fx.Populate(&serverRepo, &probeRepo),
+		fx.Populate(&serverRepo, &probeRepo, &sessionRepo),
 	)
 
 	var i1 int64
 	responses1 := make(chan []byte)
 	udp1, cancel1 := gs1.ServerFactory(
-		func(_ context.Context, conn *net.UDPConn, addr *net.UDPAddr, _ []byte) {
+		func(_ context.Context, conn *net.UDPConn, addr *net.UDPAddr, req []byte) {
+			// the server only supports the gs1 protocol
+			if !bytes.HasPrefix(req, []byte("\\status\\")) {
+				return
+			}
 			packet := <-responses1
 			conn.WriteToUDP(packet, addr) //nolint: errcheck
 			atomic.AddInt64(&i1, 1)
@@ -64,7 +76,11 @@ func TestProber_Run(t *testing.T) {
 	responses2 := make(chan []byte)
 	var i2 int64
 	udp2, cancel2 := gs1.ServerFactory(
-		func(_ context.Context, conn *net.UDPConn, addr *net.UDPAddr, _ []byte) {
+		func(_ context.Context, conn *net.UDPConn, addr *net.UDPAddr, req []byte) {
+			// the server only supports the gs1 protocol
+			if !bytes.HasPrefix(req, []byte("\\status\\")) {
+				return
+			}
 			packet := <-responses2
 			conn.WriteToUDP(packet, addr) //nolint: errcheck
 			atomic.AddInt64(&i2, 1)
@@ -75,7 +91,10 @@ func TestProber_Run(t *testing.T) {
 
 	var i3 int64
 	udp3, cancel3 := gs1.ServerFactory(
-		func(_ context.Context, _ *net.UDPConn, _ *net.UDPAddr, _ []byte) {
+		func(_ context.Context, _ *net.UDPConn, _ *net.UDPAddr, req []byte) {
+			if !bytes.HasPrefix(req, []byte("\\status\\")) {
+				return
+			}
 			atomic.AddInt64(&i3, 1)
 		},
 	)
@@ -178,15 +197,37 @@ func TestProber_Run(t *testing.T) {
 	assert.Equal(t, "-==MYT Team Svr==-", updatedSvr1.Details.Info.Hostname)
 	assert.Equal(t, 16, updatedSvr1.Details.Info.MaxPlayers)
 	assert.Equal(t, 0, updatedSvr1.Details.Info.NumPlayers)
+	assert.Equal(t, "gs1/vanilla", updatedSvr1.QueryProtocol)
+	assert.Equal(t, 1, updatedSvr1.Latency.Samples)
+	assert.Positive(t, updatedSvr1.Latency.Last)
+	assert.False(t, updatedSvr1.QueriedAt.IsZero())
+	// the details are new, so the server is refreshed again at the shortest interval
+	assert.Equal(t, updatedSvr1.QueriedAt, updatedSvr1.Schedule.ChangedAt)
+	assert.Equal(t, updatedSvr1.QueriedAt.Add(time.Second*5), updatedSvr1.Schedule.NextRefreshAt)
 
 	updatedSvr2, _ := serverRepo.Get(ctx, svr2.Addr)
 	assert.True(t, updatedSvr2.HasDiscoveryStatus(ds.Master|ds.Port|ds.Info|ds.Details))
 	assert.Equal(t, "[c=ffff00]WWW.EPiCS.TOP", updatedSvr2.Info.Hostname)
+	assert.Equal(t, "gs1/vanilla", updatedSvr2.QueryProtocol)
+	assert.Equal(t, 1, updatedSvr2.Latency.Samples)
 	assert.Equal(t, int64(1), atomic.LoadInt64(&i2))
 
+	// the players of the probed server have started their sessions
+	activeSessions, _ := sessionRepo.Active(ctx, svr2.Addr)
+	assert.Len(t, activeSessions, 8)
+	emptySvrSessions, _ := sessionRepo.Active(ctx, svr1.Addr)
+	assert.Empty(t, emptySvrSessions)
+	playerSessions, _ := sessionRepo.ListByPlayer(ctx, "chester", 10)
+	require.Len(t, playerSessions, 1)
+	assert.Equal(t, svr2.Addr, playerSessions[0].Addr)
+	assert.Equal(t, 6, playerSessions[0].Score)
+	assert.True(t, playerSessions[0].IsActive())
+
 	retriedSvr, _ := serverRepo.Get(ctx, svr3.Addr)
 	assert.True(t, retriedSvr.HasDiscoveryStatus(ds.Master|ds.Port|ds.DetailsRetry))
 	assert.Equal(t, "Swat4 Server", retriedSvr.Info.Hostname)
+	assert.False(t, retriedSvr.Latency.IsKnown())
+	assert.True(t, retriedSvr.Schedule.NextRefreshAt.IsZero())
 	assert.Equal(t, int64(1), atomic.LoadInt64(&i3))
 
 	notUpdatedSvr, _ := serverRepo.Get(ctx, svr4.Addr)
@@ -202,3 +243,69 @@ func TestProber_Run(t *testing.T) {
 	assert.Equal(t, svr3.QueryPort, retryProbe.Port)
 	assert.Equal(t, probe.GoalDetails, retryProbe.Goal)
 }
+
+func TestProber_Autoscaling(t *testing.T) {
+	var serverRepo repositories.ServerRepository
+	var probeRepo repositories.ProbeRepository
+	var collector *metrics.Collector
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	app := fx.New(
+		fx.Provide(testapp.NoLogging),
+		fx.Provide(testapp.ProvideSettings),
+		fx.Provide(testapp.ProvidePersistence),
+		application.Module,
+		fx.Supply(prober.Config{
+			PollInterval:   time.Millisecond * 10,
+			Concurrency:    6,
+			MinConcurrency: 1,
+			ScaleInterval:  time.Millisecond * 50,
+			ProbeTimeout:   time.Millisecond * 100,
+			PortOffsets:    []int{1},
+		}),
+		prober.Module,
+		fx.NopLogger,
+		fx.Invoke(func(*prober.Component) {}),
+		fx.Populate(&serverRepo, &probeRepo, &collector),
+	)
+
+	// the server never responds, so every probe lasts until timeout
+	udp, cancelSvr := gs1.ServerFactory(
+		func(context.Context, *net.UDPConn, *net.UDPAddr, []byte) {},
+	)
+	defer cancelSvr()
+	udpAddr := udp.LocalAddr()
+
+	app.Start(context.TODO()) //nolint: errcheck
+	defer func() {
+		app.Stop(context.TODO()) //nolint: errcheck
+	}()
+
+	// the pool starts with the min number of workers
+	<-time.After(time.Millisecond * 25)
+	assert.Equal(t, 1, int(testutil.ToFloat64(collector.DiscoveryWorkers)))
+
+	for i := range 30 {
+		svr := server.MustNewFromAddr(addr.NewForTesting(udpAddr.IP, 20000+i), udpAddr.Port)
+		svr.UpdateDiscoveryStatus(ds.Port)
+		svr, _ = serverRepo.Add(ctx, svr, repositories.ServerOnConflictIgnore)
+		probeRepo.Add(ctx, probe.New(svr.Addr, svr.QueryPort, probe.GoalDetails, 0)) //nolint: errcheck
+	}
+
+	// the queue is deep, so the pool is scaled up to the max
+	<-time.After(time.Millisecond * 100)
+	assert.Equal(t, 6, int(testutil.ToFloat64(collector.DiscoveryWorkers)))
+	assert.Equal(t, 6, int(testutil.ToFloat64(collector.DiscoveryWorkersTarget)))
+	assert.Positive(t, testutil.ToFloat64(collector.DiscoveryWorkersScaling.WithLabelValues("up")))
+
+	// once the queue is drained, the pool is gradually scaled down to the min
+	<-time.After(time.Millisecond * 1200)
+	probeCount, _ := probeRepo.Count(ctx)
+	assert.Equal(t, 0, probeCount)
+	assert.Equal(t, 1, int(testutil.ToFloat64(collector.DiscoveryWorkers)))
+	assert.Equal(t, 1, int(testutil.ToFloat64(collector.DiscoveryWorkersTarget)))
+	assert.Positive(t, testutil.ToFloat64(collector.DiscoveryWorkersScaling.WithLabelValues("down")))
+	assert.Equal(t, 30, int(testutil.ToFloat64(collector.DiscoveryProbeFailures.WithLabelValues("details"))))
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/sergeii/swat4master/cmd/swat4master/application"
 	"github.com/sergeii/swat4master/cmd/swat4master/components/refresher"
 	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/internal/core/entities/jobrun"
 	"github.com/sergeii/swat4master/internal/core/entities/probe"
 	"github.com/sergeii/swat4master/internal/core/entities/server"
 	"github.com/sergeii/swat4master/internal/core/repositories"
@@ -187,3 +188,74 @@ func TestRefresher_OK(t *testing.T) {
 	producedMetricValue := testutil.ToFloat64(collector.DiscoveryQueueProduced)
 	assert.InDelta(t, 12.0, producedMetricValue, 1e-9)
 }
+
+func TestRefresher_OnlyDueServers(t *testing.T) {
+	var serverRepo repositories.ServerRepository
+	var probeRepo repositories.ProbeRepository
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	app, cancelApp := makeAppWithRefresher(
+		fx.Populate(&serverRepo, &probeRepo),
+	)
+	defer cancelApp()
+	app.Start(ctx) //nolint: errcheck
+
+	now := time.Now()
+	serverfactory.Create(
+		ctx, serverRepo,
+		serverfactory.WithAddress("1.1.1.1", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Port),
+	)
+	serverfactory.Create(
+		ctx, serverRepo,
+		serverfactory.WithAddress("2.2.2.2", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Port),
+		serverfactory.WithSchedule(server.Schedule{NextRefreshAt: now.Add(-time.Second)}),
+	)
+	idle := serverfactory.Create(
+		ctx, serverRepo,
+		serverfactory.WithAddress("3.3.3.3", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Port),
+		serverfactory.WithSchedule(server.Schedule{NextRefreshAt: now.Add(time.Millisecond * 200)}),
+	)
+
+	// let refresher run a cycle, the idle server is not due yet
+	<-time.After(time.Millisecond * 150)
+
+	result, err := countRefresherProbes(ctx, probeRepo)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"1.1.1.1:10480", "2.2.2.2:10480"}, result.probes)
+
+	// the idle server is due by now
+	<-time.After(time.Millisecond * 100)
+
+	result, err = countRefresherProbes(ctx, probeRepo)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"1.1.1.1:10480", "2.2.2.2:10480", idle.Addr.String()}, result.probes)
+}
+
+func TestRefresher_RecordsJobRuns(t *testing.T) {
+	var jobRunRepo repositories.JobRunRepository
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	app, cancelApp := makeAppWithRefresher(
+		fx.Populate(&jobRunRepo),
+	)
+	defer cancelApp()
+	app.Start(ctx) //nolint: errcheck
+
+	// let refresher run a couple of cycles
+	<-time.After(time.Millisecond * 250)
+
+	runs, err := jobRunRepo.List(ctx, "refresher", 10)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	for _, run := range runs {
+		assert.Equal(t, jobrun.OutcomeSuccess, run.Outcome)
+	}
+	assert.Equal(t, time.Millisecond*100, runs[0].ScheduledAt.Sub(runs[1].ScheduledAt))
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/sergeii/swat4master/internal/core/entities/server"
 	"github.com/sergeii/swat4master/internal/core/repositories"
 	"github.com/sergeii/swat4master/internal/metrics"
+	"github.com/sergeii/swat4master/internal/reporter/relay"
 	tu "github.com/sergeii/swat4master/internal/testutils"
 	"github.com/sergeii/swat4master/internal/testutils/factories/serverfactory"
 	"github.com/sergeii/swat4master/pkg/slice"
@@ -763,3 +764,207 @@ func TestReporter_Keepalive_Errors(t *testing.T) {
 		})
 	}
 }
+
+func makeAppWithRelayingReporter(
+	listenAddr string,
+	peers, sources []string,
+	extra ...fx.Option,
+) (*fx.App, func()) {
+	fxopts := make([]fx.Option, 0, 8+len(extra))
+	fxopts = append(fxopts,
+		fx.Provide(testapp.NoLogging),
+		fx.Provide(testapp.ProvideSettings),
+		fx.Provide(testapp.ProvidePersistence),
+		application.Module,
+		fx.Supply(reporter.Config{
+			ListenAddr:      listenAddr,
+			BufferSize:      1024,
+			RelayPeers:      peers,
+			RelaySources:    sources,
+			RelayQueueSize:  10,
+			RelayMinBackoff: time.Second,
+			RelayMaxBackoff: time.Minute,
+		}),
+		reporter.Module,
+		fx.NopLogger,
+		fx.Invoke(func(*reporter.Component) {}),
+	)
+	fxopts = append(fxopts, extra...)
+	app := fx.New(fxopts...)
+	return app, func() {
+		app.Stop(context.TODO()) //nolint: errcheck
+	}
+}
+
+func readRelayed(t *testing.T, conn net.PacketConn) []byte {
+	t.Helper()
+	buf := make([]byte, 2048)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Millisecond*100)))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	return buf[:n]
+}
+
+func TestReporter_Relay_OK(t *testing.T) {
+	var collector *metrics.Collector
+
+	peer := tu.Must(net.ListenPacket("udp", "127.0.0.1:0"))
+	defer peer.Close()
+
+	ctx := context.TODO()
+	app, cancel := makeAppWithRelayingReporter(
+		"127.0.0.1:33811",
+		[]string{peer.LocalAddr().String()},
+		nil,
+		fx.Populate(&collector),
+	)
+	defer cancel()
+	app.Start(ctx) //nolint: errcheck
+
+	client := tu.NewUDPClient("127.0.0.1:33811", 1024, time.Millisecond*10)
+	defer client.Close()
+	clientAddr := client.LocalAddr
+
+	reportReq := tu.PackHeartbeatRequest([]byte{0xfe, 0xed, 0xf0, 0x0d}, tu.GenServerParams())
+	_, err := client.Send(reportReq)
+	require.NoError(t, err)
+
+	// the original source address is passed in the envelope
+	relayed := readRelayed(t, peer)
+	assert.Equal(t, []byte{0xfe, 0xfe, 'R', 0x01, 127, 0, 0, 1}, relayed[:8])
+	assert.Equal(t, uint16(clientAddr.Port), binary.BigEndian.Uint16(relayed[8:10])) //nolint:gosec
+	assert.Equal(t, reportReq, relayed[10:])
+
+	// keepalive requests are forwarded too
+	keepaliveReq := []byte{0x08, 0xfe, 0xed, 0xf0, 0x0d}
+	_, err = client.Send(keepaliveReq)
+	require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	assert.Equal(t, keepaliveReq, readRelayed(t, peer)[10:])
+
+	// other requests are not forwarded
+	tu.SendUDP("127.0.0.1:33811", []byte{0x09})
+	require.NoError(t, peer.SetReadDeadline(time.Now().Add(time.Millisecond*50)))
+	_, _, err = peer.ReadFrom(make([]byte, 1024))
+	require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+
+	forwarded := testutil.ToFloat64(collector.RelayForwarded.WithLabelValues(peer.LocalAddr().String()))
+	assert.InDelta(t, 2, forwarded, 1e-9)
+	dropped := testutil.ToFloat64(collector.RelayDropped.WithLabelValues(peer.LocalAddr().String()))
+	assert.InDelta(t, 0, dropped, 1e-9)
+}
+
+func TestReporter_Relay_RejectedRequestsAreNotForwarded(t *testing.T) {
+	var collector *metrics.Collector
+
+	peer := tu.Must(net.ListenPacket("udp", "127.0.0.1:0"))
+	defer peer.Close()
+
+	ctx := context.TODO()
+	app, cancel := makeAppWithRelayingReporter(
+		"127.0.0.1:33811",
+		[]string{peer.LocalAddr().String()},
+		nil,
+		fx.Populate(&collector),
+	)
+	defer cancel()
+	app.Start(ctx) //nolint: errcheck
+
+	// keepalive from an unknown instance is rejected
+	client := tu.NewUDPClient("127.0.0.1:33811", 1024, time.Millisecond*10)
+	defer client.Close()
+	_, err := client.Send([]byte{0x08, 0xde, 0xad, 0xbe, 0xef})
+	require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+
+	require.NoError(t, peer.SetReadDeadline(time.Now().Add(time.Millisecond*50)))
+	_, _, err = peer.ReadFrom(make([]byte, 1024))
+	require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+
+	forwarded := testutil.ToFloat64(collector.RelayForwarded.WithLabelValues(peer.LocalAddr().String()))
+	assert.InDelta(t, 0, forwarded, 1e-9)
+}
+
+func TestReporter_Relay_BetweenMasters(t *testing.T) {
+	var relayCollector, peerCollector *metrics.Collector
+	var peerServerRepo repositories.ServerRepository
+	var peerInstanceRepo repositories.InstanceRepository
+
+	ctx := context.TODO()
+	relayApp, cancelRelay := makeAppWithRelayingReporter(
+		"127.0.0.1:33811",
+		[]string{"127.0.0.1:33812"},
+		nil,
+		fx.Populate(&relayCollector),
+	)
+	defer cancelRelay()
+	relayApp.Start(ctx) //nolint: errcheck
+
+	peerApp, cancelPeer := makeAppWithRelayingReporter(
+		"127.0.0.1:33812",
+		[]string{"127.0.0.1:33811"},
+		[]string{"127.0.0.1"},
+		fx.Populate(&peerCollector, &peerServerRepo, &peerInstanceRepo),
+	)
+	defer cancelPeer()
+	peerApp.Start(ctx) //nolint: errcheck
+
+	client := tu.NewUDPClient("127.0.0.1:33811", 1024, time.Millisecond*10)
+	defer client.Close()
+
+	instanceID := []byte{0xfe, 0xed, 0xf0, 0x0d}
+	params := tu.GenExtraServerParams(map[string]string{"hostport": "10480", "hostname": "Relayed Server"})
+	resp, err := client.Send(tu.PackHeartbeatRequest(instanceID, params))
+	require.NoError(t, err)
+	// the game server is answered by the master it reports to
+	assert.Equal(t, []byte{0xfe, 0xfd, 0x01, 0xfe, 0xed, 0xf0, 0x0d}, resp[:7])
+
+	// the peer master sees the server under the address of the game server
+	svrAddr := addr.MustNewFromDotted("127.0.0.1", 10480)
+	require.Eventually(t, func() bool {
+		_, err := peerServerRepo.Get(ctx, svrAddr)
+		return err == nil
+	}, time.Millisecond*500, time.Millisecond*10)
+	svr, err := peerServerRepo.Get(ctx, svrAddr)
+	require.NoError(t, err)
+	assert.Equal(t, "Relayed Server", svr.Info.Hostname)
+	assert.True(t, svr.HasDiscoveryStatus(ds.Master))
+
+	inst, err := peerInstanceRepo.Get(ctx, instance.MustNewID(instanceID))
+	require.NoError(t, err)
+	assert.Equal(t, svrAddr.GetIP(), inst.Addr.GetIP())
+
+	// the reply of the peer is drained by the relay, the relayed request is not relayed back
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(relayCollector.RelayReplies.WithLabelValues("127.0.0.1:33812")) == 1
+	}, time.Millisecond*500, time.Millisecond*10)
+	assert.InDelta(t, 1, testutil.ToFloat64(peerCollector.ReporterRelayed), 1e-9)
+	assert.InDelta(t, 0, testutil.ToFloat64(peerCollector.RelayForwarded.WithLabelValues("127.0.0.1:33811")), 1e-9)
+	assert.InDelta(t, 0, testutil.ToFloat64(relayCollector.ReporterRelayed), 1e-9)
+}
+
+func TestReporter_Relay_UntrustedSource(t *testing.T) {
+	var collector *metrics.Collector
+	var serverRepo repositories.ServerRepository
+
+	ctx := context.TODO()
+	app, cancel := makeAppWithRelayingReporter(
+		"127.0.0.1:33812",
+		nil,
+		[]string{"10.0.0.1"},
+		fx.Populate(&collector, &serverRepo),
+	)
+	defer cancel()
+	app.Start(ctx) //nolint: errcheck
+
+	client := tu.NewUDPClient("127.0.0.1:33812", 1024, time.Millisecond*10)
+	defer client.Close()
+
+	src := &net.UDPAddr{IP: net.ParseIP("1.1.1.1"), Port: 10481}
+	reportReq := tu.PackHeartbeatRequest([]byte{0xfe, 0xed, 0xf0, 0x0d}, tu.GenServerParams())
+	_, err := client.Send(relay.Wrap(src, reportReq))
+	require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+
+	count, err := serverRepo.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+	assert.InDelta(t, 0, testutil.ToFloat64(collector.ReporterRelayed), 1e-9)
+}
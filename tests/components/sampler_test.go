@@ -0,0 +1,223 @@
+package components_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+
+	"github.com/sergeii/swat4master/cmd/swat4master/application"
+	"github.com/sergeii/swat4master/cmd/swat4master/components/sampler"
+	"github.com/sergeii/swat4master/internal/core/entities/addr"
+	ds "github.com/sergeii/swat4master/internal/core/entities/discovery/status"
+	"github.com/sergeii/swat4master/internal/core/entities/history"
+	"github.com/sergeii/swat4master/internal/core/entities/server"
+	"github.com/sergeii/swat4master/internal/core/repositories"
+	tu "github.com/sergeii/swat4master/internal/testutils"
+	"github.com/sergeii/swat4master/internal/testutils/factories/serverfactory"
+	"github.com/sergeii/swat4master/tests/testapp"
+)
+
+func makeAppWithSampler(extra ...fx.Option) (*fx.App, func()) {
+	fxopts := make([]fx.Option, 0, 8+len(extra))
+	fxopts = append(fxopts,
+		fx.Provide(testapp.NoLogging),
+		fx.Provide(testapp.ProvideSettings),
+		fx.Provide(testapp.ProvidePersistence),
+		application.Module,
+		fx.Supply(sampler.Config{
+			SampleInterval:       time.Millisecond * 100,
+			AvailabilityInterval: time.Millisecond * 100,
+			DuplicatesInterval:   time.Millisecond * 100,
+		}),
+		sampler.Module,
+		fx.NopLogger,
+		fx.Invoke(func(*sampler.Component) {}),
+	)
+	fxopts = append(fxopts, extra...)
+	app := fx.New(fxopts...)
+	return app, func() {
+		app.Stop(context.TODO()) //nolint: errcheck
+	}
+}
+
+func TestSampler_OK(t *testing.T) {
+	var serverRepo repositories.ServerRepository
+	var historyRepo repositories.HistoryRepository
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	online := serverfactory.Build(
+		serverfactory.WithAddress("1.1.1.1", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Master|ds.Info|ds.Details|ds.Port),
+		serverfactory.WithPlayers([]map[string]string{{"player": "Serge"}, {"player": "Mosquito"}}),
+	)
+	offline := serverfactory.Build(
+		serverfactory.WithAddress("2.2.2.2", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Master|ds.NoDetails),
+	)
+	undiscovered := serverfactory.Build(
+		serverfactory.WithAddress("3.3.3.3", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Master),
+	)
+
+	app, cancel := makeAppWithSampler(
+		fx.Populate(&serverRepo, &historyRepo),
+	)
+	defer cancel()
+
+	for _, svr := range []*server.Server{&online, &offline, &undiscovered} {
+		*svr = tu.Must(serverRepo.Add(ctx, *svr, repositories.ServerOnConflictIgnore))
+	}
+
+	started := time.Now()
+	app.Start(ctx) //nolint: errcheck
+
+	// let sampler run a couple of cycles
+	<-time.After(time.Millisecond * 250)
+
+	from, to := started.Add(-time.Minute), time.Now().Add(time.Minute)
+
+	points, err := historyRepo.Range(ctx, online.Addr, history.Raw, from, to)
+	require.NoError(t, err)
+	require.NotEmpty(t, points)
+	samples := 0
+	for _, pt := range points {
+		samples += pt.Samples
+		assert.InDelta(t, 1.0, pt.Uptime(), 1e-9)
+		assert.Equal(t, "A-Bomb Nightclub", pt.MapName)
+	}
+	assert.GreaterOrEqual(t, samples, 2)
+
+	points, err = historyRepo.Range(ctx, offline.Addr, history.Hourly, from, to)
+	require.NoError(t, err)
+	require.NotEmpty(t, points)
+	for _, pt := range points {
+		assert.InDelta(t, 0.0, pt.Uptime(), 1e-9)
+	}
+
+	points, err = historyRepo.Range(ctx, undiscovered.Addr, history.Raw, from, to)
+	require.NoError(t, err)
+	assert.Empty(t, points)
+}
+
+func TestSampler_TracksAvailability(t *testing.T) {
+	var serverRepo repositories.ServerRepository
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	now := time.Now()
+	online := serverfactory.Build(
+		serverfactory.WithAddress("1.1.1.1", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Master|ds.Info|ds.Details|ds.Port),
+		serverfactory.WithRefreshedAt(now),
+	)
+	silent := serverfactory.Build(
+		serverfactory.WithAddress("2.2.2.2", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Master|ds.Info|ds.Details|ds.Port),
+		serverfactory.WithRefreshedAt(now.Add(-time.Minute*10)),
+	)
+	failed := serverfactory.Build(
+		serverfactory.WithAddress("3.3.3.3", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Master|ds.NoDetails),
+		serverfactory.WithRefreshedAt(now),
+	)
+	undiscovered := serverfactory.Build(
+		serverfactory.WithAddress("4.4.4.4", 10480),
+		serverfactory.WithDiscoveryStatus(ds.New),
+		serverfactory.WithRefreshedAt(now),
+	)
+
+	app, cancel := makeAppWithSampler(
+		fx.Populate(&serverRepo),
+	)
+	defer cancel()
+
+	for _, svr := range []server.Server{online, silent, failed, undiscovered} {
+		tu.Must(serverRepo.Add(ctx, svr, repositories.ServerOnConflictIgnore))
+	}
+
+	app.Start(ctx) //nolint: errcheck
+
+	// let the availability job run a couple of cycles
+	<-time.After(time.Millisecond * 250)
+
+	svr := tu.Must(serverRepo.Get(ctx, online.Addr))
+	assert.True(t, svr.Availability.IsKnown())
+	assert.False(t, svr.Availability.IsDown())
+	assert.Empty(t, svr.Availability.Outages)
+
+	svr = tu.Must(serverRepo.Get(ctx, silent.Addr))
+	assert.True(t, svr.Availability.IsDown())
+	require.Len(t, svr.Availability.Outages, 1)
+	assert.WithinDuration(t, now.Add(-time.Minute*10), svr.Availability.Outages[0].Start, time.Millisecond)
+
+	svr = tu.Must(serverRepo.Get(ctx, failed.Addr))
+	assert.True(t, svr.Availability.IsDown())
+	require.Len(t, svr.Availability.Outages, 1)
+
+	svr = tu.Must(serverRepo.Get(ctx, undiscovered.Addr))
+	assert.False(t, svr.Availability.IsKnown())
+}
+
+func TestSampler_LinksDuplicates(t *testing.T) {
+	var serverRepo repositories.ServerRepository
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	now := time.Now()
+	players := []map[string]string{{"player": "Serge"}, {"player": "Mosquito"}}
+	reported := serverfactory.Build(
+		serverfactory.WithAddress("1.1.1.1", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Master|ds.Info|ds.Details|ds.Port),
+		serverfactory.WithRefreshedAt(now),
+		serverfactory.WithPlayers(players),
+	)
+	added := serverfactory.Build(
+		serverfactory.WithAddress("2.2.2.2", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Info|ds.Details|ds.Port),
+		serverfactory.WithRefreshedAt(now),
+		serverfactory.WithPlayers(players),
+	)
+	other := serverfactory.Build(
+		serverfactory.WithAddress("3.3.3.3", 10480),
+		serverfactory.WithDiscoveryStatus(ds.Master|ds.Info|ds.Details|ds.Port),
+		serverfactory.WithRefreshedAt(now),
+		serverfactory.WithPlayers([]map[string]string{{"player": "Serge"}}),
+	)
+
+	app, cancel := makeAppWithSampler(
+		fx.Populate(&serverRepo),
+	)
+	defer cancel()
+
+	for _, svr := range []server.Server{reported, added, other} {
+		tu.Must(serverRepo.Add(ctx, svr, repositories.ServerOnConflictIgnore))
+	}
+
+	app.Start(ctx) //nolint: errcheck
+
+	// the duplicates job may have to wait for the other jobs updating the same servers
+	require.Eventually(t, func() bool {
+		primary := tu.Must(serverRepo.Get(ctx, reported.Addr))
+		duplicate := tu.Must(serverRepo.Get(ctx, added.Addr))
+		return len(primary.Duplicates) > 0 && duplicate.IsDuplicate()
+	}, time.Second, time.Millisecond*10)
+
+	svr := tu.Must(serverRepo.Get(ctx, reported.Addr))
+	assert.False(t, svr.IsDuplicate())
+	assert.Equal(t, []addr.Addr{added.Addr}, svr.Duplicates)
+
+	svr = tu.Must(serverRepo.Get(ctx, added.Addr))
+	assert.Equal(t, reported.Addr, svr.DuplicateOf)
+
+	svr = tu.Must(serverRepo.Get(ctx, other.Addr))
+	assert.False(t, svr.IsDuplicate())
+	assert.Empty(t, svr.Duplicates)
+}